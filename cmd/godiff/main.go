@@ -0,0 +1,198 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Command godiff compares two files with the diff-match-patch algorithms.
+//
+// Usage:
+//
+//	godiff [flags] FILE1 FILE2
+//	godiff patch make [flags] OLD NEW
+//	godiff patch apply [flags] PATCH [FILE]
+//
+// A file named "-" or a missing FILE is read from standard input.
+// Comparing files exits with status 0 if the files are equal, 1 if they differ and 2 on errors.
+// Applying a patch exits with status 0 if all of its hunks applied, 1 if some of them applied, 3 if none of them applied and 2 on errors.
+// The flag -apply PATCH is a shorthand for godiff patch apply PATCH.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Exit statuses of the command.
+const (
+	exitSame    = 0
+	exitDiffer  = 1
+	exitTrouble = 2
+
+	exitApplied    = 0
+	exitPartial    = 1
+	exitNotApplied = 3
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command with the given arguments and returns its exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 0 && args[0] == "patch" {
+		return runPatch(args[1:], stdin, stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("godiff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	mode := flags.String("mode", "char", "granularity of the diff: line, word or char")
+	format := flags.String("format", "unified", "output format: unified, text, html, json, delta or patch")
+	timeout := flags.Duration("timeout", time.Second, "time to spend on a diff before settling for a coarser one (0 for unlimited)")
+	context := flags.Int("context", 3, "number of context lines of the unified format")
+	apply := flags.String("apply", "", "apply the patch in this file to FILE instead of comparing files")
+	bidi := flags.Bool("bidi", false, "isolate the text of every diff in the text and html formats, for files mixing right-to-left and left-to-right scripts")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: godiff [flags] FILE1 FILE2")
+		fmt.Fprintln(stderr, "       godiff patch make [flags] OLD NEW")
+		fmt.Fprintln(stderr, "       godiff patch apply [flags] PATCH [FILE]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitTrouble
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = *timeout
+	dmp.DiffBidiIsolation = *bidi
+
+	out := bufio.NewWriter(stdout)
+	if *apply != "" {
+		status, err := applyFiles(dmp, out, stderr, *apply, flags.Args(), stdin, diffmatchpatch.DefaultPatchApplyOptions())
+		return finish(out, stderr, status, err)
+	}
+	status, err := diffFiles(dmp, out, flags.Args(), stdin, *mode, *format, *context)
+	return finish(out, stderr, status, err)
+}
+
+// finish flushes the output of a command and returns its exit status, reporting errors.
+func finish(out *bufio.Writer, stderr io.Writer, status int, err error) int {
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "godiff: %v\n", err)
+		return exitTrouble
+	}
+	return status
+}
+
+// diffFiles writes the differences between two files in the given format.
+func diffFiles(dmp *diffmatchpatch.DiffMatchPatch, out io.Writer, names []string, stdin io.Reader, mode, format string, context int) (int, error) {
+	if len(names) != 2 {
+		return exitTrouble, errors.New("expected two files to compare")
+	}
+	if names[0] == "-" && names[1] == "-" {
+		return exitTrouble, errors.New("only one file can be read from standard input")
+	}
+	text1, err := readFile(names[0], stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+	text2, err := readFile(names[1], stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+
+	if format == "unified" {
+		lineDiffs := dmp.DiffLines(text1, text2)
+		if err := writeUnified(dmp, out, names[0], names[1], lineDiffs, context); err != nil {
+			return exitTrouble, err
+		}
+		return diffStatus(text1, text2), nil
+	}
+
+	diffs, err := diffTexts(dmp, text1, text2, mode)
+	if err != nil {
+		return exitTrouble, err
+	}
+	switch format {
+	case "text":
+		_, err = io.WriteString(out, dmp.DiffPrettyText(diffs))
+	case "html":
+		_, err = io.WriteString(out, dmp.DiffPrettyHtml(diffs))
+	case "json":
+		err = writeJSON(out, diffs)
+	case "delta":
+		_, err = fmt.Fprintln(out, dmp.DiffToDelta(diffs))
+	case "patch":
+		_, err = io.WriteString(out, dmp.PatchToText(dmp.PatchMake(text1, diffs)))
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return exitTrouble, err
+	}
+	return diffStatus(text1, text2), nil
+}
+
+// diffTexts diffs two texts at the granularity of the given mode.
+func diffTexts(dmp *diffmatchpatch.DiffMatchPatch, text1, text2, mode string) ([]diffmatchpatch.Diff, error) {
+	switch mode {
+	case "char":
+		return dmp.DiffCleanupSemantic(dmp.DiffMain(text1, text2, true)), nil
+	case "word":
+		return dmp.DiffMainWords(text1, text2), nil
+	case "line":
+		lines1, lines2, lineArray := dmp.DiffLinesToIndexes(text1, text2)
+		return dmp.DiffIndexesToLines(dmp.DiffMainIndexes(lines1, lines2), lineArray), nil
+	}
+	return nil, fmt.Errorf("unknown mode %q", mode)
+}
+
+// jsonDiff is the JSON representation of a diff.
+type jsonDiff struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// writeJSON writes diffs as a JSON array of objects with an operation ("delete", "equal" or "insert") and a text.
+func writeJSON(out io.Writer, diffs []diffmatchpatch.Diff) error {
+	encoded := make([]jsonDiff, len(diffs))
+	for i, aDiff := range diffs {
+		encoded[i] = jsonDiff{Op: strings.ToLower(aDiff.Type.String()), Text: aDiff.Text}
+	}
+	return json.NewEncoder(out).Encode(encoded)
+}
+
+// readFile reads a whole file, or standard input if the name is "-".
+func readFile(name string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	return string(data), err
+}
+
+// diffStatus returns the exit status for comparing two texts.
+func diffStatus(text1, text2 string) int {
+	if text1 == text2 {
+		return exitSame
+	}
+	return exitDiffer
+}
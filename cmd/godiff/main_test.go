@@ -0,0 +1,173 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles writes files with the given names and contents to a temporary directory and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "godiff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Args  []string
+		Stdin string
+
+		ExpectedOutput string
+		ExpectedStatus int
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"old":   "The quick brown fox.\n",
+		"new":   "The slow brown dog.\n",
+		"lines": "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n",
+		"edits": "a\nB\nc\nd\ne\nf\ng\nh\nj\nk",
+	})
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "old")
+	newFile := filepath.Join(dir, "new")
+	lines := filepath.Join(dir, "lines")
+	edits := filepath.Join(dir, "edits")
+
+	for i, tc := range []TestCase{
+		{"Equal files", []string{old, old}, "", "", 0},
+		{"Unified", []string{"-context", "1", lines, edits}, "", "--- " + lines + "\n+++ " + edits + "\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8,3 +8,3 @@\n h\n-i\n j\n+k\n\\ No newline at end of file\n", 1},
+		{"Unified hunks merged", []string{"-context", "3", lines, edits}, "", "--- " + lines + "\n+++ " + edits + "\n@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n j\n+k\n\\ No newline at end of file\n", 1},
+		{"Standard input", []string{"-format", "delta", "-", newFile}, "The quick brown fox.\n", "=4\t-5\t+slow\t=7\t-3\t+dog\t=2\n", 1},
+		{"Char JSON", []string{"-format", "json", old, newFile}, "", `[{"op":"equal","text":"The "},{"op":"delete","text":"quick"},{"op":"insert","text":"slow"},{"op":"equal","text":" brown "},{"op":"delete","text":"fox"},{"op":"insert","text":"dog"},{"op":"equal","text":".\n"}]` + "\n", 1},
+		{"Word delta", []string{"-format", "delta", "-mode", "word", old, newFile}, "", "=4\t-5\t+slow\t=7\t-3\t+dog\t=2\n", 1},
+		{"Line delta", []string{"-format", "delta", "-mode", "line", old, newFile}, "", "-21\t+The slow brown dog.%0A\n", 1},
+		{"HTML", []string{"-format", "html", "-mode", "line", old, newFile}, "", `<del style="background:#ffe6e6;">The quick brown fox.&para;<br></del><ins style="background:#e6ffe6;">The slow brown dog.&para;<br></ins>`, 1},
+		{"Bidirectional HTML", []string{"-format", "html", "-mode", "line", "-bidi", old, newFile}, "", `<del style="background:#ffe6e6;"><bdi>The quick brown fox.&para;<br></bdi></del><ins style="background:#e6ffe6;"><bdi>The slow brown dog.&para;<br></bdi></ins>`, 1},
+		{"Patch", []string{"-format", "patch", "-mode", "line", old, newFile}, "", "@@ -1,21 +1,20 @@\n-The quick brown fox.%0A\n+The slow brown dog.%0A\n", 1},
+		{"Unknown format", []string{"-format", "xml", old, newFile}, "", "", 2},
+		{"Unknown mode", []string{"-format", "json", "-mode", "byte", old, newFile}, "", "", 2},
+		{"Missing file", []string{old}, "", "", 2},
+		{"Unreadable file", []string{old, filepath.Join(dir, "missing")}, "", "", 2},
+		{"Two standard inputs", []string{"-", "-"}, "", "", 2},
+		{"Unknown flag", []string{"-color", old, newFile}, "", "", 2},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+		assert.Equal(t, tc.ExpectedStatus, status, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedOutput, stdout.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedStatus == 2, stderr.Len() != 0, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestRunApply(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Args  []string
+		Stdin string
+
+		ExpectedOutput string
+		ExpectedStatus int
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"old":     "The quick brown fox.\n",
+		"other":   "Something else entirely.\n",
+		"patch":   "@@ -1,21 +1,20 @@\n The \n-quick\n+slow\n  brown \n-fox\n+dog\n .%0A\n",
+		"garbage": "not a patch\n",
+	})
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "old")
+	patch := filepath.Join(dir, "patch")
+
+	for i, tc := range []TestCase{
+		{"Applied", []string{"-apply", patch, old}, "", "The slow brown dog.\n", 0},
+		{"Standard input", []string{"-apply", patch}, "The quick brown fox.\n", "The slow brown dog.\n", 0},
+		{"Not applied", []string{"-apply", patch, filepath.Join(dir, "other")}, "", "Something else entirely.\n", 3},
+		{"Invalid patch", []string{"-apply", filepath.Join(dir, "garbage"), old}, "", "", 2},
+		{"Too many files", []string{"-apply", patch, old, old}, "", "", 2},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+		assert.Equal(t, tc.ExpectedStatus, status, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedOutput, stdout.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestRunPatch(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Args  []string
+		Stdin string
+
+		ExpectedOutput string
+		ExpectedErrors string
+		ExpectedStatus int
+	}
+
+	filler := strings.Repeat("Lorem ipsum dolor sit amet.\n", 4)
+	dir := writeFiles(t, map[string]string{
+		"old":     "The quick brown fox.\n" + filler + "Jumps over the lazy dog.\n",
+		"new":     "The slow brown fox.\n" + filler + "Jumps over the sleepy dog.\n",
+		"partial": "The quick brown fox.\n" + filler + "Something else entirely.\n",
+		"other":   "Something else entirely.\n",
+	})
+	defer os.RemoveAll(dir)
+	old := filepath.Join(dir, "old")
+	newFile := filepath.Join(dir, "new")
+
+	var patch bytes.Buffer
+	assert.Equal(t, 0, run([]string{"patch", "make", "-mode", "word", old, newFile}, nil, &patch, ioutil.Discard))
+	assert.Equal(t, "@@ -1,13 +1,12 @@\n The \n-quick\n+slow\n  bro\n@@ -144,12 +144,14 @@\n the \n-lazy\n+sleepy\n  dog\n", patch.String())
+	patchFile := filepath.Join(dir, "patch")
+	if err := ioutil.WriteFile(patchFile, patch.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []TestCase{
+		{"Make from standard input", []string{"patch", "make", "-mode", "word", "-", newFile}, "The quick brown fox.\n" + filler + "Jumps over the lazy dog.\n", patch.String(), "", 0},
+		{"Make without new file", []string{"patch", "make", old}, "", "", "godiff: expected the old and the new file\n", 2},
+		{"Applied", []string{"patch", "apply", patchFile, old}, "", "The slow brown fox.\n" + filler + "Jumps over the sleepy dog.\n", "", 0},
+		{"Applied to standard input", []string{"patch", "apply", patchFile}, "The quick brown fox.\n" + filler + "Jumps over the lazy dog.\n", "The slow brown fox.\n" + filler + "Jumps over the sleepy dog.\n", "", 0},
+		{"Partially applied", []string{"patch", "apply", patchFile, filepath.Join(dir, "partial")}, "", "The slow brown fox.\n" + filler + "Something else entirely.\n", "godiff: hunk 2 of 2 does not apply\n", 1},
+		{"All or nothing", []string{"patch", "apply", "-all-or-nothing", patchFile, filepath.Join(dir, "partial")}, "", "The quick brown fox.\n" + filler + "Something else entirely.\n", "godiff: hunk 2 of 2 does not apply\n", 3},
+		{"Not applied", []string{"patch", "apply", patchFile, filepath.Join(dir, "other")}, "", "Something else entirely.\n", "godiff: hunk 1 of 2 does not apply\ngodiff: hunk 2 of 2 does not apply\n", 3},
+		{"Exact context", []string{"patch", "apply", "-fuzz", "0", patchFile}, "The quick brown fox.\n" + filler + "Jumps over the lazy  dog.\n", "The slow brown fox.\n" + filler + "Jumps over the lazy  dog.\n", "godiff: hunk 2 of 2 does not apply\n", 1},
+		{"Exact match", []string{"patch", "apply", "-match", "exact", patchFile}, "The quick brown fox.\n" + filler + "Jumps over the lazy  dog.\n", "The slow brown fox.\n" + filler + "Jumps over the lazy  dog.\n", "godiff: hunk 2 of 2 does not apply\n", 1},
+		{"Levenshtein match", []string{"patch", "apply", "-match", "levenshtein", patchFile}, "The quick brown fox.\n" + filler + "Jumps over the lazy  dog.\n", "The slow brown fox.\n" + filler + "Jumps over the sleepy  dog.\n", "", 0},
+		{"Unknown match", []string{"patch", "apply", "-match", "regexp", patchFile}, "", "", "godiff: unknown match algorithm \"regexp\"\n", 2},
+		{"Missing patch", []string{"patch", "apply"}, "", "", "usage: godiff patch apply [flags] PATCH [FILE]\n", 2},
+		{"Unknown command", []string{"patch", "revert"}, "", "", "godiff: unknown patch command \"revert\"\nusage: godiff patch make [flags] OLD NEW\n       godiff patch apply [flags] PATCH [FILE]\n", 2},
+	} {
+		var stdout, stderr bytes.Buffer
+		status := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+		assert.Equal(t, tc.ExpectedStatus, status, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedOutput, stdout.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, strings.HasPrefix(stderr.String(), tc.ExpectedErrors), fmt.Sprintf("Test case #%d, %s: %q", i, tc.Name, stderr.String()))
+	}
+}
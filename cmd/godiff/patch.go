@@ -0,0 +1,166 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// runPatch executes a patch subcommand and returns its exit status.
+func runPatch(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		switch args[0] {
+		case "make":
+			return runPatchMake(args[1:], stdin, stdout, stderr)
+		case "apply":
+			return runPatchApply(args[1:], stdin, stdout, stderr)
+		}
+		fmt.Fprintf(stderr, "godiff: unknown patch command %q\n", args[0])
+	}
+	fmt.Fprintln(stderr, "usage: godiff patch make [flags] OLD NEW")
+	fmt.Fprintln(stderr, "       godiff patch apply [flags] PATCH [FILE]")
+	return exitTrouble
+}
+
+// runPatchMake writes the patch which turns one file into another.
+func runPatchMake(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("godiff patch make", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	mode := flags.String("mode", "char", "granularity of the diff: line, word or char")
+	timeout := flags.Duration("timeout", time.Second, "time to spend on a diff before settling for a coarser one (0 for unlimited)")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: godiff patch make [flags] OLD NEW")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitTrouble
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = *timeout
+
+	out := bufio.NewWriter(stdout)
+	err := makePatch(dmp, out, flags.Args(), stdin, *mode)
+	return finish(out, stderr, exitSame, err)
+}
+
+// makePatch writes the patch which turns one file into another.
+func makePatch(dmp *diffmatchpatch.DiffMatchPatch, out io.Writer, names []string, stdin io.Reader, mode string) error {
+	if len(names) != 2 {
+		return errors.New("expected the old and the new file")
+	}
+	if names[0] == "-" && names[1] == "-" {
+		return errors.New("only one file can be read from standard input")
+	}
+	text1, err := readFile(names[0], stdin)
+	if err != nil {
+		return err
+	}
+	text2, err := readFile(names[1], stdin)
+	if err != nil {
+		return err
+	}
+	diffs, err := diffTexts(dmp, text1, text2, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, dmp.PatchToText(dmp.PatchMake(text1, diffs)))
+	return err
+}
+
+// runPatchApply applies a patch file to a file and writes the result.
+func runPatchApply(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts := diffmatchpatch.DefaultPatchApplyOptions()
+	flags := flag.NewFlagSet("godiff patch apply", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.IntVar(&opts.Fuzz, "fuzz", opts.Fuzz, "maximum number of edits between the expected and the actual context of a hunk (-1 to match loosely)")
+	flags.IntVar(&opts.MaxOffset, "max-offset", opts.MaxOffset, "maximum number of bytes a hunk may be moved from its expected location (-1 for no limit)")
+	flags.BoolVar(&opts.AllOrNothing, "all-or-nothing", opts.AllOrNothing, "write the unmodified file unless all hunks apply")
+	strategy := flags.String("match", opts.Strategy.String(), "algorithm locating the hunks: bitap, exact or levenshtein")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: godiff patch apply [flags] PATCH [FILE]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitTrouble
+	}
+	switch *strategy {
+	case diffmatchpatch.MatchStrategyBitap.String():
+		opts.Strategy = diffmatchpatch.MatchStrategyBitap
+	case diffmatchpatch.MatchStrategyExact.String():
+		opts.Strategy = diffmatchpatch.MatchStrategyExact
+	case diffmatchpatch.MatchStrategyLevenshtein.String():
+		opts.Strategy = diffmatchpatch.MatchStrategyLevenshtein
+	default:
+		fmt.Fprintf(stderr, "godiff: unknown match algorithm %q\n", *strategy)
+		return exitTrouble
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitTrouble
+	}
+
+	dmp := diffmatchpatch.New()
+	out := bufio.NewWriter(stdout)
+	status, err := applyFiles(dmp, out, stderr, flags.Arg(0), flags.Args()[1:], stdin, opts)
+	return finish(out, stderr, status, err)
+}
+
+// applyFiles applies a patch file to a file, writes the result and reports the hunks which did not apply.
+func applyFiles(dmp *diffmatchpatch.DiffMatchPatch, out, stderr io.Writer, patchName string, names []string, stdin io.Reader, opts diffmatchpatch.PatchApplyOptions) (int, error) {
+	if len(names) > 1 {
+		return exitTrouble, errors.New("expected at most one file to patch")
+	}
+	name := "-"
+	if len(names) == 1 {
+		name = names[0]
+	}
+	if patchName == "-" && name == "-" {
+		return exitTrouble, errors.New("only one file can be read from standard input")
+	}
+	patchText, err := readFile(patchName, stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+	patches, err := dmp.PatchFromText(patchText)
+	if err != nil {
+		return exitTrouble, fmt.Errorf("%s: %v", patchName, err)
+	}
+	text, err := readFile(name, stdin)
+	if err != nil {
+		return exitTrouble, err
+	}
+
+	// An error only tells that the text was left unmodified in all-or-nothing mode.
+	patched, applied, notApplied := dmp.PatchApplyWithOptions(patches, text, opts)
+	if _, err := io.WriteString(out, patched); err != nil {
+		return exitTrouble, err
+	}
+	failed := 0
+	for i, ok := range applied {
+		if !ok {
+			failed++
+			fmt.Fprintf(stderr, "godiff: hunk %d of %d does not apply\n", i+1, len(applied))
+		}
+	}
+	switch {
+	case failed == 0:
+		return exitApplied, nil
+	case failed == len(applied) || notApplied != nil:
+		return exitNotApplied, nil
+	}
+	return exitPartial, nil
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// writeUnified writes a line diff in the unified format of diff -u, with the given number of context lines around every change.
+func writeUnified(dmp *diffmatchpatch.DiffMatchPatch, out io.Writer, name1, name2 string, lineDiffs []diffmatchpatch.LineDiff, context int) error {
+	patches := dmp.LinePatchMake(lineDiffs, context)
+	if len(patches) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(out, "--- %s\n+++ %s\n%s", name1, name2, dmp.LinePatchToText(patches))
+	return err
+}
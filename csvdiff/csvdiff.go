@@ -0,0 +1,328 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package csvdiff compares CSV and TSV tables row by row and cell by cell, so that an inserted row does not misalign the rest of the table as in a diff of the text.
+package csvdiff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ChangeType is the kind of a change between two tables.
+type ChangeType int8
+
+const (
+	// Inserted is a row which only the second table has.
+	Inserted ChangeType = iota
+	// Deleted is a row which only the first table has.
+	Deleted
+	// Modified is a row of the first table whose cells differ in the second table.
+	Modified
+)
+
+// String returns a human readable name of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Inserted:
+		return "Inserted"
+	case Deleted:
+		return "Deleted"
+	case Modified:
+		return "Modified"
+	}
+	return fmt.Sprintf("ChangeType(%d)", t)
+}
+
+// RowChange is one difference between two tables.
+type RowChange struct {
+	Type ChangeType
+	// Index of the row in the first table, -1 if it was inserted.
+	Row1 int
+	// Index of the row in the second table, -1 if it was deleted.
+	Row2 int
+	// The row in the first table, nil if it was inserted.
+	Old []string
+	// The row in the second table, nil if it was deleted.
+	New []string
+	// The cells which differ, if the row was modified.
+	Cells []CellChange
+}
+
+// CellChange is a cell of a modified row.
+type CellChange struct {
+	// Index of the column of the cell.
+	Column int
+	// The cell in the first table, "" if the row was shorter.
+	Old string
+	// The cell in the second table, "" if the row is shorter.
+	New string
+	// Differences between the texts of the cell.
+	Diffs []diffmatchpatch.Diff
+}
+
+// String returns the change on one line, e.g. `Modified row 2 -> 3: column 1 "a" -> "b"`.
+func (c RowChange) String() string {
+	switch c.Type {
+	case Inserted:
+		return fmt.Sprintf("%v row %d: %q", c.Type, c.Row2, c.New)
+	case Deleted:
+		return fmt.Sprintf("%v row %d: %q", c.Type, c.Row1, c.Old)
+	}
+	cells := make([]string, len(c.Cells))
+	for i, cell := range c.Cells {
+		cells[i] = fmt.Sprintf("column %d %q -> %q", cell.Column, cell.Old, cell.New)
+	}
+	return fmt.Sprintf("%v row %d -> %d: %s", c.Type, c.Row1, c.Row2, strings.Join(cells, ", "))
+}
+
+// Options controls how tables are parsed and how their rows are aligned.
+type Options struct {
+	// Field delimiter, 0 for ',' or '\t' for TSV.
+	Comma rune
+	// Columns which identify the rows, e.g. an ID column, nil to align the rows by their content.
+	KeyColumns []int
+	// Minimum fraction of equal cells for rows which are not aligned by key to be reported as modified rather than deleted and inserted, 0 for 0.5.
+	Threshold float64
+}
+
+// parse reads the rows of a table.  Rows may have different numbers of cells.
+func parse(table []byte, opts Options) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(table))
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// Compare parses two tables and returns the rows which differ between them.
+func Compare(table1, table2 []byte, opts Options) ([]RowChange, error) {
+	rows1, err := parse(table1, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid first table: %v", err)
+	}
+	rows2, err := parse(table2, opts)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid second table: %v", err)
+	}
+	return CompareRows(rows1, rows2, opts), nil
+}
+
+// CompareRows returns the rows which differ between two tables already parsed, ordered by their position in the second table, with deleted rows after the row preceding them in both tables.
+// With KeyColumns, rows with the same key are aligned wherever they are, and rows with duplicate keys are aligned in order.  Otherwise, the tables are diffed row by row, and among the rows deleted and inserted at the same place, the most similar ones are reported as modified.
+func CompareRows(rows1, rows2 [][]string, opts Options) []RowChange {
+	// pairs[i] is the row of the second table aligned with the i-th row of the first table, or -1.
+	var pairs []int
+	if opts.KeyColumns != nil {
+		pairs = alignByKey(rows1, rows2, opts.KeyColumns)
+	} else {
+		threshold := opts.Threshold
+		if threshold <= 0 {
+			threshold = 0.5
+		}
+		pairs = alignByContent(rows1, rows2, threshold)
+	}
+
+	type sortedChange struct {
+		position int
+		change   RowChange
+	}
+	var changes []sortedChange
+	paired := make([]bool, len(rows2))
+	// Position in the second table after the last aligned row.
+	position := 0
+	for i, j := range pairs {
+		if j == -1 {
+			changes = append(changes, sortedChange{2*position - 1, RowChange{Type: Deleted, Row1: i, Row2: -1, Old: rows1[i]}})
+			continue
+		}
+		paired[j] = true
+		position = j + 1
+		if cells := compareCells(rows1[i], rows2[j]); len(cells) != 0 {
+			changes = append(changes, sortedChange{2 * j, RowChange{Type: Modified, Row1: i, Row2: j, Old: rows1[i], New: rows2[j], Cells: cells}})
+		}
+	}
+	for j := range rows2 {
+		if !paired[j] {
+			changes = append(changes, sortedChange{2 * j, RowChange{Type: Inserted, Row1: -1, Row2: j, New: rows2[j]}})
+		}
+	}
+	sort.SliceStable(changes, func(a, b int) bool {
+		return changes[a].position < changes[b].position
+	})
+
+	result := make([]RowChange, len(changes))
+	for i, c := range changes {
+		result[i] = c.change
+	}
+	return result
+}
+
+// rowKey returns the key of a row made of the given columns.
+func rowKey(row []string, columns []int) string {
+	key := make([]string, len(columns))
+	for i, column := range columns {
+		if column < len(row) {
+			key[i] = row[column]
+		}
+	}
+	return strings.Join(key, "\x00")
+}
+
+// alignByKey aligns the rows of two tables which have the same key.
+func alignByKey(rows1, rows2 [][]string, columns []int) []int {
+	// Rows of the second table by key, in order.
+	rowsByKey := map[string][]int{}
+	for j, row := range rows2 {
+		key := rowKey(row, columns)
+		rowsByKey[key] = append(rowsByKey[key], j)
+	}
+	pairs := make([]int, len(rows1))
+	for i, row := range rows1 {
+		key := rowKey(row, columns)
+		if candidates := rowsByKey[key]; len(candidates) != 0 {
+			pairs[i] = candidates[0]
+			rowsByKey[key] = candidates[1:]
+		} else {
+			pairs[i] = -1
+		}
+	}
+	return pairs
+}
+
+// alignByContent aligns the equal rows of two tables found by a diff, and the most similar of the rows deleted and inserted at the same place.
+func alignByContent(rows1, rows2 [][]string, threshold float64) []int {
+	indexes := map[string]int{}
+	toIndexes := func(rows [][]string) []int {
+		result := make([]int, len(rows))
+		for i, row := range rows {
+			key := strings.Join(row, "\x00") + "\x00" + fmt.Sprint(len(row))
+			index, ok := indexes[key]
+			if !ok {
+				index = len(indexes)
+				indexes[key] = index
+			}
+			result[i] = index
+		}
+		return result
+	}
+	indexes1 := toIndexes(rows1)
+	indexes2 := toIndexes(rows2)
+
+	pairs := make([]int, len(rows1))
+	i, j := 0, 0
+	diffs := diffmatchpatch.New().DiffMainIndexes(indexes1, indexes2)
+	for d := 0; d < len(diffs); d++ {
+		n := len(diffs[d].Indexes)
+		switch diffs[d].Type {
+		case diffmatchpatch.DiffEqual:
+			for k := 0; k < n; k++ {
+				pairs[i+k] = j + k
+			}
+			i += n
+			j += n
+		case diffmatchpatch.DiffInsert:
+			j += n
+		case diffmatchpatch.DiffDelete:
+			inserted := 0
+			if d+1 < len(diffs) && diffs[d+1].Type == diffmatchpatch.DiffInsert {
+				inserted = len(diffs[d+1].Indexes)
+				d++
+			}
+			alignSimilar(rows1[i:i+n], rows2[j:j+inserted], threshold, pairs[i:i+n], j)
+			i += n
+			j += inserted
+		}
+	}
+	return pairs
+}
+
+// alignSimilar pairs deleted with inserted rows in order so that the sum of the similarities of the pairs is the largest, ignoring pairs less similar than threshold.  The pairs are stored in pairs, offset by the index of the first inserted row.
+func alignSimilar(deleted, inserted [][]string, threshold float64, pairs []int, offset int) {
+	// score[a][b] is the best sum for the first a deleted and the first b inserted rows.
+	score := make([][]float64, len(deleted)+1)
+	for a := range score {
+		score[a] = make([]float64, len(inserted)+1)
+	}
+	for a := 1; a <= len(deleted); a++ {
+		for b := 1; b <= len(inserted); b++ {
+			score[a][b] = maxFloat(score[a-1][b], score[a][b-1])
+			if s := similarity(deleted[a-1], inserted[b-1]); s >= threshold {
+				score[a][b] = maxFloat(score[a][b], score[a-1][b-1]+s)
+			}
+		}
+	}
+	for a := range pairs {
+		pairs[a] = -1
+	}
+	for a, b := len(deleted), len(inserted); a > 0 && b > 0; {
+		switch {
+		case score[a][b] == score[a-1][b]:
+			a--
+		case score[a][b] == score[a][b-1]:
+			b--
+		default:
+			pairs[a-1] = offset + b - 1
+			a--
+			b--
+		}
+	}
+}
+
+// similarity returns the fraction of the cells of two rows which are equal.
+func similarity(row1, row2 []string) float64 {
+	n := len(row1)
+	if len(row2) > n {
+		n = len(row2)
+	}
+	if n == 0 {
+		return 1
+	}
+	equal := 0
+	for k := 0; k < len(row1) && k < len(row2); k++ {
+		if row1[k] == row2[k] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(n)
+}
+
+// compareCells returns the cells which differ between two rows, missing cells being empty.
+func compareCells(row1, row2 []string) []CellChange {
+	var cells []CellChange
+	dmp := diffmatchpatch.New()
+	for k := 0; k < len(row1) || k < len(row2); k++ {
+		var cell1, cell2 string
+		if k < len(row1) {
+			cell1 = row1[k]
+		}
+		if k < len(row2) {
+			cell2 = row2[k]
+		}
+		// A missing cell differs even from an empty one.
+		missing := k >= len(row1) || k >= len(row2)
+		if cell1 != cell2 || missing {
+			cells = append(cells, CellChange{Column: k, Old: cell1, New: cell2, Diffs: dmp.DiffCleanupSemantic(dmp.DiffMain(cell1, cell2, false))})
+		}
+	}
+	return cells
+}
+
+// maxFloat returns the larger of two numbers.
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
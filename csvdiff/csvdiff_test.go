@@ -0,0 +1,93 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package csvdiff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Table1 string
+		Table2 string
+		Opts   Options
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Equal", "id,name\n1,a\n2,b\n", "id,name\n1,a\n2,b\n", Options{}, []string{}},
+		{"Inserted row", "id,name\n1,a\n2,b\n", "id,name\n1,a\n3,c\n2,b\n", Options{}, []string{`Inserted row 2: ["3" "c"]`}},
+		{"Deleted row", "id,name\n1,a\n2,b\n3,c\n", "id,name\n1,a\n3,c\n", Options{}, []string{`Deleted row 2: ["2" "b"]`}},
+		{"Modified cell", "id,name,age\n1,alice,30\n2,bob,40\n", "id,name,age\n1,alice,31\n2,bob,40\n", Options{}, []string{`Modified row 1 -> 1: column 2 "30" -> "31"`}},
+		{"Modified after inserted row", "id,name,age\n1,alice,30\n2,bob,40\n", "id,name,age\n0,zoe,20\n1,alice,30\n2,bob,41\n", Options{}, []string{`Inserted row 1: ["0" "zoe" "20"]`, `Modified row 2 -> 3: column 2 "40" -> "41"`}},
+		{"Dissimilar rows", "1,a,x\n", "2,b,y\n", Options{}, []string{`Deleted row 0: ["1" "a" "x"]`, `Inserted row 0: ["2" "b" "y"]`}},
+		{"Similar rows among others", "1,a,x\n2,b,y\n", "3,c,z\n2,b,w\n", Options{}, []string{`Deleted row 0: ["1" "a" "x"]`, `Inserted row 0: ["3" "c" "z"]`, `Modified row 1 -> 1: column 2 "y" -> "w"`}},
+		{"Lower threshold", "1,a,x\n", "1,b,y\n", Options{Threshold: 0.3}, []string{`Modified row 0 -> 0: column 1 "a" -> "b", column 2 "x" -> "y"`}},
+		{"Added column", "1,a\n", "1,a,\n", Options{}, []string{`Modified row 0 -> 0: column 2 "" -> ""`}},
+		{"Moved row by key", "id,name\n1,a\n2,b\n3,c\n", "id,name\n3,c\n1,a\n2,x\n", Options{KeyColumns: []int{0}}, []string{`Modified row 2 -> 3: column 1 "b" -> "x"`}},
+		{"Inserted and deleted by key", "id,name\n1,a\n2,b\n", "id,name\n1,a\n3,b\n", Options{KeyColumns: []int{0}}, []string{`Deleted row 2: ["2" "b"]`, `Inserted row 2: ["3" "b"]`}},
+		{"Duplicate keys", "k,v\nx,1\nx,2\n", "k,v\nx,1\nx,3\n", Options{KeyColumns: []int{0}}, []string{`Modified row 2 -> 2: column 1 "2" -> "3"`}},
+		{"Composite key", "a,b,v\n1,1,x\n1,2,y\n", "a,b,v\n1,2,y\n1,1,z\n", Options{KeyColumns: []int{0, 1}}, []string{`Modified row 1 -> 2: column 2 "x" -> "z"`}},
+		{"TSV", "id\tname\n1\ta,b\n", "id\tname\n1\ta;b\n", Options{Comma: '\t'}, []string{`Modified row 1 -> 1: column 1 "a,b" -> "a;b"`}},
+		{"Quoted cells", "1,\"a, b\"\n", "1,\"a, c\"\n", Options{}, []string{`Modified row 0 -> 0: column 1 "a, b" -> "a, c"`}},
+	} {
+		changes, err := Compare([]byte(tc.Table1), []byte(tc.Table2), tc.Opts)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual := []string{}
+		for _, change := range changes {
+			actual = append(actual, change.String())
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestCompareCells(t *testing.T) {
+	changes, err := Compare([]byte("1,The quick fox\n"), []byte("1,The slow fox\n"), Options{})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, RowChange{
+			Type: Modified,
+			Row1: 0,
+			Row2: 0,
+			Old:  []string{"1", "The quick fox"},
+			New:  []string{"1", "The slow fox"},
+			Cells: []CellChange{{1, "The quick fox", "The slow fox", []diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "The "},
+				{Type: diffmatchpatch.DiffDelete, Text: "quick"},
+				{Type: diffmatchpatch.DiffInsert, Text: "slow"},
+				{Type: diffmatchpatch.DiffEqual, Text: " fox"},
+			}}},
+		}, changes[0])
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	_, err := Compare([]byte("a,\"b\n"), []byte("a\n"), Options{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid first table: ")
+	}
+	_, err = Compare([]byte("a\n"), []byte("a,b\"c\n"), Options{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Invalid second table: ")
+	}
+}
+
+func TestChangeType(t *testing.T) {
+	assert.Equal(t, "Inserted", Inserted.String())
+	assert.Equal(t, "Deleted", Deleted.String())
+	assert.Equal(t, "Modified", Modified.String())
+	assert.Equal(t, "ChangeType(7)", ChangeType(7).String())
+}
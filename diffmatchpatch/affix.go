@@ -0,0 +1,93 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode/utf8"
+)
+
+// CommonPrefixRunes returns the number of runes at the start of two rune slices which are equal.
+func CommonPrefixRunes(text1, text2 []rune) int {
+	return commonPrefixLength(text1, text2)
+}
+
+// CommonSuffixRunes returns the number of runes at the end of two rune slices which are equal.
+func CommonSuffixRunes(text1, text2 []rune) int {
+	return commonSuffixLength(text1, text2)
+}
+
+// CommonPrefixGraphemes returns the number of grapheme clusters at the start of two texts which are equal, so that a prefix never ends between a base character and its combining marks.
+// Unlike for rune slices, comparing strings is fast enough for the binary search discussed at https://neil.fraser.name/news/2007/10/09/ to pay off, so the equal bytes are found by it.
+func CommonPrefixGraphemes(text1, text2 string) int {
+	n := runeStart(text1, commonPrefixBytes(text1, text2))
+	// The boundary at n also depends on the rune following it.
+	boundaries1 := graphemeBoundaries(text1[:n+nextRuneLen(text1, n)])
+	boundaries2 := graphemeBoundaries(text2[:n+nextRuneLen(text2, n)])
+	// The prefixes are equal, so both texts have the same boundaries before n. The prefix ends at the last boundary of both.
+	clusters := 0
+	for i := 1; i < len(boundaries1) && i < len(boundaries2) && boundaries1[i] <= n && boundaries2[i] <= n; i++ {
+		clusters = i
+	}
+	return clusters
+}
+
+// CommonSuffixGraphemes returns the number of grapheme clusters at the end of two texts which are equal, found like CommonPrefixGraphemes.
+func CommonSuffixGraphemes(text1, text2 string) int {
+	n := commonSuffixBytes(text1, text2)
+	// Skip continuation bytes of a rune which differs.
+	for n > 0 && !utf8.RuneStart(text1[len(text1)-n]) {
+		n--
+	}
+	// Boundaries depend on the whole text before them, e.g. on the number of regional indicators.
+	boundaries1 := graphemeBoundaries(text1)
+	boundaries2 := graphemeBoundaries(text2)
+	clusters := 0
+	for i, j := len(boundaries1)-2, len(boundaries2)-2; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		m := len(text1) - boundaries1[i]
+		if m > n || len(text2)-boundaries2[j] != m {
+			break
+		}
+		clusters++
+	}
+	return clusters
+}
+
+// nextRuneLen returns the length in bytes of the rune at the byte offset i of text, or 0 at its end.
+func nextRuneLen(text string, i int) int {
+	_, size := utf8.DecodeRuneInString(text[i:])
+	return size
+}
+
+// commonPrefixBytes returns the length in bytes of the common prefix of two strings, found by a binary search.
+func commonPrefixBytes(text1, text2 string) int {
+	low, high := 0, min(len(text1), len(text2))
+	for low < high {
+		mid := low + (high-low+1)/2
+		if text1[low:mid] == text2[low:mid] {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low
+}
+
+// commonSuffixBytes returns the length in bytes of the common suffix of two strings, found by a binary search.
+func commonSuffixBytes(text1, text2 string) int {
+	low, high := 0, min(len(text1), len(text2))
+	for low < high {
+		mid := low + (high-low+1)/2
+		if text1[len(text1)-mid:len(text1)-low] == text2[len(text2)-mid:len(text2)-low] {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low
+}
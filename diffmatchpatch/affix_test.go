@@ -0,0 +1,65 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonAffixes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		ExpectedPrefixRunes     int
+		ExpectedSuffixRunes     int
+		ExpectedPrefixGraphemes int
+		ExpectedSuffixGraphemes int
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty texts", "", "", 0, 0, 0, 0},
+		{"Empty text", "abc", "", 0, 0, 0, 0},
+		{"Null case", "abc", "xyz", 0, 0, 0, 0},
+		{"Equal texts", "abc", "abc", 3, 3, 3, 3},
+		{"Non-null case", "1234abcdef", "1234xyz", 4, 0, 4, 0},
+		{"Whole case", "1234", "1234xyz", 4, 0, 4, 0},
+		{"Suffix", "abcdef1234", "xyz1234", 0, 4, 0, 4},
+		{"Multibyte runes", "日本語", "日本人", 2, 0, 2, 0},
+		{"Runes sharing bytes", "\u00e4", "\u00e5", 0, 0, 0, 0},
+		{"Combining marks", "cafe\u0301s", "cafes", 4, 1, 3, 1},
+		{"Combining mark at the end", "e\u0301", "a\u0301", 0, 1, 0, 0},
+		{"Flags", "\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA", "\U0001F1E9\U0001F1EA", 0, 2, 0, 1},
+		{"Odd regional indicators", "\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA", "\U0001F1F7\U0001F1E9\U0001F1EA", 0, 3, 0, 0},
+	} {
+		runes1, runes2 := []rune(tc.Text1), []rune(tc.Text2)
+		assert.Equal(t, tc.ExpectedPrefixRunes, CommonPrefixRunes(runes1, runes2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedSuffixRunes, CommonSuffixRunes(runes1, runes2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedPrefixGraphemes, CommonPrefixGraphemes(tc.Text1, tc.Text2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedSuffixGraphemes, CommonSuffixGraphemes(tc.Text1, tc.Text2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		// The results are symmetric.
+		assert.Equal(t, tc.ExpectedPrefixGraphemes, CommonPrefixGraphemes(tc.Text2, tc.Text1), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedSuffixGraphemes, CommonSuffixGraphemes(tc.Text2, tc.Text1), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestCommonAffixBytes(t *testing.T) {
+	for i, n := range []int{0, 1, 2, 7, 100, 1000} {
+		a := string(make([]byte, n))
+		assert.Equal(t, n, commonPrefixBytes(a+"x", a+"y"), fmt.Sprintf("Test case #%d", i))
+		assert.Equal(t, n, commonSuffixBytes("x"+a, "y"+a), fmt.Sprintf("Test case #%d", i))
+		assert.Equal(t, n, commonPrefixBytes(a, a+"y"), fmt.Sprintf("Test case #%d", i))
+		assert.Equal(t, n, commonSuffixBytes(a, "y"+a), fmt.Sprintf("Test case #%d", i))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode/utf8"
+)
+
+// AlignmentPair aligns a rune of the first text of a diff with a rune of the second text, or with a gap.
+type AlignmentPair struct {
+	// Index of the rune in the first text, or -1 for an inserted rune.
+	Index1 int
+	// Index of the rune in the second text, or -1 for a deleted rune.
+	Index2 int
+}
+
+// DiffAlignment returns the alignment of the runes of the texts of a diff which DiffLevenshtein counts the edits of, in the order of the texts.
+// Equal runes are aligned with each other.  Between two equalities, the deleted runes are substituted by the inserted runes in order, and the runes left over from the longer side are aligned with gaps.
+func (dmp *DiffMatchPatch) DiffAlignment(diffs []Diff) []AlignmentPair {
+	var pairs []AlignmentPair
+	index1, index2 := 0, 0
+	// Runes deleted and inserted since the last equality.
+	deletions, insertions := 0, 0
+	flush := func() {
+		for k := 0; k < deletions || k < insertions; k++ {
+			pair := AlignmentPair{-1, -1}
+			if k < deletions {
+				pair.Index1 = index1 - deletions + k
+			}
+			if k < insertions {
+				pair.Index2 = index2 - insertions + k
+			}
+			pairs = append(pairs, pair)
+		}
+		deletions, insertions = 0, 0
+	}
+
+	for _, aDiff := range diffs {
+		runes := utf8.RuneCountInString(aDiff.Text)
+		switch aDiff.Type {
+		case DiffDelete:
+			deletions += runes
+			index1 += runes
+		case DiffInsert:
+			insertions += runes
+			index2 += runes
+		case DiffEqual:
+			flush()
+			for k := 0; k < runes; k++ {
+				pairs = append(pairs, AlignmentPair{index1 + k, index2 + k})
+			}
+			index1 += runes
+			index2 += runes
+		}
+	}
+	flush()
+	return pairs
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffAlignment(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []AlignmentPair
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, nil},
+		{"Equality", []Diff{{DiffEqual, "ab"}}, []AlignmentPair{{0, 0}, {1, 1}}},
+		{"Substitution", []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "c"}}, []AlignmentPair{{0, 0}, {1, 1}, {2, 2}}},
+		{"Longer deletion", []Diff{{DiffDelete, "abc"}, {DiffInsert, "x"}, {DiffEqual, "d"}}, []AlignmentPair{{0, 0}, {1, -1}, {2, -1}, {3, 1}}},
+		{"Longer insertion", []Diff{{DiffEqual, "a"}, {DiffInsert, "xyz"}, {DiffDelete, "b"}}, []AlignmentPair{{0, 0}, {1, 1}, {-1, 2}, {-1, 3}}},
+		{"Insertion only", []Diff{{DiffInsert, "xy"}, {DiffEqual, "a"}}, []AlignmentPair{{-1, 0}, {-1, 1}, {0, 2}}},
+		{"Runes", []Diff{{DiffEqual, "日本"}, {DiffDelete, "語"}, {DiffInsert, "人"}}, []AlignmentPair{{0, 0}, {1, 1}, {2, 2}}},
+	} {
+		actual := dmp.DiffAlignment(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// Every pair which is not a match of equal runes is an edit counted by DiffLevenshtein.
+		text1, text2 := []rune(dmp.DiffText1(tc.Diffs)), []rune(dmp.DiffText2(tc.Diffs))
+		edits := 0
+		for _, pair := range actual {
+			if pair.Index1 == -1 || pair.Index2 == -1 || text1[pair.Index1] != text2[pair.Index2] {
+				edits++
+			}
+		}
+		assert.Equal(t, dmp.DiffLevenshtein(tc.Diffs), edits, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
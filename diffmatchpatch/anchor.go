@@ -0,0 +1,39 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+// Anchor is a pair of locations in two texts which a diff has to align, e.g. the starts of the same section in both texts.
+type Anchor struct {
+	// Offset in bytes in the first text.
+	Offset1 int
+	// Offset in bytes in the second text.
+	Offset2 int
+}
+
+// DiffMainWithAnchors finds the differences between two texts, aligning the texts at the given anchors.
+// Anchors must be ordered by their location in both texts, anchors which are not are ignored.
+func (dmp *DiffMatchPatch) DiffMainWithAnchors(text1, text2 string, anchors []Anchor) []Diff {
+	unprocessed := dmp.withoutPostProcess()
+	var diffs []Diff
+	last := Anchor{}
+	for _, anchor := range anchors {
+		anchor.Offset1 = runeStart(text1, anchor.Offset1)
+		anchor.Offset2 = runeStart(text2, anchor.Offset2)
+		if anchor.Offset1 < last.Offset1 || anchor.Offset2 < last.Offset2 || anchor.Offset1 > len(text1) || anchor.Offset2 > len(text2) {
+			continue
+		}
+		diffs = append(diffs, unprocessed.DiffMain(text1[last.Offset1:anchor.Offset1], text2[last.Offset2:anchor.Offset2], true)...)
+		last = anchor
+	}
+	diffs = append(diffs, unprocessed.DiffMain(text1[last.Offset1:], text2[last.Offset2:], true)...)
+	if len(diffs) == 0 {
+		return dmp.postProcessDiffs(diffs)
+	}
+	return dmp.postProcessDiffs(dmp.DiffCleanupMerge(diffs))
+}
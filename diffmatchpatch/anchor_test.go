@@ -0,0 +1,48 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainWithAnchors(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Anchors []Anchor
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	text1 := "## A\nx\n## B\ny\n"
+	text2 := "## B\nx\n## A\ny\n"
+
+	for i, tc := range []TestCase{
+		{"No anchors", "abc", "abd", nil, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Empty texts", "", "", []Anchor{{0, 0}}, nil},
+		{"Unanchored", text1, text2, nil, []Diff{{DiffEqual, "## "}, {DiffDelete, "A"}, {DiffInsert, "B"}, {DiffEqual, "\nx\n## "}, {DiffDelete, "B"}, {DiffInsert, "A"}, {DiffEqual, "\ny\n"}}},
+		{"Anchored", text1, text2, []Anchor{{7, 0}}, []Diff{{DiffDelete, "## A\nx\n"}, {DiffEqual, "## B\n"}, {DiffInsert, "x\n## A\n"}, {DiffEqual, "y\n"}}},
+		{"Out of order anchor is ignored", "abc", "abd", []Anchor{{2, 2}, {1, 3}}, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Anchor out of range is ignored", "abc", "abd", []Anchor{{2, 9}}, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Anchor within a rune", "日本", "本日", []Anchor{{4, 1}}, []Diff{{DiffDelete, "日"}, {DiffEqual, "本"}, {DiffInsert, "日"}}},
+	} {
+		actual := dmp.DiffMainWithAnchors(tc.Text1, tc.Text2, tc.Anchors)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
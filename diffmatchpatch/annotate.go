@@ -0,0 +1,119 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Annotation attributes a part of a text to the version which introduced it.
+type Annotation struct {
+	// The annotated text.
+	Text string
+	// Index of the version which introduced the text.
+	Version int
+}
+
+// Annotate attributes each rune of the last of several versions of a text to the earliest version from which it was kept unchanged, e.g. for a blame view of a wiki page.
+// Consecutive runes of the same version are merged into one annotation.  The versions are compared with diffs cleaned up by DiffCleanupSemantic, so that runes which only happen to be equal in unrelated edits are not attributed to older versions.
+func (dmp *DiffMatchPatch) Annotate(versions []string) []Annotation {
+	if len(versions) == 0 {
+		return []Annotation{}
+	}
+	origins := make([]int, utf8.RuneCountInString(versions[0]))
+	for i := 1; i < len(versions); i++ {
+		diffs := runeDiffsToDiffs(dmp.diffRunes([]rune(versions[i-1]), []rune(versions[i]), true))
+		diffs = dmp.DiffCleanupSemantic(diffs)
+		next := make([]int, 0, utf8.RuneCountInString(versions[i]))
+		previous := 0
+		for _, aDiff := range diffs {
+			n := utf8.RuneCountInString(aDiff.Text)
+			switch aDiff.Type {
+			case DiffEqual:
+				next = append(next, origins[previous:previous+n]...)
+				previous += n
+			case DiffDelete:
+				previous += n
+			case DiffInsert:
+				for j := 0; j < n; j++ {
+					next = append(next, i)
+				}
+			}
+		}
+		origins = next
+	}
+
+	annotations := []Annotation{}
+	runes := []rune(versions[len(versions)-1])
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && origins[end] == origins[start] {
+			end++
+		}
+		annotations = append(annotations, Annotation{string(runes[start:end]), origins[start]})
+		start = end
+	}
+	return annotations
+}
+
+// AnnotateLines attributes each line of the last of several versions of a text to the earliest version from which it was kept unchanged, like the blame of version control systems.  There is one annotation per line, including its line break.
+func (dmp *DiffMatchPatch) AnnotateLines(versions []string) []Annotation {
+	if len(versions) == 0 {
+		return []Annotation{}
+	}
+	origins := make([]int, len(splitLines(versions[0])))
+	for i := 1; i < len(versions); i++ {
+		ids1, ids2, _ := dmp.DiffLinesToRunes(versions[i-1], versions[i])
+		next := make([]int, 0, len(ids2))
+		previous := 0
+		for _, aDiff := range dmp.diffRunes(ids1, ids2, false) {
+			switch aDiff.Type {
+			case DiffEqual:
+				next = append(next, origins[previous:previous+len(aDiff.Text)]...)
+				previous += len(aDiff.Text)
+			case DiffDelete:
+				previous += len(aDiff.Text)
+			case DiffInsert:
+				for range aDiff.Text {
+					next = append(next, i)
+				}
+			}
+		}
+		origins = next
+	}
+
+	annotations := []Annotation{}
+	for i, line := range splitLines(versions[len(versions)-1]) {
+		annotations = append(annotations, Annotation{line, origins[i]})
+	}
+	return annotations
+}
+
+// AnnotatePatches annotates the text obtained by applying each set of patches in turn to text, where the text is version 0 and the text after applying patches[i] is version i+1.
+// Returns an error if a patch does not apply, since the annotations would not describe the history of the text.
+func (dmp *DiffMatchPatch) AnnotatePatches(text string, patches [][]Patch) (_ []Annotation, err error) {
+	defer dmp.recoverInternal(&err)
+	versions := []string{text}
+	for i, aPatches := range patches {
+		var results []bool
+		text, results = dmp.PatchApply(aPatches, text)
+		failed := 0
+		for _, applied := range results {
+			if !applied {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return nil, fmt.Errorf("%d of %d patches of version %d do not apply", failed, len(results), i+1)
+		}
+		versions = append(versions, text)
+	}
+	return dmp.Annotate(versions), nil
+}
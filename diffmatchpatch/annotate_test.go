@@ -0,0 +1,84 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Versions []string
+
+		Expected []Annotation
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No versions", nil, []Annotation{}},
+		{"Single version", []string{"abc"}, []Annotation{{"abc", 0}}},
+		{"Empty last version", []string{"abc", ""}, []Annotation{}},
+		{"Insertion", []string{"The fox.", "The quick fox."}, []Annotation{{"The ", 0}, {"quick ", 1}, {"fox.", 0}}},
+		{"Replacement", []string{"The quick fox.", "The slow fox."}, []Annotation{{"The ", 0}, {"slow", 1}, {" fox.", 0}}},
+		{"Several versions", []string{"The fox.", "The quick fox.", "The quick brown fox.", "The quick brown fox jumps."}, []Annotation{{"The ", 0}, {"quick ", 1}, {"brown ", 2}, {"fox", 0}, {" jumps", 3}, {".", 0}}},
+		{"Restored text", []string{"The fox.", "The dog.", "The fox."}, []Annotation{{"The ", 0}, {"fox", 2}, {".", 0}}},
+		{"Unicode", []string{"日本語", "日本人の言語"}, []Annotation{{"日本", 0}, {"人の言", 1}, {"語", 0}}},
+	} {
+		actual := dmp.Annotate(tc.Versions)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestAnnotateLines(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Versions []string
+
+		Expected []Annotation
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No versions", nil, []Annotation{}},
+		{"Single version", []string{"a\nb\n"}, []Annotation{{"a\n", 0}, {"b\n", 0}}},
+		{"Changed line", []string{"a\nb\nc\n", "a\nx\nc\n"}, []Annotation{{"a\n", 0}, {"x\n", 1}, {"c\n", 0}}},
+		{"Several versions", []string{"a\nb\n", "a\nb\nc\n", "z\na\nb\nc"}, []Annotation{{"z\n", 2}, {"a\n", 0}, {"b\n", 0}, {"c", 2}}},
+		{"Equal lines", []string{"a\na\n", "a\nb\na\n"}, []Annotation{{"a\n", 0}, {"b\n", 1}, {"a\n", 0}}},
+	} {
+		actual := dmp.AnnotateLines(tc.Versions)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestAnnotatePatches(t *testing.T) {
+	dmp := New()
+
+	versions := []string{"The fox.", "The quick fox.", "The quick brown fox.", "The quick brown fox jumps."}
+	var patches [][]Patch
+	for i := 1; i < len(versions); i++ {
+		patches = append(patches, dmp.PatchMake(versions[i-1], versions[i]))
+	}
+
+	actual, err := dmp.AnnotatePatches(versions[0], patches)
+	assert.NoError(t, err)
+	assert.Equal(t, dmp.Annotate(versions), actual)
+
+	_, err = dmp.AnnotatePatches("A completely different text.", patches)
+	if assert.Error(t, err) {
+		assert.Equal(t, "1 of 1 patches of version 1 do not apply", err.Error())
+	}
+}
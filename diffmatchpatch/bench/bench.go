@@ -0,0 +1,135 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package bench measures the diffs of diffmatchpatch on corpora of text pairs, so that configurations can be compared on data of the shape at hand.
+// Corpora are loaded from files, such as the speedtest corpus of the reference implementations, or generated by Similar, Random and RepeatedLines.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Config is a configuration of a diff to measure.
+type Config struct {
+	Name string
+	// Configuration of the diff.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+	// Function computing the diff with the configuration (nil for DiffMain without line mode).
+	Diff func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff
+}
+
+// DefaultConfigs returns configurations of the main diff algorithms and options.
+func DefaultConfigs() []Config {
+	exact := diffmatchpatch.New()
+	exact.DiffExactMinimal = true
+	noTimeout := diffmatchpatch.New()
+	noTimeout.DiffTimeout = 0
+	return []Config{
+		{Name: "DiffMain", DiffMatchPatch: diffmatchpatch.New()},
+		{Name: "DiffMain without timeout", DiffMatchPatch: noTimeout},
+		{Name: "DiffMain with line mode", DiffMatchPatch: diffmatchpatch.New(), Diff: func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff {
+			return dmp.DiffMain(text1, text2, true)
+		}},
+		{Name: "DiffMain with semantic cleanup", DiffMatchPatch: diffmatchpatch.New(), Diff: func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff {
+			return dmp.DiffCleanupSemantic(dmp.DiffMain(text1, text2, false))
+		}},
+		{Name: "DiffMainWords", DiffMatchPatch: diffmatchpatch.New(), Diff: func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff {
+			return dmp.DiffMainWords(text1, text2)
+		}},
+		{Name: "DiffExactMinimal", DiffMatchPatch: exact},
+	}
+}
+
+// Result is the measurement of a configuration on a corpus.
+type Result struct {
+	Corpus string
+	Config string
+	// Number of times the diff was computed.
+	Runs int
+	// Mean time and memory allocated per run.
+	Duration time.Duration
+	Bytes    uint64
+	Allocs   uint64
+	// Number of diffs and edit distance of the diff computed, to compare the quality of the diffs.
+	Diffs        int
+	EditDistance int
+}
+
+// Run computes the diff of every corpus with every configuration runs times, and returns the results by corpus, then by configuration.
+// It returns an error if a diff does not transform the first text of its corpus into the second one.
+func Run(corpora []Corpus, configs []Config, runs int) ([]Result, error) {
+	if runs < 1 {
+		runs = 1
+	}
+	var results []Result
+	for _, corpus := range corpora {
+		for _, config := range configs {
+			result, err := measure(corpus, config, runs)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// measure computes the diff of a corpus with a configuration runs times.
+func measure(corpus Corpus, config Config, runs int) (Result, error) {
+	dmp := config.DiffMatchPatch
+	if dmp == nil {
+		dmp = diffmatchpatch.New()
+	}
+	diff := config.Diff
+	if diff == nil {
+		diff = func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff {
+			return dmp.DiffMain(text1, text2, false)
+		}
+	}
+
+	var before, after runtime.MemStats
+	var diffs []diffmatchpatch.Diff
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < runs; i++ {
+		diffs = diff(dmp, corpus.Text1, corpus.Text2)
+	}
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if dmp.DiffText1(diffs) != corpus.Text1 || dmp.DiffText2(diffs) != corpus.Text2 {
+		return Result{}, fmt.Errorf("Diff of %s with %s does not transform its texts", corpus.Name, config.Name)
+	}
+	return Result{
+		Corpus:       corpus.Name,
+		Config:       config.Name,
+		Runs:         runs,
+		Duration:     duration / time.Duration(runs),
+		Bytes:        (after.TotalAlloc - before.TotalAlloc) / uint64(runs),
+		Allocs:       (after.Mallocs - before.Mallocs) / uint64(runs),
+		Diffs:        len(diffs),
+		EditDistance: dmp.DiffLevenshtein(diffs),
+	}, nil
+}
+
+// WriteTable writes results as a table aligned with spaces, with one row per result.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Corpus\tConfig\tTime\tBytes\tAllocs\tDiffs\tEdit distance")
+	for _, r := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%v\t%d\t%d\t%d\t%d\n", r.Corpus, r.Config, r.Duration, r.Bytes, r.Allocs, r.Diffs, r.EditDistance)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package bench
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestRun(t *testing.T) {
+	corpora := []Corpus{Similar(1, 500, 0.1), Similar(2, 500, 0), {Name: "Empty"}}
+	configs := DefaultConfigs()
+
+	results, err := Run(corpora, configs, 2)
+	assert.NoError(t, err)
+	if !assert.Len(t, results, len(corpora)*len(configs)) {
+		return
+	}
+	for i, result := range results {
+		assert.Equal(t, corpora[i/len(configs)].Name, result.Corpus)
+		assert.Equal(t, configs[i%len(configs)].Name, result.Config)
+		assert.Equal(t, 2, result.Runs)
+	}
+	// Unchanged texts have a single equality, or none if they are empty.
+	for _, result := range results[len(configs):] {
+		assert.Equal(t, 0, result.EditDistance, result.Config)
+		assert.True(t, result.Diffs <= 1, result.Config)
+	}
+	// The exact minimal diff is no longer than the default one.
+	assert.True(t, results[len(configs)-1].EditDistance <= results[0].EditDistance)
+
+	var table bytes.Buffer
+	assert.NoError(t, WriteTable(&table, results[:1]))
+	lines := strings.Split(table.String(), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, `^Corpus +Config +Time +Bytes +Allocs +Diffs +Edit distance$`, lines[0])
+	assert.Regexp(t, `^Similar\(1, 500, 0.1\) +DiffMain +\S+ +\d+ +\d+ +\d+ +\d+$`, lines[1])
+}
+
+func TestRunInvalidDiff(t *testing.T) {
+	broken := Config{Name: "Broken", Diff: func(dmp *diffmatchpatch.DiffMatchPatch, text1, text2 string) []diffmatchpatch.Diff {
+		return []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffInsert, Text: text2}}
+	}}
+
+	results, err := Run([]Corpus{{Name: "Empty"}, Similar(1, 100, 0)}, []Config{broken}, 1)
+	assert.EqualError(t, err, "Diff of Similar(1, 100, 0) with Broken does not transform its texts")
+	assert.Len(t, results, 1)
+}
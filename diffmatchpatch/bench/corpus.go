@@ -0,0 +1,122 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package bench
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+)
+
+// Corpus is a pair of texts to diff.
+type Corpus struct {
+	Name  string
+	Text1 string
+	Text2 string
+}
+
+// LoadCorpus reads a corpus from the files of its two texts.
+func LoadCorpus(name, path1, path2 string) (Corpus, error) {
+	text1, err := ioutil.ReadFile(path1)
+	if err != nil {
+		return Corpus{}, err
+	}
+	text2, err := ioutil.ReadFile(path2)
+	if err != nil {
+		return Corpus{}, err
+	}
+	return Corpus{Name: name, Text1: string(text1), Text2: string(text2)}, nil
+}
+
+// LoadSpeedtest reads the speedtest corpus of the reference diff-match-patch implementations from the files speedtest1.txt and speedtest2.txt of a directory, such as the testdata directory of this repository.
+func LoadSpeedtest(dir string) (Corpus, error) {
+	return LoadCorpus("Speedtest", filepath.Join(dir, "speedtest1.txt"), filepath.Join(dir, "speedtest2.txt"))
+}
+
+// words is the vocabulary of the generated texts.
+var words = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua
+	enim ad minim veniam quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate`)
+
+// randomLine returns a line of a few random words.
+func randomLine(rng *rand.Rand) string {
+	n := 3 + rng.Intn(10)
+	line := make([]string, n)
+	for i := range line {
+		line[i] = words[rng.Intn(len(words))]
+	}
+	return strings.Join(line, " ") + "\n"
+}
+
+// randomText returns a text of random lines of about size bytes.
+func randomText(rng *rand.Rand, size int) string {
+	var text strings.Builder
+	for text.Len() < size {
+		_, _ = text.WriteString(randomLine(rng))
+	}
+	return text.String()
+}
+
+// Similar generates a corpus of a random text of about size bytes and a copy of it in which a fraction of the words, given by changeRate, is deleted, replaced, or followed by an inserted word.
+// Texts are generated from seed, so that corpora with the same parameters are equal.
+func Similar(seed int64, size int, changeRate float64) Corpus {
+	rng := rand.New(rand.NewSource(seed))
+	text1 := randomText(rng, size)
+
+	var text2 strings.Builder
+	for _, line := range strings.SplitAfter(text1, "\n") {
+		lineWords := strings.Fields(line)
+		for i, word := range lineWords {
+			if rng.Float64() < changeRate {
+				switch rng.Intn(3) {
+				case 0:
+					word = ""
+				case 1:
+					word = words[rng.Intn(len(words))]
+				case 2:
+					word += " " + words[rng.Intn(len(words))]
+				}
+			}
+			if i != 0 && len(word) != 0 {
+				_, _ = text2.WriteString(" ")
+			}
+			_, _ = text2.WriteString(word)
+		}
+		if strings.HasSuffix(line, "\n") {
+			_, _ = text2.WriteString("\n")
+		}
+	}
+	return Corpus{Name: fmt.Sprintf("Similar(%d, %d, %g)", seed, size, changeRate), Text1: text1, Text2: text2.String()}
+}
+
+// Random generates a corpus of two unrelated random texts of about size bytes each, which is the worst case of most diff algorithms.
+func Random(seed int64, size int) Corpus {
+	rng := rand.New(rand.NewSource(seed))
+	return Corpus{Name: fmt.Sprintf("Random(%d, %d)", seed, size), Text1: randomText(rng, size), Text2: randomText(rng, size)}
+}
+
+// RepeatedLines generates a corpus of two texts of the given number of lines, drawn from only a few distinct lines, like logs or generated code. The second text shuffles some lines of the first one.
+func RepeatedLines(seed int64, lines int) Corpus {
+	rng := rand.New(rand.NewSource(seed))
+	distinct := make([]string, 8)
+	for i := range distinct {
+		distinct[i] = randomLine(rng)
+	}
+	lines1 := make([]string, lines)
+	for i := range lines1 {
+		lines1[i] = distinct[rng.Intn(len(distinct))]
+	}
+	lines2 := append([]string(nil), lines1...)
+	for i := 0; i < lines/10; i++ {
+		j, k := rng.Intn(lines), rng.Intn(lines)
+		lines2[j], lines2[k] = lines2[k], lines2[j]
+	}
+	return Corpus{Name: fmt.Sprintf("RepeatedLines(%d, %d)", seed, lines), Text1: strings.Join(lines1, ""), Text2: strings.Join(lines2, "")}
+}
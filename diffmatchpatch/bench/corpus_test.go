@@ -0,0 +1,57 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package bench
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSpeedtest(t *testing.T) {
+	corpus, err := LoadSpeedtest("../../testdata")
+	assert.NoError(t, err)
+	assert.Equal(t, "Speedtest", corpus.Name)
+	assert.Len(t, corpus.Text1, 12979)
+	assert.Len(t, corpus.Text2, 11918)
+
+	_, err = LoadSpeedtest("missing")
+	assert.Error(t, err)
+}
+
+func TestGenerators(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Generate func() Corpus
+
+		ExpectedName  string
+		ExpectedEqual bool
+	}
+
+	for i, tc := range []TestCase{
+		{"Similar", func() Corpus { return Similar(1, 2000, 0.1) }, "Similar(1, 2000, 0.1)", false},
+		{"Unchanged", func() Corpus { return Similar(1, 2000, 0) }, "Similar(1, 2000, 0)", true},
+		{"Random", func() Corpus { return Random(1, 2000) }, "Random(1, 2000)", false},
+		{"Repeated lines", func() Corpus { return RepeatedLines(1, 200) }, "RepeatedLines(1, 200)", false},
+	} {
+		corpus := tc.Generate()
+		assert.Equal(t, tc.ExpectedName, corpus.Name, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedEqual, corpus.Text1 == corpus.Text2, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, len(corpus.Text1) >= 1000 && len(corpus.Text2) >= 1000, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		// Corpora are reproducible.
+		assert.Equal(t, corpus, tc.Generate(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	repeated := RepeatedLines(2, 300)
+	assert.Equal(t, 300, strings.Count(repeated.Text1, "\n"))
+	assert.Equal(t, 300, strings.Count(repeated.Text2, "\n"))
+}
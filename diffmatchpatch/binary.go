@@ -0,0 +1,193 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The binary encoding starts with a magic number, the kind of the encoded value and the version of the format.
+// All numbers are unsigned varints as written by encoding/binary, texts are prefixed with their length in bytes.
+//
+// Version 1:
+//
+//	diffs:       count, then per diff: operation (0 delete, 1 equal, 2 insert), text
+//	patches:     count, then per patch: start1, start2, length1, length2, diffs
+//	signatures:  length, block size, then per block: weak checksum, 16 bytes of strong checksum
+//	rsync delta: block size, old length, new length, count, then per instruction: 0 and text, or 1, first block and number of blocks copied; then the SHA-256 of the new file
+const (
+	binaryMagic          = "dmp"
+	binaryKindDiffs      = 'D'
+	binaryKindPatches    = 'P'
+	binaryKindSignature  = 'S'
+	binaryKindRsyncDelta = 'R'
+	binaryVersion        = 1
+)
+
+// DiffToBinary encodes a diff in a compact, versioned binary format, which unlike DiffToDelta does not need the first text to be decoded.
+func (dmp *DiffMatchPatch) DiffToBinary(diffs []Diff) []byte {
+	b := appendBinaryHeader(nil, binaryKindDiffs)
+	return appendBinaryDiffs(b, diffs)
+}
+
+// DiffFromBinary decodes a diff encoded by DiffToBinary.
+func (dmp *DiffMatchPatch) DiffFromBinary(data []byte) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	r := &binaryReader{data: data}
+	if err := r.header(binaryKindDiffs); err != nil {
+		return nil, err
+	}
+	diffs, err := r.diffs()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("Trailing data after binary diffs")
+	}
+	return diffs, nil
+}
+
+// PatchToBinary encodes a list of patches in a compact, versioned binary format, which unlike PatchToText keeps the texts of the patches as they are.
+func (dmp *DiffMatchPatch) PatchToBinary(patches []Patch) []byte {
+	b := appendBinaryHeader(nil, binaryKindPatches)
+	b = appendUvarint(b, uint64(len(patches)))
+	for _, aPatch := range patches {
+		b = appendUvarint(b, uint64(aPatch.Start1))
+		b = appendUvarint(b, uint64(aPatch.Start2))
+		b = appendUvarint(b, uint64(aPatch.Length1))
+		b = appendUvarint(b, uint64(aPatch.Length2))
+		b = appendBinaryDiffs(b, aPatch.Diffs)
+	}
+	return b
+}
+
+// PatchFromBinary decodes a list of patches encoded by PatchToBinary.
+func (dmp *DiffMatchPatch) PatchFromBinary(data []byte) (_ []Patch, err error) {
+	defer dmp.recoverInternal(&err)
+	r := &binaryReader{data: data}
+	if err := r.header(binaryKindPatches); err != nil {
+		return nil, err
+	}
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	patches := []Patch{}
+	for i := 0; i < count; i++ {
+		var aPatch Patch
+		for _, field := range []*int{&aPatch.Start1, &aPatch.Start2, &aPatch.Length1, &aPatch.Length2} {
+			if *field, err = r.int(); err != nil {
+				return nil, err
+			}
+		}
+		if aPatch.Diffs, err = r.diffs(); err != nil {
+			return nil, err
+		}
+		patches = append(patches, aPatch)
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("Trailing data after binary patches")
+	}
+	return patches, nil
+}
+
+func appendBinaryHeader(b []byte, kind byte) []byte {
+	b = append(b, binaryMagic...)
+	return append(b, kind, binaryVersion)
+}
+
+func appendBinaryDiffs(b []byte, diffs []Diff) []byte {
+	b = appendUvarint(b, uint64(len(diffs)))
+	for _, aDiff := range diffs {
+		b = append(b, byte(aDiff.Type-DiffDelete))
+		b = appendUvarint(b, uint64(len(aDiff.Text)))
+		b = append(b, aDiff.Text...)
+	}
+	return b
+}
+
+// appendUvarint appends n as an unsigned varint.
+func appendUvarint(b []byte, n uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], n)]...)
+}
+
+// binaryReader consumes a value in the binary format of DiffToBinary and PatchToBinary.
+type binaryReader struct {
+	data []byte
+}
+
+// header checks the magic number, the kind and the version of the encoded value.
+func (r *binaryReader) header(kind byte) error {
+	if len(r.data) < len(binaryMagic)+2 || string(r.data[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("Missing binary diff header")
+	}
+	if r.data[len(binaryMagic)] != kind {
+		return fmt.Errorf("Binary data of kind %q instead of %q", r.data[len(binaryMagic)], kind)
+	}
+	if version := r.data[len(binaryMagic)+1]; version != binaryVersion {
+		return fmt.Errorf("Unsupported binary diff version %v", version)
+	}
+	r.data = r.data[len(binaryMagic)+2:]
+	return nil
+}
+
+func (r *binaryReader) diffs() ([]Diff, error) {
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+	diffs := []Diff{}
+	for i := 0; i < count; i++ {
+		if len(r.data) == 0 {
+			return nil, errors.New("Truncated binary diff")
+		}
+		op := Operation(r.data[0]) + DiffDelete
+		if op != DiffDelete && op != DiffEqual && op != DiffInsert {
+			return nil, fmt.Errorf("Invalid diff operation %v in binary diff", r.data[0])
+		}
+		r.data = r.data[1:]
+		n, err := r.int()
+		if err != nil {
+			return nil, err
+		}
+		if n > len(r.data) {
+			return nil, errors.New("Truncated binary diff")
+		}
+		diffs = append(diffs, Diff{op, string(r.data[:n])})
+		r.data = r.data[n:]
+	}
+	return diffs, nil
+}
+
+// count reads a number of items, each of which takes at least one byte.
+func (r *binaryReader) count() (int, error) {
+	n, err := r.int()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(r.data) {
+		return 0, errors.New("Truncated binary diff")
+	}
+	return n, nil
+}
+
+func (r *binaryReader) int() (int, error) {
+	n, size := binary.Uvarint(r.data)
+	if size == 0 {
+		return 0, errors.New("Truncated binary diff")
+	}
+	if size < 0 || n > uint64(int(^uint(0)>>1)) {
+		return 0, errors.New("Integer in binary diff is too large")
+	}
+	r.data = r.data[size:]
+	return int(n), nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToBinary(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []byte
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []byte{'d', 'm', 'p', 'D', 1, 0}},
+		{"Diffs", []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "%0A"}}, []byte{'d', 'm', 'p', 'D', 1, 3, 1, 2, 'a', 'b', 0, 1, 'c', 2, 3, '%', '0', 'A'}},
+	} {
+		actual := dmp.DiffToBinary(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		decoded, err := dmp.DiffFromBinary(actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diffs, decoded, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffFromBinary(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Data []byte
+
+		Expected    []Diff
+		ExpectedErr error
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Unicode", []byte{'d', 'm', 'p', 'D', 1, 1, 2, 6, 0xe6, 0x97, 0xa5, 0xe6, 0x9c, 0xac}, []Diff{{DiffInsert, "日本"}}, nil},
+		{"Missing header", []byte{}, nil, errors.New("Missing binary diff header")},
+		{"Patches", []byte{'d', 'm', 'p', 'P', 1, 0}, nil, errors.New(`Binary data of kind 'P' instead of 'D'`)},
+		{"Unknown version", []byte{'d', 'm', 'p', 'D', 2, 0}, nil, errors.New("Unsupported binary diff version 2")},
+		{"Invalid operation", []byte{'d', 'm', 'p', 'D', 1, 1, 3, 0}, nil, errors.New("Invalid diff operation 3 in binary diff")},
+		{"Truncated text", []byte{'d', 'm', 'p', 'D', 1, 1, 1, 5, 'a'}, nil, errors.New("Truncated binary diff")},
+		{"Too many diffs", []byte{'d', 'm', 'p', 'D', 1, 100, 1, 0}, nil, errors.New("Truncated binary diff")},
+		{"Huge number", []byte{'d', 'm', 'p', 'D', 1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, nil, errors.New("Integer in binary diff is too large")},
+		{"Trailing data", []byte{'d', 'm', 'p', 'D', 1, 0, 0}, nil, errors.New("Trailing data after binary diffs")},
+	} {
+		actual, err := dmp.DiffFromBinary(tc.Data)
+		assert.Equal(t, tc.ExpectedErr, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchToBinary(t *testing.T) {
+	dmp := New()
+
+	text1 := "The quick brown fox jumps over the lazy dog.\n"
+	text2 := "That quick brown fox jumped over a lazy dog.\n%"
+	patches := dmp.PatchMake(text1, text2)
+
+	data := dmp.PatchToBinary(patches)
+	assert.Equal(t, []byte{'d', 'm', 'p', 'P', 1, byte(len(patches))}, data[:6])
+
+	decoded, err := dmp.PatchFromBinary(data)
+	assert.NoError(t, err)
+	assert.Equal(t, patches, decoded)
+	patched, _ := dmp.PatchApply(decoded, text1)
+	assert.Equal(t, text2, patched)
+
+	decoded, err = dmp.PatchFromBinary(dmp.PatchToBinary(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []Patch{}, decoded)
+
+	_, err = dmp.PatchFromBinary(data[:len(data)-1])
+	assert.Equal(t, errors.New("Truncated binary diff"), err)
+	_, err = dmp.PatchFromBinary(dmp.DiffToBinary(nil))
+	assert.Equal(t, errors.New(`Binary data of kind 'D' instead of 'P'`), err)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+)
+
+// DiffCanonicalize rewrites a diff into its canonical form, which has no empty diffs, no adjacent diffs of the same operation, and deletions before insertions.
+// Unlike DiffCleanupMerge it does not factor out or shift text, so two diffs are canonically equal if and only if they describe the same edits.
+// The diffs returned by the functions of this package are canonical, except for those decoded by DiffFromBinary which are returned as encoded.
+func (dmp *DiffMatchPatch) DiffCanonicalize(diffs []Diff) []Diff {
+	diffs = repairRuneBoundaries(diffs)
+	if isCanonical(diffs) {
+		return diffs
+	}
+
+	canonical := make([]Diff, 0, len(diffs))
+	var deleted, inserted strings.Builder
+	flush := func() {
+		if deleted.Len() != 0 {
+			canonical = append(canonical, Diff{DiffDelete, deleted.String()})
+			deleted.Reset()
+		}
+		if inserted.Len() != 0 {
+			canonical = append(canonical, Diff{DiffInsert, inserted.String()})
+			inserted.Reset()
+		}
+	}
+	for _, aDiff := range diffs {
+		if len(aDiff.Text) == 0 {
+			continue
+		}
+		switch aDiff.Type {
+		case DiffDelete:
+			deleted.WriteString(aDiff.Text)
+		case DiffInsert:
+			inserted.WriteString(aDiff.Text)
+		case DiffEqual:
+			flush()
+			if n := len(canonical); n != 0 && canonical[n-1].Type == DiffEqual {
+				canonical[n-1].Text += aDiff.Text
+			} else {
+				canonical = append(canonical, aDiff)
+			}
+		}
+	}
+	flush()
+	return canonical
+}
+
+// isCanonical returns whether a diff is in the form established by DiffCanonicalize.
+func isCanonical(diffs []Diff) bool {
+	for i, aDiff := range diffs {
+		if len(aDiff.Text) == 0 {
+			return false
+		}
+		if i == 0 {
+			continue
+		}
+		previous := diffs[i-1].Type
+		switch {
+		case previous == aDiff.Type:
+			return false
+		case previous == DiffInsert && aDiff.Type == DiffDelete:
+			return false
+		}
+	}
+	return true
+}
+
+// DiffsEqual returns whether two diffs describe the same edits, i.e. whether they are equal in canonical form.  E.g. a split equality is equal to the joined one.
+func (dmp *DiffMatchPatch) DiffsEqual(diffs1, diffs2 []Diff) bool {
+	diffs1, diffs2 = dmp.DiffCanonicalize(diffs1), dmp.DiffCanonicalize(diffs2)
+	if len(diffs1) != len(diffs2) {
+		return false
+	}
+	for i := range diffs1 {
+		if diffs1[i] != diffs2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PatchesEqual returns whether two lists of patches have the same positions and lengths, and diffs which describe the same edits.
+func (dmp *DiffMatchPatch) PatchesEqual(patches1, patches2 []Patch) bool {
+	if len(patches1) != len(patches2) {
+		return false
+	}
+	for i, p1 := range patches1 {
+		p2 := patches2[i]
+		if p1.Start1 != p2.Start1 || p1.Start2 != p2.Start2 || p1.Length1 != p2.Length1 || p1.Length2 != p2.Length2 || !dmp.DiffsEqual(p1.Diffs, p2.Diffs) {
+			return false
+		}
+	}
+	return true
+}
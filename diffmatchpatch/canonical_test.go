@@ -0,0 +1,142 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCanonicalize(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []Diff{}},
+		{"Already canonical", []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "d"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "d"}}},
+		{"Empty diffs", []Diff{{DiffEqual, ""}, {DiffDelete, "a"}, {DiffInsert, ""}}, []Diff{{DiffDelete, "a"}}},
+		{"Only empty diffs", []Diff{{DiffEqual, ""}, {DiffInsert, ""}}, []Diff{}},
+		{"Adjacent equalities", []Diff{{DiffEqual, "a"}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "ab"}}},
+		{"Equalities around an empty edit", []Diff{{DiffEqual, "a"}, {DiffDelete, ""}, {DiffEqual, "b"}}, []Diff{{DiffEqual, "ab"}}},
+		{"Insertion before deletion", []Diff{{DiffInsert, "a"}, {DiffDelete, "b"}}, []Diff{{DiffDelete, "b"}, {DiffInsert, "a"}}},
+		{"Interleaved edits", []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}, {DiffDelete, "c"}, {DiffInsert, "d"}, {DiffEqual, "e"}}, []Diff{{DiffDelete, "ac"}, {DiffInsert, "bd"}, {DiffEqual, "e"}}},
+		{"No factoring", []Diff{{DiffDelete, "ab"}, {DiffInsert, "ac"}}, []Diff{{DiffDelete, "ab"}, {DiffInsert, "ac"}}},
+	} {
+		actual := dmp.DiffCanonicalize(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, isCanonical(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffCanonicalOutput(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+	}
+
+	dmp := New()
+
+	// A diff with empty and unordered operations, which the cleanups must not pass through.
+	raw := []Diff{{DiffEqual, ""}, {DiffInsert, "a"}, {DiffDelete, "a"}, {DiffInsert, ""}, {DiffEqual, "b"}}
+	text1 := "The quick brown fox.\nJumps over.\n"
+	text2 := "The quick red fox.\nJumped over it.\n"
+	other := "A quick brown dog.\nJumps over.\n"
+	clientPrime, serverPrime := dmp.DiffTransform(dmp.DiffMain(text1, text2, false), dmp.DiffMain(text1, other, false))
+
+	for i, tc := range []TestCase{
+		{"DiffMain", dmp.DiffMain(text1, text2, false)},
+		{"DiffMain line mode", dmp.DiffMain(text1, text2, true)},
+		{"DiffBisect", dmp.DiffBisect("a", "b", time.Time{})},
+		{"DiffBisect empty", dmp.DiffBisect("", "ab", time.Time{})},
+		{"DiffCleanupMerge", dmp.DiffCleanupMerge(raw)},
+		{"DiffCleanupSemantic", dmp.DiffCleanupSemantic(raw)},
+		{"DiffCleanupSemanticLossless", dmp.DiffCleanupSemanticLossless(raw)},
+		{"DiffCleanupEfficiency", dmp.DiffCleanupEfficiency(raw)},
+		{"DiffCleanupLines", dmp.DiffCleanupLines(raw)},
+		{"DiffMainWithAnchors", dmp.DiffMainWithAnchors(text1, text2, []Anchor{{Offset1: 10, Offset2: 10}})},
+		{"DiffTransform client", clientPrime},
+		{"DiffTransform server", serverPrime},
+		{"DiffN", dmp.DiffN([]string{"axc", "abc"}).Diffs[1][0]},
+	} {
+		assert.True(t, isCanonical(tc.Diffs), fmt.Sprintf("Test case #%d, %s: %q", i, tc.Name, tc.Diffs))
+	}
+}
+
+func TestDiffsEqual(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs1 []Diff
+		Diffs2 []Diff
+
+		Expected bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, []Diff{}, true},
+		{"Same diffs", []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}}, true},
+		{"Split equality", []Diff{{DiffEqual, "a"}, {DiffEqual, "b"}, {DiffInsert, "c"}}, []Diff{{DiffEqual, "ab"}, {DiffInsert, "c"}}, true},
+		{"Empty diffs", []Diff{{DiffDelete, ""}, {DiffEqual, "a"}}, []Diff{{DiffEqual, "a"}}, true},
+		{"Order of edits", []Diff{{DiffInsert, "a"}, {DiffDelete, "b"}}, []Diff{{DiffDelete, "b"}, {DiffInsert, "a"}}, true},
+		{"Different texts", []Diff{{DiffEqual, "a"}}, []Diff{{DiffEqual, "b"}}, false},
+		{"Different edits", []Diff{{DiffDelete, "ab"}, {DiffInsert, "ac"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}}, false},
+		{"Different lengths", []Diff{{DiffEqual, "a"}}, []Diff{{DiffEqual, "a"}, {DiffInsert, "b"}}, false},
+	} {
+		assert.Equal(t, tc.Expected, dmp.DiffsEqual(tc.Diffs1, tc.Diffs2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, dmp.DiffsEqual(tc.Diffs2, tc.Diffs1), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchesEqual(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Patches1 []Patch
+		Patches2 []Patch
+
+		Expected bool
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+	modified := func(modify func(patches []Patch)) []Patch {
+		patches := dmp.PatchDeepCopy(patches)
+		modify(patches)
+		return patches
+	}
+	parsed, err := dmp.PatchFromText(dmp.PatchToText(patches))
+	assert.NoError(t, err)
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, []Patch{}, true},
+		{"Parsed patches", patches, parsed, true},
+		{"Split equality", patches, modified(func(p []Patch) {
+			p[0].Diffs = append([]Diff{{DiffEqual, ""}}, p[0].Diffs...)
+		}), true},
+		{"Different start", patches, modified(func(p []Patch) { p[1].Start2++ }), false},
+		{"Different diffs", patches, modified(func(p []Patch) { p[0].Diffs[1].Text = "x" }), false},
+		{"Missing patch", patches, patches[:1], false},
+	} {
+		assert.Equal(t, tc.Expected, dmp.PatchesEqual(tc.Patches1, tc.Patches2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// defaultChunkSize is the average length in runes of the chunks of DiffChunksToChars when DiffChunkSize is not set.
+const defaultChunkSize = 64
+
+// gearTable holds a pseudo-random number for every byte, which the rolling hash of chunkBoundaries adds up.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range table {
+		// Xorshift, so that the table is the same on every platform.
+		x ^= x << 13
+		x ^= x >> 7
+		x ^= x << 17
+		table[i] = x
+	}
+	return table
+}()
+
+// chunkBoundaries returns the ends of the content-defined chunks of about size runes a text is split into.
+// A chunk ends where the rolling gear hash of the last 64 runes has its top bits unset, so that the boundaries only depend on the text around them and resynchronise shortly after an edit. Chunks are between size/4 and 4*size runes long.
+func chunkBoundaries(text []rune, size int) []int {
+	if size < 1 {
+		size = 1
+	}
+	minSize, maxSize := size/4, 4*size
+	if minSize < 1 {
+		minSize = 1
+	}
+	// The hash matches the mask once every 2^k runes on average, after the minimum length of a chunk.
+	k := uint(bits.Len(uint(size-minSize+1)) - 1)
+	mask := ^uint64(0) << (64 - k)
+	if k == 0 {
+		mask = 0
+	}
+
+	var ends []int
+	start := 0
+	var h uint64
+	for i, r := range text {
+		h = h<<1 + gearTable[uint8(r)^uint8(r>>8)^uint8(r>>16)]
+		if n := i + 1 - start; n >= maxSize || n >= minSize && h&mask == 0 {
+			ends = append(ends, i+1)
+			start = i + 1
+			h = 0
+		}
+	}
+	if start < len(text) {
+		ends = append(ends, len(text))
+	}
+	return ends
+}
+
+// chunksToIDs splits two texts into content-defined chunks and numbers the distinct chunks, so that the chunks can be diffed like runes, the same way as diffLinesToIDs numbers lines.
+// Once chunkArray holds maxChunks chunks, the rest of the text is a single chunk. Half of the chunks are allocated to text1, so that text2 has some left.
+func chunksToIDs(text1, text2 []rune, size int, chunkArray []string, maxChunks int) ([]rune, []rune, []string) {
+	chunkHash := map[string]rune{}
+	for i, chunk := range chunkArray {
+		chunkHash[chunk] = rune(i)
+	}
+
+	toIDs := func(text []rune, maxChunks int) []rune {
+		ends := chunkBoundaries(text, size)
+		ids := make([]rune, 0, len(ends))
+		start := 0
+		for _, end := range ends {
+			if len(chunkArray) >= maxChunks {
+				end = len(text)
+			}
+			chunk := string(text[start:end])
+			start = end
+
+			id, ok := chunkHash[chunk]
+			if !ok {
+				id = rune(len(chunkArray))
+				chunkArray = append(chunkArray, chunk)
+				chunkHash[chunk] = id
+			}
+			ids = append(ids, id)
+			if start == len(text) {
+				break
+			}
+		}
+		return ids
+	}
+
+	ids1 := toIDs(text1, maxChunks/2)
+	ids2 := toIDs(text2, maxChunks)
+	return ids1, ids2, chunkArray
+}
+
+// chunkSize returns DiffChunkSize, or the default size if it is not set.
+func (dmp *DiffMatchPatch) chunkSize() int {
+	if dmp.DiffChunkSize > 0 {
+		return dmp.DiffChunkSize
+	}
+	return defaultChunkSize
+}
+
+// DiffChunksToChars splits two texts into content-defined chunks of about DiffChunkSize runes, and reduces the texts to strings in which every rune stands for one chunk, like DiffLinesToChars does for lines.
+// Equal runs of text are mostly split into equal chunks, even within a single long line. DiffCharsToLines converts the diff of the strings back into text.
+func (dmp *DiffMatchPatch) DiffChunksToChars(text1, text2 string) (string, string, []string) {
+	runes1, runes2, chunkArray := dmp.DiffChunksToRunes(text1, text2)
+	return string(runes1), string(runes2), chunkArray
+}
+
+// DiffChunksToRunes is DiffChunksToChars for rune slices.
+func (dmp *DiffMatchPatch) DiffChunksToRunes(text1, text2 string) ([]rune, []rune, []string) {
+	// Like for lines, the first chunk is a junk entry to avoid generating a null character.
+	ids1, ids2, chunkArray := chunksToIDs([]rune(text1), []rune(text2), dmp.chunkSize(), []string{""}, maxRuneInt)
+	for _, ids := range [][]rune{ids1, ids2} {
+		for i, id := range ids {
+			ids[i] = intToRune(uint32(id))
+		}
+	}
+	return ids1, ids2, chunkArray
+}
+
+// usesChunks returns whether the line mode speedup diffs chunks of DiffChunkSize instead of lines, i.e. whether the lines of the texts are longer than the chunks on average.
+func (dmp *DiffMatchPatch) usesChunks(text1, text2 []rune) bool {
+	if dmp.DiffChunkSize <= 0 {
+		return false
+	}
+	lines := 2
+	for _, text := range [][]rune{text1, text2} {
+		for _, r := range text {
+			if r == '\n' {
+				lines++
+			}
+		}
+	}
+	return len(text1)+len(text2) > lines*dmp.DiffChunkSize
+}
+
+// diffChunks computes a chunk by chunk diff of two texts.
+func (dmp *DiffMatchPatch) diffChunks(text1, text2 []rune, deadline time.Time) []Diff {
+	ids1, ids2, chunkArray := chunksToIDs(text1, text2, dmp.DiffChunkSize, nil, math.MaxInt32)
+	return dmp.diffIDs(ids1, ids2, chunkArray, deadline)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkBoundaries(t *testing.T) {
+	s1, _ := speedtestTexts()
+	text := []rune(strings.Replace(s1, "\n", " ", -1))
+
+	for i, size := range []int{1, 4, 16, 64, 256} {
+		ends := chunkBoundaries(text, size)
+		assert.Equal(t, len(text), ends[len(ends)-1], fmt.Sprintf("Test case #%d, size %d", i, size))
+		start := 0
+		for _, end := range ends[:len(ends)-1] {
+			assert.True(t, end-start >= size/4 && end-start <= 4*size, fmt.Sprintf("Test case #%d, size %d: chunk of %d runes", i, size, end-start))
+			start = end
+		}
+	}
+
+	// Boundaries after an insertion are those of the original text, shifted by the length of the insertion.
+	ends := chunkBoundaries(text, 64)
+	inserted := chunkBoundaries(append([]rune("inserted text"), text...), 64)
+	shifted := map[int]bool{}
+	for _, end := range inserted {
+		shifted[end-len("inserted text")] = true
+	}
+	resynchronised := 0
+	for _, end := range ends {
+		if shifted[end] {
+			resynchronised++
+		}
+	}
+	assert.True(t, resynchronised > len(ends)*9/10, fmt.Sprintf("%d of %d boundaries resynchronised", resynchronised, len(ends)))
+}
+
+func TestDiffChunksToChars(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+	}
+
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffChunkSize = 8
+
+	for i, tc := range []TestCase{
+		{"Empty", "", ""},
+		{"Equal", "The quick brown fox jumps over the lazy dog.", "The quick brown fox jumps over the lazy dog."},
+		{"Different", "The quick brown fox jumps over the lazy dog.", "The quick brown fox leaps over the lazy cat."},
+		{"Speedtest", s1, s2},
+	} {
+		chars1, chars2, chunkArray := dmp.DiffChunksToChars(tc.Text1, tc.Text2)
+		assert.Equal(t, "", chunkArray[0], fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.Text1 == tc.Text2 {
+			assert.Equal(t, chars1, chars2, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+
+		diffs := dmp.DiffMain(chars1, chars2, false)
+		diffs, err := dmp.DiffCharsToLinesChecked(diffs, chunkArray)
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffMainChunks(t *testing.T) {
+	// A long single line, like minified JSON, where the line mode speedup does not help.
+	s1, s2 := speedtestTexts()
+	s1 = strings.Replace(s1, "\n", " ", -1)
+	s2 = strings.Replace(s2, "\n", " ", -1)
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	dmp.DiffChunkSize = 64
+	dmp.DiffMetrics = &Metrics{}
+
+	diffs := dmp.DiffMain(s1, s2, true)
+	assert.Equal(t, s1, dmp.DiffText1(diffs))
+	assert.Equal(t, s2, dmp.DiffText2(diffs))
+	assert.True(t, dmp.DiffMetrics.LineMode > 0)
+
+	// Texts with short lines are still diffed line by line.
+	assert.False(t, dmp.usesChunks([]rune("a\nb\nc\n"), []rune("a\nc\n")))
+	assert.True(t, dmp.usesChunks([]rune(s1), []rune(s2)))
+	dmp.DiffChunkSize = 0
+	assert.False(t, dmp.usesChunks([]rune(s1), []rune(s2)))
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode"
+)
+
+// comparesExactly returns whether texts are diffed as they are, rather than with some differences ignored.
+func (dmp *DiffMatchPatch) comparesExactly() bool {
+	return !dmp.IgnoreAllSpace && !dmp.IgnoreSpaceChange && !dmp.IgnoreBlankLines && !dmp.IgnoreLineEndings && dmp.Normalizer == nil
+}
+
+// NormalizationForm is a Unicode normalization form, such as norm.NFC or norm.NFD of the package golang.org/x/text/unicode/norm.
+type NormalizationForm interface {
+	String(s string) string
+}
+
+// WithUnicodeNormalization returns a copy of dmp whose diffs compare text in the normalization form, e.g. so that "é" precomposed compares equal to "e" followed by a combining acute accent.
+// The form is applied before the Normalizer of dmp, if any. As with Normalizer, the diffs contain the original texts, and parts which compare equal are emitted with the text of text1.
+func (dmp *DiffMatchPatch) WithUnicodeNormalization(form NormalizationForm) *DiffMatchPatch {
+	normalized := *dmp
+	if normalizer := dmp.Normalizer; normalizer != nil {
+		normalized.Normalizer = func(s string) string {
+			return normalizer(form.String(s))
+		}
+	} else {
+		normalized.Normalizer = form.String
+	}
+	return &normalized
+}
+
+// diffMainCompared diffs the texts reduced to the units which take part in the comparison, and maps the result back onto the original texts.
+// Parts which compare equal are emitted with the text of text1, so the differences which are ignored do not show up in the diff.
+func (dmp *DiffMatchPatch) diffMainCompared(text1, text2 []rune, checklines bool) []Diff {
+	// With a normalizer, units are the numbers of the distinct normalized grapheme clusters.
+	var ids map[string]rune
+	if dmp.Normalizer != nil {
+		ids = map[string]rune{}
+	}
+	units1, starts1 := dmp.comparisonUnits(text1, ids)
+	units2, starts2 := dmp.comparisonUnits(text2, ids)
+
+	// Every unit extends up to the next one, so that ignored text belongs to the unit in front of it.
+	span := func(text []rune, starts []int, from, to int) string {
+		start, end := 0, len(text)
+		if from > 0 {
+			start = starts[from]
+		}
+		if to < len(starts) {
+			end = starts[to]
+		}
+		return string(text[start:end])
+	}
+
+	var diffs []Diff
+	if len(units1) == 0 && len(text1) != 0 {
+		// Only ignored text, which has no unit to belong to.
+		diffs = append(diffs, Diff{DiffEqual, string(text1)})
+	}
+	pointer1, pointer2 := 0, 0
+	for _, aDiff := range dmp.diffRunes(units1, units2, checklines) {
+		n := len(aDiff.Text)
+		switch aDiff.Type {
+		case DiffEqual:
+			diffs = append(diffs, Diff{DiffEqual, span(text1, starts1, pointer1, pointer1+n)})
+			pointer1 += n
+			pointer2 += n
+		case DiffDelete:
+			diffs = append(diffs, Diff{DiffDelete, span(text1, starts1, pointer1, pointer1+n)})
+			pointer1 += n
+		case DiffInsert:
+			diffs = append(diffs, Diff{DiffInsert, span(text2, starts2, pointer2, pointer2+n)})
+			pointer2 += n
+		}
+	}
+	// The diff is already merged, merging the original texts would split the units.
+	return dmp.DiffCanonicalize(diffs)
+}
+
+// comparisonUnits reduces a text to the units which take part in a comparison, and returns them with their offsets in the text.
+// Units are the runes of the text, unless ids is given to number the normalized grapheme clusters of the text.
+func (dmp *DiffMatchPatch) comparisonUnits(text []rune, ids map[string]rune) ([]rune, []int) {
+	units := make([]rune, 0, len(text))
+	starts := make([]int, 0, len(text))
+
+	add := func(token []rune, key string, start int) {
+		unit := token[0]
+		if ids != nil {
+			id, ok := ids[key]
+			if !ok {
+				id = rune(len(ids))
+				ids[key] = id
+			}
+			unit = id
+		}
+		units = append(units, unit)
+		starts = append(starts, start)
+	}
+	space := []rune{' '}
+	newline := []rune{'\n'}
+
+	for lineStart := 0; lineStart < len(text); {
+		lineEnd := lineStart
+		for lineEnd < len(text) && text[lineEnd] != '\n' {
+			lineEnd++
+		}
+		line := text[lineStart:lineEnd]
+		newlineStart := lineEnd
+		if dmp.IgnoreLineEndings && lineEnd < len(text) && len(line) != 0 && line[len(line)-1] == '\r' {
+			// The carriage return belongs to the line break.
+			line = line[:len(line)-1]
+			newlineStart--
+		}
+		if dmp.IgnoreBlankLines && isBlank(line) {
+			// Skip the line including its line break.
+			lineStart = lineEnd + 1
+			continue
+		}
+
+		boundaries := dmp.comparisonBoundaries(line)
+		for t := 0; t < len(boundaries)-1; {
+			token := line[boundaries[t]:boundaries[t+1]]
+			if !isBlank(token) || (!dmp.IgnoreAllSpace && !dmp.IgnoreSpaceChange) {
+				key := ""
+				if ids != nil {
+					key = dmp.Normalizer(string(token))
+				}
+				add(token, key, lineStart+boundaries[t])
+				t++
+				continue
+			}
+			spaceStart := boundaries[t]
+			for t < len(boundaries)-1 && isBlank(line[boundaries[t]:boundaries[t+1]]) {
+				t++
+			}
+			if !dmp.IgnoreAllSpace && t < len(boundaries)-1 {
+				// A change of the amount of white space, but not trailing white space, is ignored.
+				add(space, " ", lineStart+spaceStart)
+			}
+		}
+		if lineEnd < len(text) {
+			add(newline, "\n", newlineStart)
+		}
+		lineStart = lineEnd + 1
+	}
+
+	return units, starts
+}
+
+// comparisonBoundaries returns the offsets at which the tokens of a line start, followed by len(line).
+// Tokens are single runes, or grapheme clusters if they are normalized.
+func (dmp *DiffMatchPatch) comparisonBoundaries(line []rune) []int {
+	boundaries := make([]int, 0, len(line)+1)
+	if dmp.Normalizer == nil {
+		for i := range line {
+			boundaries = append(boundaries, i)
+		}
+		return append(boundaries, len(line))
+	}
+
+	// Convert the byte offsets of the clusters to rune offsets.
+	text := string(line)
+	byteBoundaries := graphemeBoundaries(text)
+	runes := 0
+	for i := range text {
+		if i == byteBoundaries[len(boundaries)] {
+			boundaries = append(boundaries, runes)
+		}
+		runes++
+	}
+	return append(boundaries, len(line))
+}
+
+// isBlank returns whether a line contains nothing but white space.
+func isBlank(line []rune) bool {
+	for _, r := range line {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffIgnoreSpace(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1             string
+		Text2             string
+		IgnoreAllSpace    bool
+		IgnoreSpaceChange bool
+		IgnoreBlankLines  bool
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No white space", "abc", "abd", true, false, false, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"All space", "a b\tc\n", "abc  \n", true, false, false, []Diff{{DiffEqual, "a b\tc\n"}}},
+		{"All space keeps line breaks", "a b\nc", "a bc", true, false, false, []Diff{{DiffEqual, "a b"}, {DiffDelete, "\n"}, {DiffEqual, "c"}}},
+		{"All space with change", "if (a == b)\n", "if(a==c)\n", true, false, false, []Diff{{DiffEqual, "if (a == "}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, ")\n"}}},
+		{"Space change", "a  b \nc", "a b\nc", false, true, false, []Diff{{DiffEqual, "a  b \nc"}}},
+		{"Space change keeps separation", "a b", "ab", false, true, false, []Diff{{DiffEqual, "a"}, {DiffDelete, " "}, {DiffEqual, "b"}}},
+		{"Space change with tabs", "\tx = 1\n", "  x =\t2\n", false, true, false, []Diff{{DiffEqual, "\tx = "}, {DiffDelete, "1"}, {DiffInsert, "2"}, {DiffEqual, "\n"}}},
+		{"Blank lines", "a\n\n  \nb\n", "a\nb\n\n", false, false, true, []Diff{{DiffEqual, "a\n\n  \nb\n"}}},
+		{"Blank lines with change", "a\n\nb\n", "a\nc\n", false, false, true, []Diff{{DiffEqual, "a\n\n"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "\n"}}},
+		{"Blank lines still compare white space", "a \n", "a\n", false, false, true, []Diff{{DiffEqual, "a"}, {DiffDelete, " "}, {DiffEqual, "\n"}}},
+		{"Only white space", "  ", "\t", true, false, false, []Diff{{DiffEqual, "  "}}},
+		{"Only white space inserted", "", " \n", false, false, true, nil},
+		{"Insertion into white space", "  ", "x", true, false, false, []Diff{{DiffEqual, "  "}, {DiffInsert, "x"}}},
+	} {
+		dmp.IgnoreAllSpace = tc.IgnoreAllSpace
+		dmp.IgnoreSpaceChange = tc.IgnoreSpaceChange
+		dmp.IgnoreBlankLines = tc.IgnoreBlankLines
+
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffNormalizer(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1      string
+		Text2      string
+		Normalizer func(string) string
+		IgnoreAll  bool
+
+		Expected []Diff
+	}
+
+	// Composes the only decomposed character of the test cases, like NFC does.
+	nfc := func(s string) string {
+		return strings.Replace(s, "é", "é", -1)
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Case-insensitive", "Hello World", "hello WORLD", strings.ToLower, false, []Diff{{DiffEqual, "Hello World"}}},
+		{"Case-insensitive with change", "Hello World", "HELLO there", strings.ToLower, false, []Diff{{DiffEqual, "Hello "}, {DiffDelete, "Wo"}, {DiffInsert, "the"}, {DiffEqual, "r"}, {DiffDelete, "ld"}, {DiffInsert, "e"}}},
+		{"Composed and decomposed", "café au lait", "café o lait", nfc, false, []Diff{{DiffEqual, "café "}, {DiffDelete, "au"}, {DiffInsert, "o"}, {DiffEqual, " lait"}}},
+		{"Clusters are not split", "é", "e", nfc, false, []Diff{{DiffDelete, "é"}, {DiffInsert, "e"}}},
+		{"Combined with white space", "A B\n", "ab\n", strings.ToLower, true, []Diff{{DiffEqual, "A B\n"}}},
+	} {
+		dmp.Normalizer = tc.Normalizer
+		dmp.IgnoreAllSpace = tc.IgnoreAll
+
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+// composedForm composes the decomposed characters of the test cases, like norm.NFC does.
+type composedForm struct{}
+
+func (composedForm) String(s string) string {
+	return strings.NewReplacer("e\u0301", "\u00e9", "A\u030a", "\u00c5", "\u212b", "\u00c5").Replace(s)
+}
+
+func TestDiffWithUnicodeNormalization(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1      string
+		Text2      string
+		Normalizer func(string) string
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Composed and decomposed", "caf\u00e9", "cafe\u0301", nil, []Diff{{DiffEqual, "caf\u00e9"}}},
+		{"Decomposed and composed", "cafe\u0301 noir", "caf\u00e9 bleu", nil, []Diff{{DiffEqual, "cafe\u0301 "}, {DiffDelete, "noir"}, {DiffInsert, "bleu"}}},
+		{"Original bytes of edits", "\u00c5 x", "A\u030a \u212b", nil, []Diff{{DiffEqual, "\u00c5 "}, {DiffDelete, "x"}, {DiffInsert, "\u212b"}}},
+		{"Different letters", "caf\u00e9", "cafe", nil, []Diff{{DiffEqual, "caf"}, {DiffDelete, "\u00e9"}, {DiffInsert, "e"}}},
+		{"With a normalizer", "CAF\u00e9", "cafe\u0301", strings.ToLower, []Diff{{DiffEqual, "CAF\u00e9"}}},
+	} {
+		dmp.Normalizer = tc.Normalizer
+		actual := dmp.WithUnicodeNormalization(composedForm{}).DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+	// The configuration of dmp is left as is.
+	dmp.Normalizer = nil
+	dmp.WithUnicodeNormalization(composedForm{})
+	assert.Nil(t, dmp.Normalizer)
+}
+
+func TestDiffIgnoreLineEndings(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []Diff
+	}
+
+	dmp := New()
+	dmp.IgnoreLineEndings = true
+
+	for i, tc := range []TestCase{
+		{"Only line endings", "a\r\nb\r\n", "a\nb\n", []Diff{{DiffEqual, "a\r\nb\r\n"}}},
+		{"Mixed line endings", "a\nb\r\nc", "a\r\nb\nc", []Diff{{DiffEqual, "a\nb\r\nc"}}},
+		{"Change", "a\r\nb\r\n", "a\nc\n", []Diff{{DiffEqual, "a\r\n"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "\r\n"}}},
+		{"Inserted line", "a\r\nb\r\n", "a\nx\nb\n", []Diff{{DiffEqual, "a\r\n"}, {DiffInsert, "x\n"}, {DiffEqual, "b\r\n"}}},
+		{"Carriage return within a line", "a\rb\n", "ab\n", []Diff{{DiffEqual, "a"}, {DiffDelete, "\r"}, {DiffEqual, "b\n"}}},
+	} {
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
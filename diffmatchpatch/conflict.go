@@ -0,0 +1,65 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+// Conflict is a region of a base text which patches of two patch sets both change.
+type Conflict struct {
+	// Index of the conflicting patch in the first patch set.
+	IndexA int
+	// Index of the conflicting patch in the second patch set.
+	IndexB int
+	// Location in bytes of the region in the base text, and its length, which is 0 if a patch inserts text at the edge of or within the changes of the other.
+	Start  int
+	Length int
+}
+
+// patchRange is the part of a base text which a patch changes, without its context.
+type patchRange struct {
+	start, end int
+}
+
+// patchRanges locates the changes of each patch in the text the patches were made against.
+// Patches are expected in the text as modified by the preceding patches, so the shifts of the preceding patches are undone.
+func (dmp *DiffMatchPatch) patchRanges(patches []Patch) []patchRange {
+	ranges := make([]patchRange, len(patches))
+	shift := 0
+	for i, aPatch := range patches {
+		start := aPatch.Start1 - shift
+		// Skip the leading and trailing context.
+		first, last := 0, len(aPatch.Diffs)
+		for first < last && aPatch.Diffs[first].Type == DiffEqual {
+			start += len(aPatch.Diffs[first].Text)
+			first++
+		}
+		for last > first && aPatch.Diffs[last-1].Type == DiffEqual {
+			last--
+		}
+		ranges[i] = patchRange{start, start + len(dmp.DiffText1(aPatch.Diffs[first:last]))}
+		shift += aPatch.Length2 - aPatch.Length1
+	}
+	return ranges
+}
+
+// PatchConflicts reports the regions of a base text which patches of two patch sets, both made against the base text, change concurrently, e.g. to detect conflicts before transforming or merging the changes of two users.
+// Patches conflict if their changes overlap, or if one inserts text at the edge of or within the changes of the other.  Their context is not taken into account.
+func (dmp *DiffMatchPatch) PatchConflicts(a, b []Patch) []Conflict {
+	rangesA := dmp.patchRanges(a)
+	rangesB := dmp.patchRanges(b)
+	conflicts := []Conflict{}
+	for i, rangeA := range rangesA {
+		for j, rangeB := range rangesB {
+			start := max(rangeA.start, rangeB.start)
+			end := min(rangeA.end, rangeB.end)
+			if start < end || (start == end && (rangeA.start == rangeA.end || rangeB.start == rangeB.end)) {
+				conflicts = append(conflicts, Conflict{IndexA: i, IndexB: j, Start: start, Length: end - start})
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchConflicts(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		TextA string
+		TextB string
+
+		Expected []Conflict
+	}
+
+	dmp := New()
+
+	base := "The quick brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs."
+
+	for i, tc := range []TestCase{
+		{"No changes", base, base, []Conflict{}},
+		{"Separate changes", "The slow brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", "The quick brown fox jumps over the lazy dog.  Pack my box with six dozen liquor jugs.", []Conflict{}},
+		{"Same change", "The slow brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", "The slow brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", []Conflict{{0, 0, 4, 5}}},
+		{"Adjacent changes", "The quick red fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", "The quick brown cat jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", []Conflict{}},
+		{"Overlapping deletions", "The quick fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", "The quick jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", []Conflict{{0, 0, 10, 6}}},
+		{"Insertions at the same place", "The quick brown fox jumps over the very lazy dog.  Pack my box with five dozen liquor jugs.", "The quick brown fox jumps over the old lazy dog.  Pack my box with five dozen liquor jugs.", []Conflict{{0, 0, 35, 0}}},
+		{"Shifted by a preceding patch", "A quick brown fox jumps over the lazy dog.  Pack my box with six dozen liquor jugs.", "The quick brown fox jumps over the lazy dog.  Pack my box with seven dozen liquor jugs.", []Conflict{{1, 0, 63, 4}}},
+	} {
+		a := dmp.PatchMake(base, tc.TextA)
+		b := dmp.PatchMake(base, tc.TextB)
+		actual := dmp.PatchConflicts(a, b)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// Conflicts are symmetric.
+		for j := range actual {
+			actual[j].IndexA, actual[j].IndexB = actual[j].IndexB, actual[j].IndexA
+		}
+		assert.ElementsMatch(t, actual, dmp.PatchConflicts(b, a), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package conformance checks diffmatchpatch against test vectors taken from the test suites of the reference diff-match-patch implementations.
+// The vectors in testdata are a hand-picked selection of cases, not the complete suites.
+// Vectors are stored as a JSON array, with diffs written as [operation, text] pairs like in the JavaScript and Python ports.
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Vector is a single conformance test.  Which of the inputs are used depends on Function.
+type Vector struct {
+	Name string `json:"name"`
+	// Name of the reference function under test, e.g. "diff_toDelta".
+	Function string `json:"function"`
+
+	Diffs   Diffs  `json:"diffs,omitempty"`
+	Text1   string `json:"text1,omitempty"`
+	Text2   string `json:"text2,omitempty"`
+	Text    string `json:"text,omitempty"`
+	Delta   string `json:"delta,omitempty"`
+	Patch   string `json:"patch,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Loc     int    `json:"loc,omitempty"`
+
+	// The expected result, whose type depends on Function.
+	Expected json.RawMessage `json:"expected"`
+}
+
+// Diffs is a list of diffs encoded as [operation, text] pairs.
+type Diffs []diffmatchpatch.Diff
+
+// MarshalJSON encodes diffs as [operation, text] pairs.
+func (d Diffs) MarshalJSON() ([]byte, error) {
+	pairs := make([][2]interface{}, len(d))
+	for i, aDiff := range d {
+		pairs[i] = [2]interface{}{aDiff.Type, aDiff.Text}
+	}
+	return json.Marshal(pairs)
+}
+
+// UnmarshalJSON decodes diffs from [operation, text] pairs.
+func (d *Diffs) UnmarshalJSON(data []byte) error {
+	var pairs [][2]json.RawMessage
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	diffs := make(Diffs, len(pairs))
+	for i, pair := range pairs {
+		var op int
+		if err := json.Unmarshal(pair[0], &op); err != nil {
+			return err
+		}
+		if op < -1 || op > 1 {
+			return fmt.Errorf("Invalid diff operation %v", op)
+		}
+		diffs[i].Type = diffmatchpatch.Operation(op)
+		if err := json.Unmarshal(pair[1], &diffs[i].Text); err != nil {
+			return err
+		}
+	}
+	*d = diffs
+	return nil
+}
+
+// Load reads a JSON array of vectors.
+func Load(r io.Reader) ([]Vector, error) {
+	var vectors []Vector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// patchApplyResult is the result of patch_apply, a [text, results] pair.
+type patchApplyResult [2]interface{}
+
+// Check runs the vector against dmp and returns an error describing any difference from the expected result.
+// dmp should have StrictCompat set so that deltas count UTF-16 code units like the reference implementations.  Like the reference tests, diff_main vectors are checked without a timeout.
+func Check(dmp *diffmatchpatch.DiffMatchPatch, v Vector) error {
+	var actual, expected interface{}
+	switch v.Function {
+	case "diff_main":
+		// The reference tests disable the timeout to get deterministic results.
+		settings := *dmp
+		settings.DiffTimeout = 0
+		actual = Diffs(settings.DiffMain(v.Text1, v.Text2, false))
+		expected = &Diffs{}
+	case "diff_levenshtein":
+		actual = dmp.DiffLevenshtein(v.Diffs)
+		expected = new(int)
+	case "diff_toDelta":
+		actual = dmp.DiffToDelta(v.Diffs)
+		expected = new(string)
+	case "diff_fromDelta":
+		diffs, err := dmp.DiffFromDelta(v.Text1, v.Delta)
+		if err != nil {
+			return err
+		}
+		actual = Diffs(diffs)
+		expected = &Diffs{}
+	case "patch_make":
+		actual = dmp.PatchToText(dmp.PatchMake(v.Text1, v.Text2))
+		expected = new(string)
+	case "patch_fromText":
+		patches, err := dmp.PatchFromText(v.Patch)
+		if err != nil {
+			return err
+		}
+		actual = dmp.PatchToText(patches)
+		expected = new(string)
+	case "patch_apply":
+		text, results := dmp.PatchApply(dmp.PatchMake(v.Text1, v.Text2), v.Text)
+		applied := make([]interface{}, len(results))
+		for i, result := range results {
+			applied[i] = result
+		}
+		actual = patchApplyResult{text, applied}
+		expected = &patchApplyResult{}
+	case "match_main":
+		actual = dmp.MatchMain(v.Text, v.Pattern, v.Loc)
+		expected = new(int)
+	default:
+		return fmt.Errorf("%s: unsupported function %q", v.Name, v.Function)
+	}
+
+	if err := json.Unmarshal(v.Expected, expected); err != nil {
+		return fmt.Errorf("%s: invalid expected result: %v", v.Name, err)
+	}
+	expected = reflect.ValueOf(expected).Elem().Interface()
+	if diffs, ok := actual.(Diffs); ok && diffs == nil {
+		// An empty result is an empty list, not null.
+		actual = Diffs{}
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		return fmt.Errorf("%s: %s returned %#v, expected %#v", v.Name, v.Function, actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestUpstreamVectors(t *testing.T) {
+	f, err := os.Open("testdata/upstream.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	vectors, err := Load(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, vectors)
+
+	dmp := diffmatchpatch.New()
+	dmp.StrictCompat = true
+
+	for i, v := range vectors {
+		assert.NoError(t, Check(dmp, v), fmt.Sprintf("Test case #%d, %s", i, v.Name))
+	}
+}
+
+func TestCheck(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Vector string
+
+		ErrorContains string
+	}
+
+	dmp := diffmatchpatch.New()
+	dmp.StrictCompat = true
+
+	for i, tc := range []TestCase{
+		{"Match", `{"name": "n", "function": "diff_toDelta", "diffs": [[0, "a"], [1, "b"]], "expected": "=1\t+b"}`, ""},
+		{"Mismatch", `{"name": "n", "function": "diff_toDelta", "diffs": [[0, "a"], [1, "b"]], "expected": "=1\t+c"}`, "expected"},
+		{"Split surrogate pair", `{"name": "n", "function": "diff_fromDelta", "text1": "🅰", "delta": "=1", "expected": [[0, "🅰"]]}`, "surrogate pair"},
+		{"Invalid expected result", `{"name": "n", "function": "match_main", "text": "abc", "pattern": "b", "expected": "1"}`, "invalid expected result"},
+		{"Unsupported function", `{"name": "n", "function": "diff_bisect", "expected": null}`, "unsupported function"},
+	} {
+		var v Vector
+		if !assert.NoError(t, json.Unmarshal([]byte(tc.Vector), &v), fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			continue
+		}
+		err := Check(dmp, v)
+		if tc.ErrorContains == "" {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			assert.Contains(t, err.Error(), tc.ErrorContains, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffsJSON(t *testing.T) {
+	diffs := Diffs{{Type: diffmatchpatch.DiffDelete, Text: "a"}, {Type: diffmatchpatch.DiffEqual, Text: "b"}, {Type: diffmatchpatch.DiffInsert, Text: "c"}}
+
+	data, err := json.Marshal(diffs)
+	assert.NoError(t, err)
+	assert.Equal(t, `[[-1,"a"],[0,"b"],[1,"c"]]`, string(data))
+
+	var actual Diffs
+	assert.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, diffs, actual)
+
+	err = json.Unmarshal([]byte(`[[2, "a"]]`), &actual)
+	assert.True(t, err != nil && strings.Contains(err.Error(), "Invalid diff operation"))
+}
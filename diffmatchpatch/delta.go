@@ -0,0 +1,139 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DeltaUnit is the unit in which deltas count the lengths of equalities and deletions.
+type DeltaUnit int
+
+const (
+	// DeltaRunes counts Unicode code points, like the Python port.
+	DeltaRunes DeltaUnit = iota
+	// DeltaUTF16 counts UTF-16 code units, like the JavaScript and Java ports.
+	DeltaUTF16
+	// DeltaBytes counts bytes of UTF-8.
+	DeltaBytes
+)
+
+// deltaUnitNames are the names of the units in the header of version 2 deltas.
+var deltaUnitNames = map[DeltaUnit]string{
+	DeltaRunes: "runes",
+	DeltaUTF16: "utf16",
+	DeltaBytes: "bytes",
+}
+
+// String returns the name of the unit as used in version 2 deltas.
+func (u DeltaUnit) String() string {
+	if name, ok := deltaUnitNames[u]; ok {
+		return name
+	}
+	return fmt.Sprintf("DeltaUnit(%d)", int(u))
+}
+
+// deltaEscape names the escaping of inserted text: percent-encoding of everything JavaScript's encodeURI encodes, except for spaces.
+const deltaEscape = "uri"
+
+// DiffToDeltaV2 encodes a diff like DiffToDelta, prefixed by a header line which declares the counting unit and the escaping of inserted text, e.g. "v2 units=utf16 escape=uri".
+func (dmp *DiffMatchPatch) DiffToDeltaV2(diffs []Diff, unit DeltaUnit) string {
+	return fmt.Sprintf("v2 units=%s escape=%s\n", unit, deltaEscape) + dmp.diffToDelta(diffs, unit)
+}
+
+// DiffFromDeltaV2 decodes a delta produced by DiffToDeltaV2 of the source text text1, using the counting unit declared by its header.
+func (dmp *DiffMatchPatch) DiffFromDeltaV2(text1 string, delta string) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	newline := strings.IndexByte(delta, '\n')
+	if newline == -1 {
+		return nil, errors.New("Missing header in DiffFromDeltaV2")
+	}
+	fields := strings.Fields(delta[:newline])
+	if len(fields) == 0 || fields[0] != "v2" {
+		return nil, fmt.Errorf("Unsupported delta version in DiffFromDeltaV2: %q", delta[:newline])
+	}
+
+	unit := DeltaUnit(-1)
+	escape := ""
+	for _, field := range fields[1:] {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid header field in DiffFromDeltaV2: %q", field)
+		}
+		switch kv[0] {
+		case "units":
+			for u, name := range deltaUnitNames {
+				if name == kv[1] {
+					unit = u
+				}
+			}
+			if unit == -1 {
+				return nil, fmt.Errorf("Unsupported unit in DiffFromDeltaV2: %q", kv[1])
+			}
+		case "escape":
+			if kv[1] != deltaEscape {
+				return nil, fmt.Errorf("Unsupported escaping in DiffFromDeltaV2: %q", kv[1])
+			}
+			escape = kv[1]
+		default:
+			return nil, fmt.Errorf("Invalid header field in DiffFromDeltaV2: %q", field)
+		}
+	}
+	if unit == -1 || escape == "" {
+		return nil, errors.New("Incomplete header in DiffFromDeltaV2")
+	}
+
+	return dmp.diffFromDelta(text1, delta[newline+1:], unit)
+}
+
+// deltaUnit returns the unit counted by DiffToDelta and DiffFromDelta.
+func (dmp *DiffMatchPatch) deltaUnit() DeltaUnit {
+	if dmp.StrictCompat {
+		return DeltaUTF16
+	}
+	return dmp.DeltaUnits
+}
+
+// deltaLength returns the length of text in the given unit.
+func deltaLength(text string, unit DeltaUnit) int {
+	switch unit {
+	case DeltaUTF16:
+		return utf16Len(text)
+	case DeltaBytes:
+		return len(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// deltaAdvance returns the byte offset which is n units after the byte offset i of text.  The result exceeds len(text) if text is too short.
+func deltaAdvance(text string, i int, n int, unit DeltaUnit) (int, error) {
+	if unit == DeltaBytes {
+		i += n
+		if i < len(text) && !utf8.RuneStart(text[i]) {
+			return 0, errors.New("Delta splits a UTF-8 sequence in DiffFromDelta")
+		}
+		return i, nil
+	}
+	for n > 0 && i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unit == DeltaUTF16 {
+			n -= utf16RuneLen(r)
+		} else {
+			n--
+		}
+		i += size
+	}
+	if n < 0 {
+		return 0, errors.New("Delta splits a surrogate pair in DiffFromDelta")
+	}
+	return i + n, nil
+}
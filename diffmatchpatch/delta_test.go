@@ -0,0 +1,116 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToDeltaV2(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+		Unit  DeltaUnit
+
+		Expected string
+	}
+
+	dmp := New()
+
+	diffs := []Diff{{DiffEqual, "aé"}, {DiffDelete, "\U0001F170"}, {DiffInsert, "x y"}, {DiffEqual, "z"}}
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, DeltaRunes, "v2 units=runes escape=uri\n"},
+		{"Runes", diffs, DeltaRunes, "v2 units=runes escape=uri\n=2\t-1\t+x y\t=1"},
+		{"UTF-16", diffs, DeltaUTF16, "v2 units=utf16 escape=uri\n=2\t-2\t+x y\t=1"},
+		{"Bytes", diffs, DeltaBytes, "v2 units=bytes escape=uri\n=3\t-4\t+x y\t=1"},
+	} {
+		actual := dmp.DiffToDeltaV2(tc.Diffs, tc.Unit)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actualDiffs, err := dmp.DiffFromDeltaV2(dmp.DiffText1(tc.Diffs), actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if len(tc.Diffs) == 0 {
+			assert.Empty(t, actualDiffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.Equal(t, tc.Diffs, actualDiffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffFromDeltaV2(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Delta string
+
+		ErrorMessage string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Header fields in any order", "ab", "v2 escape=uri units=bytes\n=1\t-1", ""},
+		{"Missing header", "ab", "=2", "Missing header in DiffFromDeltaV2"},
+		{"Legacy delta", "ab", "=1\n=1", "Unsupported delta version in DiffFromDeltaV2: \"=1\""},
+		{"Unknown unit", "ab", "v2 units=words escape=uri\n=2", "Unsupported unit in DiffFromDeltaV2: \"words\""},
+		{"Unknown escaping", "ab", "v2 units=runes escape=base64\n=2", "Unsupported escaping in DiffFromDeltaV2: \"base64\""},
+		{"Unknown field", "ab", "v2 units=runes escape=uri level=9\n=2", "Invalid header field in DiffFromDeltaV2: \"level=9\""},
+		{"Incomplete header", "ab", "v2 units=runes\n=2", "Incomplete header in DiffFromDeltaV2"},
+		{"Split UTF-8 sequence", "é", "v2 units=bytes escape=uri\n=1\t-1", "Delta splits a UTF-8 sequence in DiffFromDelta"},
+		{"Split surrogate pair", "\U0001F170", "v2 units=utf16 escape=uri\n=1\t-1", "Delta splits a surrogate pair in DiffFromDelta"},
+		{"Wrong unit", "é", "v2 units=runes escape=uri\n=2", "Delta length (2) is different from source text length (1)"},
+	} {
+		_, err := dmp.DiffFromDeltaV2(tc.Text1, tc.Delta)
+		if tc.ErrorMessage == "" {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.EqualError(t, err, tc.ErrorMessage, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffDeltaUnits(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Units DeltaUnit
+
+		Expected string
+	}
+
+	dmp := New()
+
+	diffs := []Diff{{DiffEqual, "\U0001F170"}, {DiffDelete, "é"}, {DiffInsert, "x"}, {DiffEqual, "\U0001F171"}}
+
+	for i, tc := range []TestCase{
+		{"Runes", DeltaRunes, "=1\t-1\t+x\t=1"},
+		{"UTF-16", DeltaUTF16, "=2\t-1\t+x\t=2"},
+		{"Bytes", DeltaBytes, "=4\t-2\t+x\t=4"},
+	} {
+		dmp.DeltaUnits = tc.Units
+
+		actual := dmp.DiffToDelta(diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actualDiffs, err := dmp.DiffFromDelta(dmp.DiffText1(diffs), actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, diffs, actualDiffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// StrictCompat always counts UTF-16 code units.
+	dmp.DeltaUnits = DeltaBytes
+	dmp.StrictCompat = true
+	assert.Equal(t, "=2\t-1\t+x\t=2", dmp.DiffToDelta(diffs))
+}
@@ -12,12 +12,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"html"
 	"math"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 )
@@ -36,12 +37,54 @@ const (
 	DiffEqual Operation = 0
 )
 
-// Diff represents one diff operation
+// ParseOperation parses the name of an operation as returned by its String method, e.g. "Insert".
+func ParseOperation(name string) (Operation, error) {
+	for _, op := range []Operation{DiffDelete, DiffEqual, DiffInsert} {
+		if op.String() == name {
+			return op, nil
+		}
+	}
+	return 0, fmt.Errorf("Invalid diff operation name: %q", name)
+}
+
+// Diff represents one diff operation.
+// The functions of this package return diffs in canonical form, see DiffCanonicalize.
 type Diff struct {
 	Type Operation
 	Text string
 }
 
+// NewDiff creates a diff, checking that op is one of DiffDelete, DiffInsert and DiffEqual, e.g. to reject diffs with bogus operations loaded from JSON.
+func NewDiff(op Operation, text string) (Diff, error) {
+	if !op.valid() {
+		return Diff{}, fmt.Errorf("Invalid diff operation: %v", op)
+	}
+	return Diff{op, text}, nil
+}
+
+// valid returns whether op is one of DiffDelete, DiffInsert and DiffEqual.
+func (op Operation) valid() bool {
+	return op == DiffDelete || op == DiffInsert || op == DiffEqual
+}
+
+// runeDiff is a Diff whose text is a slice of the runes being diffed, so that the diff computation can share memory with its input.
+type runeDiff struct {
+	Type Operation
+	Text []rune
+}
+
+// runeDiffsToDiffs converts the result of a diff computation to the public representation.
+func runeDiffsToDiffs(diffs []runeDiff) []Diff {
+	if diffs == nil {
+		return nil
+	}
+	converted := make([]Diff, len(diffs))
+	for i, aDiff := range diffs {
+		converted[i] = Diff{aDiff.Type, string(aDiff.Text)}
+	}
+	return converted
+}
+
 // splice removes amount elements from slice at index index, replacing them with elements.
 func splice(slice []Diff, index int, amount int, elements ...Diff) []Diff {
 	if len(elements) == amount {
@@ -88,19 +131,119 @@ func (dmp *DiffMatchPatch) DiffMain(text1, text2 string, checklines bool) []Diff
 // DiffMainRunes finds the differences between two rune sequences.
 // If an invalid UTF-8 sequence is encountered, it will be replaced by the Unicode replacement character.
 func (dmp *DiffMatchPatch) DiffMainRunes(text1, text2 []rune, checklines bool) []Diff {
-	var deadline time.Time
-	if dmp.DiffTimeout > 0 {
-		deadline = time.Now().Add(dmp.DiffTimeout)
+	if !dmp.comparesExactly() {
+		return dmp.postProcessDiffs(dmp.diffMainCompared(text1, text2, checklines))
+	}
+	return dmp.postProcessDiffs(dmp.DiffCanonicalize(runeDiffsToDiffs(dmp.diffRunes(text1, text2, checklines))))
+}
+
+// DiffMainTruncated is DiffMain which also returns whether DiffTimeout truncated the diff, in which case it is not optimal.
+func (dmp *DiffMatchPatch) DiffMainTruncated(text1, text2 string, checklines bool) ([]Diff, bool) {
+	return dmp.DiffMainRunesTruncated([]rune(text1), []rune(text2), checklines)
+}
+
+// DiffMainRunesTruncated is DiffMainRunes which also returns whether DiffTimeout truncated the diff, in which case it is not optimal.
+func (dmp *DiffMatchPatch) DiffMainRunesTruncated(text1, text2 []rune, checklines bool) ([]Diff, bool) {
+	timed := *dmp
+	var timedOut int32
+	timed.timedOut = &timedOut
+	diffs := timed.DiffMainRunes(text1, text2, checklines)
+	return diffs, atomic.LoadInt32(&timedOut) != 0
+}
+
+// DiffFlags reports why a diff is coarser than the optimal diff.
+type DiffFlags struct {
+	// Whether DiffTimeout truncated the diff.
+	TimedOut bool
+	// Whether MaxMemoryBytes made parts of the diff fall back to the line mode speedup or to deleting and inserting them whole.
+	MemoryLimited bool
+}
+
+// DiffMainWithFlags is DiffMain which also reports whether the diff was degraded by DiffTimeout or MaxMemoryBytes.
+func (dmp *DiffMatchPatch) DiffMainWithFlags(text1, text2 string, checklines bool) ([]Diff, DiffFlags) {
+	return dmp.DiffMainRunesWithFlags([]rune(text1), []rune(text2), checklines)
+}
+
+// DiffMainRunesWithFlags is DiffMainRunes which also reports whether the diff was degraded by DiffTimeout or MaxMemoryBytes.
+func (dmp *DiffMatchPatch) DiffMainRunesWithFlags(text1, text2 []rune, checklines bool) ([]Diff, DiffFlags) {
+	flagged := *dmp
+	var timedOut, memoryLimited int32
+	flagged.timedOut = &timedOut
+	flagged.memoryLimited = &memoryLimited
+	diffs := flagged.DiffMainRunes(text1, text2, checklines)
+	return diffs, DiffFlags{
+		TimedOut:      atomic.LoadInt32(&timedOut) != 0,
+		MemoryLimited: atomic.LoadInt32(&memoryLimited) != 0,
+	}
+}
+
+// diffRunes starts a diff computation of two rune sequences with the configured timeout and parallelism.
+func (dmp *DiffMatchPatch) diffRunes(text1, text2 []rune, checklines bool) []runeDiff {
+	if dmp.DiffProgress != nil && dmp.progress == nil {
+		return dmp.diffRunesWithProgress(text1, text2, checklines)
+	}
+	if dmp.DiffMetrics != nil && dmp.metrics == nil {
+		return dmp.diffRunesWithMetrics(text1, text2, checklines)
+	}
+	if dmp.MaxEditDistance > 0 && dmp.editDistance == nil {
+		return dmp.diffRunesBounded(text1, text2, checklines)
+	}
+	deadline := dmp.diffDeadline()
+	if dmp.DiffParallelism > 1 && dmp.workers == nil {
+		// Share one pool of workers across the whole recursion of this diff.
+		parallel := *dmp
+		parallel.workers = make(chan struct{}, dmp.DiffParallelism-1)
+		return parallel.diffMainRunes(text1, text2, checklines, deadline)
 	}
 	return dmp.diffMainRunes(text1, text2, checklines, deadline)
 }
 
-func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, deadline time.Time) []Diff {
+// diffDeadline returns the time at which a diff started now has to give up, or the zero time if it may take forever.
+func (dmp *DiffMatchPatch) diffDeadline() time.Time {
+	if dmp.DiffTimeout > 0 && !dmp.DiffExactMinimal {
+		return time.Now().Add(dmp.DiffTimeout)
+	}
+	return time.Time{}
+}
+
+// diffJob is an independent part of a diff computation.
+type diffJob struct {
+	text1, text2 []rune
+	checklines   bool
+	diffs        []runeDiff
+}
+
+// diffJobs computes the diffs of all jobs, in parallel if workers are available.
+func (dmp *DiffMatchPatch) diffJobs(jobs []*diffJob, deadline time.Time) {
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		if dmp.workers != nil && i < len(jobs)-1 {
+			select {
+			case dmp.workers <- struct{}{}:
+				wg.Add(1)
+				go func(job *diffJob) {
+					defer wg.Done()
+					job.diffs = dmp.diffMainRunes(job.text1, job.text2, job.checklines, deadline)
+					<-dmp.workers
+				}(job)
+				continue
+			default:
+				// All workers are busy, compute the job on this goroutine.
+			}
+		}
+		job.diffs = dmp.diffMainRunes(job.text1, job.text2, job.checklines, deadline)
+	}
+	wg.Wait()
+}
+
+func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, deadline time.Time) []runeDiff {
+	dmp.addRecursion()
 	if runesEqual(text1, text2) {
-		var diffs []Diff
+		var diffs []runeDiff
 		if len(text1) > 0 {
-			diffs = append(diffs, Diff{DiffEqual, string(text1)})
+			diffs = append(diffs, runeDiff{DiffEqual, text1})
 		}
+		dmp.reportProgress(2 * len(text1))
 		return diffs
 	}
 	// Trim off common prefix (speedup).
@@ -114,30 +257,42 @@ func (dmp *DiffMatchPatch) diffMainRunes(text1, text2 []rune, checklines bool, d
 	commonsuffix := text1[len(text1)-commonlength:]
 	text1 = text1[:len(text1)-commonlength]
 	text2 = text2[:len(text2)-commonlength]
+	dmp.reportProgress(2 * (len(commonprefix) + len(commonsuffix)))
 
 	// Compute the diff on the middle block.
-	diffs := dmp.diffCompute(text1, text2, checklines, deadline)
-
-	// Restore the prefix and suffix.
-	if len(commonprefix) != 0 {
-		diffs = append([]Diff{{DiffEqual, string(commonprefix)}}, diffs...)
-	}
-	if len(commonsuffix) != 0 {
-		diffs = append(diffs, Diff{DiffEqual, string(commonsuffix)})
+	middle := dmp.diffCompute(text1, text2, checklines, deadline)
+
+	// Restore the prefix and suffix, leaving room for the dummy entry of the merge.
+	diffs := middle
+	if len(commonprefix) != 0 || len(commonsuffix) != 0 {
+		diffs = make([]runeDiff, 0, len(middle)+3)
+		if len(commonprefix) != 0 {
+			diffs = append(diffs, runeDiff{DiffEqual, commonprefix})
+		}
+		diffs = append(diffs, middle...)
+		if len(commonsuffix) != 0 {
+			diffs = append(diffs, runeDiff{DiffEqual, commonsuffix})
+		}
 	}
 
-	return dmp.DiffCleanupMerge(diffs)
+	start := dmp.metricsNow()
+	diffs = diffCleanupMergeRunes(diffs)
+	dmp.addDuration(metricCleanup, start)
+	return diffs
 }
 
 // diffCompute finds the differences between two rune slices.  Assumes that the texts do not have any common prefix or suffix.
-func (dmp *DiffMatchPatch) diffCompute(text1, text2 []rune, checklines bool, deadline time.Time) []Diff {
-	diffs := []Diff{}
+func (dmp *DiffMatchPatch) diffCompute(text1, text2 []rune, checklines bool, deadline time.Time) []runeDiff {
 	if len(text1) == 0 {
 		// Just add some text (speedup).
-		return append(diffs, Diff{DiffInsert, string(text2)})
+		return dmp.countOperations([]runeDiff{{DiffInsert, text2}})
 	} else if len(text2) == 0 {
 		// Just delete some text (speedup).
-		return append(diffs, Diff{DiffDelete, string(text1)})
+		return dmp.countOperations([]runeDiff{{DiffDelete, text1}})
+	} else if dmp.operationsExhausted() || dmp.editDistanceExhausted() || dmp.progressCancelled() {
+		// The result is discarded or no longer wanted, give up.
+		dmp.reportProgress(len(text1) + len(text2))
+		return []runeDiff{{DiffDelete, text1}, {DiffInsert, text2}}
 	}
 
 	var longtext, shorttext []rune
@@ -156,49 +311,55 @@ func (dmp *DiffMatchPatch) diffCompute(text1, text2 []rune, checklines bool, dea
 			op = DiffDelete
 		}
 		// Shorter text is inside the longer text (speedup).
-		return []Diff{
-			Diff{op, string(longtext[:i])},
-			Diff{DiffEqual, string(shorttext)},
-			Diff{op, string(longtext[i+len(shorttext):])},
-		}
+		return dmp.countOperations([]runeDiff{
+			{op, longtext[:i]},
+			{DiffEqual, shorttext},
+			{op, longtext[i+len(shorttext):]},
+		})
 	} else if len(shorttext) == 1 {
 		// Single character string.
 		// After the previous speedup, the character can't be an equality.
-		return []Diff{
-			{DiffDelete, string(text1)},
-			{DiffInsert, string(text2)},
-		}
+		return dmp.countOperations([]runeDiff{
+			{DiffDelete, text1},
+			{DiffInsert, text2},
+		})
 		// Check to see if the problem can be split in two.
 	} else if hm := dmp.diffHalfMatch(text1, text2); hm != nil {
 		// A half-match was found, sort out the return data.
-		text1A := hm[0]
-		text1B := hm[1]
-		text2A := hm[2]
-		text2B := hm[3]
-		midCommon := hm[4]
+		text1A := hm.prefix1
+		text1B := hm.suffix1
+		text2A := hm.prefix2
+		text2B := hm.suffix2
+		midCommon := hm.common
+		dmp.reportProgress(2 * len(midCommon))
 		// Send both pairs off for separate processing.
-		diffsA := dmp.diffMainRunes(text1A, text2A, checklines, deadline)
-		diffsB := dmp.diffMainRunes(text1B, text2B, checklines, deadline)
+		jobA := &diffJob{text1: text1A, text2: text2A, checklines: checklines}
+		jobB := &diffJob{text1: text1B, text2: text2B, checklines: checklines}
+		dmp.diffJobs([]*diffJob{jobA, jobB}, deadline)
 		// Merge the results.
-		diffs := diffsA
-		diffs = append(diffs, Diff{DiffEqual, string(midCommon)})
-		diffs = append(diffs, diffsB...)
+		diffs := make([]runeDiff, 0, len(jobA.diffs)+len(jobB.diffs)+1)
+		diffs = append(diffs, jobA.diffs...)
+		diffs = append(diffs, runeDiff{DiffEqual, midCommon})
+		diffs = append(diffs, jobB.diffs...)
 		return diffs
-	} else if checklines && len(text1) > 100 && len(text2) > 100 {
+	} else if checklines && !dmp.DiffExactMinimal && dmp.DiffLineModeThreshold > 0 && len(text1) > dmp.DiffLineModeThreshold && len(text2) > dmp.DiffLineModeThreshold {
 		return dmp.diffLineMode(text1, text2, deadline)
+	} else if dmp.exceedsMemory(text1, text2) {
+		return dmp.diffMemoryLimited(text1, text2, checklines, deadline)
 	}
 	return dmp.diffBisect(text1, text2, deadline)
 }
 
-// diffLineMode does a quick line-level diff on both []runes, then rediff the parts for greater accuracy. This speedup can produce non-minimal diffs.
-func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time) []Diff {
-	// Scan the text on a line-by-line basis first.
-	text1, text2, linearray := dmp.DiffLinesToRunes(string(text1), string(text2))
-
-	diffs := dmp.diffMainRunes(text1, text2, false, deadline)
-
-	// Convert the diff back to original text.
-	diffs = dmp.DiffCharsToLines(diffs, linearray)
+// diffLineMode does a quick line-level diff on both []runes, or a chunk-level diff if DiffChunkSize is set and the lines are long, then rediff the parts for greater accuracy. This speedup can produce non-minimal diffs.
+func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time) []runeDiff {
+	start := dmp.metricsNow()
+	// Scan the text on a line-by-line basis first, or chunk by chunk if the lines are long.
+	var diffs []Diff
+	if dmp.usesChunks(text1, text2) {
+		diffs = dmp.diffChunks(text1, text2, deadline)
+	} else {
+		diffs = dmp.diffLines(string(text1), string(text2), deadline)
+	}
 	// Eliminate freak matches (e.g. blank lines)
 	diffs = dmp.DiffCleanupSemantic(diffs)
 
@@ -206,46 +367,73 @@ func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time)
 	// Add a dummy entry at the end.
 	diffs = append(diffs, Diff{DiffEqual, ""})
 
-	pointer := 0
 	countDelete := 0
 	countInsert := 0
 
-	// NOTE: Rune slices are slower than using strings in this case.
-	textDelete := ""
-	textInsert := ""
+	// The diff is rebuilt from pieces which are either kept as they are or replaced by the result of a job.
+	// The diffs are mapped back onto the original runes, so that no text needs to be copied.
+	var pieces [][]runeDiff
+	var piece []runeDiff
+	var jobs []*diffJob
+	jobPieces := map[*diffJob]int{}
+	pointer1, pointer2 := 0, 0
+	start1, start2 := 0, 0
 
-	for pointer < len(diffs) {
-		switch diffs[pointer].Type {
+	for _, aDiff := range diffs {
+		length := utf8.RuneCountInString(aDiff.Text)
+		switch aDiff.Type {
 		case DiffInsert:
 			countInsert++
-			textInsert += diffs[pointer].Text
+			pointer2 += length
 		case DiffDelete:
 			countDelete++
-			textDelete += diffs[pointer].Text
+			pointer1 += length
 		case DiffEqual:
 			// Upon reaching an equality, check for prior redundancies.
 			if countDelete >= 1 && countInsert >= 1 {
-				// Delete the offending records and add the merged ones.
-				diffs = splice(diffs, pointer-countDelete-countInsert,
-					countDelete+countInsert)
-
-				pointer = pointer - countDelete - countInsert
-				a := dmp.diffMainRunes([]rune(textDelete), []rune(textInsert), false, deadline)
-				for j := len(a) - 1; j >= 0; j-- {
-					diffs = splice(diffs, pointer, 0, a[j])
-				}
-				pointer = pointer + len(a)
+				// Replace the offending records with a rediff of them.
+				pieces = append(pieces, piece)
+				job := &diffJob{text1: text1[start1:pointer1], text2: text2[start2:pointer2]}
+				jobs = append(jobs, job)
+				jobPieces[job] = len(pieces)
+				pieces = append(pieces, nil)
+				piece = nil
+			} else if countDelete != 0 {
+				piece = append(piece, runeDiff{DiffDelete, text1[start1:pointer1]})
+				dmp.reportProgress(pointer1 - start1)
+				dmp.chargeOperations(1)
+				dmp.chargeEditDistance(pointer1 - start1)
+			} else if countInsert != 0 {
+				piece = append(piece, runeDiff{DiffInsert, text2[start2:pointer2]})
+				dmp.reportProgress(pointer2 - start2)
+				dmp.chargeOperations(1)
+				dmp.chargeEditDistance(pointer2 - start2)
+			}
+			if length != 0 {
+				piece = append(piece, runeDiff{DiffEqual, text1[pointer1 : pointer1+length]})
+				dmp.reportProgress(2 * length)
 			}
+			pointer1 += length
+			pointer2 += length
+			start1, start2 = pointer1, pointer2
 
 			countInsert = 0
 			countDelete = 0
-			textDelete = ""
-			textInsert = ""
 		}
-		pointer++
+	}
+	pieces = append(pieces, piece)
+	dmp.addDuration(metricLineMode, start)
+
+	dmp.diffJobs(jobs, deadline)
+	for _, job := range jobs {
+		pieces[jobPieces[job]] = job.diffs
 	}
 
-	return diffs[:len(diffs)-1] // Remove the dummy entry at the end.
+	rediffed := make([]runeDiff, 0, len(diffs))
+	for _, piece := range pieces {
+		rediffed = append(rediffed, piece...)
+	}
+	return rediffed
 }
 
 // DiffBisect finds the 'middle snake' of a diff, split the problem in two and return the recursively constructed diff.
@@ -253,21 +441,47 @@ func (dmp *DiffMatchPatch) diffLineMode(text1, text2 []rune, deadline time.Time)
 // See Myers 1986 paper: An O(ND) Difference Algorithm and Its Variations.
 func (dmp *DiffMatchPatch) DiffBisect(text1, text2 string, deadline time.Time) []Diff {
 	// Unused in this code, but retained for interface compatibility.
-	return dmp.diffBisect([]rune(text1), []rune(text2), deadline)
+	return dmp.DiffCanonicalize(runeDiffsToDiffs(dmp.diffBisect([]rune(text1), []rune(text2), deadline)))
+}
+
+// BisectSplit is the point at which DiffBisect splits two texts in two, where the 'middle snake' of their diff ends.
+type BisectSplit struct {
+	// Number of runes of the first and of the second text before the split.
+	X, Y int
+}
+
+// DiffMiddleSnake finds the point at which DiffBisect splits two texts, and returns false if the texts have nothing in common or the search gave up because of the deadline.
+func (dmp *DiffMatchPatch) DiffMiddleSnake(text1, text2 string, deadline time.Time) (BisectSplit, bool) {
+	return dmp.middleSnake([]rune(text1), []rune(text2), deadline)
 }
 
 // diffBisect finds the 'middle snake' of a diff, splits the problem in two and returns the recursively constructed diff.
 // See Myers's 1986 paper: An O(ND) Difference Algorithm and Its Variations.
-func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time) []Diff {
+func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time) []runeDiff {
+	if split, ok := dmp.middleSnake(runes1, runes2, deadline); ok {
+		return dmp.diffBisectSplit(runes1, runes2, split, deadline)
+	}
+	// Diff took too long and hit the deadline or number of diffs equals number of characters, no commonality at all.
+	return dmp.countOperations([]runeDiff{
+		{DiffDelete, runes1},
+		{DiffInsert, runes2},
+	})
+}
+
+// middleSnake finds the end of the 'middle snake' of a diff, where diffBisect splits the problem in two.
+func (dmp *DiffMatchPatch) middleSnake(runes1, runes2 []rune, deadline time.Time) (BisectSplit, bool) {
 	// Cache the text lengths to prevent multiple calls.
 	runes1Len, runes2Len := len(runes1), len(runes2)
+	start := dmp.metricsNow()
 
 	maxD := (runes1Len + runes2Len + 1) / 2
 	vOffset := maxD
-	vLength := 2 * maxD
+	// Leave room for the initial paths at vOffset+1 when the texts are a single character long.
+	vLength := 2*maxD + 2
 
-	v1 := make([]int, vLength)
-	v2 := make([]int, vLength)
+	v, buf := dmp.getInts(2 * vLength)
+	v1 := v[:vLength]
+	v2 := v[vLength:]
 	for i := range v1 {
 		v1[i] = -1
 		v2[i] = -1
@@ -286,6 +500,18 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 	for d := 0; d < maxD; d++ {
 		// Bail out if deadline is reached.
 		if !deadline.IsZero() && d%16 == 0 && time.Now().After(deadline) {
+			dmp.markTimedOut()
+			break
+		}
+		// Give the progress callback a chance to cancel long computations.
+		if dmp.progress != nil && d%16 == 0 {
+			if dmp.reportProgress(0); dmp.progressCancelled() {
+				break
+			}
+		}
+		// Bail out if the paths have not met within the edit distance which is left.
+		if dmp.editDistance != nil && int64(2*d-1) > atomic.LoadInt64(dmp.editDistance) {
+			atomic.StoreInt64(dmp.editDistance, -1)
 			break
 		}
 
@@ -322,11 +548,17 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 					x2 := runes1Len - v2[k2Offset]
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(runes1, runes2, x1, y1, deadline)
+						putInts(buf)
+						dmp.addDuration(metricBisect, start)
+						return BisectSplit{x1, y1}, true
 					}
 				}
 			}
 		}
+		if dmp.editDistance != nil && int64(2*d) > atomic.LoadInt64(dmp.editDistance) {
+			atomic.StoreInt64(dmp.editDistance, -1)
+			break
+		}
 		// Walk the reverse path one step.
 		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
 			k2Offset := vOffset + k2
@@ -360,31 +592,34 @@ func (dmp *DiffMatchPatch) diffBisect(runes1, runes2 []rune, deadline time.Time)
 					x2 = runes1Len - x2
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(runes1, runes2, x1, y1, deadline)
+						putInts(buf)
+						dmp.addDuration(metricBisect, start)
+						return BisectSplit{x1, y1}, true
 					}
 				}
 			}
 		}
 	}
-	// Diff took too long and hit the deadline or number of diffs equals number of characters, no commonality at all.
-	return []Diff{
-		{DiffDelete, string(runes1)},
-		{DiffInsert, string(runes2)},
-	}
+	putInts(buf)
+	dmp.addDuration(metricBisect, start)
+	return BisectSplit{}, false
 }
 
-func (dmp *DiffMatchPatch) diffBisectSplit(runes1, runes2 []rune, x, y int,
-	deadline time.Time) []Diff {
-	runes1a := runes1[:x]
-	runes2a := runes2[:y]
-	runes1b := runes1[x:]
-	runes2b := runes2[y:]
-
-	// Compute both diffs serially.
-	diffs := dmp.diffMainRunes(runes1a, runes2a, false, deadline)
-	diffsb := dmp.diffMainRunes(runes1b, runes2b, false, deadline)
-
-	return append(diffs, diffsb...)
+// diffBisectSplit diffs the parts of two texts before and after a split point, and joins the diffs.
+func (dmp *DiffMatchPatch) diffBisectSplit(runes1, runes2 []rune, split BisectSplit, deadline time.Time) []runeDiff {
+	runes1a := runes1[:split.X]
+	runes2a := runes2[:split.Y]
+	runes1b := runes1[split.X:]
+	runes2b := runes2[split.Y:]
+
+	// Compute both diffs, in parallel if workers are available.
+	jobA := &diffJob{text1: runes1a, text2: runes2a}
+	jobB := &diffJob{text1: runes1b, text2: runes2b}
+	dmp.diffJobs([]*diffJob{jobA, jobB}, deadline)
+
+	diffs := make([]runeDiff, 0, len(jobA.diffs)+len(jobB.diffs)+1)
+	diffs = append(diffs, jobA.diffs...)
+	return append(diffs, jobB.diffs...)
 }
 
 // DiffLinesToChars splits two texts into a list of strings, and educes the texts to a string of hashes where each Unicode character represents one line.
@@ -401,20 +636,35 @@ func (dmp *DiffMatchPatch) DiffLinesToRunes(text1, text2 string) ([]rune, []rune
 }
 
 // DiffCharsToLines rehydrates the text in a diff from a string of line hashes to real lines of text.
+// Runes which do not stand for a line of lineArray are kept as they are.
 func (dmp *DiffMatchPatch) DiffCharsToLines(diffs []Diff, lineArray []string) []Diff {
+	hydrated, _ := dmp.DiffCharsToLinesChecked(diffs, lineArray)
+	return hydrated
+}
+
+// DiffCharsToLinesChecked is DiffCharsToLines which also returns an error if a rune does not stand for a line of lineArray.
+func (dmp *DiffMatchPatch) DiffCharsToLinesChecked(diffs []Diff, lineArray []string) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
 	hydrated := make([]Diff, 0, len(diffs))
 	for _, aDiff := range diffs {
 		runes := []rune(aDiff.Text)
 		text := make([]string, len(runes))
 
 		for i, r := range runes {
-			text[i] = lineArray[runeToInt(r)]
+			if index := runeToInt(r); index < uint32(len(lineArray)) {
+				text[i] = lineArray[index]
+			} else {
+				text[i] = string(r)
+				if err == nil {
+					err = fmt.Errorf("Invalid line hash %q in DiffCharsToLines", r)
+				}
+			}
 		}
 
 		aDiff.Text = strings.Join(text, "")
 		hydrated = append(hydrated, aDiff)
 	}
-	return hydrated
+	return hydrated, err
 }
 
 // DiffCommonPrefix determines the common prefix length of two strings.
@@ -497,26 +747,61 @@ func (dmp *DiffMatchPatch) DiffCommonOverlap(text1 string, text2 string) int {
 }
 
 // DiffHalfMatch checks whether the two texts share a substring which is at least half the length of the longer text. This speedup can produce non-minimal diffs.
+// It returns nil if DiffTimeout is not positive and AllowNonMinimal is not set; HalfMatch returns the result as a struct.
 func (dmp *DiffMatchPatch) DiffHalfMatch(text1, text2 string) []string {
 	// Unused in this code, but retained for interface compatibility.
-	runeSlices := dmp.diffHalfMatch([]rune(text1), []rune(text2))
-	if runeSlices == nil {
+	hm := dmp.diffHalfMatch([]rune(text1), []rune(text2))
+	if hm == nil {
 		return nil
 	}
+	return []string{string(hm.prefix1), string(hm.suffix1), string(hm.prefix2), string(hm.suffix2), string(hm.common)}
+}
+
+// HalfMatch is a substring which two texts share, at least half as long as the longer text, together with the parts of the texts around it.
+type HalfMatch struct {
+	// Parts of the first text before and after the common substring.
+	Prefix1, Suffix1 string
+	// Parts of the second text before and after the common substring.
+	Prefix2, Suffix2 string
+	// The common substring.
+	Common string
+}
 
-	result := make([]string, len(runeSlices))
-	for i, r := range runeSlices {
-		result[i] = string(r)
+// HalfMatch looks for a substring which two texts share and which is at least half as long as the longer text, regardless of DiffTimeout and AllowNonMinimal, and returns false if there is none.
+// DiffMain uses it to diff the prefixes and the suffixes separately, which is fast but does not always find a minimal diff. The substring is the longer of those grown from seeds in the second and the third quarter of the longer text, which is not necessarily the longest one.
+func (dmp *DiffMatchPatch) HalfMatch(text1, text2 string) (HalfMatch, bool) {
+	hm := dmp.halfMatch([]rune(text1), []rune(text2))
+	if hm == nil {
+		return HalfMatch{}, false
 	}
-	return result
+	return HalfMatch{
+		Prefix1: string(hm.prefix1),
+		Suffix1: string(hm.suffix1),
+		Prefix2: string(hm.prefix2),
+		Suffix2: string(hm.suffix2),
+		Common:  string(hm.common),
+	}, true
+}
+
+// runeHalfMatch is a HalfMatch of rune slices.
+type runeHalfMatch struct {
+	prefix1, suffix1 []rune
+	prefix2, suffix2 []rune
+	common           []rune
 }
 
-func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) [][]rune {
-	if dmp.DiffTimeout <= 0 {
+// diffHalfMatch is halfMatch if DiffTimeout or AllowNonMinimal permits it.
+func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) *runeHalfMatch {
+	if dmp.DiffTimeout <= 0 && !dmp.AllowNonMinimal || dmp.DiffExactMinimal {
 		// Don't risk returning a non-optimal diff if we have unlimited time.
 		return nil
 	}
+	return dmp.halfMatch(text1, text2)
+}
 
+// halfMatch finds a substring which two texts share and which is at least half as long as the longer text.
+// Returns nil if there was no match.
+func (dmp *DiffMatchPatch) halfMatch(text1, text2 []rune) *runeHalfMatch {
 	var longtext, shorttext []rune
 	if len(text1) > len(text2) {
 		longtext = text1
@@ -536,7 +821,7 @@ func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) [][]rune {
 	// Check again based on the third quarter.
 	hm2 := dmp.diffHalfMatchI(longtext, shorttext, int(float64(len(longtext)+1)/2))
 
-	hm := [][]rune{}
+	var hm *runeHalfMatch
 	if hm1 == nil && hm2 == nil {
 		return nil
 	} else if hm2 == nil {
@@ -545,7 +830,7 @@ func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) [][]rune {
 		hm = hm2
 	} else {
 		// Both matched.  Select the longest.
-		if len(hm1[4]) > len(hm2[4]) {
+		if len(hm1.common) > len(hm2.common) {
 			hm = hm1
 		} else {
 			hm = hm2
@@ -557,12 +842,12 @@ func (dmp *DiffMatchPatch) diffHalfMatch(text1, text2 []rune) [][]rune {
 		return hm
 	}
 
-	return [][]rune{hm[2], hm[3], hm[0], hm[1], hm[4]}
+	return &runeHalfMatch{hm.prefix2, hm.suffix2, hm.prefix1, hm.suffix1, hm.common}
 }
 
 // diffHalfMatchI checks if a substring of shorttext exist within longtext such that the substring is at least half the length of longtext?
-// Returns a slice containing the prefix of longtext, the suffix of longtext, the prefix of shorttext, the suffix of shorttext and the common middle, or null if there was no match.
-func (dmp *DiffMatchPatch) diffHalfMatchI(l, s []rune, i int) [][]rune {
+// Returns the half-match with longtext as the first text and shorttext as the second one, or nil if there was no match.
+func (dmp *DiffMatchPatch) diffHalfMatchI(l, s []rune, i int) *runeHalfMatch {
 	var bestCommonA []rune
 	var bestCommonB []rune
 	var bestCommonLen int
@@ -593,17 +878,44 @@ func (dmp *DiffMatchPatch) diffHalfMatchI(l, s []rune, i int) [][]rune {
 		return nil
 	}
 
-	return [][]rune{
-		bestLongtextA,
-		bestLongtextB,
-		bestShorttextA,
-		bestShorttextB,
-		append(bestCommonA, bestCommonB...),
+	return &runeHalfMatch{
+		prefix1: bestLongtextA,
+		suffix1: bestLongtextB,
+		prefix2: bestShorttextA,
+		suffix2: bestShorttextB,
+		common:  append(bestCommonA, bestCommonB...),
+	}
+}
+
+// SemanticCleanupOptions tunes how aggressively DiffCleanupSemanticWithOptions coalesces trivial equalities.
+type SemanticCleanupOptions struct {
+	// Equalities shorter than this many runes are eliminated whenever there are edits on both sides of them (0 to disable).
+	MinEqualityLength int
+	// An equality is eliminated if it is no longer than EqualityRatio times the edits on each of its sides (0 to disable).
+	EqualityRatio float64
+	// An overlap of a deletion and an insertion is extracted as an equality if it is at least OverlapRatio times as long as one of the edits (values above 1 disable this).
+	OverlapRatio float64
+	// Whether edits are shifted sideways to align with word boundaries, see DiffCleanupSemanticLossless.
+	AlignBoundaries bool
+}
+
+// DefaultSemanticCleanupOptions returns the options used by DiffCleanupSemantic.
+func DefaultSemanticCleanupOptions() SemanticCleanupOptions {
+	return SemanticCleanupOptions{
+		EqualityRatio:   1.0,
+		OverlapRatio:    0.5,
+		AlignBoundaries: true,
 	}
 }
 
 // DiffCleanupSemantic reduces the number of edits by eliminating semantically trivial equalities.
 func (dmp *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
+	return dmp.DiffCleanupSemanticWithOptions(diffs, DefaultSemanticCleanupOptions())
+}
+
+// DiffCleanupSemanticWithOptions reduces the number of edits by eliminating semantically trivial equalities, as tuned by opts.
+func (dmp *DiffMatchPatch) DiffCleanupSemanticWithOptions(diffs []Diff, opts SemanticCleanupOptions) []Diff {
+	diffs = repairRuneBoundaries(diffs)
 	changes := false
 	// Stack of indices where equalities are found.
 	equalities := make([]int, 0, len(diffs))
@@ -634,11 +946,13 @@ func (dmp *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
 				lengthDeletions2 += utf8.RuneCountInString(diffs[pointer].Text)
 			}
 			// Eliminate an equality that is smaller or equal to the edits on both sides of it.
-			difference1 := int(math.Max(float64(lengthInsertions1), float64(lengthDeletions1)))
-			difference2 := int(math.Max(float64(lengthInsertions2), float64(lengthDeletions2)))
-			if utf8.RuneCountInString(lastequality) > 0 &&
-				(utf8.RuneCountInString(lastequality) <= difference1) &&
-				(utf8.RuneCountInString(lastequality) <= difference2) {
+			difference1 := math.Max(float64(lengthInsertions1), float64(lengthDeletions1))
+			difference2 := math.Max(float64(lengthInsertions2), float64(lengthDeletions2))
+			equalityLength := utf8.RuneCountInString(lastequality)
+			if equalityLength > 0 &&
+				((equalityLength < opts.MinEqualityLength && difference1 > 0) ||
+					(float64(equalityLength) <= opts.EqualityRatio*difference1 &&
+						float64(equalityLength) <= opts.EqualityRatio*difference2)) {
 				// Duplicate record.
 				insPoint := equalities[len(equalities)-1]
 				diffs = splice(diffs, insPoint, 0, Diff{DiffDelete, lastequality})
@@ -671,24 +985,27 @@ func (dmp *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
 	if changes {
 		diffs = dmp.DiffCleanupMerge(diffs)
 	}
-	diffs = dmp.DiffCleanupSemanticLossless(diffs)
+	if opts.AlignBoundaries {
+		diffs = dmp.DiffCleanupSemanticLossless(diffs)
+	}
 	// Find any overlaps between deletions and insertions.
 	// e.g: <del>abcxxx</del><ins>xxxdef</ins>
 	//   -> <del>abc</del>xxx<ins>def</ins>
 	// e.g: <del>xxxabc</del><ins>defxxx</ins>
 	//   -> <ins>def</ins>xxx<del>abc</del>
-	// Only extract an overlap if it is as big as the edit ahead or behind it.
+	// Only extract an overlap if it is big enough compared to the edit ahead or behind it.
 	pointer = 1
 	for pointer < len(diffs) {
 		if diffs[pointer-1].Type == DiffDelete &&
 			diffs[pointer].Type == DiffInsert {
 			deletion := diffs[pointer-1].Text
 			insertion := diffs[pointer].Text
-			overlapLength1 := dmp.DiffCommonOverlap(deletion, insertion)
-			overlapLength2 := dmp.DiffCommonOverlap(insertion, deletion)
+			overlapLength1 := dmp.diffCommonOverlap(deletion, insertion)
+			overlapLength2 := dmp.diffCommonOverlap(insertion, deletion)
 			if overlapLength1 >= overlapLength2 {
-				if float64(overlapLength1) >= float64(utf8.RuneCountInString(deletion))/2 ||
-					float64(overlapLength1) >= float64(utf8.RuneCountInString(insertion))/2 {
+				if overlapLength1 > 0 &&
+					(float64(overlapLength1) >= float64(utf8.RuneCountInString(deletion))*opts.OverlapRatio ||
+						float64(overlapLength1) >= float64(utf8.RuneCountInString(insertion))*opts.OverlapRatio) {
 
 					// Overlap found. Insert an equality and trim the surrounding edits.
 					diffs = splice(diffs, pointer, 0, Diff{DiffEqual, insertion[:overlapLength1]})
@@ -698,8 +1015,9 @@ func (dmp *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
 					pointer++
 				}
 			} else {
-				if float64(overlapLength2) >= float64(utf8.RuneCountInString(deletion))/2 ||
-					float64(overlapLength2) >= float64(utf8.RuneCountInString(insertion))/2 {
+				if overlapLength2 > 0 &&
+					(float64(overlapLength2) >= float64(utf8.RuneCountInString(deletion))*opts.OverlapRatio ||
+						float64(overlapLength2) >= float64(utf8.RuneCountInString(insertion))*opts.OverlapRatio) {
 					// Reverse overlap found. Insert an equality and swap and trim the surrounding edits.
 					overlap := Diff{DiffEqual, deletion[:overlapLength2]}
 					diffs = splice(diffs, pointer, 0, overlap)
@@ -715,7 +1033,37 @@ func (dmp *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
 		pointer++
 	}
 
-	return diffs
+	return dmp.DiffCanonicalize(diffs)
+}
+
+// diffCommonOverlap is DiffCommonOverlap shortened, if needed, so that the overlap does not split a grapheme cluster of either text when DiffGraphemeClusters is set.
+func (dmp *DiffMatchPatch) diffCommonOverlap(text1, text2 string) int {
+	overlap := dmp.DiffCommonOverlap(text1, text2)
+	if !dmp.DiffGraphemeClusters {
+		return overlap
+	}
+	// Step down through the boundaries of both texts until the overlap starts and ends on one.
+	boundaries1 := graphemeBoundaries(text1)
+	boundaries2 := graphemeBoundaries(text2)
+	i, j := 0, len(boundaries2)-1
+	for overlap > 0 {
+		for boundaries1[i] < len(text1)-overlap {
+			i++
+		}
+		for boundaries2[j] > overlap {
+			j--
+		}
+		overlap1 := len(text1) - boundaries1[i]
+		if overlap1 != boundaries2[j] {
+			overlap = min(overlap1, boundaries2[j])
+		} else if text1[len(text1)-overlap1:] == text2[:overlap1] {
+			return overlap1
+		} else {
+			// A shorter overlap must be checked again.
+			overlap = overlap1 - 1
+		}
+	}
+	return overlap
 }
 
 // Define some regex patterns for matching boundaries.
@@ -772,6 +1120,7 @@ func diffCleanupSemanticScore(one, two string) int {
 // DiffCleanupSemanticLossless looks for single edits surrounded on both sides by equalities which can be shifted sideways to align the edit to a word boundary.
 // E.g: The c<ins>at c</ins>ame. -> The <ins>cat </ins>came.
 func (dmp *DiffMatchPatch) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
+	diffs = repairRuneBoundaries(diffs)
 	pointer := 1
 
 	// Intentionally ignore the first and last element (don't need checking).
@@ -785,20 +1134,38 @@ func (dmp *DiffMatchPatch) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 			equality2 := diffs[pointer+1].Text
 
 			// First, shift the edit as far left as possible.
-			commonOffset := dmp.DiffCommonSuffix(equality1, edit)
+			editRunes := []rune(edit)
+			commonOffset := commonSuffixLength([]rune(equality1), editRunes)
 			if commonOffset > 0 {
-				commonString := edit[len(edit)-commonOffset:]
-				equality1 = equality1[0 : len(equality1)-commonOffset]
-				edit = commonString + edit[:len(edit)-commonOffset]
+				commonString := string(editRunes[len(editRunes)-commonOffset:])
+				equality1 = equality1[0 : len(equality1)-len(commonString)]
+				edit = commonString + edit[:len(edit)-len(commonString)]
 				equality2 = commonString + equality2
 			}
 
+			// In grapheme cluster mode only offsets on cluster boundaries of the surrounding text are acceptable.
+			aligned := func(equality1, edit string) bool {
+				return true
+			}
+			if dmp.DiffGraphemeClusters {
+				boundaries := map[int]bool{}
+				for _, b := range graphemeBoundaries(equality1 + edit + equality2) {
+					boundaries[b] = true
+				}
+				aligned = func(equality1, edit string) bool {
+					return boundaries[len(equality1)] && boundaries[len(equality1)+len(edit)]
+				}
+			}
+
 			// Second, step character by character right, looking for the best fit.
 			bestEquality1 := equality1
 			bestEdit := edit
 			bestEquality2 := equality2
-			bestScore := diffCleanupSemanticScore(equality1, edit) +
-				diffCleanupSemanticScore(edit, equality2)
+			bestScore := -1
+			if aligned(equality1, edit) {
+				bestScore = diffCleanupSemanticScore(equality1, edit) +
+					diffCleanupSemanticScore(edit, equality2)
+			}
 
 			for len(edit) != 0 && len(equality2) != 0 {
 				_, sz := utf8.DecodeRuneInString(edit)
@@ -811,7 +1178,7 @@ func (dmp *DiffMatchPatch) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 				score := diffCleanupSemanticScore(equality1, edit) +
 					diffCleanupSemanticScore(edit, equality2)
 				// The >= encourages trailing rather than leading whitespace on edits.
-				if score >= bestScore {
+				if score >= bestScore && aligned(equality1, edit) {
 					bestScore = score
 					bestEquality1 = equality1
 					bestEdit = edit
@@ -840,11 +1207,12 @@ func (dmp *DiffMatchPatch) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 		pointer++
 	}
 
-	return diffs
+	return dmp.DiffCanonicalize(diffs)
 }
 
 // DiffCleanupEfficiency reduces the number of edits by eliminating operationally trivial equalities.
 func (dmp *DiffMatchPatch) DiffCleanupEfficiency(diffs []Diff) []Diff {
+	diffs = repairRuneBoundaries(diffs)
 	changes := false
 	// Stack of indices where equalities are found.
 	type equality struct {
@@ -950,20 +1318,75 @@ func (dmp *DiffMatchPatch) DiffCleanupEfficiency(diffs []Diff) []Diff {
 		diffs = dmp.DiffCleanupMerge(diffs)
 	}
 
-	return diffs
+	return dmp.DiffCanonicalize(diffs)
+}
+
+// DiffCleanupCompact folds every equality of at most maxEqual runes between two edits into the edits, so that the diff shows a single replacement instead of many small edits, e.g. for views which only highlight replaced spans.
+// Unlike DiffCleanupSemantic and DiffCleanupEfficiency it only considers the length of the equalities.
+func (dmp *DiffMatchPatch) DiffCleanupCompact(diffs []Diff, maxEqual int) []Diff {
+	diffs = repairRuneBoundaries(diffs)
+	cleaned := make([]Diff, 0, len(diffs))
+	// The edits since the last equality which was kept, including the equalities folded into them.
+	var deleted, inserted strings.Builder
+	edits := false
+	flush := func() {
+		if deleted.Len() != 0 {
+			cleaned = append(cleaned, Diff{DiffDelete, deleted.String()})
+		}
+		if inserted.Len() != 0 {
+			cleaned = append(cleaned, Diff{DiffInsert, inserted.String()})
+		}
+		deleted.Reset()
+		inserted.Reset()
+		edits = false
+	}
+
+	for i, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffDelete:
+			deleted.WriteString(aDiff.Text)
+			edits = edits || len(aDiff.Text) != 0
+		case DiffInsert:
+			inserted.WriteString(aDiff.Text)
+			edits = edits || len(aDiff.Text) != 0
+		case DiffEqual:
+			if edits && utf8.RuneCountInString(aDiff.Text) <= maxEqual && editFollows(diffs[i+1:]) {
+				deleted.WriteString(aDiff.Text)
+				inserted.WriteString(aDiff.Text)
+				continue
+			}
+			flush()
+			cleaned = append(cleaned, aDiff)
+		}
+	}
+	flush()
+
+	return dmp.DiffCanonicalize(cleaned)
+}
+
+// editFollows returns whether the first diff which is not empty is an insertion or a deletion.
+func editFollows(diffs []Diff) bool {
+	for _, aDiff := range diffs {
+		if len(aDiff.Text) != 0 {
+			return aDiff.Type != DiffEqual
+		}
+	}
+	return false
 }
 
 // DiffCleanupMerge reorders and merges like edit sections. Merge equalities.
 // Any edit section can move as long as it doesn't cross an equality.
 func (dmp *DiffMatchPatch) DiffCleanupMerge(diffs []Diff) []Diff {
+	diffs = repairRuneBoundaries(diffs)
 	// Add a dummy entry at the end.
 	diffs = append(diffs, Diff{DiffEqual, ""})
 	pointer := 0
 	countDelete := 0
 	countInsert := 0
 	commonlength := 0
-	textDelete := []rune(nil)
-	textInsert := []rune(nil)
+	textDelete, bufDelete := dmp.getRunes()
+	textInsert, bufInsert := dmp.getRunes()
+	emptyDelete, emptyInsert := textDelete, textInsert
 
 	for pointer < len(diffs) {
 		switch diffs[pointer].Type {
@@ -1036,11 +1459,19 @@ func (dmp *DiffMatchPatch) DiffCleanupMerge(diffs []Diff) []Diff {
 			}
 			countInsert = 0
 			countDelete = 0
-			textDelete = nil
-			textInsert = nil
+			if cap(textDelete) > cap(emptyDelete) {
+				emptyDelete = textDelete[:0]
+			}
+			if cap(textInsert) > cap(emptyInsert) {
+				emptyInsert = textInsert[:0]
+			}
+			textDelete = emptyDelete
+			textInsert = emptyInsert
 			break
 		}
 	}
+	putRunes(bufDelete, emptyDelete)
+	putRunes(bufInsert, emptyInsert)
 
 	if len(diffs[len(diffs)-1].Text) == 0 {
 		diffs = diffs[0 : len(diffs)-1] // Remove the dummy entry at the end.
@@ -1075,7 +1506,119 @@ func (dmp *DiffMatchPatch) DiffCleanupMerge(diffs []Diff) []Diff {
 
 	// If shifts were made, the diff needs reordering and another shift sweep.
 	if changes {
-		diffs = dmp.DiffCleanupMerge(diffs)
+		return dmp.DiffCleanupMerge(diffs)
+	}
+
+	return dmp.DiffCanonicalize(diffs)
+}
+
+// diffCleanupMergeRunes is DiffCleanupMerge for the internal diff representation. Texts are joined without copying whenever they are adjacent in memory.
+func diffCleanupMergeRunes(diffs []runeDiff) []runeDiff {
+	// Add a dummy entry at the end.
+	diffs = append(diffs, runeDiff{DiffEqual, nil})
+	pointer := 0
+	countDelete := 0
+	countInsert := 0
+	commonlength := 0
+	textDelete := []rune(nil)
+	textInsert := []rune(nil)
+
+	for pointer < len(diffs) {
+		switch diffs[pointer].Type {
+		case DiffInsert:
+			countInsert++
+			textInsert = runesConcat(textInsert, diffs[pointer].Text)
+			pointer++
+		case DiffDelete:
+			countDelete++
+			textDelete = runesConcat(textDelete, diffs[pointer].Text)
+			pointer++
+		case DiffEqual:
+			// Upon reaching an equality, check for prior redundancies.
+			if countDelete+countInsert > 1 {
+				if countDelete != 0 && countInsert != 0 {
+					// Factor out any common prefixies.
+					commonlength = commonPrefixLength(textInsert, textDelete)
+					if commonlength != 0 {
+						x := pointer - countDelete - countInsert
+						if x > 0 && diffs[x-1].Type == DiffEqual {
+							diffs[x-1].Text = runesConcat(diffs[x-1].Text, textInsert[:commonlength])
+						} else {
+							diffs = append([]runeDiff{{DiffEqual, textInsert[:commonlength]}}, diffs...)
+							pointer++
+						}
+						textInsert = textInsert[commonlength:]
+						textDelete = textDelete[commonlength:]
+					}
+					// Factor out any common suffixies.
+					commonlength = commonSuffixLength(textInsert, textDelete)
+					if commonlength != 0 {
+						insertIndex := len(textInsert) - commonlength
+						deleteIndex := len(textDelete) - commonlength
+						diffs[pointer].Text = runesConcat(textInsert[insertIndex:], diffs[pointer].Text)
+						textInsert = textInsert[:insertIndex]
+						textDelete = textDelete[:deleteIndex]
+					}
+				}
+				// Delete the offending records and add the merged ones.
+				start := pointer - countDelete - countInsert
+				merged := diffs[start:start]
+				if countDelete != 0 {
+					merged = append(merged, runeDiff{DiffDelete, textDelete})
+				}
+				if countInsert != 0 {
+					merged = append(merged, runeDiff{DiffInsert, textInsert})
+				}
+				// The merged records never outnumber the offending ones, so they are written in place.
+				diffs = append(diffs[:start+len(merged)], diffs[pointer:]...)
+				pointer = start + len(merged) + 1
+			} else if pointer != 0 && diffs[pointer-1].Type == DiffEqual {
+				// Merge this equality with the previous one.
+				diffs[pointer-1].Text = runesConcat(diffs[pointer-1].Text, diffs[pointer].Text)
+				diffs = append(diffs[:pointer], diffs[pointer+1:]...)
+			} else {
+				pointer++
+			}
+			countInsert = 0
+			countDelete = 0
+			textDelete = nil
+			textInsert = nil
+		}
+	}
+
+	if len(diffs[len(diffs)-1].Text) == 0 {
+		diffs = diffs[0 : len(diffs)-1] // Remove the dummy entry at the end.
+	}
+
+	// Second pass: look for single edits surrounded on both sides by equalities which can be shifted sideways to eliminate an equality. E.g: A<ins>BA</ins>C -> <ins>AB</ins>AC
+	changes := false
+	pointer = 1
+	// Intentionally ignore the first and last element (don't need checking).
+	for pointer < (len(diffs) - 1) {
+		if diffs[pointer-1].Type == DiffEqual &&
+			diffs[pointer+1].Type == DiffEqual {
+			// This is a single edit surrounded by equalities.
+			if runesHasSuffix(diffs[pointer].Text, diffs[pointer-1].Text) {
+				// Shift the edit over the previous equality.
+				diffs[pointer].Text = runesConcat(diffs[pointer-1].Text,
+					diffs[pointer].Text[:len(diffs[pointer].Text)-len(diffs[pointer-1].Text)])
+				diffs[pointer+1].Text = runesConcat(diffs[pointer-1].Text, diffs[pointer+1].Text)
+				diffs = append(diffs[:pointer-1], diffs[pointer:]...)
+				changes = true
+			} else if runesHasPrefix(diffs[pointer].Text, diffs[pointer+1].Text) {
+				// Shift the edit over the next equality.
+				diffs[pointer-1].Text = runesConcat(diffs[pointer-1].Text, diffs[pointer+1].Text)
+				diffs[pointer].Text = runesConcat(diffs[pointer].Text[len(diffs[pointer+1].Text):], diffs[pointer+1].Text)
+				diffs = append(diffs[:pointer+1], diffs[pointer+2:]...)
+				changes = true
+			}
+		}
+		pointer++
+	}
+
+	// If shifts were made, the diff needs reordering and another shift sweep.
+	if changes {
+		diffs = diffCleanupMergeRunes(diffs)
 	}
 
 	return diffs
@@ -1116,51 +1659,64 @@ func (dmp *DiffMatchPatch) DiffXIndex(diffs []Diff, loc int) int {
 
 // DiffPrettyHtml converts a []Diff into a pretty HTML report.
 // It is intended as an example from which to write one's own display functions.
+// Unless DiffHtmlSanitizer is set, the texts of the diffs are escaped, so that the report is safe to embed as the content of an HTML element whatever the texts contain: it consists of <span>, <ins>, <del>, <bdi> and <br> elements with constant attributes, and text with no markup.
 func (dmp *DiffMatchPatch) DiffPrettyHtml(diffs []Diff) string {
 	var buff bytes.Buffer
-	for _, diff := range diffs {
-		text := strings.Replace(html.EscapeString(diff.Text), "\n", "&para;<br>", -1)
-		switch diff.Type {
-		case DiffInsert:
-			_, _ = buff.WriteString("<ins style=\"background:#e6ffe6;\">")
-			_, _ = buff.WriteString(text)
-			_, _ = buff.WriteString("</ins>")
-		case DiffDelete:
-			_, _ = buff.WriteString("<del style=\"background:#ffe6e6;\">")
-			_, _ = buff.WriteString(text)
-			_, _ = buff.WriteString("</del>")
-		case DiffEqual:
-			_, _ = buff.WriteString("<span>")
-			_, _ = buff.WriteString(text)
-			_, _ = buff.WriteString("</span>")
-		}
-	}
+	_ = dmp.DiffRender(&buff, diffs, HtmlRenderer{BidiIsolation: dmp.DiffBidiIsolation, Sanitizer: dmp.DiffHtmlSanitizer})
 	return buff.String()
 }
 
 // DiffPrettyText converts a []Diff into a colored text report.
 func (dmp *DiffMatchPatch) DiffPrettyText(diffs []Diff) string {
 	var buff bytes.Buffer
-	for _, diff := range diffs {
-		text := diff.Text
+	_ = dmp.DiffRender(&buff, diffs, TextRenderer{BidiIsolation: dmp.DiffBidiIsolation})
+	return buff.String()
+}
 
-		switch diff.Type {
+// DiffDump converts a []Diff into a listing for debugging, with one numbered line per diff giving its operation and its quoted text.
+// E.g. "0. Equal: \"a\"\n1. Delete: \"b\\n\"\n".
+func (dmp *DiffMatchPatch) DiffDump(diffs []Diff) string {
+	var buff bytes.Buffer
+	for i, aDiff := range diffs {
+		_, _ = fmt.Fprintf(&buff, "%d. %v: %q\n", i, aDiff.Type, aDiff.Text)
+	}
+	return buff.String()
+}
+
+// DiffDumpCompact converts a []Diff into a single line for log messages, with the quoted text of every diff prefixed by "=", "-" or "+".
+// E.g. "=\"a\" -\"b\\n\" +\"c\"".
+func (dmp *DiffMatchPatch) DiffDumpCompact(diffs []Diff) string {
+	var buff bytes.Buffer
+	for i, aDiff := range diffs {
+		if i != 0 {
+			_ = buff.WriteByte(' ')
+		}
+		switch aDiff.Type {
 		case DiffInsert:
-			_, _ = buff.WriteString("\x1b[32m")
-			_, _ = buff.WriteString(text)
-			_, _ = buff.WriteString("\x1b[0m")
+			_ = buff.WriteByte('+')
 		case DiffDelete:
-			_, _ = buff.WriteString("\x1b[31m")
-			_, _ = buff.WriteString(text)
-			_, _ = buff.WriteString("\x1b[0m")
+			_ = buff.WriteByte('-')
 		case DiffEqual:
-			_, _ = buff.WriteString(text)
+			_ = buff.WriteByte('=')
+		default:
+			_, _ = fmt.Fprintf(&buff, "%v", aDiff.Type)
 		}
+		_, _ = buff.WriteString(strconv.Quote(aDiff.Text))
 	}
-
 	return buff.String()
 }
 
+// isolateLines encloses every line of text between the Unicode characters FIRST STRONG ISOLATE and POP DIRECTIONAL ISOLATE, since isolates end with their line.
+func isolateLines(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if len(line) != 0 {
+			lines[i] = "\u2068" + line + "\u2069"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // DiffText1 computes and returns the source text (all equalities and deletions).
 func (dmp *DiffMatchPatch) DiffText1(diffs []Diff) string {
 	//StringBuilder text = new StringBuilder()
@@ -1187,6 +1743,7 @@ func (dmp *DiffMatchPatch) DiffText2(diffs []Diff) string {
 }
 
 // DiffLevenshtein computes the Levenshtein distance that is the number of inserted, deleted or substituted characters.
+// Characters are counted as runes, i.e. Unicode code points rather than bytes, see DiffLevenshteinGraphemes to count user-perceived characters.
 func (dmp *DiffMatchPatch) DiffLevenshtein(diffs []Diff) int {
 	levenshtein := 0
 	insertions := 0
@@ -1210,9 +1767,55 @@ func (dmp *DiffMatchPatch) DiffLevenshtein(diffs []Diff) int {
 	return levenshtein
 }
 
+// DiffLevenshteinGraphemes computes the Levenshtein distance in user-perceived characters, i.e. extended grapheme clusters as defined by UAX #29.
+// A cluster which is only partially changed, e.g. by replacing one of its combining marks, counts as one edit.
+func (dmp *DiffMatchPatch) DiffLevenshteinGraphemes(diffs []Diff) int {
+	index1 := graphemeIndex(dmp.DiffText1(diffs))
+	index2 := graphemeIndex(dmp.DiffText2(diffs))
+
+	levenshtein := 0
+	// Byte offsets into text1 and text2.
+	pos1, pos2 := 0, 0
+	// Byte ranges of the edits since the last equality.
+	start1, end1, start2, end2 := 0, 0, 0, 0
+
+	// clusters counts the grapheme clusters touched by the bytes [start, end).
+	clusters := func(index []int, start, end int) int {
+		if start == end {
+			return 0
+		}
+		return index[end-1] - index[start] + 1
+	}
+
+	for _, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffInsert:
+			pos2 += len(aDiff.Text)
+			end2 = pos2
+		case DiffDelete:
+			pos1 += len(aDiff.Text)
+			end1 = pos1
+		case DiffEqual:
+			// A deletion and an insertion is one substitution.
+			levenshtein += max(clusters(index1, start1, end1), clusters(index2, start2, end2))
+			pos1 += len(aDiff.Text)
+			pos2 += len(aDiff.Text)
+			start1, end1, start2, end2 = pos1, pos1, pos2, pos2
+		}
+	}
+
+	levenshtein += max(clusters(index1, start1, end1), clusters(index2, start2, end2))
+	return levenshtein
+}
+
 // DiffToDelta crushes the diff into an encoded string which describes the operations required to transform text1 into text2.
 // E.g. =3\t-2\t+ing  -> Keep 3 chars, delete 2 chars, insert 'ing'. Operations are tab-separated.  Inserted text is escaped using %xx notation.
 func (dmp *DiffMatchPatch) DiffToDelta(diffs []Diff) string {
+	return dmp.diffToDelta(diffs, dmp.deltaUnit())
+}
+
+// diffToDelta encodes the diff as a delta counting lengths in the given unit.
+func (dmp *DiffMatchPatch) diffToDelta(diffs []Diff, unit DeltaUnit) string {
 	var text bytes.Buffer
 	for _, aDiff := range diffs {
 		switch aDiff.Type {
@@ -1223,12 +1826,12 @@ func (dmp *DiffMatchPatch) DiffToDelta(diffs []Diff) string {
 			break
 		case DiffDelete:
 			_, _ = text.WriteString("-")
-			_, _ = text.WriteString(strconv.Itoa(utf8.RuneCountInString(aDiff.Text)))
+			_, _ = text.WriteString(strconv.Itoa(deltaLength(aDiff.Text, unit)))
 			_, _ = text.WriteString("\t")
 			break
 		case DiffEqual:
 			_, _ = text.WriteString("=")
-			_, _ = text.WriteString(strconv.Itoa(utf8.RuneCountInString(aDiff.Text)))
+			_, _ = text.WriteString(strconv.Itoa(deltaLength(aDiff.Text, unit)))
 			_, _ = text.WriteString("\t")
 			break
 		}
@@ -1244,8 +1847,14 @@ func (dmp *DiffMatchPatch) DiffToDelta(diffs []Diff) string {
 
 // DiffFromDelta given the original text1, and an encoded string which describes the operations required to transform text1 into text2, comAdde the full diff.
 func (dmp *DiffMatchPatch) DiffFromDelta(text1 string, delta string) (diffs []Diff, err error) {
-	i := 0
-	runes := []rune(text1)
+	defer dmp.recoverInternal(&err)
+	return dmp.diffFromDelta(text1, delta, dmp.deltaUnit())
+}
+
+// diffFromDelta decodes a delta of text1 which counts lengths in the given unit.
+func (dmp *DiffMatchPatch) diffFromDelta(text1 string, delta string, unit DeltaUnit) (diffs []Diff, err error) {
+	i := 0       // Number of units consumed.
+	pointer := 0 // Byte offset into text1.
 
 	for _, token := range strings.Split(delta, "\t") {
 		if len(token) == 0 {
@@ -1278,12 +1887,17 @@ func (dmp *DiffMatchPatch) DiffFromDelta(text1 string, delta string) (diffs []Di
 			}
 
 			i += int(n)
+			end, err := deltaAdvance(text1, pointer, int(n), unit)
+			if err != nil {
+				return nil, err
+			}
 			// Break out if we are out of bounds, go1.6 can't handle this very well
-			if i > len(runes) {
+			if end > len(text1) {
+				pointer = end
 				break
 			}
-			// Remember that string slicing is by byte - we want by rune here.
-			text := string(runes[i-int(n) : i])
+			text := text1[pointer:end]
+			pointer = end
 
 			if op == '=' {
 				diffs = append(diffs, Diff{DiffEqual, text})
@@ -1296,11 +1910,76 @@ func (dmp *DiffMatchPatch) DiffFromDelta(text1 string, delta string) (diffs []Di
 		}
 	}
 
-	if i != len(runes) {
-		return nil, fmt.Errorf("Delta length (%v) is different from source text length (%v)", i, len(text1))
+	if pointer != len(text1) {
+		return nil, fmt.Errorf("Delta length (%v) is different from source text length (%v)", i, deltaLength(text1, unit))
+	}
+	if dmp.StrictUTF8 {
+		if err := checkRuneBoundaries(diffs); err != nil {
+			return nil, err
+		}
+	}
+
+	return dmp.DiffCanonicalize(diffs), nil
+}
+
+// diffLines computes a line by line diff of two texts.
+func (dmp *DiffMatchPatch) diffLines(text1, text2 string, deadline time.Time) []Diff {
+	ids1, ids2, lineArray := diffLinesToIDs(text1, text2)
+	return dmp.diffIDs(ids1, ids2, lineArray, deadline)
+}
+
+// diffIDs diffs two texts which have been split into segments, such as lines, given as the IDs of the segments of segmentArray.
+func (dmp *DiffMatchPatch) diffIDs(ids1, ids2 []rune, segmentArray []string, deadline time.Time) []Diff {
+	// Only the operations of the character diff count towards the limits and the progress.
+	lineDmp := *dmp
+	lineDmp.operations = nil
+	lineDmp.editDistance = nil
+	lineDmp.progress = nil
+	lineDiffs := lineDmp.diffMainRunes(ids1, ids2, false, deadline)
+
+	// Convert the diff back to the segments.
+	diffs := make([]Diff, len(lineDiffs))
+	var text strings.Builder
+	for i, aDiff := range lineDiffs {
+		text.Reset()
+		for _, id := range aDiff.Text {
+			text.WriteString(segmentArray[id])
+		}
+		diffs[i] = Diff{aDiff.Type, text.String()}
+	}
+	return diffs
+}
+
+// diffLinesToIDs splits two texts into lines and numbers the distinct lines, so that the lines can be diffed like runes.
+// The numbers are used as they are rather than encoded as valid runes, so neither are they ever converted to text nor is the number of distinct lines limited by the range of Unicode.
+func diffLinesToIDs(text1, text2 string) ([]rune, []rune, []string) {
+	var lineArray []string // e.g. lineArray[4] == 'Hello\n'
+	lineHash := map[string]rune{}
+
+	toIDs := func(text string) []rune {
+		ids := make([]rune, 0, strings.Count(text, "\n")+1)
+		for lineStart := 0; lineStart < len(text); {
+			lineEnd := strings.IndexByte(text[lineStart:], '\n')
+			if lineEnd == -1 {
+				lineEnd = len(text)
+			} else {
+				lineEnd += lineStart + 1
+			}
+			line := text[lineStart:lineEnd]
+			lineStart = lineEnd
+
+			id, ok := lineHash[line]
+			if !ok {
+				id = rune(len(lineArray))
+				lineArray = append(lineArray, line)
+				lineHash[line] = id
+			}
+			ids = append(ids, id)
+		}
+		return ids
 	}
 
-	return diffs, nil
+	return toIDs(text1), toIDs(text2), lineArray
 }
 
 // diffLinesToStrings splits two texts into a list of strings. Each string represents one line.
@@ -1310,14 +1989,16 @@ func (dmp *DiffMatchPatch) diffLinesToStrings(text1, text2 string) (string, stri
 
 	lineHash := make(map[string]int)
 	//Each string has the index of lineArray which it points to
-	strIndexArray1 := dmp.diffLinesToStringsMunge(text1, &lineArray, lineHash)
-	strIndexArray2 := dmp.diffLinesToStringsMunge(text2, &lineArray, lineHash)
+	// Allocate half of the line hashes to text1, so that text2 has some left.
+	strIndexArray1 := dmp.diffLinesToStringsMunge(text1, &lineArray, lineHash, maxRuneInt/2)
+	strIndexArray2 := dmp.diffLinesToStringsMunge(text2, &lineArray, lineHash, maxRuneInt)
 
 	return intArrayToString(strIndexArray1), intArrayToString(strIndexArray2), lineArray
 }
 
 // diffLinesToStringsMunge splits a text into an array of strings, and reduces the texts to a []string.
-func (dmp *DiffMatchPatch) diffLinesToStringsMunge(text string, lineArray *[]string, lineHash map[string]int) []uint32 {
+// Once lineArray holds maxLines lines, the rest of the text is a single line, so that no index exceeds maxLines.
+func (dmp *DiffMatchPatch) diffLinesToStringsMunge(text string, lineArray *[]string, lineHash map[string]int, maxLines int) []uint32 {
 	// Walk the text, pulling out a substring for each line. text.split('\n') would would temporarily double our memory footprint. Modifying text would create many large strings to garbage collect.
 	lineStart := 0
 	lineEnd := -1
@@ -1326,7 +2007,7 @@ func (dmp *DiffMatchPatch) diffLinesToStringsMunge(text string, lineArray *[]str
 	for lineEnd < len(text)-1 {
 		lineEnd = indexOf(text, "\n", lineStart)
 
-		if lineEnd == -1 {
+		if lineEnd == -1 || len(*lineArray) >= maxLines {
 			lineEnd = len(text) - 1
 		}
 
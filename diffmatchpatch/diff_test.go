@@ -10,9 +10,12 @@ package diffmatchpatch
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -60,6 +63,57 @@ func diffRebuildTexts(diffs []Diff) []string {
 	return texts
 }
 
+func TestParseOperation(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected      Operation
+		ExpectedError error
+	}
+
+	for i, tc := range []TestCase{
+		{"Delete", "Delete", DiffDelete, nil},
+		{"Equal", "Equal", DiffEqual, nil},
+		{"Insert", "Insert", DiffInsert, nil},
+		{"Lower case", "insert", 0, errors.New(`Invalid diff operation name: "insert"`)},
+		{"Unknown operation", "Operation(7)", 0, errors.New(`Invalid diff operation name: "Operation(7)"`)},
+		{"Empty", "", 0, errors.New(`Invalid diff operation name: ""`)},
+	} {
+		actual, err := ParseOperation(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if err == nil {
+			assert.Equal(t, tc.Text, actual.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestNewDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Op   Operation
+		Text string
+
+		Expected      Diff
+		ExpectedError error
+	}
+
+	for i, tc := range []TestCase{
+		{"Delete", DiffDelete, "abc", Diff{DiffDelete, "abc"}, nil},
+		{"Insert", DiffInsert, "", Diff{DiffInsert, ""}, nil},
+		{"Equal", DiffEqual, "日本", Diff{DiffEqual, "日本"}, nil},
+		{"Bogus operation", 7, "abc", Diff{}, errors.New("Invalid diff operation: Operation(7)")},
+		{"Negative operation", -2, "abc", Diff{}, errors.New("Invalid diff operation: Operation(-2)")},
+	} {
+		actual, err := NewDiff(tc.Op, tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffCommonPrefix(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -222,6 +276,35 @@ func TestDiffCommonOverlap(t *testing.T) {
 	}
 }
 
+func TestDiffCommonOverlapGraphemes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected int
+	}
+
+	dmp := New()
+	dmp.DiffGraphemeClusters = true
+
+	long := strings.Repeat("e\u0301x", 1000)
+
+	for i, tc := range []TestCase{
+		{"ASCII", "123456xxx", "xxxabcd", 3},
+		{"Whole clusters", "abe\u0301", "e\u0301cd", 3},
+		{"Overlap inside cluster", "xe", "e\u0301y", 0},
+		{"Shorter overlap on a boundary", "ae\u0301e", "e\u0301e\u0301b", 0},
+		{"Shorter overlap", "abab", "abab\u0301c", 2},
+		{"No shorter overlap", "xabcb", "abcb\u0301", 0},
+		{"Long overlap", "y" + long, long + "z", len(long)},
+	} {
+		actual := dmp.diffCommonOverlap(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffHalfMatch(t *testing.T) {
 	type TestCase struct {
 		Text1 string
@@ -265,6 +348,40 @@ func TestDiffHalfMatch(t *testing.T) {
 		actual := dmp.DiffHalfMatch(tc.Text1, tc.Text2)
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
 	}
+
+	// AllowNonMinimal permits half-matches without a timeout.
+	dmp.AllowNonMinimal = true
+	assert.Equal(t, []string{"qHillo", "w", "x", "Hulloy", "HelloHe"}, dmp.DiffHalfMatch("qHilloHelloHew", "xHelloHeHulloy"))
+	dmp.DiffExactMinimal = true
+	assert.Nil(t, dmp.DiffHalfMatch("qHilloHelloHew", "xHelloHeHulloy"))
+}
+
+func TestHalfMatch(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected   HalfMatch
+		ExpectedOK bool
+	}
+
+	dmp := New()
+	// HalfMatch ignores DiffTimeout.
+	dmp.DiffTimeout = 0
+
+	for i, tc := range []TestCase{
+		{"No match", "1234567890", "abcdef", HalfMatch{}, false},
+		{"Too short", "123", "23", HalfMatch{}, false},
+		{"Single match", "1234567890", "a345678z", HalfMatch{Prefix1: "12", Suffix1: "90", Prefix2: "a", Suffix2: "z", Common: "345678"}, true},
+		{"Reversed", "a345678z", "1234567890", HalfMatch{Prefix1: "a", Suffix1: "z", Prefix2: "12", Suffix2: "90", Common: "345678"}, true},
+		{"Non-optimal", "qHilloHelloHew", "xHelloHeHulloy", HalfMatch{Prefix1: "qHillo", Suffix1: "w", Prefix2: "x", Suffix2: "Hulloy", Common: "HelloHe"}, true},
+	} {
+		actual, ok := dmp.HalfMatch(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.ExpectedOK, ok, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
 }
 
 func BenchmarkDiffHalfMatch(b *testing.B) {
@@ -290,8 +407,8 @@ func TestDiffBisectSplit(t *testing.T) {
 	for _, tc := range []TestCase{
 		{"STUV\x05WX\x05YZ\x05[", "WĺĻļ\x05YZ\x05ĽľĿŀZ"},
 	} {
-		diffs := dmp.diffBisectSplit([]rune(tc.Text1),
-			[]rune(tc.Text2), 7, 6, time.Now().Add(time.Hour))
+		diffs := runeDiffsToDiffs(dmp.diffBisectSplit([]rune(tc.Text1),
+			[]rune(tc.Text2), BisectSplit{7, 6}, time.Now().Add(time.Hour)))
 
 		for _, d := range diffs {
 			assert.True(t, utf8.ValidString(d.Text))
@@ -346,6 +463,75 @@ func TestDiffLinesToChars(t *testing.T) {
 	assert.Equal(t, lineList, actualLines)
 }
 
+func TestDiffLinesToStringsMunge(t *testing.T) {
+	dmp := New()
+
+	// Once the lines are used up, the rest of the text is a single line.
+	lineArray := []string{""}
+	lineHash := map[string]int{}
+	assert.Equal(t, []uint32{1, 2, 3}, dmp.diffLinesToStringsMunge("a\nb\na\nc\nd\ne", &lineArray, lineHash, 3))
+	assert.Equal(t, []string{"", "a\n", "b\n", "a\nc\nd\ne"}, lineArray)
+	assert.Equal(t, []uint32{4}, dmp.diffLinesToStringsMunge("a\nb\n", &lineArray, lineHash, 3))
+	assert.Equal(t, []string{"", "a\n", "b\n", "a\nc\nd\ne", "a\nb\n"}, lineArray)
+}
+
+func TestDiffLinesToIDs(t *testing.T) {
+	type TestCase struct {
+		Text1 string
+		Text2 string
+
+		ExpectedIDs1  []rune
+		ExpectedIDs2  []rune
+		ExpectedLines []string
+	}
+
+	for i, tc := range []TestCase{
+		{"", "alpha\r\nbeta\r\n\r\n\r\n", []rune{}, []rune{0, 1, 2, 2}, []string{"alpha\r\n", "beta\r\n", "\r\n"}},
+		{"a", "b", []rune{0}, []rune{1}, []string{"a", "b"}},
+		// Omit final newline.
+		{"alpha\nbeta\nalpha", "", []rune{0, 1, 2}, []rune{}, []string{"alpha\n", "beta\n", "alpha"}},
+		// Same lines in Text1 and Text2
+		{"abc\ndefg\n12345\n", "abc\ndef\n12345\n678", []rune{0, 1, 2}, []rune{0, 3, 2, 4}, []string{"abc\n", "defg\n", "12345\n", "def\n", "678"}},
+	} {
+		actualIDs1, actualIDs2, actualLines := diffLinesToIDs(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.ExpectedIDs1, actualIDs1, fmt.Sprintf("Test case #%d, %#v", i, tc))
+		assert.Equal(t, tc.ExpectedIDs2, actualIDs2, fmt.Sprintf("Test case #%d, %#v", i, tc))
+		assert.Equal(t, tc.ExpectedLines, actualLines, fmt.Sprintf("Test case #%d, %#v", i, tc))
+	}
+}
+
+func TestDiffLineModeManyLines(t *testing.T) {
+	// More distinct lines than fit into the Basic Multilingual Plane, and lines which look like the old line hashes.
+	var lines1, lines2 strings.Builder
+	for x := 0; x < 70000; x++ {
+		line := strconv.Itoa(x) + "\n"
+		if x%1000 == 0 {
+			line = string(rune(x)) + "\x01\n"
+		}
+		lines1.WriteString(line)
+		if x%7000 == 0 {
+			lines2.WriteString("changed " + line)
+		} else {
+			lines2.WriteString(line)
+		}
+	}
+	text1 := lines1.String()
+	text2 := lines2.String()
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs := dmp.DiffMain(text1, text2, true)
+
+	assert.Equal(t, text1, dmp.DiffText1(diffs))
+	assert.Equal(t, text2, dmp.DiffText2(diffs))
+	for _, aDiff := range diffs {
+		if aDiff.Type != DiffEqual && aDiff.Text != "" {
+			assert.Equal(t, DiffInsert, aDiff.Type)
+			assert.Equal(t, "changed ", aDiff.Text)
+		}
+	}
+}
+
 func TestDiffCharsToLines(t *testing.T) {
 	type TestCase struct {
 		Diffs []Diff
@@ -388,6 +574,12 @@ func TestDiffCharsToLines(t *testing.T) {
 
 	actual := dmp.DiffCharsToLines([]Diff{Diff{DiffDelete, chars}}, lineList)
 	assert.Equal(t, []Diff{Diff{DiffDelete, strings.Join(lineList, "")}}, actual)
+
+	// Runes which do not stand for a line are kept.
+	actual, err := dmp.DiffCharsToLinesChecked([]Diff{{DiffEqual, "\x01\x05"}}, []string{"", "alpha\n"})
+	assert.Equal(t, []Diff{{DiffEqual, "alpha\n\x05"}}, actual)
+	assert.EqualError(t, err, `Invalid line hash '\x05' in DiffCharsToLines`)
+	assert.Equal(t, actual, dmp.DiffCharsToLines([]Diff{{DiffEqual, "\x01\x05"}}, []string{"", "alpha\n"}))
 }
 
 func TestDiffCleanupMerge(t *testing.T) {
@@ -611,6 +803,64 @@ func TestDiffCleanupSemanticLossless(t *testing.T) {
 	}
 }
 
+func TestDiffCleanupSemanticGraphemeClusters(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected         []Diff
+		ExpectedGrapheme []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{
+			"Combining mark after whitespace",
+			[]Diff{
+				{DiffEqual, "x "},
+				{DiffInsert, "\u0301y "},
+				{DiffEqual, "\u0301z"},
+			},
+			[]Diff{
+				{DiffEqual, "x "},
+				{DiffInsert, "\u0301y "},
+				{DiffEqual, "\u0301z"},
+			},
+			[]Diff{
+				{DiffEqual, "x"},
+				{DiffInsert, " \u0301y"},
+				{DiffEqual, " \u0301z"},
+			},
+		},
+		{
+			"Overlap inside cluster",
+			[]Diff{
+				{DiffDelete, "xe"},
+				{DiffInsert, "e\u0301y"},
+			},
+			[]Diff{
+				{DiffDelete, "x"},
+				{DiffEqual, "e"},
+				{DiffInsert, "\u0301y"},
+			},
+			[]Diff{
+				{DiffDelete, "xe"},
+				{DiffInsert, "e\u0301y"},
+			},
+		},
+	} {
+		dmp.DiffGraphemeClusters = false
+		actual := dmp.DiffCleanupSemantic(append([]Diff{}, tc.Diffs...))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		dmp.DiffGraphemeClusters = true
+		actual = dmp.DiffCleanupSemantic(append([]Diff{}, tc.Diffs...))
+		assert.Equal(t, tc.ExpectedGrapheme, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffCleanupSemantic(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -867,6 +1117,75 @@ func TestDiffCleanupSemantic(t *testing.T) {
 	}
 }
 
+func TestDiffCleanupSemanticWithOptions(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Options func(*SemanticCleanupOptions)
+		Diffs   []Diff
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{
+			"Defaults",
+			func(opts *SemanticCleanupOptions) {},
+			[]Diff{{DiffDelete, "abc"}, {DiffEqual, "x"}, {DiffInsert, "def"}},
+			[]Diff{{DiffDelete, "abcx"}, {DiffInsert, "xdef"}},
+		},
+		{
+			"Minimum equality length",
+			func(opts *SemanticCleanupOptions) { opts.MinEqualityLength = 3 },
+			[]Diff{{DiffDelete, "a"}, {DiffEqual, "xy"}, {DiffDelete, "b"}},
+			[]Diff{{DiffDelete, "axyb"}, {DiffInsert, "xy"}},
+		},
+		{
+			"Minimum equality length needs edits on both sides",
+			func(opts *SemanticCleanupOptions) { opts.MinEqualityLength = 3 },
+			[]Diff{{DiffEqual, "xy"}, {DiffDelete, "b"}},
+			[]Diff{{DiffEqual, "xy"}, {DiffDelete, "b"}},
+		},
+		{
+			"No equality elimination",
+			func(opts *SemanticCleanupOptions) { opts.EqualityRatio = 0 },
+			[]Diff{{DiffDelete, "abc"}, {DiffEqual, "x"}, {DiffInsert, "def"}},
+			[]Diff{{DiffDelete, "abc"}, {DiffEqual, "x"}, {DiffInsert, "def"}},
+		},
+		{
+			"Aggressive equality elimination",
+			func(opts *SemanticCleanupOptions) { opts.EqualityRatio, opts.OverlapRatio = 3, 2 },
+			[]Diff{{DiffDelete, "a"}, {DiffEqual, "xyz"}, {DiffInsert, "b"}},
+			[]Diff{{DiffDelete, "axyz"}, {DiffInsert, "xyzb"}},
+		},
+		{
+			"Overlap extraction",
+			func(opts *SemanticCleanupOptions) {},
+			[]Diff{{DiffDelete, "abcxxx"}, {DiffInsert, "xxxdef"}},
+			[]Diff{{DiffDelete, "abc"}, {DiffEqual, "xxx"}, {DiffInsert, "def"}},
+		},
+		{
+			"No overlap extraction",
+			func(opts *SemanticCleanupOptions) { opts.OverlapRatio = 2 },
+			[]Diff{{DiffDelete, "abcxxx"}, {DiffInsert, "xxxdef"}},
+			[]Diff{{DiffDelete, "abcxxx"}, {DiffInsert, "xxxdef"}},
+		},
+		{
+			"No boundary alignment",
+			func(opts *SemanticCleanupOptions) { opts.AlignBoundaries = false },
+			[]Diff{{DiffEqual, "The c"}, {DiffInsert, "ow and the c"}, {DiffEqual, "at."}},
+			[]Diff{{DiffEqual, "The c"}, {DiffInsert, "ow and the c"}, {DiffEqual, "at."}},
+		},
+	} {
+		opts := DefaultSemanticCleanupOptions()
+		tc.Options(&opts)
+		actual := dmp.DiffCleanupSemanticWithOptions(tc.Diffs, opts)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func BenchmarkDiffCleanupSemantic(b *testing.B) {
 	s1, s2 := speedtestTexts()
 
@@ -987,6 +1306,59 @@ func TestDiffCleanupEfficiency(t *testing.T) {
 	}
 }
 
+func TestDiffCleanupCompact(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs    []Diff
+		MaxEqual int
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, 3, []Diff{}},
+		{"No edits", []Diff{{DiffEqual, "abc"}}, 3, []Diff{{DiffEqual, "abc"}}},
+		{
+			"Small equality",
+			[]Diff{{DiffEqual, "The "}, {DiffDelete, "cat"}, {DiffInsert, "dog"}, {DiffEqual, " "}, {DiffDelete, "sat"}, {DiffInsert, "ran"}, {DiffEqual, " away"}},
+			3,
+			[]Diff{{DiffEqual, "The "}, {DiffDelete, "cat sat"}, {DiffInsert, "dog ran"}, {DiffEqual, " away"}},
+		},
+		{
+			"Long equality",
+			[]Diff{{DiffDelete, "ab"}, {DiffEqual, "wxyz"}, {DiffInsert, "34"}},
+			3,
+			[]Diff{{DiffDelete, "ab"}, {DiffEqual, "wxyz"}, {DiffInsert, "34"}},
+		},
+		{
+			"Several equalities",
+			[]Diff{{DiffDelete, "a"}, {DiffEqual, "1"}, {DiffInsert, "b"}, {DiffEqual, "22"}, {DiffDelete, "c"}, {DiffEqual, "日本"}, {DiffInsert, "d"}},
+			2,
+			[]Diff{{DiffDelete, "a122c日本"}, {DiffInsert, "1b22日本d"}},
+		},
+		{
+			"Edges are kept",
+			[]Diff{{DiffEqual, "x"}, {DiffDelete, "a"}, {DiffEqual, "y"}},
+			3,
+			[]Diff{{DiffEqual, "x"}, {DiffDelete, "a"}, {DiffEqual, "y"}},
+		},
+		{
+			"Zero length",
+			[]Diff{{DiffDelete, "a"}, {DiffEqual, "1"}, {DiffInsert, "b"}},
+			0,
+			[]Diff{{DiffDelete, "a"}, {DiffEqual, "1"}, {DiffInsert, "b"}},
+		},
+	} {
+		actual := dmp.DiffCleanupCompact(tc.Diffs, tc.MaxEqual)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText1(tc.Diffs), dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText2(tc.Diffs), dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffPrettyHtml(t *testing.T) {
 	type TestCase struct {
 		Diffs []Diff
@@ -1012,6 +1384,47 @@ func TestDiffPrettyHtml(t *testing.T) {
 	}
 }
 
+func TestDiffPrettyHtmlEscaping(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+	}
+
+	dmp := New()
+	tag := regexp.MustCompile(`<[^>]*>`)
+	allowedTag := regexp.MustCompile(`^<(/?(span|ins|del|bdi)|br|ins style="background:#e6ffe6;"|del style="background:#ffe6e6;")>$`)
+
+	for i, tc := range []TestCase{
+		{"Script", "<script>alert(1)</script>"},
+		{"Attribute", `"><img src=x onerror=alert(1)>`},
+		{"Single quotes", `' onmouseover='alert(1)`},
+		{"Comment", "<!-- --><![CDATA[x]]>"},
+		{"Entities", "&lt;script&gt; &amp; &#60;"},
+		{"Unterminated tag", "<a href=javascript:alert(1)"},
+		{"Control characters", "\x00<\r\n>\x0c"},
+		{"Invalid UTF-8", "\xff<\xfe>"},
+	} {
+		for _, bidi := range []bool{false, true} {
+			dmp.DiffBidiIsolation = bidi
+			diffs := []Diff{{DiffEqual, tc.Text}, {DiffDelete, tc.Text}, {DiffInsert, tc.Text}}
+			actual := dmp.DiffPrettyHtml(diffs)
+			for _, element := range tag.FindAllString(actual, -1) {
+				assert.Regexp(t, allowedTag, element, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+			text := html.UnescapeString(strings.Replace(tag.ReplaceAllString(actual, ""), "&para;", "\n", -1))
+			assert.Equal(t, strings.Repeat(tc.Text, 3), text, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// A sanitizer lets trusted markup through.
+	dmp.DiffBidiIsolation = false
+	dmp.DiffHtmlSanitizer = func(text string) string {
+		return strings.NewReplacer("&lt;b&gt;", "<b>", "&lt;/b&gt;", "</b>").Replace(html.EscapeString(text))
+	}
+	assert.Equal(t, "<span><b>a</b></span><del style=\"background:#ffe6e6;\">&lt;i&gt;b</del><ins style=\"background:#e6ffe6;\">\n</ins>", dmp.DiffPrettyHtml([]Diff{{DiffEqual, "<b>a</b>"}, {DiffDelete, "<i>b"}, {DiffInsert, "\n"}}))
+}
+
 func TestDiffPrettyText(t *testing.T) {
 	type TestCase struct {
 		Diffs []Diff
@@ -1037,6 +1450,58 @@ func TestDiffPrettyText(t *testing.T) {
 	}
 }
 
+func TestDiffPrettyBidiIsolation(t *testing.T) {
+	dmp := New()
+	dmp.DiffBidiIsolation = true
+
+	diffs := []Diff{
+		{DiffEqual, "שלום "},
+		{DiffDelete, "world"},
+		{DiffInsert, "עולם\n!"},
+		{DiffEqual, "\n"},
+	}
+	assert.Equal(t, "<span><bdi>שלום </bdi></span><del style=\"background:#ffe6e6;\"><bdi>world</bdi></del><ins style=\"background:#e6ffe6;\"><bdi>עולם&para;<br>!</bdi></ins><span><bdi>&para;<br></bdi></span>", dmp.DiffPrettyHtml(diffs))
+	assert.Equal(t, "\u2068שלום \u2069\x1b[31m\u2068world\u2069\x1b[0m\x1b[32m\u2068עולם\u2069\n\u2068!\u2069\x1b[0m\n", dmp.DiffPrettyText(diffs))
+}
+
+func TestDiffDump(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected        string
+		ExpectedCompact string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, "", ""},
+		{
+			"Operations",
+			[]Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}},
+			"0. Equal: \"a\"\n1. Delete: \"b\"\n2. Insert: \"c\"\n",
+			`="a" -"b" +"c"`,
+		},
+		{
+			"Escaping",
+			[]Diff{{DiffEqual, "a\n"}, {DiffDelete, "\t\"\x00"}, {DiffInsert, "日本\u200b"}},
+			"0. Equal: \"a\\n\"\n1. Delete: \"\\t\\\"\\x00\"\n2. Insert: \"日本\\u200b\"\n",
+			`="a\n" -"\t\"\x00" +"日本\u200b"`,
+		},
+		{
+			"Invalid operation",
+			[]Diff{{Operation(7), "x"}},
+			"0. Operation(7): \"x\"\n",
+			`Operation(7)"x"`,
+		},
+	} {
+		assert.Equal(t, tc.Expected, dmp.DiffDump(tc.Diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedCompact, dmp.DiffDumpCompact(tc.Diffs), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffText(t *testing.T) {
 	type TestCase struct {
 		Diffs []Diff
@@ -1160,6 +1625,42 @@ func TestDiffDelta(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestDiffDeltaStrictCompat(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected       string
+		ExpectedStrict string
+	}
+
+	dmp := New()
+	strict := New()
+	strict.StrictCompat = true
+
+	for i, tc := range []TestCase{
+		{"Basic Multilingual Plane", []Diff{{DiffEqual, "\u0680 \x00"}, {DiffDelete, "\u0681"}}, "=3\t-1", "=3\t-1"},
+		{"Astral plane", []Diff{{DiffInsert, "\U0001F170"}, {DiffEqual, "\U0001F170\U0001F171"}}, "+%F0%9F%85%B0\t=2", "+%F0%9F%85%B0\t=4"},
+		{"Mixed", []Diff{{DiffDelete, "a\U0001F600b"}, {DiffEqual, "\u00e9"}}, "-3\t=1", "-4\t=1"},
+	} {
+		delta := dmp.DiffToDelta(tc.Diffs)
+		assert.Equal(t, tc.Expected, delta, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual, err := dmp.DiffFromDelta(dmp.DiffText1(tc.Diffs), delta)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diffs, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		delta = strict.DiffToDelta(tc.Diffs)
+		assert.Equal(t, tc.ExpectedStrict, delta, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual, err = strict.DiffFromDelta(strict.DiffText1(tc.Diffs), delta)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Diffs, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	_, err := strict.DiffFromDelta("\U0001F170", "=1\t+x\t=1")
+	assert.EqualError(t, err, "Delta splits a surrogate pair in DiffFromDelta")
+}
+
 func TestDiffXIndex(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -1196,12 +1697,40 @@ func TestDiffLevenshtein(t *testing.T) {
 		{"Levenshtein with trailing equality", []Diff{{DiffDelete, "абв"}, {DiffInsert, "1234"}, {DiffEqual, "эюя"}}, 4},
 		{"Levenshtein with leading equality", []Diff{{DiffEqual, "эюя"}, {DiffDelete, "абв"}, {DiffInsert, "1234"}}, 4},
 		{"Levenshtein with middle equality", []Diff{{DiffDelete, "абв"}, {DiffEqual, "эюя"}, {DiffInsert, "1234"}}, 7},
+		{"ASCII", []Diff{{DiffDelete, "abc"}, {DiffInsert, "1234"}, {DiffEqual, "xyz"}}, 4},
+		{"Runes rather than bytes", []Diff{{DiffEqual, "日本"}, {DiffDelete, "語"}, {DiffInsert, "人"}}, 1},
 	} {
 		actual := dmp.DiffLevenshtein(tc.Diffs)
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
 	}
 }
 
+func TestDiffLevenshteinGraphemes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected int
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"ASCII", "kitten", "sitting", 3},
+		{"CJK", "日本語", "日本人", 1},
+		{"Combining mark replaced", "cafe\u0301", "cafe\u0300", 1},
+		{"Combining mark added", "cafe", "cafe\u0301", 1},
+		{"Emoji ZWJ sequence", "👨\u200d👩\u200d👧 family", "👨\u200d👩\u200d👦 family", 1},
+		{"Flags", "🇩🇪🇫🇷", "🇩🇪🇮🇹", 1},
+	} {
+		diffs := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		actual := dmp.DiffLevenshteinGraphemes(diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestDiffBisect(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -1258,6 +1787,59 @@ func TestDiffBisect(t *testing.T) {
 	}, dmp.DiffBisect("\xe0\xe5", "\xe0\xe5", time.Now().Add(time.Minute)))
 }
 
+func TestDiffBisectSingleRuneOutOfRange(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Single runes", "a", "b"},
+		{"Single multibyte runes", "日", "本"},
+		{"Single rune and two runes", "a", "bc"},
+	} {
+		actual := dmp.DiffBisect(tc.Text1, tc.Text2, time.Time{})
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffMiddleSnake(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1    string
+		Text2    string
+		Deadline time.Time
+
+		Expected   BisectSplit
+		ExpectedOK bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Middle snake", "cat", "map", time.Time{}, BisectSplit{2, 2}, true},
+		{"Unicode", "日本語", "日本人", time.Time{}, BisectSplit{3, 2}, true},
+		{"Nothing in common", "abc", "xyz", time.Time{}, BisectSplit{}, false},
+		{"Timeout", "cat", "map", time.Now().Add(time.Nanosecond), BisectSplit{}, false},
+	} {
+		actual, ok := dmp.DiffMiddleSnake(tc.Text1, tc.Text2, tc.Deadline)
+		assert.Equal(t, tc.ExpectedOK, ok, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if ok {
+			// DiffBisect diffs the texts before and after the split separately.
+			runes1, runes2 := []rune(tc.Text1), []rune(tc.Text2)
+			expected := append(dmp.DiffMain(string(runes1[:actual.X]), string(runes2[:actual.Y]), false), dmp.DiffMain(string(runes1[actual.X:]), string(runes2[actual.Y:]), false)...)
+			assert.Equal(t, dmp.DiffCleanupMerge(expected), dmp.DiffBisect(tc.Text1, tc.Text2, tc.Deadline), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
 func TestDiffMain(t *testing.T) {
 	type TestCase struct {
 		Text1 string
@@ -1418,6 +2000,39 @@ func TestDiffMainWithTimeout(t *testing.T) {
 	assert.True(t, delta < (dmp.DiffTimeout*100), fmt.Sprintf("%v !< %v", delta, dmp.DiffTimeout*100))
 }
 
+func TestDiffMainTruncated(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Timeout time.Duration
+
+		ExpectedTruncated bool
+	}
+
+	a := strings.Repeat("`Twas brillig, and the slithy toves\nDid gyre and gimble in the wabe:\n", 1024)
+	b := strings.Repeat("I am the very model of a modern major general,\nI've information vegetable, animal, and mineral,\n", 1024)
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No timeout", a[:1000], b[:1000], 0, false},
+		{"Fast diff", "The quick brown fox", "The slow green turtle", time.Second, false},
+		{"Timeout", a, b, 10 * time.Millisecond, true},
+	} {
+		dmp.DiffTimeout = tc.Timeout
+
+		actual, truncated := dmp.DiffMainTruncated(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.ExpectedTruncated, truncated, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if !tc.ExpectedTruncated {
+			assert.Equal(t, dmp.DiffMain(tc.Text1, tc.Text2, false), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
 func TestDiffMainWithCheckLines(t *testing.T) {
 	type TestCase struct {
 		Text1 string
@@ -1453,6 +2068,109 @@ func TestDiffMainWithCheckLines(t *testing.T) {
 	}
 }
 
+func TestDiffMainWithParallelism(t *testing.T) {
+	s1, s2 := speedtestTexts()
+
+	serial := New()
+	serial.DiffTimeout = 0
+
+	parallel := New()
+	parallel.DiffTimeout = 0
+	parallel.DiffParallelism = 4
+
+	for _, checklines := range []bool{false, true} {
+		expected := serial.DiffMain(s1, s2, checklines)
+		actual := parallel.DiffMain(s1, s2, checklines)
+		assert.Equal(t, expected, actual, fmt.Sprintf("checklines %v", checklines))
+	}
+}
+
+func TestDiffMainWithLineModeThreshold(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Threshold int
+		Text1     string
+		Text2     string
+
+		Expected []Diff
+	}
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+
+	for i, tc := range []TestCase{
+		{"Short texts stay below the default", 100, "abc\nab\nabc\n", "x\nabc\nba\n", []Diff{{DiffInsert, "x\n"}, {DiffEqual, "abc\n"}, {DiffDelete, "a"}, {DiffEqual, "b"}, {DiffDelete, "\n"}, {DiffEqual, "a"}, {DiffDelete, "bc"}, {DiffEqual, "\n"}}},
+		{"Always use line mode", 1, "abc\nab\nabc\n", "x\nabc\nba\n", []Diff{{DiffInsert, "x\n"}, {DiffEqual, "abc\n"}, {DiffInsert, "b"}, {DiffEqual, "a"}, {DiffDelete, "b\nabc"}, {DiffEqual, "\n"}}},
+		{"Never use line mode", 0, "abc\nab\nabc\n", "x\nabc\nba\n", []Diff{{DiffInsert, "x\n"}, {DiffEqual, "abc\n"}, {DiffDelete, "a"}, {DiffEqual, "b"}, {DiffDelete, "\n"}, {DiffEqual, "a"}, {DiffDelete, "bc"}, {DiffEqual, "\n"}}},
+	} {
+		dmp.DiffLineModeThreshold = tc.Threshold
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, true)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Without line mode the checklines flag makes no difference, even for long texts.
+	dmp.DiffLineModeThreshold = 0
+	text1 := strings.Repeat("1234567890\n", 13)
+	text2 := strings.Repeat("abcdefghij\n1234567890\n1234567890\n1234567890\n", 3) + "abcdefghij\n"
+	assert.Equal(t, dmp.DiffMain(text1, text2, false), dmp.DiffMain(text1, text2, true))
+}
+
+func TestDiffMainExactMinimal(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+	}
+
+	dmp := New()
+	dmp.DiffExactMinimal = true
+	dmp.DiffLineModeThreshold = 1
+
+	// The number of inserted and deleted runes of a minimal diff, computed from the longest common subsequence.
+	minimalEdits := func(text1, text2 []rune) int {
+		lcs := make([][]int, len(text1)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(text2)+1)
+		}
+		for i := 1; i <= len(text1); i++ {
+			for j := 1; j <= len(text2); j++ {
+				if text1[i-1] == text2[j-1] {
+					lcs[i][j] = lcs[i-1][j-1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i-1][j], lcs[i][j-1])
+				}
+			}
+		}
+		return len(text1) + len(text2) - 2*lcs[len(text1)][len(text2)]
+	}
+	edits := func(diffs []Diff) int {
+		n := 0
+		for _, aDiff := range diffs {
+			if aDiff.Type != DiffEqual {
+				n += len([]rune(aDiff.Text))
+			}
+		}
+		return n
+	}
+
+	for i, tc := range []TestCase{
+		{"Half-match", "qHilloHelloHew", "xHelloHeHulloy"},
+		{"Line mode", "abc\nab\nabc\n", "x\nabc\nba\n"},
+	} {
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, true)
+		assert.Equal(t, minimalEdits([]rune(tc.Text1), []rune(tc.Text2)), edits(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// The speedups do not find a minimal diff of these texts.
+		fast := New()
+		fast.DiffLineModeThreshold = 1
+		assert.True(t, edits(fast.DiffMain(tc.Text1, tc.Text2, true)) > edits(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestMassiveRuneDiffConversion(t *testing.T) {
 	sNew, err := ioutil.ReadFile("../testdata/fixture.go")
 	if err != nil {
@@ -1530,6 +2248,32 @@ func BenchmarkDiffMainLarge(b *testing.B) {
 	}
 }
 
+func BenchmarkDiffMainLargeParallel(b *testing.B) {
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffParallelism = 4
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, true)
+	}
+}
+
+func BenchmarkDiffMainLargeReuseBuffers(b *testing.B) {
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffReuseBuffers = true
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, true)
+	}
+}
+
 func BenchmarkDiffMainRunesLargeLines(b *testing.B) {
 	s1, s2 := speedtestTexts()
 
@@ -15,32 +15,113 @@ import (
 
 // DiffMatchPatch holds the configuration for diff-match-patch operations.
 type DiffMatchPatch struct {
-	// Number of seconds to map a diff before giving up (0 for infinity).
+	// Time to map a diff before giving up (0 for infinity). Sub-second durations are honored.
 	DiffTimeout time.Duration
 	// Cost of an empty edit operation in terms of edit characters.
 	DiffEditCost int
+	// Both texts must be longer than this many runes for a diff with checklines to use the line-level speedup (0 = never, 1 = always).
+	DiffLineModeThreshold int
 	// How far to search for a match (0 = exact location, 1000+ = broad match). A match this many characters away from the expected location will add 1.0 to the score (0.0 is a perfect match).
 	MatchDistance int
 	// When deleting a large block of text (over ~64 characters), how close do the contents have to be to match the expected contents. (0.0 = perfection, 1.0 = very loose).  Note that MatchThreshold controls how closely the end points of a delete need to match.
 	PatchDeleteThreshold float64
 	// Chunk size for context length.
 	PatchMargin int
-	// The number of bits in an int.
+	// The maximum pattern length used for fuzzy matching in PatchApply (0 for unlimited). Longer patterns are split or matched by their end points.
 	MatchMaxBits int
 	// At what point is no match declared (0.0 = perfection, 1.0 = very loose).
 	MatchThreshold float64
+	// Whether semantic cleanup must keep extended grapheme clusters (e.g. emoji sequences and combining marks) within a single diff.
+	DiffGraphemeClusters bool
+	// Maximum number of goroutines used to compute independent parts of a diff (0 or 1 to compute serially).
+	DiffParallelism int
+	// Unit in which DiffToDelta and DiffFromDelta count lengths. Use DeltaUTF16 to exchange deltas with JavaScript clients.
+	DeltaUnits DeltaUnit
+	// Whether DiffToDelta and DiffFromDelta count UTF-16 code units like the JavaScript and Java ports of diff-match-patch, regardless of DeltaUnits. Other deviations from the reference implementations, such as canonical diffs and patches padded only at the edges of the text, are not affected.
+	StrictCompat bool
+	// Whether to reuse internal buffers across diff computations instead of allocating them for every call, which reduces garbage collection when diffing many texts.
+	DiffReuseBuffers bool
+	// Maximum length in runes of each text given to DiffMainChecked and DiffMainRunesChecked (0 for unlimited).
+	MaxTextLength int
+	// Maximum number of insertions and deletions DiffMainChecked and DiffMainRunesChecked may compute before giving up (0 for unlimited).
+	MaxDiffOperations int
+	// Whether DiffMain ignores all white space within lines, like diff -w.
+	IgnoreAllSpace bool
+	// Whether DiffMain ignores changes in the amount of white space and white space at the end of lines, like diff -b.
+	IgnoreSpaceChange bool
+	// Whether DiffMain ignores lines which contain nothing but white space, like diff -B.
+	IgnoreBlankLines bool
+	// Function which DiffMain applies to every grapheme cluster before comparing it, e.g. strings.ToLower to diff case-insensitively. The diff still contains the original text.
+	Normalizer func(string) string
+	// Whether DiffMain treats \r\n and \n line endings as equal, and PatchApply converts the line endings of patches to those of the text.
+	IgnoreLineEndings bool
+	// Whether DiffLines diffs every replaced line with the line replacing it, to tell which parts of the line changed.
+	DiffLinesIntraline bool
+	// Number of inserted and deleted runes beyond which DiffMain gives up and returns a diff which deletes the first text and inserts the second one (0 for unlimited).
+	MaxEditDistance int
+	// Function which DiffMain calls with the number of runes of both texts it has resolved so far and their total number. Returning false cancels the diff, which then ends like a diff reaching DiffTimeout.
+	DiffProgress func(done, total int) bool
+	// Metrics to which DiffMain adds the metrics of every diff it computes (nil to not collect metrics).
+	DiffMetrics *Metrics
+	// Segmenter splitting texts into words for DiffMainWords (nil for ScriptSegmenter).
+	WordSegmenter WordSegmenter
+	// Whether DiffPrettyHtml and DiffPrettyText isolate the text of every diff from its neighbours, with <bdi> elements and Unicode FSI and PDI characters, so that the bidirectional algorithm does not reorder diffs of mixed right-to-left and left-to-right text.
+	DiffBidiIsolation bool
+	// Maximum number of context lines at each end of a hunk which LinePatchApply may ignore to find where the hunk applies, like the fuzz factor of GNU patch.
+	LinePatchFuzz int
+	// Whether DiffMain always finds a diff with the fewest inserted and deleted runes, ignoring DiffTimeout and skipping the half-match and line mode speedups. The diff then takes time proportional to the length of the texts times the number of edits, which only suits short texts.
+	DiffExactMinimal bool
+	// Whether the methods which return errors recover from internal inconsistencies, such as an index out of range caused by a bug, and return ErrInternal instead of panicking.
+	Hardened bool
+	// Function which DiffPrettyHtml calls with the text of every diff to render trusted markup in it, e.g. with an HTML sanitizer, instead of escaping the text (nil to escape all text). Its result is written as is, so it must be safe HTML.
+	DiffHtmlSanitizer func(text string) string
+	// Whether the methods which return errors reject texts which are not valid UTF-8, runes which cannot be encoded in UTF-8, and diffs which begin or end within a rune, instead of repairing them. Diffs are repaired by moving the bytes of split runes into the adjacent edits and replacing invalid bytes with U+FFFD.
+	StrictUTF8 bool
+	// Whether DiffLines ignores a byte order mark at the start of the texts, which is then left out of the diff.
+	IgnoreBOM bool
+	// Estimated number of bytes of working memory beyond which DiffMain does not bisect a part of the texts, but falls back to the line mode speedup if checklines is set, or else to a diff which deletes the part of the first text and inserts the part of the second one (0 for unlimited).
+	MaxMemoryBytes int
+	// Average length in runes of the content-defined chunks which the line mode speedup of DiffMain diffs instead of lines if the lines of the texts are longer on average, e.g. in minified JavaScript or JSON (0 to always diff lines).
+	DiffChunkSize int
+	// Length in bytes of the blocks RsyncSignature computes checksums of (0 for 2048).
+	RsyncBlockSize int
+	// Length in runes of the shingles of the texts MinHash and HashSimilarity compare (0 for 4).
+	ShingleSize int
+	// Whether DiffMain may split the texts at a substring they share which is at least half as long as the longer text, which is fast but can produce non-minimal diffs, even if DiffTimeout is not positive. Like in the reference implementations, DiffMain otherwise only does so with a positive DiffTimeout.
+	AllowNonMinimal bool
+
+	// Semaphore limiting the goroutines of one diff computation, nil when computing serially.
+	workers chan struct{}
+	// Operations a checked diff computation may still produce, nil when unlimited.
+	operations *int64
+	// Edit distance a diff computation bounded by MaxEditDistance may still use, nil when unbounded.
+	editDistance *int64
+	// Progress of a diff computation reported to DiffProgress, nil when not reported.
+	progress *diffProgress
+	// Metrics of a diff computation collected for DiffMetrics, nil when not collected.
+	metrics *diffMetrics
+	// Set to 1 when DiffTimeout truncates a diff computation, nil when not tracked.
+	timedOut *int32
+	// Set to 1 when MaxMemoryBytes coarsens a diff computation, nil when not tracked.
+	memoryLimited *int32
+	// Whether patches applied report their placements in runes rather than bytes.
+	runePlacements bool
+	// Functions added by WithPostProcess.
+	postProcess []func([]Diff) []Diff
 }
 
 // New creates a new DiffMatchPatch object with default parameters.
 func New() *DiffMatchPatch {
 	// Defaults.
 	return &DiffMatchPatch{
-		DiffTimeout:          time.Second,
-		DiffEditCost:         4,
-		MatchThreshold:       0.5,
-		MatchDistance:        1000,
-		PatchDeleteThreshold: 0.5,
-		PatchMargin:          4,
-		MatchMaxBits:         32,
+		DiffTimeout:           time.Second,
+		DiffEditCost:          4,
+		DiffLineModeThreshold: 100,
+		MatchThreshold:        0.5,
+		MatchDistance:         1000,
+		PatchDeleteThreshold:  0.5,
+		PatchMargin:           4,
+		MatchMaxBits:          32,
+		LinePatchFuzz:         2,
 	}
 }
@@ -0,0 +1,46 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+// EditOperation is one operation of an edit script, located in both texts.
+type EditOperation struct {
+	Op Operation
+	// Offset in bytes of the operation in the old text. For an insertion, this is where the text is inserted.
+	PosOld int
+	// Offset in bytes of the operation in the new text. For a deletion, this is where the text was deleted.
+	PosNew int
+	// Length of Text in bytes.
+	Len  int
+	Text string
+}
+
+// EditScript is a list of edit operations in the order of the texts.
+type EditScript []EditOperation
+
+// DiffToEditScript converts a diff to an edit script, which locates every operation in the old and the new text.
+func (dmp *DiffMatchPatch) DiffToEditScript(diffs []Diff) EditScript {
+	script := make(EditScript, 0, len(diffs))
+	posOld, posNew := 0, 0
+	for _, aDiff := range diffs {
+		script = append(script, EditOperation{
+			Op:     aDiff.Type,
+			PosOld: posOld,
+			PosNew: posNew,
+			Len:    len(aDiff.Text),
+			Text:   aDiff.Text,
+		})
+		if aDiff.Type != DiffInsert {
+			posOld += len(aDiff.Text)
+		}
+		if aDiff.Type != DiffDelete {
+			posNew += len(aDiff.Text)
+		}
+	}
+	return script
+}
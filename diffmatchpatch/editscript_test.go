@@ -0,0 +1,54 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToEditScript(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected EditScript
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, EditScript{}},
+		{
+			"Replacement",
+			[]Diff{{DiffEqual, "The "}, {DiffDelete, "quick"}, {DiffInsert, "slow"}, {DiffEqual, " fox"}},
+			EditScript{
+				{DiffEqual, 0, 0, 4, "The "},
+				{DiffDelete, 4, 4, 5, "quick"},
+				{DiffInsert, 9, 4, 4, "slow"},
+				{DiffEqual, 9, 8, 4, " fox"},
+			},
+		},
+		{
+			"Multibyte",
+			[]Diff{{DiffInsert, "日本"}, {DiffEqual, "語"}, {DiffDelete, "x"}},
+			EditScript{
+				{DiffInsert, 0, 0, 6, "日本"},
+				{DiffEqual, 0, 6, 3, "語"},
+				{DiffDelete, 3, 9, 1, "x"},
+			},
+		},
+	} {
+		actual := dmp.DiffToEditScript(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EOL is a style of line endings.
+type EOL int8
+
+const (
+	// EOLNone is the style of texts without line breaks.
+	EOLNone EOL = iota
+	// EOLLF ends lines with \n, as on Unix.
+	EOLLF
+	// EOLCRLF ends lines with \r\n, as on Windows.
+	EOLCRLF
+	// EOLCR ends lines with \r, as on classic Mac OS.
+	EOLCR
+)
+
+// String returns the usual name of the line endings.
+func (e EOL) String() string {
+	switch e {
+	case EOLNone:
+		return "None"
+	case EOLLF:
+		return "LF"
+	case EOLCRLF:
+		return "CRLF"
+	case EOLCR:
+		return "CR"
+	}
+	return fmt.Sprintf("EOL(%d)", e)
+}
+
+// BOM is the byte order mark, which some editors put at the start of UTF-8 texts.
+const BOM = "\uFEFF"
+
+// DetectEOL returns the prevailing style of the line endings of a text, i.e. the most frequent one.
+// Ties are resolved in favour of LF, then CRLF.
+func DetectEOL(text string) EOL {
+	crlf := strings.Count(text, "\r\n")
+	lf := strings.Count(text, "\n") - crlf
+	cr := strings.Count(text, "\r") - crlf
+	switch {
+	case lf == 0 && crlf == 0 && cr == 0:
+		return EOLNone
+	case lf >= crlf && lf >= cr:
+		return EOLLF
+	case crlf >= cr:
+		return EOLCRLF
+	}
+	return EOLCR
+}
+
+// StripBOM returns a text without its leading byte order mark, and whether it had one.
+func StripBOM(text string) (string, bool) {
+	if strings.HasPrefix(text, BOM) {
+		return text[len(BOM):], true
+	}
+	return text, false
+}
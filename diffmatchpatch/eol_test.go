@@ -0,0 +1,66 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectEOL(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected EOL
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", EOLNone},
+		{"Single line", "abc", EOLNone},
+		{"LF", "a\nb\n", EOLLF},
+		{"CRLF", "a\r\nb\r\n", EOLCRLF},
+		{"CR", "a\rb\r", EOLCR},
+		{"Mostly CRLF", "a\r\nb\r\nc\n", EOLCRLF},
+		{"Mostly LF", "a\r\nb\nc\n", EOLLF},
+		{"Tie of LF and CRLF", "a\r\nb\n", EOLLF},
+		{"Tie of CRLF and CR", "a\r\nb\r", EOLCRLF},
+	} {
+		assert.Equal(t, tc.Expected, DetectEOL(tc.Text), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	assert.Equal(t, "CRLF", EOLCRLF.String())
+	assert.Equal(t, "EOL(9)", EOL(9).String())
+}
+
+func TestStripBOM(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected    string
+		ExpectedBOM bool
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", "", false},
+		{"No BOM", "abc", "abc", false},
+		{"BOM", "\uFEFFabc", "abc", true},
+		{"BOM only", "\uFEFF", "", true},
+		{"BOM within the text", "a\uFEFFbc", "a\uFEFFbc", false},
+	} {
+		actual, bom := StripBOM(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedBOM, bom, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
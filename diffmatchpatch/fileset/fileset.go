@@ -0,0 +1,176 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package fileset compares two sets of files, such as two directory trees, and describes how every file changed.
+// Files are paired by their relative paths, and deleted files which are similar enough to created files are reported as renamed.
+package fileset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Op describes how a file changed.
+type Op int8
+
+const (
+	// Modified files have the same path in both sets.
+	Modified Op = iota
+	// Created files only exist in the new set.
+	Created
+	// Deleted files only exist in the old set.
+	Deleted
+	// Renamed files have been moved to another path, and may have been modified as well.
+	Renamed
+)
+
+// String returns the name of the operation.
+func (op Op) String() string {
+	switch op {
+	case Modified:
+		return "Modified"
+	case Created:
+		return "Created"
+	case Deleted:
+		return "Deleted"
+	case Renamed:
+		return "Renamed"
+	}
+	return fmt.Sprintf("Op(%d)", op)
+}
+
+// FileDiff is the change of one file.
+type FileDiff struct {
+	Op Op
+	// Slash-separated path of the file relative to the old root, empty for created files.
+	OldPath string
+	// Slash-separated path of the file relative to the new root, empty for deleted files.
+	NewPath string
+	// Patches turning the old content of the file into the new one. Created files are patched from, and deleted files to the empty text.
+	Patches []diffmatchpatch.Patch
+	// For renamed files, the similarity of the old and the new content as computed by DetectRenames.
+	Similarity float64
+}
+
+// Comparer compares sets of files.
+type Comparer struct {
+	// Configuration of the diffs and patches of the file contents.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+	// Minimum similarity of a deleted and a created file to report them as renamed (0 to never detect renames).
+	RenameThreshold float64
+}
+
+// New creates a new Comparer with default parameters.
+func New() *Comparer {
+	return &Comparer{
+		DiffMatchPatch:  diffmatchpatch.New(),
+		RenameThreshold: 0.5,
+	}
+}
+
+// CompareDirs compares the regular files of two directory trees.
+func (c *Comparer) CompareDirs(oldRoot, newRoot string) ([]FileDiff, error) {
+	oldFiles, err := readTree(oldRoot)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := readTree(newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return c.CompareFiles(oldFiles, newFiles), nil
+}
+
+// CompareFiles compares two sets of files given as contents by path. Unchanged files are omitted.
+// The file diffs are sorted by path, using the old path of deleted files and the new path of all others.
+func (c *Comparer) CompareFiles(oldFiles, newFiles map[string]string) []FileDiff {
+	dmp := c.DiffMatchPatch
+	var fileDiffs []FileDiff
+	deleted := map[string]string{}
+	for path, oldText := range oldFiles {
+		newText, ok := newFiles[path]
+		if !ok {
+			deleted[path] = oldText
+			continue
+		}
+		if oldText != newText {
+			fileDiffs = append(fileDiffs, FileDiff{Op: Modified, OldPath: path, NewPath: path, Patches: dmp.PatchMake(oldText, newText)})
+		}
+	}
+	created := map[string]string{}
+	for path, newText := range newFiles {
+		if _, ok := oldFiles[path]; !ok {
+			created[path] = newText
+		}
+	}
+
+	if c.RenameThreshold > 0 {
+		for _, rename := range dmp.DetectRenames(deleted, created, c.RenameThreshold) {
+			oldText, newText := deleted[rename.OldPath], created[rename.NewPath]
+			fileDiff := FileDiff{Op: Renamed, OldPath: rename.OldPath, NewPath: rename.NewPath, Similarity: rename.Score}
+			if oldText != newText {
+				fileDiff.Patches = dmp.PatchMake(oldText, newText)
+			}
+			fileDiffs = append(fileDiffs, fileDiff)
+			delete(deleted, rename.OldPath)
+			delete(created, rename.NewPath)
+		}
+	}
+
+	for path, oldText := range deleted {
+		fileDiffs = append(fileDiffs, FileDiff{Op: Deleted, OldPath: path, Patches: dmp.PatchMake(oldText, "")})
+	}
+	for path, newText := range created {
+		fileDiffs = append(fileDiffs, FileDiff{Op: Created, NewPath: path, Patches: dmp.PatchMake("", newText)})
+	}
+
+	sort.Slice(fileDiffs, func(i, j int) bool {
+		return fileDiffs[i].path() < fileDiffs[j].path()
+	})
+	return fileDiffs
+}
+
+// path returns the path by which file diffs are sorted.
+func (d FileDiff) path() string {
+	if d.Op == Deleted {
+		return d.OldPath
+	}
+	return d.NewPath
+}
+
+// readTree reads the regular files below root by their slash-separated relative paths.
+func readTree(root string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package fileset
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// summary describes a file diff without its patches.
+func summary(d FileDiff) string {
+	switch d.Op {
+	case Renamed:
+		return fmt.Sprintf("%v %s -> %s %.2f", d.Op, d.OldPath, d.NewPath, d.Similarity)
+	case Deleted:
+		return fmt.Sprintf("%v %s", d.Op, d.OldPath)
+	}
+	return fmt.Sprintf("%v %s", d.Op, d.NewPath)
+}
+
+func TestCompareFiles(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		OldFiles        map[string]string
+		NewFiles        map[string]string
+		RenameThreshold float64
+
+		Expected []string
+	}
+
+	story := strings.Repeat("Once upon a time there was a file.\n", 8)
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil, 0.5, nil},
+		{"Unchanged", map[string]string{"a": "x"}, map[string]string{"a": "x"}, 0.5, nil},
+		{"Modified", map[string]string{"a": "x", "b": "y"}, map[string]string{"a": "x", "b": "z"}, 0.5, []string{"Modified b"}},
+		{"Created and deleted", map[string]string{"a": "abc"}, map[string]string{"b": "xyz"}, 0.5, []string{"Deleted a", "Created b"}},
+		{"Renamed", map[string]string{"a": story}, map[string]string{"dir/b": story}, 0.5, []string{"Renamed a -> dir/b 1.00"}},
+		{"Renamed and modified", map[string]string{"a": story}, map[string]string{"b": story + "The end.\n"}, 0.5, []string{"Renamed a -> b 0.98"}},
+		{"Renames disabled", map[string]string{"a": story}, map[string]string{"b": story}, 0, []string{"Deleted a", "Created b"}},
+		{"Too different to rename", map[string]string{"a": story}, map[string]string{"b": "Something else entirely.\n"}, 0.5, []string{"Deleted a", "Created b"}},
+		{"Most similar first", map[string]string{"a": story, "b": story + "The end.\n"}, map[string]string{"c": story + "The end.\n"}, 0.5, []string{"Deleted a", "Renamed b -> c 1.00"}},
+		{"Path reused", map[string]string{"a": story, "b": "Something else entirely.\n"}, map[string]string{"b": story, "c": "Something else entirely.\n"}, 0.5, []string{"Deleted a", "Modified b", "Created c"}},
+	} {
+		c := New()
+		c.RenameThreshold = tc.RenameThreshold
+
+		var actual []string
+		for _, fileDiff := range c.CompareFiles(tc.OldFiles, tc.NewFiles) {
+			actual = append(actual, summary(fileDiff))
+			oldText, newText := tc.OldFiles[fileDiff.OldPath], tc.NewFiles[fileDiff.NewPath]
+			patched, _ := c.DiffMatchPatch.PatchApply(fileDiff.Patches, oldText)
+			assert.Equal(t, newText, patched, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestCompareDirs(t *testing.T) {
+	root, err := ioutil.TempDir("", "fileset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	story := strings.Repeat("Once upon a time there was a file.\n", 8)
+	for path, content := range map[string]string{
+		"old/same.txt":       "same\n",
+		"old/changed.txt":    "before\n",
+		"old/gone.txt":       "gone\n",
+		"old/story.txt":      story,
+		"new/same.txt":       "same\n",
+		"new/changed.txt":    "after\n",
+		"new/sub/added.txt":  "added\n",
+		"new/sub/story.txt":  story,
+		"new/sub/empty/.dir": "",
+	} {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fileDiffs, err := New().CompareDirs(filepath.Join(root, "old"), filepath.Join(root, "new"))
+	assert.NoError(t, err)
+	var actual []string
+	for _, fileDiff := range fileDiffs {
+		actual = append(actual, summary(fileDiff))
+	}
+	assert.Equal(t, []string{"Modified changed.txt", "Deleted gone.txt", "Created sub/added.txt", "Created sub/empty/.dir", "Renamed story.txt -> sub/story.txt 1.00"}, actual)
+
+	_, err = New().CompareDirs(filepath.Join(root, "missing"), filepath.Join(root, "new"))
+	assert.Error(t, err)
+}
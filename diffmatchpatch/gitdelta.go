@@ -0,0 +1,186 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// gitDeltaMaxCopy is the largest copy git's own encoder emits in a single instruction.
+	gitDeltaMaxCopy = 0x10000
+	// gitDeltaMaxInsert is the largest literal which fits into a single insert instruction.
+	gitDeltaMaxInsert = 0x7f
+)
+
+// DiffToGitDelta encodes a diff in git's binary delta format, as used by packfiles and "GIT binary patch" delta hunks.
+// Equalities become copies from the source text, insertions become literal data, and deletions are skipped.
+func (dmp *DiffMatchPatch) DiffToGitDelta(diffs []Diff) []byte {
+	var delta []byte
+	delta = appendGitDeltaSize(delta, len(dmp.DiffText1(diffs)))
+	delta = appendGitDeltaSize(delta, len(dmp.DiffText2(diffs)))
+
+	offset := 0 // Offset into the source text.
+	for _, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffEqual:
+			for size := len(aDiff.Text); size > 0; {
+				n := min(size, gitDeltaMaxCopy)
+				delta = appendGitDeltaCopy(delta, offset, n)
+				offset += n
+				size -= n
+			}
+		case DiffInsert:
+			for text := aDiff.Text; len(text) > 0; {
+				n := min(len(text), gitDeltaMaxInsert)
+				delta = append(delta, byte(n))
+				delta = append(delta, text[:n]...)
+				text = text[n:]
+			}
+		case DiffDelete:
+			offset += len(aDiff.Text)
+		}
+	}
+
+	return delta
+}
+
+// GitDeltaToDiff decodes a delta in git's binary delta format into a diff of the source text text1.
+// Copies which move forward through the source become equalities, any other copy is turned into an insertion.
+func (dmp *DiffMatchPatch) GitDeltaToDiff(text1 string, delta []byte) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	sourceSize, delta, err := readGitDeltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSize != len(text1) {
+		return nil, fmt.Errorf("Delta source size (%v) is different from source text length (%v)", sourceSize, len(text1))
+	}
+	targetSize, delta, err := readGitDeltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Diff
+	pointer := 0 // Offset into the source text up to which it has been consumed.
+	length := 0  // Length of the target text decoded so far.
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			if op == 0 {
+				return nil, errors.New("Invalid opcode 0 in git delta")
+			}
+			n := int(op)
+			if n > len(delta) {
+				return nil, fmt.Errorf("Insert of %v bytes exceeds the end of the git delta", n)
+			}
+			diffs = append(diffs, Diff{DiffInsert, string(delta[:n])})
+			delta = delta[n:]
+			length += n
+			continue
+		}
+
+		// A copy: the low four bits select the offset bytes, the next three bits the size bytes.
+		var offset, size int
+		for i := uint(0); i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, errors.New("Truncated copy instruction in git delta")
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				size |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if size == 0 {
+			size = gitDeltaMaxCopy
+		}
+		if offset+size > len(text1) {
+			return nil, fmt.Errorf("Copy of %v bytes at %v exceeds the source text length (%v)", size, offset, len(text1))
+		}
+
+		if offset >= pointer {
+			if offset > pointer {
+				diffs = append(diffs, Diff{DiffDelete, text1[pointer:offset]})
+			}
+			diffs = append(diffs, Diff{DiffEqual, text1[offset : offset+size]})
+			pointer = offset + size
+		} else {
+			// The data was already consumed, it has to be inserted again.
+			diffs = append(diffs, Diff{DiffInsert, text1[offset : offset+size]})
+		}
+		length += size
+	}
+	if pointer < len(text1) {
+		diffs = append(diffs, Diff{DiffDelete, text1[pointer:]})
+	}
+
+	if length != targetSize {
+		return nil, fmt.Errorf("Delta target size (%v) is different from decoded length (%v)", targetSize, length)
+	}
+
+	return dmp.DiffCleanupMerge(diffs), nil
+}
+
+// appendGitDeltaSize appends n as a little-endian base 128 number.
+func appendGitDeltaSize(delta []byte, n int) []byte {
+	for n >= 0x80 {
+		delta = append(delta, byte(n)|0x80)
+		n >>= 7
+	}
+	return append(delta, byte(n))
+}
+
+// readGitDeltaSize reads a little-endian base 128 number from the start of delta and returns it together with the rest of delta.
+func readGitDeltaSize(delta []byte) (int, []byte, error) {
+	n := 0
+	for shift := uint(0); ; shift += 7 {
+		if len(delta) == 0 {
+			return 0, nil, errors.New("Truncated size in git delta header")
+		}
+		if shift > 56 {
+			return 0, nil, errors.New("Size in git delta header is too large")
+		}
+		b := delta[0]
+		delta = delta[1:]
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n, delta, nil
+		}
+	}
+}
+
+// appendGitDeltaCopy appends an instruction copying size bytes at offset of the source text, leaving out zero bytes of both.
+func appendGitDeltaCopy(delta []byte, offset, size int) []byte {
+	op := byte(0x80)
+	var args []byte
+	for i := uint(0); i < 4; i++ {
+		if b := byte(offset >> (8 * i)); b != 0 {
+			op |= 1 << i
+			args = append(args, b)
+		}
+	}
+	if size != gitDeltaMaxCopy {
+		// A size of zero stands for gitDeltaMaxCopy.
+		for i := uint(0); i < 3; i++ {
+			if b := byte(size >> (8 * i)); b != 0 {
+				op |= 1 << (4 + i)
+				args = append(args, b)
+			}
+		}
+	}
+	return append(append(delta, op), args...)
+}
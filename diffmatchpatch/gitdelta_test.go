@@ -0,0 +1,83 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToGitDelta(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []byte
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []byte{0x00, 0x00}},
+		{"Copy, skip and insert", []Diff{{DiffEqual, "abc"}, {DiffDelete, "d"}, {DiffInsert, "xy"}, {DiffEqual, "ef"}}, []byte{0x06, 0x07, 0x90, 0x03, 0x02, 'x', 'y', 0x91, 0x04, 0x02}},
+		{"Long insert", []Diff{{DiffInsert, strings.Repeat("x", 130)}}, append(append(append([]byte{0x00, 0x82, 0x01, 0x7f}, strings.Repeat("x", 127)...), 0x03), "xxx"...)},
+		{"Long copy", []Diff{{DiffEqual, strings.Repeat("x", 0x10001)}}, []byte{0x81, 0x80, 0x04, 0x81, 0x80, 0x04, 0x80, 0x94, 0x01, 0x01}},
+	} {
+		actual := dmp.DiffToGitDelta(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestGitDeltaToDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Delta []byte
+
+		Expected      []Diff
+		ErrorContains string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Copy, skip and insert", "abcdef", []byte{0x06, 0x07, 0x90, 0x03, 0x02, 'x', 'y', 0x91, 0x04, 0x02}, []Diff{{DiffEqual, "abc"}, {DiffDelete, "d"}, {DiffInsert, "xy"}, {DiffEqual, "ef"}}, ""},
+		{"Backward copy", "abcdef", []byte{0x06, 0x06, 0x91, 0x03, 0x03, 0x90, 0x03}, []Diff{{DiffDelete, "abc"}, {DiffEqual, "def"}, {DiffInsert, "abc"}}, ""},
+		{"Repeated copy", "ab", []byte{0x02, 0x04, 0x90, 0x02, 0x90, 0x02}, []Diff{{DiffEqual, "ab"}, {DiffInsert, "ab"}}, ""},
+		{"Source size mismatch", "abc", []byte{0x02, 0x00}, nil, "source size"},
+		{"Target size mismatch", "ab", []byte{0x02, 0x01, 0x90, 0x02}, nil, "target size"},
+		{"Truncated header", "", []byte{0x80}, nil, "Truncated size"},
+		{"Truncated insert", "", []byte{0x00, 0x02, 0x02, 'x'}, nil, "exceeds the end"},
+		{"Truncated copy", "ab", []byte{0x02, 0x02, 0x91, 0x00}, nil, "Truncated copy"},
+		{"Copy out of bounds", "ab", []byte{0x02, 0x02, 0x91, 0x01, 0x02}, nil, "exceeds the source"},
+		{"Reserved opcode", "", []byte{0x00, 0x00, 0x00}, nil, "opcode 0"},
+	} {
+		actual, err := dmp.GitDeltaToDiff(tc.Text1, tc.Delta)
+		if tc.ErrorContains != "" {
+			if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+				assert.Contains(t, err.Error(), tc.ErrorContains, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Deltas round-trip.
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	actual, err := dmp.GitDeltaToDiff(text1, dmp.DiffToGitDelta(diffs))
+	assert.NoError(t, err)
+	assert.Equal(t, diffs, actual)
+}
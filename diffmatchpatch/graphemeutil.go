@@ -0,0 +1,176 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode"
+)
+
+// graphemeProperty is the Grapheme_Cluster_Break property of a rune as defined by UAX #29.
+type graphemeProperty int
+
+const (
+	graphemeOther graphemeProperty = iota
+	graphemeCR
+	graphemeLF
+	graphemeControl
+	graphemeExtend
+	graphemeZWJ
+	graphemeRegionalIndicator
+	graphemeSpacingMark
+	graphemeL
+	graphemeV
+	graphemeT
+	graphemeLV
+	graphemeLVT
+)
+
+// extendedPictographic approximates the Extended_Pictographic property of UTS #51, which the standard library does not provide.
+var extendedPictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00a9, 0x00a9, 1},
+		{0x00ae, 0x00ae, 1},
+		{0x203c, 0x203c, 1},
+		{0x2049, 0x2049, 1},
+		{0x2122, 0x2122, 1},
+		{0x2139, 0x2139, 1},
+		{0x2194, 0x21aa, 1},
+		{0x2300, 0x23ff, 1},
+		{0x24c2, 0x24c2, 1},
+		{0x25aa, 0x25fe, 1},
+		{0x2600, 0x27bf, 1},
+		{0x2934, 0x2935, 1},
+		{0x2b05, 0x2b55, 1},
+		{0x3030, 0x3030, 1},
+		{0x303d, 0x303d, 1},
+		{0x3297, 0x3297, 1},
+		{0x3299, 0x3299, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f000, 0x1f1e5, 1},
+		{0x1f200, 0x1f3fa, 1},
+		{0x1f400, 0x1faff, 1},
+		{0x1fc00, 0x1fffd, 1},
+	},
+}
+
+// graphemePropertyOf classifies r for grapheme cluster segmentation.
+func graphemePropertyOf(r rune) graphemeProperty {
+	switch {
+	case r == '\r':
+		return graphemeCR
+	case r == '\n':
+		return graphemeLF
+	case r == 0x200d:
+		return graphemeZWJ
+	case r == 0x200c, r >= 0x1f3fb && r <= 0x1f3ff, r >= 0xe0020 && r <= 0xe007f:
+		// Zero width non-joiner, emoji modifiers and tag characters.
+		return graphemeExtend
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return graphemeRegionalIndicator
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return graphemeL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return graphemeV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return graphemeT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return graphemeLV
+		}
+		return graphemeLVT
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return graphemeExtend
+	case unicode.Is(unicode.Mc, r):
+		return graphemeSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return graphemeControl
+	}
+	return graphemeOther
+}
+
+// graphemeBoundaries returns the byte offsets at which the extended grapheme clusters of text start, followed by len(text).
+// The segmentation follows the rules of UAX #29 except for the Prepend rule.
+func graphemeBoundaries(text string) []int {
+	boundaries := []int{}
+	var prev graphemeProperty
+	// Whether the current cluster consists of an Extended_Pictographic rune followed by Extend runes, optionally ending in a ZWJ.
+	pictographic := false
+	// Number of consecutive regional indicators preceding the current rune.
+	regional := 0
+
+	for i, r := range text {
+		prop := graphemePropertyOf(r)
+		isPictographic := unicode.Is(extendedPictographic, r)
+		if i == 0 || graphemeBreak(prev, prop, pictographic && isPictographic, regional) {
+			boundaries = append(boundaries, i)
+		}
+
+		switch {
+		case isPictographic:
+			pictographic = true
+		case prop == graphemeExtend && pictographic, prop == graphemeZWJ && pictographic:
+			// Still part of an emoji sequence.
+		default:
+			pictographic = false
+		}
+		if prop == graphemeRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = prop
+	}
+
+	return append(boundaries, len(text))
+}
+
+// graphemeBreak reports whether there is a grapheme cluster boundary between runes with the properties prev and next.
+// joinsEmoji is true if next is pictographic and follows an emoji sequence, regional is the number of regional indicators preceding next.
+func graphemeBreak(prev, next graphemeProperty, joinsEmoji bool, regional int) bool {
+	switch {
+	case prev == graphemeCR && next == graphemeLF:
+		return false
+	case prev == graphemeCR, prev == graphemeLF, prev == graphemeControl:
+		return true
+	case next == graphemeCR, next == graphemeLF, next == graphemeControl:
+		return true
+	case prev == graphemeL && (next == graphemeL || next == graphemeV || next == graphemeLV || next == graphemeLVT):
+		return false
+	case (prev == graphemeLV || prev == graphemeV) && (next == graphemeV || next == graphemeT):
+		return false
+	case (prev == graphemeLVT || prev == graphemeT) && next == graphemeT:
+		return false
+	case next == graphemeExtend, next == graphemeZWJ, next == graphemeSpacingMark:
+		return false
+	case prev == graphemeZWJ && joinsEmoji:
+		return false
+	case prev == graphemeRegionalIndicator && next == graphemeRegionalIndicator:
+		// Regional indicators pair up into flags.
+		return regional%2 == 0
+	}
+	return true
+}
+
+// graphemeCount returns the number of extended grapheme clusters in text.
+func graphemeCount(text string) int {
+	return len(graphemeBoundaries(text)) - 1
+}
+
+// graphemeIndex maps every byte offset of text to the index of the grapheme cluster containing it.
+func graphemeIndex(text string) []int {
+	boundaries := graphemeBoundaries(text)
+	index := make([]int, len(text))
+	for c := 0; c < len(boundaries)-1; c++ {
+		for i := boundaries[c]; i < boundaries[c+1]; i++ {
+			index[i] = c
+		}
+	}
+	return index
+}
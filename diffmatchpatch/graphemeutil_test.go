@@ -0,0 +1,48 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphemeBoundaries(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []int
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", []int{0}},
+		{"ASCII", "abc", []int{0, 1, 2, 3}},
+		{"CRLF", "a\r\nb", []int{0, 1, 3, 4}},
+		{"Combining marks", "e\u0301\u0302x", []int{0, 5, 6}},
+		{"Hangul jamo", "\u1100\u1161\u11a8\uac00", []int{0, 9, 12}},
+		{"Emoji modifier", "👍🏽!", []int{0, 8, 9}},
+		{"Emoji ZWJ sequence", "👨\u200d👩\u200d👧", []int{0, 18}},
+		{"ZWJ without emoji", "a\u200db", []int{0, 4, 5}},
+		{"Variation selector", "\u2764\ufe0fx", []int{0, 6, 7}},
+		{"Flags", "🇩🇪🇫🇷🇮", []int{0, 8, 16, 20}},
+		{"Control", "a\u0000\u0301", []int{0, 1, 2, 4}},
+	} {
+		actual := graphemeBoundaries(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestGraphemeIndex(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 1, 1, 2}, graphemeIndex("ae\u0301b"))
+	assert.Equal(t, 3, graphemeCount("ae\u0301b"))
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInternal is returned by the methods which return errors in Hardened mode when they run into an internal inconsistency, such as an index out of range.
+var ErrInternal = errors.New("Internal error in diffmatchpatch")
+
+// recoverInternal turns a panic of a method returning err into ErrInternal in Hardened mode.  It must be deferred by the method itself.
+func (dmp *DiffMatchPatch) recoverInternal(err *error) {
+	if !dmp.Hardened {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = ErrInternal
+	}
+}
+
+// fuzzTexts splits fuzzer input at NUL bytes into n valid UTF-8 texts.
+func fuzzTexts(data []byte, n int) []string {
+	texts := make([]string, n)
+	for i, part := range bytes.SplitN(data, []byte{0}, n) {
+		texts[i] = strings.ToValidUTF8(string(part), "�")
+	}
+	return texts
+}
+
+// FuzzDiffMain is an entry point for fuzzers such as go-fuzz, which diffs the two texts of data separated by a NUL byte and returns an error if the library misbehaves.
+// A go-fuzz function can panic with the error to report a crash.
+func (dmp *DiffMatchPatch) FuzzDiffMain(data []byte) error {
+	hardened := *dmp
+	hardened.Hardened = true
+	texts := fuzzTexts(data, 2)
+
+	diffs, err := hardened.DiffMainChecked(texts[0], texts[1], true)
+	if err == ErrInternal {
+		return err
+	} else if err != nil {
+		// The input exceeds the configured limits.
+		return nil
+	}
+	if dmp.DiffText1(diffs) != texts[0] || dmp.DiffText2(diffs) != texts[1] {
+		return fmt.Errorf("DiffMain does not reproduce the texts %q and %q", texts[0], texts[1])
+	}
+	decoded, err := hardened.DiffFromDelta(texts[0], dmp.DiffToDelta(diffs))
+	if err != nil {
+		return err
+	}
+	if dmp.DiffText2(decoded) != texts[1] {
+		return fmt.Errorf("DiffFromDelta does not reproduce the text %q", texts[1])
+	}
+	return nil
+}
+
+// FuzzPatchApply is an entry point for fuzzers such as go-fuzz, which makes patches between the first two texts of data separated by NUL bytes, applies them to the first and the third text, and returns an error if the library misbehaves.
+// A go-fuzz function can panic with the error to report a crash.
+func (dmp *DiffMatchPatch) FuzzPatchApply(data []byte) error {
+	hardened := *dmp
+	hardened.Hardened = true
+	texts := fuzzTexts(data, 3)
+
+	patches := dmp.PatchMake(texts[0], texts[1])
+	parsed, err := hardened.PatchFromText(dmp.PatchToText(patches))
+	if err != nil {
+		return err
+	}
+	if dmp.PatchToText(parsed) != dmp.PatchToText(patches) {
+		return fmt.Errorf("PatchFromText does not reproduce the patches %q", dmp.PatchToText(patches))
+	}
+	patched, results, err := hardened.PatchApplyChecked(patches, texts[0])
+	if err != nil {
+		return err
+	}
+	for i, applied := range results {
+		if !applied {
+			return fmt.Errorf("Patch %d does not apply to the text %q it was made from", i, texts[0])
+		}
+	}
+	if patched != texts[1] {
+		return fmt.Errorf("PatchApply does not turn %q into %q", texts[0], texts[1])
+	}
+	// The patches may not apply to an arbitrary text, but must not break.
+	if _, _, err := hardened.PatchApplyWithOptions(patches, texts[2], DefaultPatchApplyOptions()); err != nil {
+		return err
+	}
+	return nil
+}
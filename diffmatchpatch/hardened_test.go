@@ -0,0 +1,76 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenReader stands for an inconsistency which makes a method panic.
+type brokenReader struct{}
+
+func (brokenReader) Read(p []byte) (int, error) {
+	return len(p) + 1, nil
+}
+
+func TestHardened(t *testing.T) {
+	dmp := New()
+
+	assert.Panics(t, func() {
+		_, _ = dmp.MatchStream(brokenReader{}, "abc", 0.5)
+	})
+
+	dmp.Hardened = true
+	var err error
+	assert.NotPanics(t, func() {
+		_, err = dmp.MatchStream(brokenReader{}, "abc", 0.5)
+	})
+	assert.Equal(t, ErrInternal, err)
+
+	// Errors which are not internal are returned as they are.
+	_, err = dmp.PatchFromText("Bad\nPatch\n")
+	assert.EqualError(t, err, "Invalid patch string: Bad")
+}
+
+func TestFuzzEntryPoints(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Data string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", ""},
+		{"One text", "abc"},
+		{"Two texts", "The quick brown fox\x00The slow brown dog"},
+		{"Three texts", "The quick brown fox\x00The slow brown dog\x00A quick brown fox!"},
+		{"Invalid UTF-8", "a\xffb\x00a\xfeb\x00\xc0"},
+	} {
+		assert.NoError(t, dmp.FuzzDiffMain([]byte(tc.Data)), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.NoError(t, dmp.FuzzPatchApply([]byte(tc.Data)), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Random inputs from a small alphabet, so that the texts share some text.
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []byte("ab \n\x00日")
+	for n := 0; n < 200; n++ {
+		data := make([]byte, rng.Intn(200))
+		for i := range data {
+			data[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		assert.NoError(t, dmp.FuzzDiffMain(data), fmt.Sprintf("%q", data))
+		assert.NoError(t, dmp.FuzzPatchApply(data), fmt.Sprintf("%q", data))
+	}
+}
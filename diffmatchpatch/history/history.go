@@ -0,0 +1,183 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package history stores the versions of a text compactly, as a wiki or a content management system keeps the revisions of a page.
+// Versions are stored as deltas from their previous version, in the format of DiffToDeltaV2 counting runes, which start from a snapshot of a version stored in full.
+package history
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// History is the list of versions of a text, numbered from 0.
+type History struct {
+	// Configuration of the diffs between versions.  Differences it ignores, such as with IgnoreAllSpace or a Normalizer, are stored all the same.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+	// Number of versions after which Append stores a snapshot, bounding the number of deltas to apply to reconstruct a version (0 for no limit).
+	SnapshotInterval int
+	// Size of the deltas since the last snapshot, relative to the size of the text, beyond which Append stores a snapshot (0 for no limit).
+	SnapshotRatio float64
+
+	storage  Storage
+	versions int
+	// Text of the latest version, from which the delta of the next version is computed.
+	latest string
+	// Number of deltas, and their total size, since the last snapshot.
+	deltas     int
+	deltasSize int
+}
+
+// New creates a new, empty History with default parameters, which keeps its versions in memory.
+func New() *History {
+	return &History{
+		DiffMatchPatch: diffmatchpatch.New(),
+		storage:        &MemoryStorage{},
+	}
+}
+
+// Open creates a History with default parameters of the given number of versions already kept by storage.
+func Open(storage Storage, versions int) (*History, error) {
+	h := &History{
+		DiffMatchPatch: diffmatchpatch.New(),
+		storage:        storage,
+		versions:       versions,
+	}
+	if versions == 0 {
+		return h, nil
+	}
+	var err error
+	if h.latest, err = h.reconstruct(versions - 1); err != nil {
+		return nil, err
+	}
+	// Count the deltas since the last snapshot.
+	for v := versions - 1; v > 0; v-- {
+		record, err := storage.Get(v)
+		if err != nil {
+			return nil, err
+		}
+		if record.Snapshot {
+			break
+		}
+		h.deltas++
+		h.deltasSize += len(record.Data)
+	}
+	return h, nil
+}
+
+// Len returns the number of versions.
+func (h *History) Len() int {
+	return h.versions
+}
+
+// Append adds text as the latest version and returns its version number.
+// The first version is stored as a snapshot, every other one as a delta from the version before it, unless SnapshotInterval or SnapshotRatio call for a snapshot.
+func (h *History) Append(text string) (int, error) {
+	record := Record{Snapshot: true, Data: text}
+	if h.versions != 0 {
+		delta, err := h.delta(h.latest, text)
+		if err != nil {
+			return 0, err
+		}
+		if !h.needsSnapshot(len(delta), len(text)) {
+			record = Record{Data: delta}
+		}
+	}
+	if err := h.storage.Put(h.versions, record); err != nil {
+		return 0, err
+	}
+	if record.Snapshot {
+		h.deltas, h.deltasSize = 0, 0
+	} else {
+		h.deltas++
+		h.deltasSize += len(record.Data)
+	}
+	h.latest = text
+	h.versions++
+	return h.versions - 1, nil
+}
+
+// needsSnapshot returns whether a version should be stored as a snapshot rather than as one more delta of the given size.
+func (h *History) needsSnapshot(deltaSize, textSize int) bool {
+	if h.SnapshotInterval > 0 && h.deltas+1 >= h.SnapshotInterval {
+		return true
+	}
+	return h.SnapshotRatio > 0 && float64(h.deltasSize+deltaSize) > h.SnapshotRatio*float64(textSize)
+}
+
+// Rebase stores the latest version as a snapshot, so that the versions appended from now on are reconstructed without the versions before it.
+func (h *History) Rebase() error {
+	if h.versions == 0 {
+		return nil
+	}
+	if err := h.storage.Put(h.versions-1, Record{Snapshot: true, Data: h.latest}); err != nil {
+		return err
+	}
+	h.deltas, h.deltasSize = 0, 0
+	return nil
+}
+
+// Version reconstructs the text of a version from the nearest snapshot before it.
+func (h *History) Version(version int) (string, error) {
+	if version < 0 || version >= h.versions {
+		return "", fmt.Errorf("Version %d does not exist", version)
+	}
+	if version == h.versions-1 {
+		return h.latest, nil
+	}
+	return h.reconstruct(version)
+}
+
+// reconstruct reads a version from the storage.
+func (h *History) reconstruct(version int) (string, error) {
+	// Collect the records back to the snapshot.
+	var records []string
+	start := version
+	for {
+		record, err := h.storage.Get(start)
+		if err != nil {
+			return "", err
+		}
+		records = append(records, record.Data)
+		if record.Snapshot {
+			break
+		}
+		if start == 0 {
+			return "", errors.New("Version 0 is not a snapshot")
+		}
+		start--
+	}
+	text := records[len(records)-1]
+	for v := start + 1; v <= version; v++ {
+		diffs, err := h.DiffMatchPatch.DiffFromDeltaV2(text, records[version-v])
+		if err != nil {
+			return "", fmt.Errorf("Invalid delta of version %d: %v", v, err)
+		}
+		text = h.DiffMatchPatch.DiffText2(diffs)
+	}
+	return text, nil
+}
+
+// delta computes a compact delta turning text1 into text2.  Its header declares its unit, so that it decodes whatever the DeltaUnits of the DiffMatchPatch.
+func (h *History) delta(text1, text2 string) (string, error) {
+	// The delta has to reproduce text2 exactly, so no differences may be ignored.
+	exact := *h.DiffMatchPatch
+	exact.IgnoreAllSpace = false
+	exact.IgnoreSpaceChange = false
+	exact.IgnoreBlankLines = false
+	exact.IgnoreLineEndings = false
+	exact.Normalizer = nil
+	diffs := exact.DiffCleanupEfficiency(exact.DiffMain(text1, text2, true))
+	if exact.DiffText1(diffs) != text1 || exact.DiffText2(diffs) != text2 {
+		// A function added by WithPostProcess changed the text.
+		return "", fmt.Errorf("Diff of version %d does not reproduce its text", h.versions)
+	}
+	return exact.DiffToDeltaV2(diffs, diffmatchpatch.DeltaRunes), nil
+}
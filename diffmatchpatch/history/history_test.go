@@ -0,0 +1,221 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+var versions = []string{
+	"The quick brown fox jumps over the lazy dog.\n",
+	"The quick brown fox jumps over the lazy dog.\nPack my box with five dozen liquor jugs.\n",
+	"That quick brown fox jumped over a lazy dog.\nPack my box with five dozen liquor jugs.\n",
+	"",
+	"日本語のテキスト\n",
+	"日本語のテキストです。\n",
+}
+
+// snapshots returns the versions of a history which are stored as snapshots.
+func snapshots(h *History) []int {
+	var snapshots []int
+	for v := 0; v < h.Len(); v++ {
+		if record, _ := h.storage.Get(v); record.Snapshot {
+			snapshots = append(snapshots, v)
+		}
+	}
+	return snapshots
+}
+
+func TestHistory(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		// Versions after which the history is rebased.
+		Rebases          []int
+		SnapshotInterval int
+		SnapshotRatio    float64
+
+		ExpectedSnapshots []int
+	}
+
+	for i, tc := range []TestCase{
+		{"Deltas only", nil, 0, 0, []int{0}},
+		{"Rebased", []int{2, 3}, 0, 0, []int{0, 2, 3}},
+		{"Rebased at the end", []int{len(versions) - 1}, 0, 0, []int{0, 5}},
+		{"Snapshot interval", nil, 2, 0, []int{0, 2, 4}},
+		{"Snapshot interval of one", nil, 1, 0, []int{0, 1, 2, 3, 4, 5}},
+		{"Snapshot interval after rebase", []int{1}, 3, 0, []int{0, 1, 4}},
+		{"Snapshot ratio", nil, 0, 0.7, []int{0, 1, 3, 4, 5}},
+		{"Large snapshot ratio", nil, 0, 5, []int{0, 3}},
+	} {
+		h := New()
+		h.SnapshotInterval = tc.SnapshotInterval
+		h.SnapshotRatio = tc.SnapshotRatio
+		for v, text := range versions {
+			actual, err := h.Append(text)
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			assert.Equal(t, v, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			for _, rebase := range tc.Rebases {
+				if rebase == v {
+					assert.NoError(t, h.Rebase(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				}
+			}
+		}
+		assert.Equal(t, len(versions), h.Len(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedSnapshots, snapshots(h), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		for v, expected := range versions {
+			actual, err := h.Version(v)
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s, version %d", i, tc.Name, v))
+			assert.Equal(t, expected, actual, fmt.Sprintf("Test case #%d, %s, version %d", i, tc.Name, v))
+		}
+		for _, v := range []int{-1, len(versions)} {
+			_, err := h.Version(v)
+			assert.Equal(t, fmt.Errorf("Version %d does not exist", v), err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestHistoryRecords(t *testing.T) {
+	h := New()
+	assert.NoError(t, h.Rebase())
+	assert.Equal(t, 0, h.Len())
+
+	text := strings.Repeat("Once upon a time there was a text.\n", 100)
+	_, _ = h.Append(text)
+	_, _ = h.Append(text + "The end.\n")
+	record, err := h.storage.Get(0)
+	assert.NoError(t, err)
+	assert.Equal(t, Record{Snapshot: true, Data: text}, record)
+	// Versions after the first one are stored as deltas which declare their unit.
+	record, err = h.storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Record{Data: "v2 units=runes escape=uri\n=3500\t+The end.%0A"}, record)
+
+	assert.NoError(t, h.storage.Put(1, Record{Data: "v2 units=runes escape=uri\n=1"}))
+	_, _ = h.Append("")
+	_, err = h.Version(1)
+	assert.Equal(t, errors.New("Invalid delta of version 1: Delta length (1) is different from source text length (3500)"), err)
+
+	assert.NoError(t, h.storage.Put(0, Record{Data: "=1"}))
+	_, err = h.Version(1)
+	assert.Equal(t, errors.New("Version 0 is not a snapshot"), err)
+}
+
+func TestOpen(t *testing.T) {
+	storage := &MemoryStorage{}
+	h, err := Open(storage, 0)
+	assert.NoError(t, err)
+	h.SnapshotInterval = 2
+	for _, text := range versions[:4] {
+		_, err = h.Append(text)
+		assert.NoError(t, err)
+	}
+
+	reopened, err := Open(storage, 4)
+	assert.NoError(t, err)
+	reopened.SnapshotInterval = 2
+	for _, text := range versions[4:] {
+		_, err = reopened.Append(text)
+		assert.NoError(t, err)
+	}
+	// The interval continues from the snapshot stored before reopening.
+	assert.Equal(t, []int{0, 2, 4}, snapshots(reopened))
+	for v, expected := range versions {
+		actual, err := reopened.Version(v)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	_, err = Open(storage, 7)
+	assert.Equal(t, errors.New("Version 6 is not stored"), err)
+
+	// The stored deltas declare their unit, so that they decode with other settings.
+	h = New()
+	h.DiffMatchPatch.DeltaUnits = diffmatchpatch.DeltaUTF16
+	for _, text := range []string{"😀a", "😀ab", "😀abc"} {
+		_, err = h.Append(text)
+		assert.NoError(t, err)
+	}
+	reopened, err = Open(h.storage, 3)
+	assert.NoError(t, err)
+	actual, err := reopened.Version(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "😀ab", actual)
+}
+
+func TestHistoryIgnoredDifferences(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+	}
+
+	spaces := diffmatchpatch.New()
+	spaces.IgnoreAllSpace = true
+	lower := diffmatchpatch.New()
+	lower.Normalizer = strings.ToLower
+	lineEndings := diffmatchpatch.New()
+	lineEndings.IgnoreLineEndings = true
+
+	texts := []string{"a b\n", "a   B\r\n", "c\n"}
+
+	for i, tc := range []TestCase{
+		{"Ignore all space", spaces},
+		{"Normalizer", lower},
+		{"Ignore line endings", lineEndings},
+	} {
+		h := New()
+		h.DiffMatchPatch = tc.DiffMatchPatch
+		for _, text := range texts {
+			_, err := h.Append(text)
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		for v, expected := range texts {
+			actual, err := h.Version(v)
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s, version %d", i, tc.Name, v))
+			assert.Equal(t, expected, actual, fmt.Sprintf("Test case #%d, %s, version %d", i, tc.Name, v))
+		}
+	}
+
+	// Post-processing which changes the text is rejected.
+	h := New()
+	h.DiffMatchPatch = diffmatchpatch.New().WithPostProcess(func(diffs []diffmatchpatch.Diff) []diffmatchpatch.Diff {
+		return append(diffs, diffmatchpatch.Diff{Type: diffmatchpatch.DiffInsert, Text: "!"})
+	})
+	_, err := h.Append("a")
+	assert.NoError(t, err)
+	_, err = h.Append("b")
+	assert.Equal(t, errors.New("Diff of version 1 does not reproduce its text"), err)
+	assert.Equal(t, 1, h.Len())
+}
+
+// failingStorage is a Storage which cannot store anything.
+type failingStorage struct {
+	MemoryStorage
+}
+
+func (s *failingStorage) Put(version int, record Record) error {
+	return errors.New("Storage is full")
+}
+
+func TestHistoryStorageError(t *testing.T) {
+	h, err := Open(&failingStorage{}, 0)
+	assert.NoError(t, err)
+	_, err = h.Append("text")
+	assert.Equal(t, errors.New("Storage is full"), err)
+	assert.Equal(t, 0, h.Len())
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package history
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Record is how a version is stored.
+type Record struct {
+	// Whether Data is the full text of the version rather than a delta from the previous version.
+	Snapshot bool
+	Data     string
+}
+
+// Storage stores the records of the versions of a History, e.g. in a database.
+type Storage interface {
+	// Get returns the record of a version.
+	Get(version int) (Record, error)
+	// Put stores the record of a version, replacing the record it may already have.
+	Put(version int, record Record) error
+}
+
+// MemoryStorage is a Storage which keeps the records in memory.  The zero value is an empty storage ready to use.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	records map[int]Record
+}
+
+// Get returns the record of a version.
+func (s *MemoryStorage) Get(version int) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[version]
+	if !ok {
+		return Record{}, fmt.Errorf("Version %d is not stored", version)
+	}
+	return record, nil
+}
+
+// Put stores the record of a version.
+func (s *MemoryStorage) Put(version int, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records == nil {
+		s.records = map[int]Record{}
+	}
+	s.records[version] = record
+	return nil
+}
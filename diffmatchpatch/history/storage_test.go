@@ -0,0 +1,35 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package history
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	var storage MemoryStorage
+
+	_, err := storage.Get(0)
+	assert.Equal(t, errors.New("Version 0 is not stored"), err)
+
+	assert.NoError(t, storage.Put(0, Record{Snapshot: true, Data: "abc"}))
+	assert.NoError(t, storage.Put(1, Record{Data: "=3\t+d"}))
+	record, err := storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Record{Data: "=3\t+d"}, record)
+
+	// Records are replaced.
+	assert.NoError(t, storage.Put(1, Record{Snapshot: true, Data: "abcd"}))
+	record, err = storage.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Record{Snapshot: true, Data: "abcd"}, record)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+)
+
+// isHtmlTagStart returns whether the text at i starts a tag, comment or declaration rather than a literal '<'.
+func isHtmlTagStart(text string, i int) bool {
+	if text[i] != '<' || i+1 >= len(text) {
+		return false
+	}
+	c := text[i+1]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '/' || c == '!' || c == '?'
+}
+
+// htmlTagEnd returns the offset after the tag starting text, skipping '>' within quoted attribute values.  The content of comments, scripts and style sheets is part of their tag.
+func htmlTagEnd(text string) int {
+	if strings.HasPrefix(text, "<!--") {
+		if end := strings.Index(text[4:], "-->"); end != -1 {
+			return 4 + end + 3
+		}
+		return len(text)
+	}
+	var quote byte
+	end := len(text)
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+		} else if c == '"' || c == '\'' {
+			quote = c
+		} else if c == '>' {
+			end = i + 1
+			break
+		}
+	}
+	lower := strings.ToLower(text[:end])
+	for _, name := range []string{"script", "style"} {
+		if strings.HasPrefix(lower, "<"+name) && (len(lower) == len(name)+1 || !isWordRune(rune(lower[len(name)+1]))) {
+			// Raw text elements end at their closing tag.
+			closing := strings.Index(strings.ToLower(text[end:]), "</"+name)
+			if closing == -1 {
+				return len(text)
+			}
+			return end + closing + htmlTagEnd(text[end+closing:])
+		}
+	}
+	return end
+}
+
+// htmlEntityEnd returns the length of the character reference, like "&amp;", starting text, or 0 if there is none.
+func htmlEntityEnd(text string) int {
+	i := 1
+	if i < len(text) && text[i] == '#' {
+		i++
+	}
+	start := i
+	for i < len(text) && (text[i] >= 'a' && text[i] <= 'z' || text[i] >= 'A' && text[i] <= 'Z' || text[i] >= '0' && text[i] <= '9') {
+		i++
+	}
+	if i == start || i == len(text) || text[i] != ';' {
+		return 0
+	}
+	return i + 1
+}
+
+// htmlTokens splits HTML into tags and words of text, as split by ScriptSegmenter.  Character references are single words, so that they are never split by a change.
+func htmlTokens(text string) []string {
+	var tokens []string
+	for len(text) != 0 {
+		var token string
+		if isHtmlTagStart(text, 0) {
+			token = text[:htmlTagEnd(text)]
+		} else if n := htmlEntityEnd(text); text[0] == '&' && n != 0 {
+			token = text[:n]
+		} else {
+			// Words end before the next tag or character reference.
+			end := 1
+			for end < len(text) && text[end] != '&' && !isHtmlTagStart(text, end) {
+				end++
+			}
+			token = nextScriptWord(text[:end])
+		}
+		tokens = append(tokens, token)
+		text = text[len(token):]
+	}
+	return tokens
+}
+
+// isHtmlTag returns whether a token of htmlTokens is a tag rather than text.
+func isHtmlTag(token string) bool {
+	return isHtmlTagStart(token, 0)
+}
+
+// DiffHtml compares two HTML documents word by word and returns the second one with the deleted text in <del> elements and the inserted text in <ins> elements.
+// Tags are compared like words, but only the tags of the second document are kept and the <ins> and <del> elements only enclose text, so the markup stays as balanced as the second document.
+func (dmp *DiffMatchPatch) DiffHtml(html1, html2 string) string {
+	var tokenArray []string
+	tokenHash := map[string]int{}
+	toIndexes := func(text string) []int {
+		tokens := htmlTokens(text)
+		indexes := make([]int, len(tokens))
+		for i, token := range tokens {
+			index, ok := tokenHash[token]
+			if !ok {
+				index = len(tokenArray)
+				tokenArray = append(tokenArray, token)
+				tokenHash[token] = index
+			}
+			indexes[i] = index
+		}
+		return indexes
+	}
+	indexes1 := toIndexes(html1)
+	indexes2 := toIndexes(html2)
+
+	var out strings.Builder
+	var text strings.Builder
+	// flush writes the text gathered since the last tag.  White space alone is not marked, so that no elements appear where only tags are allowed.
+	flush := func(op Operation) {
+		switch {
+		case text.Len() == 0:
+		case op == DiffEqual:
+			out.WriteString(text.String())
+		case strings.TrimSpace(text.String()) == "":
+			if op == DiffInsert {
+				out.WriteString(text.String())
+			}
+		case op == DiffInsert:
+			out.WriteString("<ins>" + text.String() + "</ins>")
+		case op == DiffDelete:
+			out.WriteString("<del>" + text.String() + "</del>")
+		}
+		text.Reset()
+	}
+	for _, aDiff := range dmp.DiffMainIndexes(indexes1, indexes2) {
+		for _, index := range aDiff.Indexes {
+			token := tokenArray[index]
+			if !isHtmlTag(token) {
+				text.WriteString(token)
+				continue
+			}
+			flush(aDiff.Type)
+			// Deleted tags are dropped.
+			if aDiff.Type != DiffDelete {
+				out.WriteString(token)
+			}
+		}
+		flush(aDiff.Type)
+	}
+	return out.String()
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlTokens(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", nil},
+		{"Text", "Hello world", []string{"Hello", " ", "world"}},
+		{"Tags", "<p class=\"x\">Hi</p>", []string{"<p class=\"x\">", "Hi", "</p>"}},
+		{"Quoted angle bracket", "<a title=\"a>b\">x</a>", []string{"<a title=\"a>b\">", "x", "</a>"}},
+		{"Entities", "Fish &amp; chips&#33;", []string{"Fish", " ", "&amp;", " ", "chips", "&#33;"}},
+		{"Literal characters", "a < b & c", []string{"a", " ", "<", " ", "b", " ", "&", " ", "c"}},
+		{"Comment", "x<!-- a <b> -->y", []string{"x", "<!-- a <b> -->", "y"}},
+		{"Script", "<script>if (a < b) {}</script><b>x</b>", []string{"<script>if (a < b) {}</script>", "<b>", "x", "</b>"}},
+		{"Style", "<STYLE type=\"text/css\">p > b {}</STYLE>", []string{"<STYLE type=\"text/css\">p > b {}</STYLE>"}},
+		{"Unterminated tag", "x<p", []string{"x", "<p"}},
+	} {
+		actual := htmlTokens(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffHtml(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Html1 string
+		Html2 string
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Equal", "<p>Hello world</p>", "<p>Hello world</p>", "<p>Hello world</p>"},
+		{"Changed word", "<p>Hello world</p>", "<p>Hello there</p>", "<p>Hello <del>world</del><ins>there</ins></p>"},
+		{"Change across tags", "<p>a <b>bold</b> claim</p>", "<p>a claim</p>", "<p>a <del>bold</del>claim</p>"},
+		{"Added markup", "<p>a bold claim</p>", "<p>a <b>bold</b> claim</p>", "<p>a <b>bold</b> claim</p>"},
+		{"Changed attribute", "<a href=\"x\">link</a>", "<a href=\"y\">link</a>", "<a href=\"y\">link</a>"},
+		{"Added paragraph", "<p>One</p>\n<p>Three</p>", "<p>One</p>\n<p>Two</p>\n<p>Three</p>", "<p>One</p>\n<p><ins>Two</ins></p>\n<p>Three</p>"},
+		{"Deleted list item", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>", "<ul>\n<li>a</li>\n</ul>", "<ul>\n<li>a</li>\n<del>b</del></ul>"},
+		{"Entity", "<p>Fish &amp; chips</p>", "<p>Fish &lt; chips</p>", "<p>Fish <del>&amp;</del><ins>&lt;</ins> chips</p>"},
+		{"Script", "<script>var a = 1;</script><p>x</p>", "<script>var a = 2;</script><p>x</p>", "<script>var a = 2;</script><p>x</p>"},
+	} {
+		actual := dmp.DiffHtml(tc.Html1, tc.Html2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Range is a half-open interval [Start, End) of byte offsets into a text.
+type Range struct {
+	Start int
+	End   int
+}
+
+// DiffIncremental updates a diff after an edit of its destination text.
+// prevDiffs transforms some text1 into oldText, and newText is oldText with the bytes in editedRange replaced. The result transforms text1 into newText; only the part of prevDiffs overlapping the edited range is recomputed, everything else is reused.
+// If prevDiffs does not produce oldText, or newText does not differ from oldText only inside editedRange, the diff is computed from scratch.
+func (dmp *DiffMatchPatch) DiffIncremental(prevDiffs []Diff, oldText, newText string, editedRange Range) []Diff {
+	start, end := editedRange.Start, editedRange.End
+	if start < 0 || start > end || end > len(oldText) ||
+		!strings.HasPrefix(newText, oldText[:start]) || !strings.HasSuffix(newText[start:], oldText[end:]) ||
+		dmp.DiffText2(prevDiffs) != oldText {
+		return dmp.DiffMain(dmp.DiffText1(prevDiffs), newText, true)
+	}
+	// Widen the range to whole runes.
+	start = runeStart(oldText, start)
+	for end < len(oldText) && !utf8.RuneStart(oldText[end]) {
+		end++
+	}
+
+	const (
+		prefix = iota
+		middle
+		suffix
+	)
+	var parts [3][]Diff
+	// The parts are consecutive, once a diff is assigned to a part the following ones can't go to an earlier part.
+	phase := prefix
+	add := func(part int, aDiff Diff) {
+		if len(aDiff.Text) == 0 {
+			return
+		}
+		phase = max(phase, part)
+		parts[phase] = append(parts[phase], aDiff)
+	}
+
+	pos := 0 // Offset into oldText.
+	for _, aDiff := range prevDiffs {
+		switch aDiff.Type {
+		case DiffDelete:
+			if pos < start {
+				add(prefix, aDiff)
+			} else if pos > end {
+				add(suffix, aDiff)
+			} else {
+				add(middle, aDiff)
+			}
+		case DiffInsert:
+			next := pos + len(aDiff.Text)
+			if next <= start {
+				add(prefix, aDiff)
+			} else if pos >= end {
+				add(suffix, aDiff)
+			} else {
+				add(middle, aDiff)
+			}
+			pos = next
+		case DiffEqual:
+			// Split the equality at the edges of the edited range.
+			text := aDiff.Text
+			if cut := min(max(start-pos, 0), len(text)); cut > 0 {
+				add(prefix, Diff{DiffEqual, text[:cut]})
+				text = text[cut:]
+				pos += cut
+			}
+			if cut := min(max(end-pos, 0), len(text)); cut > 0 {
+				add(middle, Diff{DiffEqual, text[:cut]})
+				text = text[cut:]
+				pos += cut
+			}
+			add(suffix, Diff{DiffEqual, text})
+			pos += len(text)
+		}
+	}
+
+	// The middle part covers oldText[middleStart:middleEnd], which became newText[middleStart:middleEnd+len(newText)-len(oldText)].
+	middleStart := len(dmp.DiffText2(parts[prefix]))
+	middleEnd := len(oldText) - len(dmp.DiffText2(parts[suffix]))
+	middleText := newText[middleStart : middleEnd+len(newText)-len(oldText)]
+
+	diffs := append([]Diff{}, parts[prefix]...)
+	diffs = append(diffs, dmp.withoutPostProcess().DiffMain(dmp.DiffText1(parts[middle]), middleText, true)...)
+	diffs = append(diffs, parts[suffix]...)
+	return dmp.postProcessDiffs(dmp.DiffCleanupMerge(diffs))
+}
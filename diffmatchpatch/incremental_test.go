@@ -0,0 +1,51 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffIncremental(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		OldText string
+		NewText string
+		Range   Range
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Append", "The quick brown fox.", "The quick red fox.", "The quick red fox. Jumps!", Range{18, 18}},
+		{"Insert inside equality", "The quick brown fox.", "The quick red fox.", "The very quick red fox.", Range{4, 4}},
+		{"Replace inside insertion", "The quick brown fox.", "The quick red fox.", "The quick rad fox.", Range{11, 12}},
+		{"Delete across edits", "The quick brown fox.", "The slow red fox.", "The fox.", Range{4, 13}},
+		{"Undo edit", "The quick brown fox.", "The quick red fox.", "The quick brown fox.", Range{10, 13}},
+		{"Empty old text", "abc", "", "xyz", Range{0, 0}},
+		{"Multi-byte", "日本語のテキスト", "日本のテキスト", "日本のテキストです", Range{21, 21}},
+		{"Inconsistent range", "The quick brown fox.", "The quick red fox.", "A slow red fox!", Range{0, 3}},
+		{"Invalid range", "abc", "abd", "abx", Range{3, 1}},
+	} {
+		prevDiffs := dmp.DiffMain(tc.Text1, tc.OldText, false)
+		actual := dmp.DiffIncremental(prevDiffs, tc.OldText, tc.NewText, tc.Range)
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.NewText, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Unchanged parts of the previous diff are reused as they are.
+	prevDiffs := []Diff{{DiffDelete, "a"}, {DiffEqual, "bcd"}, {DiffInsert, "e"}, {DiffEqual, "fgh"}}
+	actual := dmp.DiffIncremental(prevDiffs, "bcdefgh", "bcdefghi", Range{7, 7})
+	assert.Equal(t, []Diff{{DiffDelete, "a"}, {DiffEqual, "bcd"}, {DiffInsert, "e"}, {DiffEqual, "fgh"}, {DiffInsert, "i"}}, actual)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+)
+
+// IndexDiff represents one diff operation on sequences of indexes, e.g. of lines.
+type IndexDiff struct {
+	Type    Operation
+	Indexes []int
+}
+
+// DiffLinesToIndexes splits two texts into lines and reduces the texts to the indexes of their lines in the returned array of distinct lines.
+// Unlike DiffLinesToRunes it works for any number of distinct lines.
+func (dmp *DiffMatchPatch) DiffLinesToIndexes(text1, text2 string) ([]int, []int, []string) {
+	ids1, ids2, lineArray := diffLinesToIDs(text1, text2)
+	return runesToInts(ids1), runesToInts(ids2), lineArray
+}
+
+// DiffMainIndexes finds the differences between two sequences of indexes, e.g. the results of DiffLinesToIndexes.
+func (dmp *DiffMatchPatch) DiffMainIndexes(indexes1, indexes2 []int) []IndexDiff {
+	// Number the distinct indexes densely, so that they can be diffed like runes.
+	var values []int
+	ids := map[int]rune{}
+	toIDs := func(indexes []int) []rune {
+		runes := make([]rune, len(indexes))
+		for i, index := range indexes {
+			id, ok := ids[index]
+			if !ok {
+				id = rune(len(values))
+				values = append(values, index)
+				ids[index] = id
+			}
+			runes[i] = id
+		}
+		return runes
+	}
+	runes1 := toIDs(indexes1)
+	runes2 := toIDs(indexes2)
+
+	runeDiffs := dmp.diffRunes(runes1, runes2, false)
+	if runeDiffs == nil {
+		return nil
+	}
+	diffs := make([]IndexDiff, len(runeDiffs))
+	for i, aDiff := range runeDiffs {
+		indexes := make([]int, len(aDiff.Text))
+		for j, id := range aDiff.Text {
+			indexes[j] = values[id]
+		}
+		diffs[i] = IndexDiff{aDiff.Type, indexes}
+	}
+	return diffs
+}
+
+// DiffIndexesToLines rehydrates a diff of indexes to the lines of text they refer to.
+func (dmp *DiffMatchPatch) DiffIndexesToLines(diffs []IndexDiff, lineArray []string) []Diff {
+	hydrated := make([]Diff, 0, len(diffs))
+	var text strings.Builder
+	for _, aDiff := range diffs {
+		text.Reset()
+		for _, index := range aDiff.Indexes {
+			text.WriteString(lineArray[index])
+		}
+		hydrated = append(hydrated, Diff{aDiff.Type, text.String()})
+	}
+	return hydrated
+}
+
+func runesToInts(runes []rune) []int {
+	ints := make([]int, len(runes))
+	for i, r := range runes {
+		ints[i] = int(r)
+	}
+	return ints
+}
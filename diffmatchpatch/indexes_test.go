@@ -0,0 +1,101 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLinesToIndexes(t *testing.T) {
+	type TestCase struct {
+		Text1 string
+		Text2 string
+
+		ExpectedIndexes1 []int
+		ExpectedIndexes2 []int
+		ExpectedLines    []string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"", "alpha\r\nbeta\r\n\r\n\r\n", []int{}, []int{0, 1, 2, 2}, []string{"alpha\r\n", "beta\r\n", "\r\n"}},
+		{"a", "b", []int{0}, []int{1}, []string{"a", "b"}},
+		// Omit final newline.
+		{"alpha\nbeta\nalpha", "", []int{0, 1, 2}, []int{}, []string{"alpha\n", "beta\n", "alpha"}},
+		// Same lines in Text1 and Text2
+		{"abc\ndefg\n12345\n", "abc\ndef\n12345\n678", []int{0, 1, 2}, []int{0, 3, 2, 4}, []string{"abc\n", "defg\n", "12345\n", "def\n", "678"}},
+	} {
+		actualIndexes1, actualIndexes2, actualLines := dmp.DiffLinesToIndexes(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.ExpectedIndexes1, actualIndexes1, fmt.Sprintf("Test case #%d, %#v", i, tc))
+		assert.Equal(t, tc.ExpectedIndexes2, actualIndexes2, fmt.Sprintf("Test case #%d, %#v", i, tc))
+		assert.Equal(t, tc.ExpectedLines, actualLines, fmt.Sprintf("Test case #%d, %#v", i, tc))
+	}
+}
+
+func TestDiffMainIndexes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Indexes1 []int
+		Indexes2 []int
+
+		Expected []IndexDiff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, nil, nil},
+		{"Equal", []int{1, 2, 3}, []int{1, 2, 3}, []IndexDiff{{DiffEqual, []int{1, 2, 3}}}},
+		{"Insertion", []int{1, 2, 3}, []int{1, 2, 4, 3}, []IndexDiff{{DiffEqual, []int{1, 2}}, {DiffInsert, []int{4}}, {DiffEqual, []int{3}}}},
+		{"Deletion", []int{1, 2, 3}, []int{3}, []IndexDiff{{DiffDelete, []int{1, 2}}, {DiffEqual, []int{3}}}},
+		{"Large and negative indexes", []int{-1, 1 << 40, 7}, []int{1 << 40, 7, -1}, []IndexDiff{{DiffDelete, []int{-1}}, {DiffEqual, []int{1 << 40, 7}}, {DiffInsert, []int{-1}}}},
+	} {
+		actual := dmp.DiffMainIndexes(tc.Indexes1, tc.Indexes2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffIndexesToLines(t *testing.T) {
+	dmp := New()
+
+	actual := dmp.DiffIndexesToLines([]IndexDiff{
+		{DiffEqual, []int{0, 1, 0}},
+		{DiffInsert, []int{1, 0, 1}},
+	}, []string{"alpha\n", "beta\n"})
+	assert.Equal(t, []Diff{
+		{DiffEqual, "alpha\nbeta\nalpha\n"},
+		{DiffInsert, "beta\nalpha\nbeta\n"},
+	}, actual)
+
+	// More distinct lines than DiffLinesToRunes can represent safely.
+	n := 200000
+	var lineList []string
+	for x := 0; x < n; x++ {
+		lineList = append(lineList, strconv.Itoa(x)+"\n")
+	}
+	text1 := strings.Join(lineList, "")
+	text2 := strings.Join(lineList[:n/2], "") + "changed\n" + strings.Join(lineList[n/2+1:], "")
+
+	indexes1, indexes2, lineArray := dmp.DiffLinesToIndexes(text1, text2)
+	assert.Equal(t, n+1, len(lineArray))
+	diffs := dmp.DiffIndexesToLines(dmp.DiffMainIndexes(indexes1, indexes2), lineArray)
+	assert.Equal(t, []Diff{
+		{DiffEqual, strings.Join(lineList[:n/2], "")},
+		{DiffDelete, lineList[n/2]},
+		{DiffInsert, "changed\n"},
+		{DiffEqual, strings.Join(lineList[n/2+1:], "")},
+	}, diffs)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+)
+
+// JSONPatchOpTextDelta is a custom JSON Patch operation which edits the string at its path.  Its value is a delta as produced by DiffToDelta.
+const JSONPatchOpTextDelta = "x-text-delta"
+
+// JSONPatchOperation is a JSON Patch (RFC 6902) operation on a string value of a JSON document.
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// DiffToJSONPatch converts a diff of the string at the JSON pointer path into JSON Patch operations.
+// The diff is carried by a single JSONPatchOpTextDelta operation, which only consumers aware of this extension can apply, see DiffToJSONPatchReplace for a standard alternative.  No operations are returned if the diff does not change the text.
+func (dmp *DiffMatchPatch) DiffToJSONPatch(path string, diffs []Diff) []JSONPatchOperation {
+	if !diffChanges(diffs) {
+		return []JSONPatchOperation{}
+	}
+	return []JSONPatchOperation{{Op: JSONPatchOpTextDelta, Path: path, Value: dmp.DiffToDelta(diffs)}}
+}
+
+// DiffToJSONPatchReplace converts a diff of the string at the JSON pointer path into standard JSON Patch operations: a "test" of the old string followed by a "replace" with the new string.  No operations are returned if the diff does not change the text.
+func (dmp *DiffMatchPatch) DiffToJSONPatchReplace(path string, diffs []Diff) []JSONPatchOperation {
+	if !diffChanges(diffs) {
+		return []JSONPatchOperation{}
+	}
+	return []JSONPatchOperation{
+		{Op: "test", Path: path, Value: dmp.DiffText1(diffs)},
+		{Op: "replace", Path: path, Value: dmp.DiffText2(diffs)},
+	}
+}
+
+// JSONPatchApplyText applies a JSON Patch operation produced by DiffToJSONPatch or DiffToJSONPatchReplace to the string text found at its path.
+func (dmp *DiffMatchPatch) JSONPatchApplyText(op JSONPatchOperation, text string) (_ string, err error) {
+	defer dmp.recoverInternal(&err)
+	switch op.Op {
+	case "test":
+		if text != op.Value {
+			return text, fmt.Errorf("JSON Patch test failed at %q", op.Path)
+		}
+		return text, nil
+	case "replace":
+		return op.Value, nil
+	case JSONPatchOpTextDelta:
+		diffs, err := dmp.DiffFromDelta(text, op.Value)
+		if err != nil {
+			return text, err
+		}
+		return dmp.DiffText2(diffs), nil
+	}
+	return text, fmt.Errorf("Unsupported JSON Patch operation %q on a string", op.Op)
+}
+
+// diffChanges reports whether diffs contain any insertions or deletions.
+func diffChanges(diffs []Diff) bool {
+	for _, aDiff := range diffs {
+		if aDiff.Type != DiffEqual && len(aDiff.Text) != 0 {
+			return true
+		}
+	}
+	return false
+}
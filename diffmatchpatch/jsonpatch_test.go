@@ -0,0 +1,82 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToJSONPatch(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected        string
+		ExpectedReplace string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No change", []Diff{{DiffEqual, "abc"}}, `[]`, `[]`},
+		{"Edit", []Diff{{DiffEqual, "jump"}, {DiffDelete, "s"}, {DiffInsert, "ed"}}, `[{"op":"x-text-delta","path":"/a/b","value":"=4\t-1\t+ed"}]`, `[{"op":"test","path":"/a/b","value":"jumps"},{"op":"replace","path":"/a/b","value":"jumped"}]`},
+	} {
+		actual, err := json.Marshal(dmp.DiffToJSONPatch("/a/b", tc.Diffs))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.Expected, string(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, err = json.Marshal(dmp.DiffToJSONPatchReplace("/a/b", tc.Diffs))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.ExpectedReplace, string(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestJSONPatchApplyText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Op   JSONPatchOperation
+		Text string
+
+		Expected      string
+		ExpectedError bool
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Test", JSONPatchOperation{"test", "/a", "jumps"}, "jumps", "jumps", false},
+		{"Failed test", JSONPatchOperation{"test", "/a", "jumps"}, "jumped", "jumped", true},
+		{"Replace", JSONPatchOperation{"replace", "/a", "jumped"}, "jumps", "jumped", false},
+		{"Delta", JSONPatchOperation{JSONPatchOpTextDelta, "/a", "=4\t-1\t+ed"}, "jumps", "jumped", false},
+		{"Delta on a different text", JSONPatchOperation{JSONPatchOpTextDelta, "/a", "=4\t-1\t+ed"}, "jump", "jump", true},
+		{"Unsupported operation", JSONPatchOperation{"move", "/a", ""}, "jumps", "jumps", true},
+	} {
+		actual, err := dmp.JSONPatchApplyText(tc.Op, tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedError {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// Operations round-trip.
+	text1, text2 := speedtestTexts()
+	for _, op := range dmp.DiffToJSONPatch("/text", dmp.DiffMain(text1, text2, false)) {
+		actual, err := dmp.JSONPatchApplyText(op, text1)
+		assert.NoError(t, err)
+		assert.Equal(t, text2, actual)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package langdiff compares source code token by token, as split by the lexer of its programming language.
+// Unlike the diffs of characters, the diffs never split an identifier, a number or a string literal.
+package langdiff
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Token is the position of a lexical token in a source text.
+type Token struct {
+	// Byte offset of the start of the token.
+	Start int
+	// Byte offset of the end of the token.
+	End int
+}
+
+// Lexer splits a source text into tokens, in the order of the text.  The text between the tokens, such as white space, is compared as tokens of its own.
+type Lexer func(text string) ([]Token, error)
+
+// Differ compares source texts token by token.
+type Differ struct {
+	// Configuration of the diffs of the token sequences.
+	DiffMatchPatch *diffmatchpatch.DiffMatchPatch
+	// Lexer of the programming language of the texts.
+	Lexer Lexer
+}
+
+// New creates a new Differ with default parameters which splits texts with lexer.
+func New(lexer Lexer) *Differ {
+	return &Differ{
+		DiffMatchPatch: diffmatchpatch.New(),
+		Lexer:          lexer,
+	}
+}
+
+// Diff compares two source texts token by token.  The boundaries of the diffs are always boundaries of tokens.
+func (d *Differ) Diff(text1, text2 string) ([]diffmatchpatch.Diff, error) {
+	var tokenArray []string
+	tokenHash := map[string]int{}
+	toIndexes := func(text string) ([]int, error) {
+		tokens, err := d.Lexer(text)
+		if err != nil {
+			return nil, err
+		}
+		var indexes []int
+		add := func(token string) {
+			index, ok := tokenHash[token]
+			if !ok {
+				index = len(tokenArray)
+				tokenArray = append(tokenArray, token)
+				tokenHash[token] = index
+			}
+			indexes = append(indexes, index)
+		}
+		pos := 0
+		for i, t := range tokens {
+			if t.Start < pos || t.End <= t.Start || t.End > len(text) {
+				return nil, fmt.Errorf("Token %d at [%d, %d) is out of order or out of range", i, t.Start, t.End)
+			}
+			if pos < t.Start {
+				add(text[pos:t.Start])
+			}
+			add(text[t.Start:t.End])
+			pos = t.End
+		}
+		if pos < len(text) {
+			add(text[pos:])
+		}
+		return indexes, nil
+	}
+
+	indexes1, err := toIndexes(text1)
+	if err != nil {
+		return nil, err
+	}
+	indexes2, err := toIndexes(text2)
+	if err != nil {
+		return nil, err
+	}
+	dmp := d.DiffMatchPatch
+	return dmp.DiffIndexesToLines(dmp.DiffMainIndexes(indexes1, indexes2), tokenArray), nil
+}
+
+// GoLexer splits Go source code into tokens, comments included.
+func GoLexer(text string) ([]Token, error) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(text))
+	var s scanner.Scanner
+	var scanErr error
+	s.Init(file, []byte(text), func(pos token.Position, msg string) {
+		if scanErr == nil {
+			scanErr = fmt.Errorf("%d:%d: %s", pos.Line, pos.Column, msg)
+		}
+	}, scanner.ScanComments)
+
+	var tokens []Token
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// Automatically inserted semicolons are white space.
+			continue
+		}
+		start := file.Offset(pos)
+		end := start + len(lit)
+		switch {
+		case len(lit) == 0:
+			end = start + len(tok.String())
+		case tok == token.COMMENT || lit[0] == '`':
+			// The scanner drops carriage returns from the literals of comments and raw strings.
+			end = literalEnd(text, start)
+		}
+		tokens = append(tokens, Token{start, end})
+	}
+	if scanErr != nil {
+		return nil, scanErr
+	}
+	return tokens, nil
+}
+
+// literalEnd returns the end of the comment or raw string literal which starts at start in Go source code.
+func literalEnd(text string, start int) int {
+	rest := text[start:]
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		if end := strings.IndexByte(rest, '\n'); end != -1 {
+			return start + len(strings.TrimSuffix(rest[:end], "\r"))
+		}
+	case strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end != -1 {
+			return start + end + 4
+		}
+	default:
+		if end := strings.IndexByte(rest[1:], '`'); end != -1 {
+			return start + end + 2
+		}
+	}
+	// The literal ends with the text, or is not terminated as the scanner reports.
+	return len(text)
+}
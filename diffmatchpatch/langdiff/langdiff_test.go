@@ -0,0 +1,126 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package langdiff
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []diffmatchpatch.Diff
+	}
+
+	d := New(GoLexer)
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", []diffmatchpatch.Diff{}},
+		{"Identical", "x := 1\n", "x := 1\n", []diffmatchpatch.Diff{{Type: diffmatchpatch.DiffEqual, Text: "x := 1\n"}}},
+		{
+			"Identifiers and strings",
+			"x := foo(1, \"hello world\") // call\n",
+			"x := food(1, \"hello word\") // call it\n",
+			[]diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "x := "},
+				{Type: diffmatchpatch.DiffDelete, Text: "foo"},
+				{Type: diffmatchpatch.DiffInsert, Text: "food"},
+				{Type: diffmatchpatch.DiffEqual, Text: "(1, "},
+				{Type: diffmatchpatch.DiffDelete, Text: "\"hello world\""},
+				{Type: diffmatchpatch.DiffInsert, Text: "\"hello word\""},
+				{Type: diffmatchpatch.DiffEqual, Text: ") "},
+				{Type: diffmatchpatch.DiffDelete, Text: "// call"},
+				{Type: diffmatchpatch.DiffInsert, Text: "// call it"},
+				{Type: diffmatchpatch.DiffEqual, Text: "\n"},
+			},
+		},
+		{
+			"Comments and white space",
+			"a /* c */ + b",
+			"a + bb",
+			[]diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "a "},
+				{Type: diffmatchpatch.DiffDelete, Text: "/* c */ "},
+				{Type: diffmatchpatch.DiffEqual, Text: "+ "},
+				{Type: diffmatchpatch.DiffDelete, Text: "b"},
+				{Type: diffmatchpatch.DiffInsert, Text: "bb"},
+			},
+		},
+		{
+			"Raw strings",
+			"s := `raw\r\nstring`\n",
+			"s := `raw\r\nstrings`\n",
+			[]diffmatchpatch.Diff{
+				{Type: diffmatchpatch.DiffEqual, Text: "s := "},
+				{Type: diffmatchpatch.DiffDelete, Text: "`raw\r\nstring`"},
+				{Type: diffmatchpatch.DiffInsert, Text: "`raw\r\nstrings`"},
+				{Type: diffmatchpatch.DiffEqual, Text: "\n"},
+			},
+		},
+	} {
+		actual, err := d.Diff(tc.Text1, tc.Text2)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffLexerErrors(t *testing.T) {
+	_, err := New(GoLexer).Diff("x := 1", "x := \"1")
+	assert.Equal(t, errors.New("1:6: string literal not terminated"), err)
+
+	overlapping := func(text string) ([]Token, error) {
+		return []Token{{0, 2}, {1, 3}}, nil
+	}
+	_, err = New(overlapping).Diff("abc", "abc")
+	assert.Equal(t, errors.New("Token 1 at [1, 3) is out of order or out of range"), err)
+
+	beyond := func(text string) ([]Token, error) {
+		return []Token{{0, len(text) + 1}}, nil
+	}
+	_, err = New(beyond).Diff("abc", "abc")
+	assert.Equal(t, errors.New("Token 0 at [0, 4) is out of order or out of range"), err)
+}
+
+func TestGoLexer(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", "", nil},
+		{"Statement", "x := a.b[1] + 2.5e3\n", []string{"x", ":=", "a", ".", "b", "[", "1", "]", "+", "2.5e3"}},
+		{"Carriage returns", "a := `x\r\ny`\r\n// c\r\nb /* x\r\n */ c", []string{"a", ":=", "`x\r\ny`", "// c", "b", "/* x\r\n */", "c"}},
+		{"Comment at the end", "a // c", []string{"a", "// c"}},
+	} {
+		tokens, err := GoLexer(tc.Text)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		var actual []string
+		for _, token := range tokens {
+			actual = append(actual, tc.Text[token.Start:token.End])
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	_, err := GoLexer("/* unterminated")
+	assert.Equal(t, errors.New("1:1: comment not terminated"), err)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+)
+
+// lcsDiffs computes a diff of two texts with the fewest inserted and deleted runes, whose equalities make up a longest common subsequence of the texts.
+func lcsDiffs(text1, text2 string) []runeDiff {
+	dmp := New()
+	dmp.DiffExactMinimal = true
+	return dmp.diffRunes([]rune(text1), []rune(text2), false)
+}
+
+// LCS returns a longest common subsequence of two texts, i.e. the longest text made of runes of both texts in the same order, though not necessarily adjacent.
+// It is found by a minimal diff, so it takes time proportional to the length of the texts times the number of runes they do not have in common.
+func LCS(text1, text2 string) string {
+	var lcs strings.Builder
+	for _, aDiff := range lcsDiffs(text1, text2) {
+		if aDiff.Type == DiffEqual {
+			_, _ = lcs.WriteString(string(aDiff.Text))
+		}
+	}
+	return lcs.String()
+}
+
+// LCSLength returns the length in runes of the longest common subsequence of two texts, as returned by LCS.
+func LCSLength(text1, text2 string) int {
+	n := 0
+	for _, aDiff := range lcsDiffs(text1, text2) {
+		if aDiff.Type == DiffEqual {
+			n += len(aDiff.Text)
+		}
+	}
+	return n
+}
+
+// suffixState is a state of a suffix automaton, which stands for the substrings ending at the same set of positions.
+type suffixState struct {
+	// Length of the longest substring of the state.
+	length int
+	// State of the longest suffix of the substrings which ends at other positions too, or -1 for the initial state.
+	link int
+	next map[rune]int
+}
+
+// LongestCommonSubstring returns the longest text which both texts contain, the first one in text2 if there are several, or "" if they have no rune in common.
+// It builds a suffix automaton of the first text, so it takes time and memory linear in the length of the texts.
+func LongestCommonSubstring(text1, text2 string) string {
+	runes1, runes2 := []rune(text1), []rune(text2)
+	states := make([]suffixState, 1, 2*len(runes1)+1)
+	states[0] = suffixState{link: -1, next: map[rune]int{}}
+	last := 0
+	for _, r := range runes1 {
+		cur := len(states)
+		states = append(states, suffixState{length: states[last].length + 1, next: map[rune]int{}})
+		p := last
+		for ; p != -1; p = states[p].link {
+			if _, ok := states[p].next[r]; ok {
+				break
+			}
+			states[p].next[r] = cur
+		}
+		if p == -1 {
+			states[cur].link = 0
+		} else if q := states[p].next[r]; states[p].length+1 == states[q].length {
+			states[cur].link = q
+		} else {
+			// Split q so that the state of the suffix has the right length.
+			clone := len(states)
+			next := make(map[rune]int, len(states[q].next))
+			for k, v := range states[q].next {
+				next[k] = v
+			}
+			states = append(states, suffixState{length: states[p].length + 1, link: states[q].link, next: next})
+			for ; p != -1 && states[p].next[r] == q; p = states[p].link {
+				states[p].next[r] = clone
+			}
+			states[q].link = clone
+			states[cur].link = clone
+		}
+		last = cur
+	}
+
+	// Walk the second text through the automaton, following suffix links on mismatches.
+	state, length := 0, 0
+	bestLength, bestEnd := 0, 0
+	for i, r := range runes2 {
+		for state != 0 {
+			if _, ok := states[state].next[r]; ok {
+				break
+			}
+			state = states[state].link
+			length = states[state].length
+		}
+		if next, ok := states[state].next[r]; ok {
+			state = next
+			length++
+		}
+		if length > bestLength {
+			bestLength, bestEnd = length, i+1
+		}
+	}
+	return string(runes2[bestEnd-bestLength : bestEnd])
+}
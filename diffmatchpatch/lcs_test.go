@@ -0,0 +1,114 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLCS(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty texts", "", "", ""},
+		{"Empty text", "abc", "", ""},
+		{"Equal texts", "abc", "abc", "abc"},
+		{"Nothing in common", "abc", "xyz", ""},
+		{"Subsequence", "ABCBDAB", "BDCABA", "BCBA"},
+		{"Unicode", "日本語のテキスト", "日本のテスト", "日本のテスト"},
+	} {
+		assert.Equal(t, tc.Expected, LCS(tc.Text1, tc.Text2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, len([]rune(tc.Expected)), LCSLength(tc.Text1, tc.Text2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Compare the lengths with dynamic programming on random texts.
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		text1, text2 := randomText(rng, 30, "abc"), randomText(rng, 30, "abc")
+		lengths := make([][]int, len(text1)+1)
+		for x := range lengths {
+			lengths[x] = make([]int, len(text2)+1)
+			for y := range lengths[x] {
+				if x == 0 || y == 0 {
+					continue
+				} else if text1[x-1] == text2[y-1] {
+					lengths[x][y] = lengths[x-1][y-1] + 1
+				} else {
+					lengths[x][y] = max(lengths[x-1][y], lengths[x][y-1])
+				}
+			}
+		}
+		assert.Equal(t, lengths[len(text1)][len(text2)], LCSLength(text1, text2), fmt.Sprintf("%q %q", text1, text2))
+	}
+}
+
+func TestLongestCommonSubstring(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty texts", "", "", ""},
+		{"Empty text", "abc", "", ""},
+		{"Equal texts", "abc", "abc", "abc"},
+		{"Nothing in common", "abc", "xyz", ""},
+		{"Substring", "xabcdy", "zzabcdzz", "abcd"},
+		{"First of several", "abxcd", "cdab", "cd"},
+		{"Repetitions", "abababab", "babab", "babab"},
+		{"Unicode", "日本語のテキスト", "テキストの日本", "テキスト"},
+	} {
+		assert.Equal(t, tc.Expected, LongestCommonSubstring(tc.Text1, tc.Text2), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Compare the lengths with dynamic programming on random texts.
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		text1, text2 := randomText(rng, 30, "abc"), randomText(rng, 30, "abc")
+		best := 0
+		for x := range text1 {
+			for y := range text2 {
+				n := 0
+				for x+n < len(text1) && y+n < len(text2) && text1[x+n] == text2[y+n] {
+					n++
+				}
+				best = max(best, n)
+			}
+		}
+		actual := LongestCommonSubstring(text1, text2)
+		assert.Equal(t, best, len(actual), fmt.Sprintf("%q %q", text1, text2))
+		assert.Contains(t, text1, actual)
+		assert.Contains(t, text2, actual)
+	}
+}
+
+// randomText returns a text of n runes drawn from alphabet.
+func randomText(rng *rand.Rand, n int, alphabet string) string {
+	runes := []rune(alphabet)
+	text := make([]rune, n)
+	for i := range text {
+		text[i] = runes[rng.Intn(len(runes))]
+	}
+	return string(text)
+}
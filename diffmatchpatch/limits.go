@@ -0,0 +1,190 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// TextTooLongError is returned by the checked diff functions when a text is longer than MaxTextLength.
+type TextTooLongError struct {
+	// Length of the text in runes.
+	Length int
+	// The limit which was exceeded.
+	Max int
+}
+
+func (e *TextTooLongError) Error() string {
+	return fmt.Sprintf("Text of %d runes exceeds the maximum length of %d runes", e.Length, e.Max)
+}
+
+// TooManyOperationsError is returned by the checked diff functions when a diff needs more than MaxDiffOperations insertions and deletions.
+type TooManyOperationsError struct {
+	// The limit which was exceeded.
+	Max int
+}
+
+func (e *TooManyOperationsError) Error() string {
+	return fmt.Sprintf("Diff exceeds the maximum of %d operations", e.Max)
+}
+
+// DiffMainChecked is DiffMain bounded by MaxTextLength and MaxDiffOperations, which also rejects texts which are not valid UTF-8 if StrictUTF8 is set.
+func (dmp *DiffMatchPatch) DiffMainChecked(text1, text2 string, checklines bool) ([]Diff, error) {
+	if dmp.StrictUTF8 {
+		if err := checkUTF8("text1", text1); err != nil {
+			return nil, err
+		}
+		if err := checkUTF8("text2", text2); err != nil {
+			return nil, err
+		}
+	}
+	// Check the lengths before converting the texts.
+	if err := dmp.checkTextLength(utf8.RuneCountInString(text1)); err != nil {
+		return nil, err
+	}
+	if err := dmp.checkTextLength(utf8.RuneCountInString(text2)); err != nil {
+		return nil, err
+	}
+	return dmp.DiffMainRunesChecked([]rune(text1), []rune(text2), checklines)
+}
+
+// DiffMainRunesChecked is DiffMainRunes bounded by MaxTextLength and MaxDiffOperations, which also rejects runes which cannot be encoded in UTF-8 if StrictUTF8 is set.
+// The computation stops as soon as the limit of operations is exceeded.
+func (dmp *DiffMatchPatch) DiffMainRunesChecked(text1, text2 []rune, checklines bool) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	if dmp.StrictUTF8 {
+		if err := checkRunes("text1", text1); err != nil {
+			return nil, err
+		}
+		if err := checkRunes("text2", text2); err != nil {
+			return nil, err
+		}
+	}
+	if err := dmp.checkTextLength(len(text1)); err != nil {
+		return nil, err
+	}
+	if err := dmp.checkTextLength(len(text2)); err != nil {
+		return nil, err
+	}
+	if dmp.MaxDiffOperations <= 0 {
+		return dmp.DiffMainRunes(text1, text2, checklines), nil
+	}
+
+	checked := *dmp
+	remaining := int64(dmp.MaxDiffOperations)
+	checked.operations = &remaining
+	diffs := checked.DiffMainRunes(text1, text2, checklines)
+	if atomic.LoadInt64(&remaining) < 0 {
+		return nil, &TooManyOperationsError{Max: dmp.MaxDiffOperations}
+	}
+	return diffs, nil
+}
+
+func (dmp *DiffMatchPatch) checkTextLength(length int) error {
+	if dmp.MaxTextLength > 0 && length > dmp.MaxTextLength {
+		return &TextTooLongError{Length: length, Max: dmp.MaxTextLength}
+	}
+	return nil
+}
+
+// operationsExhausted returns whether a checked diff computation has exceeded its limit of operations.
+func (dmp *DiffMatchPatch) operationsExhausted() bool {
+	return dmp.operations != nil && atomic.LoadInt64(dmp.operations) < 0
+}
+
+// countOperations charges the insertions and deletions of a final part of a diff to the limit of operations and to MaxEditDistance, and reports the part as progress.
+func (dmp *DiffMatchPatch) countOperations(diffs []runeDiff) []runeDiff {
+	dmp.reportProgress(diffLength(diffs))
+	if dmp.operations == nil && dmp.editDistance == nil {
+		return diffs
+	}
+	n, distance := 0, 0
+	for _, aDiff := range diffs {
+		if aDiff.Type != DiffEqual && len(aDiff.Text) != 0 {
+			n++
+			distance += len(aDiff.Text)
+		}
+	}
+	dmp.chargeOperations(n)
+	dmp.chargeEditDistance(distance)
+	return diffs
+}
+
+// chargeEditDistance charges n inserted or deleted runes to MaxEditDistance.
+func (dmp *DiffMatchPatch) chargeEditDistance(n int) {
+	if dmp.editDistance != nil {
+		atomic.AddInt64(dmp.editDistance, -int64(n))
+	}
+}
+
+// chargeOperations charges n insertions or deletions to the limit of operations.
+func (dmp *DiffMatchPatch) chargeOperations(n int) {
+	if dmp.operations != nil {
+		atomic.AddInt64(dmp.operations, -int64(n))
+	}
+}
+
+// diffRunesBounded diffs two rune sequences unless they are further apart than MaxEditDistance.
+func (dmp *DiffMatchPatch) diffRunesBounded(text1, text2 []rune, checklines bool) []runeDiff {
+	if abs(len(text1)-len(text2)) <= dmp.MaxEditDistance {
+		bounded := *dmp
+		remaining := int64(dmp.MaxEditDistance)
+		bounded.editDistance = &remaining
+		diffs := bounded.diffRunes(text1, text2, checklines)
+		if atomic.LoadInt64(&remaining) >= 0 {
+			return diffs
+		}
+	}
+
+	// The texts are too different.
+	var diffs []runeDiff
+	if len(text1) != 0 {
+		diffs = append(diffs, runeDiff{DiffDelete, text1})
+	}
+	if len(text2) != 0 {
+		diffs = append(diffs, runeDiff{DiffInsert, text2})
+	}
+	return diffs
+}
+
+// editDistanceExhausted returns whether a diff computation has exceeded MaxEditDistance.
+func (dmp *DiffMatchPatch) editDistanceExhausted() bool {
+	return dmp.editDistance != nil && atomic.LoadInt64(dmp.editDistance) < 0
+}
+
+// bisectMemory estimates the bytes of the V-arrays diffBisect allocates for two texts of the given lengths.
+func bisectMemory(len1, len2 int) int64 {
+	return 2 * int64(len1+len2+3) * strconv.IntSize / 8
+}
+
+// exceedsMemory returns whether bisecting two texts would exceed MaxMemoryBytes. Parallel diffs may bisect on every worker at once.
+func (dmp *DiffMatchPatch) exceedsMemory(text1, text2 []rune) bool {
+	if dmp.MaxMemoryBytes <= 0 {
+		return false
+	}
+	estimate := bisectMemory(len(text1), len(text2))
+	if dmp.DiffParallelism > 1 {
+		estimate *= int64(dmp.DiffParallelism)
+	}
+	return estimate > int64(dmp.MaxMemoryBytes)
+}
+
+// diffMemoryLimited diffs two texts too long to bisect within MaxMemoryBytes, line by line if checklines is set and the texts have several lines, and else by deleting the first text and inserting the second one.
+// The replaced lines of the line mode are rediffed without checklines, so that they are bisected if they fit and replaced whole otherwise.
+func (dmp *DiffMatchPatch) diffMemoryLimited(text1, text2 []rune, checklines bool, deadline time.Time) []runeDiff {
+	dmp.markMemoryLimited()
+	if checklines && (runesIndex(text1, []rune{'\n'}) != -1 || runesIndex(text2, []rune{'\n'}) != -1) {
+		return dmp.diffLineMode(text1, text2, deadline)
+	}
+	return dmp.countOperations([]runeDiff{{DiffDelete, text1}, {DiffInsert, text2}})
+}
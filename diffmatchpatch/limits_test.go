@@ -0,0 +1,165 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainChecked(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1             string
+		Text2             string
+		MaxTextLength     int
+		MaxDiffOperations int
+
+		ExpectedError error
+	}
+
+	lines1 := strings.Repeat("1234567890\n", 20)
+	lines2 := strings.Repeat("abcdefghij\n1234567890\n", 10)
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+
+	for i, tc := range []TestCase{
+		{"Unlimited", "abc", "ab123c", 0, 0, nil},
+		{"Length within limit", "日本語", "日本", 3, 0, nil},
+		{"First text too long", "日本語", "日本", 2, 0, &TextTooLongError{Length: 3, Max: 2}},
+		{"Second text too long", "ab", "abcd", 3, 0, &TextTooLongError{Length: 4, Max: 3}},
+		{"Operations within limit", "abc", "ab123c", 0, 1, nil},
+		{"Replacement within limit", "The quick brown fox", "The slow green turtle", 0, 20, nil},
+		{"Too many operations", "The quick brown fox", "The slow green turtle", 0, 2, &TooManyOperationsError{Max: 2}},
+		{"Identical texts", "abc", "abc", 0, 1, nil},
+		{"Lines within limit", lines1, lines2, 0, 40, nil},
+		{"Too many lines", lines1, lines2, 0, 5, &TooManyOperationsError{Max: 5}},
+	} {
+		dmp.MaxTextLength = tc.MaxTextLength
+		dmp.MaxDiffOperations = tc.MaxDiffOperations
+
+		actual, err := dmp.DiffMainChecked(tc.Text1, tc.Text2, true)
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedError != nil {
+			assert.Nil(t, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			continue
+		}
+		assert.Equal(t, dmp.DiffMain(tc.Text1, tc.Text2, true), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.MaxDiffOperations > 0 {
+			operations := 0
+			for _, aDiff := range actual {
+				if aDiff.Type != DiffEqual {
+					operations++
+				}
+			}
+			assert.True(t, operations <= tc.MaxDiffOperations, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffMainCheckedGivesUpEarly(t *testing.T) {
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	dmp.MaxDiffOperations = 10
+
+	start := time.Now()
+	_, err := dmp.DiffMainChecked(s1, s2, false)
+	assert.Equal(t, &TooManyOperationsError{Max: 10}, err)
+
+	dmp.MaxDiffOperations = 0
+	unlimited := time.Now()
+	dmp.DiffMain(s1, s2, false)
+	assert.True(t, time.Since(unlimited) > unlimited.Sub(start))
+}
+
+func TestDiffMaxEditDistance(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1           string
+		Text2           string
+		MaxEditDistance int
+
+		Expected []Diff
+	}
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+
+	for i, tc := range []TestCase{
+		{"Unlimited", "abcd", "xbcy", 0, []Diff{{DiffDelete, "a"}, {DiffInsert, "x"}, {DiffEqual, "bc"}, {DiffDelete, "d"}, {DiffInsert, "y"}}},
+		{"Within the distance", "abcd", "xbcy", 4, []Diff{{DiffDelete, "a"}, {DiffInsert, "x"}, {DiffEqual, "bc"}, {DiffDelete, "d"}, {DiffInsert, "y"}}},
+		{"Beyond the distance", "abcd", "xbcy", 3, []Diff{{DiffDelete, "abcd"}, {DiffInsert, "xbcy"}}},
+		{"Lengths too different", "a", "abcdef", 4, []Diff{{DiffDelete, "a"}, {DiffInsert, "abcdef"}}},
+		{"Equal texts", "abc", "abc", 1, []Diff{{DiffEqual, "abc"}}},
+		{"Insertion", "", "ab", 2, []Diff{{DiffInsert, "ab"}}},
+	} {
+		dmp.MaxEditDistance = tc.MaxEditDistance
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Distant texts are given up on early.
+	s1, s2 := speedtestTexts()
+	dmp.MaxEditDistance = 100
+	start := time.Now()
+	diffs := dmp.DiffMain(s1, s2, false)
+	bounded := time.Since(start)
+	assert.Equal(t, []Diff{{DiffDelete, s1}, {DiffInsert, s2}}, diffs)
+
+	dmp.MaxEditDistance = 0
+	start = time.Now()
+	dmp.DiffMain(s1, s2, false)
+	assert.True(t, time.Since(start) > bounded)
+}
+
+func TestDiffMaxMemoryBytes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1          string
+		Text2          string
+		Checklines     bool
+		MaxMemoryBytes int
+
+		Expected              []Diff
+		ExpectedMemoryLimited bool
+	}
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+
+	for i, tc := range []TestCase{
+		{"Unlimited", "abcd", "xbcy", false, 0, []Diff{{DiffDelete, "a"}, {DiffInsert, "x"}, {DiffEqual, "bc"}, {DiffDelete, "d"}, {DiffInsert, "y"}}, false},
+		{"Within the limit", "abcd", "xbcy", false, 1000, []Diff{{DiffDelete, "a"}, {DiffInsert, "x"}, {DiffEqual, "bc"}, {DiffDelete, "d"}, {DiffInsert, "y"}}, false},
+		{"Beyond the limit", "abcd", "xbcy", false, 100, []Diff{{DiffDelete, "abcd"}, {DiffInsert, "xbcy"}}, true},
+		{"No bisection needed", "abc", "ab123c", false, 1, []Diff{{DiffEqual, "ab"}, {DiffInsert, "123"}, {DiffEqual, "c"}}, false},
+		{"Line mode", "one\ntwo\nthree\nfour\nsix\n", "uno\ntwo\nthree\nfour\nseis\n", true, 250, []Diff{{DiffDelete, "o"}, {DiffInsert, "u"}, {DiffEqual, "n"}, {DiffDelete, "e"}, {DiffInsert, "o"}, {DiffEqual, "\ntwo\nthree\nfour\ns"}, {DiffInsert, "e"}, {DiffEqual, "i"}, {DiffDelete, "x"}, {DiffInsert, "s"}, {DiffEqual, "\n"}}, true},
+		{"Line mode without lines", "abcd", "xbcy", true, 100, []Diff{{DiffDelete, "abcd"}, {DiffInsert, "xbcy"}}, true},
+	} {
+		dmp.MaxMemoryBytes = tc.MaxMemoryBytes
+		actual, flags := dmp.DiffMainWithFlags(tc.Text1, tc.Text2, tc.Checklines)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, DiffFlags{MemoryLimited: tc.ExpectedMemoryLimited}, flags, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Coarsened diffs are also reported by the metrics.
+	dmp.MaxMemoryBytes = 100
+	dmp.DiffMetrics = &Metrics{}
+	dmp.DiffMain("abcd", "xbcy", false)
+	assert.True(t, dmp.DiffMetrics.MemoryLimited)
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinePatch is a hunk of a line-oriented patch, like the hunks of diff -u, whose context and positions are whole lines.
+// Unlike Patch, both start positions refer to the texts before and after all patches, as in the unified format.
+type LinePatch struct {
+	// Lines of the hunk, one line per diff, including its line break.
+	Diffs []Diff
+	// Index of the first line of the hunk in the first and second text, counting from 0.
+	Start1 int
+	Start2 int
+	// Number of lines of the hunk in the first and second text.
+	Length1 int
+	Length2 int
+}
+
+// String emulates GNU diff's format.
+// Header: @@ -382,8 +481,9 @@
+// Lines are not escaped, a line without a line break is followed by a "\ No newline at end of file" line.
+func (p *LinePatch) String() string {
+	var text bytes.Buffer
+	_, _ = text.WriteString("@@ -" + lineRange(p.Start1, p.Length1) + " +" + lineRange(p.Start2, p.Length2) + " @@\n")
+	for _, aDiff := range p.Diffs {
+		switch aDiff.Type {
+		case DiffInsert:
+			_, _ = text.WriteString("+")
+		case DiffDelete:
+			_, _ = text.WriteString("-")
+		case DiffEqual:
+			_, _ = text.WriteString(" ")
+		}
+		_, _ = text.WriteString(aDiff.Text)
+		if !strings.HasSuffix(aDiff.Text, "\n") {
+			_, _ = text.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	return text.String()
+}
+
+// lineRange formats the start and the number of lines of a hunk like diff -u.
+func lineRange(start, length int) string {
+	switch length {
+	case 0:
+		// An empty range starts at the line before it.
+		return strconv.Itoa(start) + ",0"
+	case 1:
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(length)
+}
+
+// LinePatchMake computes the hunks of a line diff, with the given number of context lines around every change.  Changes separated by no more than twice the context share a hunk, like with diff -u.
+func (dmp *DiffMatchPatch) LinePatchMake(lineDiffs []LineDiff, context int) []LinePatch {
+	patches := []LinePatch{}
+	if context < 0 {
+		context = 0
+	}
+	for i := 0; i < len(lineDiffs); {
+		if lineDiffs[i].Type == DiffEqual {
+			i++
+			continue
+		}
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(lineDiffs) && j <= end+2*context+1; j++ {
+			if lineDiffs[j].Type != DiffEqual {
+				end = j
+			}
+		}
+		i = end + 1
+		end = min(len(lineDiffs), end+context+1)
+		patches = append(patches, linePatch(lineDiffs, start, end))
+	}
+	return patches
+}
+
+// linePatch makes the hunk of the lines from start to end of a line diff.
+func linePatch(lineDiffs []LineDiff, start, end int) LinePatch {
+	var patch LinePatch
+	// Lines before the hunk give its start in both texts.
+	for _, lineDiff := range lineDiffs[:start] {
+		if lineDiff.Type != DiffInsert {
+			patch.Start1++
+		}
+		if lineDiff.Type != DiffDelete {
+			patch.Start2++
+		}
+	}
+	for _, lineDiff := range lineDiffs[start:end] {
+		patch.Diffs = append(patch.Diffs, Diff{lineDiff.Type, lineDiff.Text})
+		if lineDiff.Type != DiffInsert {
+			patch.Length1++
+		}
+		if lineDiff.Type != DiffDelete {
+			patch.Length2++
+		}
+	}
+	return patch
+}
+
+// LinePatchToText takes a list of line patches and returns a textual representation in the unified format.
+func (dmp *DiffMatchPatch) LinePatchToText(patches []LinePatch) string {
+	var text bytes.Buffer
+	for _, aPatch := range patches {
+		_, _ = text.WriteString(aPatch.String())
+	}
+	return text.String()
+}
+
+// linePatchHeader matches the header of a hunk, which may be followed by the section the hunk is in.
+var linePatchHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// LinePatchFromText parses the hunks of a textual representation in the unified format, e.g. of diff -u.  The "---" and "+++" lines naming the files before the first hunk are skipped.
+func (dmp *DiffMatchPatch) LinePatchFromText(text string) (_ []LinePatch, err error) {
+	defer dmp.recoverInternal(&err)
+	patches := []LinePatch{}
+	lines := splitLines(text)
+	i := 0
+	for i < len(lines) && (strings.HasPrefix(lines[i], "--- ") || strings.HasPrefix(lines[i], "+++ ")) {
+		i++
+	}
+	for i < len(lines) {
+		header := strings.TrimSuffix(lines[i], "\n")
+		m := linePatchHeader.FindStringSubmatch(header)
+		if m == nil {
+			return patches, errors.New("Invalid hunk header: " + header)
+		}
+		var patch LinePatch
+		patch.Start1, patch.Length1 = parseLineRange(m[1], m[2])
+		patch.Start2, patch.Length2 = parseLineRange(m[3], m[4])
+		i++
+
+		count1, count2 := 0, 0
+		for count1 < patch.Length1 || count2 < patch.Length2 || i < len(lines) && strings.HasPrefix(lines[i], "\\") {
+			if i == len(lines) {
+				return patches, errors.New("Truncated hunk: " + header)
+			}
+			line := lines[i]
+			i++
+			if line == "\n" {
+				// A context line which lost its leading space.
+				line = " \n"
+			}
+			var op Operation
+			switch line[0] {
+			case ' ':
+				op = DiffEqual
+			case '-':
+				op = DiffDelete
+			case '+':
+				op = DiffInsert
+			case '\\':
+				// The previous line has no line break.
+				if n := len(patch.Diffs); n != 0 {
+					patch.Diffs[n-1].Text = strings.TrimSuffix(patch.Diffs[n-1].Text, "\n")
+				}
+				continue
+			default:
+				return patches, errors.New("Invalid hunk line: " + strings.TrimSuffix(line, "\n"))
+			}
+			if op != DiffInsert {
+				count1++
+			}
+			if op != DiffDelete {
+				count2++
+			}
+			if count1 > patch.Length1 || count2 > patch.Length2 {
+				return patches, errors.New("Hunk has more lines than its header: " + header)
+			}
+			patch.Diffs = append(patch.Diffs, Diff{op, line[1:]})
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// parseLineRange parses the start and the number of lines of a hunk header.
+func parseLineRange(start, length string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	if len(length) == 0 {
+		return s - 1, 1
+	}
+	l, _ := strconv.Atoi(length)
+	if l == 0 {
+		// An empty range starts at the line before it.
+		return s, 0
+	}
+	return s - 1, l
+}
+
+// LinePatchApply applies line patches to the text like GNU patch.  Every hunk is applied where its lines match exactly nearest to where it is expected, ignoring up to LinePatchFuzz context lines at each end of the hunk if it does not match otherwise.
+// Returns the patched text, as well as an array of true/false values indicating which patches were applied.
+func (dmp *DiffMatchPatch) LinePatchApply(patches []LinePatch, text string) (string, []bool) {
+	lines := splitLines(text)
+	results := make([]bool, len(patches))
+	// delta is the number of lines the applied hunks added, offset how many lines away from where it was expected the last hunk applied.
+	delta, offset := 0, 0
+	for i, aPatch := range patches {
+		var before, after []string
+		for _, aDiff := range aPatch.Diffs {
+			if aDiff.Type != DiffInsert {
+				before = append(before, aDiff.Text)
+			}
+			if aDiff.Type != DiffDelete {
+				after = append(after, aDiff.Text)
+			}
+		}
+		leading, trailing := 0, 0
+		for leading < len(aPatch.Diffs) && aPatch.Diffs[leading].Type == DiffEqual {
+			leading++
+		}
+		for trailing < len(aPatch.Diffs)-leading && aPatch.Diffs[len(aPatch.Diffs)-1-trailing].Type == DiffEqual {
+			trailing++
+		}
+
+		for fuzz := 0; fuzz <= dmp.LinePatchFuzz && (fuzz == 0 || fuzz <= leading || fuzz <= trailing); fuzz++ {
+			// Ignore context lines at both ends of the hunk.
+			skip1, skip2 := min(fuzz, leading), min(fuzz, trailing)
+			pattern := before[skip1 : len(before)-skip2]
+			expected := aPatch.Start1 + delta + offset + skip1
+			loc := findLines(lines, pattern, expected)
+			if loc == -1 {
+				continue
+			}
+			replacement := after[skip1 : len(after)-skip2]
+			patched := make([]string, 0, len(lines)-len(pattern)+len(replacement))
+			patched = append(patched, lines[:loc]...)
+			patched = append(patched, replacement...)
+			lines = append(patched, lines[loc+len(pattern):]...)
+
+			offset = loc - skip1 - aPatch.Start1 - delta
+			delta += len(after) - len(before)
+			results[i] = true
+			break
+		}
+	}
+	return strings.Join(lines, ""), results
+}
+
+// findLines returns the index of the occurrence of pattern in lines nearest to expected, or -1.
+func findLines(lines, pattern []string, expected int) int {
+	last := len(lines) - len(pattern)
+	for distance := 0; expected-distance >= 0 || expected+distance <= last; distance++ {
+		for _, loc := range []int{expected + distance, expected - distance} {
+			if loc >= 0 && loc <= last && linesEqual(lines[loc:loc+len(pattern)], pattern) {
+				return loc
+			}
+		}
+	}
+	return -1
+}
+
+// linesEqual returns whether two lists of lines are equal.
+func linesEqual(lines1, lines2 []string) bool {
+	for i := range lines1 {
+		if lines1[i] != lines2[i] {
+			return false
+		}
+	}
+	return true
+}
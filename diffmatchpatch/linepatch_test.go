@@ -0,0 +1,117 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinePatchMakeAndLinePatchToText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Context int
+
+		Expected string
+	}
+
+	dmp := New()
+
+	lines := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	edits := "a\nB\nc\nd\ne\nf\ng\nh\nj\nk"
+
+	for i, tc := range []TestCase{
+		{"Equal", lines, lines, 3, ""},
+		{"Separate hunks", lines, edits, 1, "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n@@ -8,3 +8,3 @@\n h\n-i\n j\n+k\n\\ No newline at end of file\n"},
+		{"Merged hunks", lines, edits, 3, "@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n j\n+k\n\\ No newline at end of file\n"},
+		{"No context", "a\nb\nc\n", "a\nc\nd\n", 0, "@@ -2 +1,0 @@\n-b\n@@ -3,0 +3 @@\n+d\n"},
+		{"Negative context", "a\nb\nc\n", "a\nc\n", -1, "@@ -2 +1,0 @@\n-b\n"},
+		{"Missing line break", "a\nb", "a\nb\n", 1, "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n"},
+	} {
+		patches := dmp.LinePatchMake(dmp.DiffLines(tc.Text1, tc.Text2), tc.Context)
+		actual := dmp.LinePatchToText(patches)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		parsed, err := dmp.LinePatchFromText(actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, patches, parsed, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestLinePatchFromText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected      []LinePatch
+		ExpectedError error
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", "", []LinePatch{}, nil},
+		{"File headers and section", "--- old.go\n+++ new.go\n@@ -3,2 +3,2 @@ func main() {\n x\n-y\n+z\n", []LinePatch{
+			{Diffs: []Diff{{DiffEqual, "x\n"}, {DiffDelete, "y\n"}, {DiffInsert, "z\n"}}, Start1: 2, Start2: 2, Length1: 2, Length2: 2},
+		}, nil},
+		{"Blank context line", "@@ -1,3 +1,2 @@\n a\n\n-b\n", []LinePatch{
+			{Diffs: []Diff{{DiffEqual, "a\n"}, {DiffEqual, "\n"}, {DiffDelete, "b\n"}}, Start1: 0, Start2: 0, Length1: 3, Length2: 2},
+		}, nil},
+		{"Invalid header", "@@ -1 @@\n", []LinePatch{}, errors.New("Invalid hunk header: @@ -1 @@")},
+		{"Invalid line", "@@ -1 +1 @@\n*a\n", []LinePatch{}, errors.New("Invalid hunk line: *a")},
+		{"Truncated", "@@ -1,2 +1,2 @@\n a\n", []LinePatch{}, errors.New("Truncated hunk: @@ -1,2 +1,2 @@")},
+		{"Too many lines", "@@ -1 +1 @@\n-a\n-b\n+c\n", []LinePatch{}, errors.New("Hunk has more lines than its header: @@ -1 +1 @@")},
+	} {
+		actual, err := dmp.LinePatchFromText(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestLinePatchApply(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+		Text  string
+		Fuzz  int
+
+		Expected        string
+		ExpectedResults []bool
+	}
+
+	lines := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	edits := "a\nB\nc\nd\ne\nf\ng\nh\nj\nk\n"
+
+	for i, tc := range []TestCase{
+		{"Exact", lines, edits, lines, 0, edits, []bool{true, true}},
+		{"Offset", lines, edits, "0\n1\n" + lines, 0, "0\n1\n" + edits, []bool{true, true}},
+		{"Offset carried over", lines, edits, "0\n" + lines[:8] + "1\n2\n" + lines[8:], 0, "0\n" + edits[:8] + "1\n2\n" + edits[8:], []bool{true, true}},
+		{"Changed context without fuzz", lines, edits, "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", 0, "A\nb\nc\nd\ne\nf\ng\nh\nj\nk\n", []bool{false, true}},
+		{"Changed context with fuzz", lines, edits, "A\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", 1, "A\nB\nc\nd\ne\nf\ng\nh\nj\nk\n", []bool{true, true}},
+		{"Changed deletion", lines, edits, "a\nb\nc\nd\ne\nf\ng\nh\nI\nj\n", 2, "a\nB\nc\nd\ne\nf\ng\nh\nI\nj\n", []bool{true, false}},
+		{"Missing line break", "a\nb", "a\nb\nc", "x\na\nb", 0, "x\na\nb\nc", []bool{true}},
+		{"Empty text", "", "a\n", "", 0, "a\n", []bool{true}},
+	} {
+		dmp := New()
+		dmp.LinePatchFuzz = tc.Fuzz
+		patches := dmp.LinePatchMake(dmp.DiffLines(tc.Text1, tc.Text2), 1)
+		actual, results := dmp.LinePatchApply(patches, tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedResults, results, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+)
+
+// LineDiff is one line of a line by line diff.
+type LineDiff struct {
+	Type Operation
+	// The line, including its line break.
+	Text string
+	// Number of the line in the first text, counting from 1, or 0 for an inserted line.
+	Line1 int
+	// Number of the line in the second text, counting from 1, or 0 for a deleted line.
+	Line2 int
+	// With DiffLinesIntraline, the character diff from a deleted line to the inserted line which replaces it. Both lines share the diff.
+	Changes []Diff
+}
+
+// DiffLines finds the differences between two texts line by line.
+func (dmp *DiffMatchPatch) DiffLines(text1, text2 string) []LineDiff {
+	if dmp.IgnoreBOM {
+		text1, _ = StripBOM(text1)
+		text2, _ = StripBOM(text2)
+	}
+	diffs := dmp.diffLines(text1, text2, dmp.diffDeadline())
+	// Semantic cleanup may shift edits within lines, which the line cleanup undoes.
+	diffs = dmp.DiffCleanupLines(dmp.DiffCleanupSemantic(diffs))
+
+	var lineDiffs []LineDiff
+	line1, line2 := 1, 1
+	for _, aDiff := range diffs {
+		for _, line := range splitLines(aDiff.Text) {
+			lineDiff := LineDiff{Type: aDiff.Type, Text: line}
+			if aDiff.Type != DiffInsert {
+				lineDiff.Line1 = line1
+				line1++
+			}
+			if aDiff.Type != DiffDelete {
+				lineDiff.Line2 = line2
+				line2++
+			}
+			lineDiffs = append(lineDiffs, lineDiff)
+		}
+	}
+	if dmp.DiffLinesIntraline {
+		dmp.diffLinesIntraline(lineDiffs)
+	}
+	return lineDiffs
+}
+
+// diffLinesIntraline pairs the deleted lines with the inserted lines which follow them, and diffs every pair character by character.
+func (dmp *DiffMatchPatch) diffLinesIntraline(lineDiffs []LineDiff) {
+	for i := 0; i < len(lineDiffs); {
+		deletes := i
+		for deletes < len(lineDiffs) && lineDiffs[deletes].Type == DiffDelete {
+			deletes++
+		}
+		inserts := deletes
+		for inserts < len(lineDiffs) && lineDiffs[inserts].Type == DiffInsert {
+			inserts++
+		}
+		for k := 0; k < deletes-i && k < inserts-deletes; k++ {
+			deleted, inserted := &lineDiffs[i+k], &lineDiffs[deletes+k]
+			changes := dmp.DiffCleanupSemantic(dmp.withoutPostProcess().DiffMain(deleted.Text, inserted.Text, false))
+			deleted.Changes = changes
+			inserted.Changes = changes
+		}
+		if inserts == i {
+			inserts++
+		}
+		i = inserts
+	}
+}
+
+// splitLines splits a text after every line break.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// DiffCleanupLines coarsens a diff so that every operation consists of whole lines.
+// Lines which are changed in part are diffed again line by line.
+func (dmp *DiffMatchPatch) DiffCleanupLines(diffs []Diff) []Diff {
+	diffs = repairRuneBoundaries(diffs)
+	var cleaned []Diff
+	emit := func(op Operation, text string) {
+		if len(text) == 0 {
+			return
+		}
+		if n := len(cleaned); n != 0 && cleaned[n-1].Type == op {
+			cleaned[n-1].Text += text
+			return
+		}
+		cleaned = append(cleaned, Diff{op, text})
+	}
+
+	// The lines between equal lines, in both texts. They are diffed again line by line.
+	var pending1, pending2 strings.Builder
+	deadline := dmp.diffDeadline()
+	flush := func() {
+		for _, aDiff := range dmp.diffLines(pending1.String(), pending2.String(), deadline) {
+			emit(aDiff.Type, aDiff.Text)
+		}
+		pending1.Reset()
+		pending2.Reset()
+	}
+
+	// Whether the texts are at the start of a line.
+	lineStart1, lineStart2 := true, true
+	for i, aDiff := range diffs {
+		if len(aDiff.Text) == 0 {
+			continue
+		}
+		endsLine := aDiff.Text[len(aDiff.Text)-1] == '\n'
+		switch aDiff.Type {
+		case DiffDelete:
+			pending1.WriteString(aDiff.Text)
+			lineStart1 = endsLine
+		case DiffInsert:
+			pending2.WriteString(aDiff.Text)
+			lineStart2 = endsLine
+		case DiffEqual:
+			// Find the whole lines of the equality.
+			start := 0
+			if !lineStart1 || !lineStart2 {
+				start = strings.IndexByte(aDiff.Text, '\n') + 1
+			}
+			end := strings.LastIndexByte(aDiff.Text, '\n') + 1
+			if i == len(diffs)-1 {
+				// The last line of the texts does not need to end with a line break.
+				end = len(aDiff.Text)
+			}
+			if start == 0 && !(lineStart1 && lineStart2) || start >= end {
+				pending1.WriteString(aDiff.Text)
+				pending2.WriteString(aDiff.Text)
+			} else {
+				pending1.WriteString(aDiff.Text[:start])
+				pending2.WriteString(aDiff.Text[:start])
+				flush()
+				emit(DiffEqual, aDiff.Text[start:end])
+				pending1.WriteString(aDiff.Text[end:])
+				pending2.WriteString(aDiff.Text[end:])
+			}
+			lineStart1, lineStart2 = endsLine, endsLine
+		}
+	}
+	flush()
+
+	return dmp.DiffCanonicalize(cleaned)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCleanupLines(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, nil},
+		{"Whole lines", []Diff{{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "c\n"}}, []Diff{{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "c\n"}}},
+		{"Changed characters", []Diff{{DiffEqual, "a\n"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "\nc\n"}}, []Diff{{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "x\n"}, {DiffEqual, "c\n"}}},
+		{"Insertion across a line break", []Diff{{DiffEqual, "a\nb"}, {DiffInsert, "\nb"}, {DiffEqual, "\n"}}, []Diff{{DiffEqual, "a\nb\n"}, {DiffInsert, "b\n"}}},
+		{"Several lines in one", []Diff{{DiffEqual, "one\ntw"}, {DiffDelete, "o\nthr"}, {DiffEqual, "ee\nfour\n"}}, []Diff{{DiffEqual, "one\n"}, {DiffDelete, "two\nthree\n"}, {DiffInsert, "twee\n"}, {DiffEqual, "four\n"}}},
+		{"Last line without line break", []Diff{{DiffEqual, "a\nb"}, {DiffInsert, "c"}}, []Diff{{DiffEqual, "a\n"}, {DiffDelete, "b"}, {DiffInsert, "bc"}}},
+		{"Equal last line without line break", []Diff{{DiffInsert, "x\n"}, {DiffEqual, "a"}}, []Diff{{DiffInsert, "x\n"}, {DiffEqual, "a"}}},
+	} {
+		actual := dmp.DiffCleanupLines(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText1(tc.Diffs), dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.DiffText2(tc.Diffs), dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Every operation of a cleaned up character diff consists of whole lines.
+	text1 := "The quick\nbrown fox\njumps over\nthe lazy\ndog.\n"
+	text2 := "The quick\nred fox\njumps\nover the lazy\ndog.\n"
+	for _, aDiff := range dmp.DiffCleanupLines(dmp.DiffMain(text1, text2, false)) {
+		assert.True(t, strings.HasSuffix(aDiff.Text, "\n"), fmt.Sprintf("%q", aDiff.Text))
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []LineDiff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty", "", "", nil},
+		{"Equal", "a\nb", "a\nb", []LineDiff{{DiffEqual, "a\n", 1, 1, nil}, {DiffEqual, "b", 2, 2, nil}}},
+		{
+			"Changed line",
+			"a\nb\nc\n", "a\nx\nc\n",
+			[]LineDiff{{DiffEqual, "a\n", 1, 1, nil}, {DiffDelete, "b\n", 2, 0, nil}, {DiffInsert, "x\n", 0, 2, nil}, {DiffEqual, "c\n", 3, 3, nil}},
+		},
+		{
+			"Inserted and deleted lines",
+			"one\ntwo\nthree\n", "zero\none\nthree\nfour",
+			[]LineDiff{{DiffInsert, "zero\n", 0, 1, nil}, {DiffEqual, "one\n", 1, 2, nil}, {DiffDelete, "two\n", 2, 0, nil}, {DiffEqual, "three\n", 3, 3, nil}, {DiffInsert, "four", 0, 4, nil}},
+		},
+		{
+			"Blank lines",
+			"a\n\nb\n", "a\nb\n\n",
+			[]LineDiff{{DiffEqual, "a\n", 1, 1, nil}, {DiffDelete, "\n", 2, 0, nil}, {DiffEqual, "b\n", 3, 2, nil}, {DiffInsert, "\n", 0, 3, nil}},
+		},
+	} {
+		actual := dmp.DiffLines(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffLinesIgnoreBOM(t *testing.T) {
+	dmp := New()
+	text1, text2 := "\uFEFFa\nb\n", "a\nc\n"
+
+	assert.Equal(t, []LineDiff{{DiffDelete, "\uFEFFa\n", 1, 0, nil}, {DiffDelete, "b\n", 2, 0, nil}, {DiffInsert, "a\n", 0, 1, nil}, {DiffInsert, "c\n", 0, 2, nil}}, dmp.DiffLines(text1, text2))
+
+	dmp.IgnoreBOM = true
+	assert.Equal(t, []LineDiff{{DiffEqual, "a\n", 1, 1, nil}, {DiffDelete, "b\n", 2, 0, nil}, {DiffInsert, "c\n", 0, 2, nil}}, dmp.DiffLines(text1, text2))
+}
+
+func TestDiffLinesIntraline(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []LineDiff
+	}
+
+	dmp := New()
+	dmp.DiffLinesIntraline = true
+
+	fox := []Diff{{DiffEqual, "the "}, {DiffDelete, "quick"}, {DiffInsert, "slow"}, {DiffEqual, " fox\n"}}
+	dog := []Diff{{DiffEqual, "lazy "}, {DiffDelete, "dog"}, {DiffInsert, "cat"}, {DiffEqual, "\n"}}
+
+	for i, tc := range []TestCase{
+		{
+			"Replaced line",
+			"a\nthe quick fox\nb\n", "a\nthe slow fox\nb\n",
+			[]LineDiff{{DiffEqual, "a\n", 1, 1, nil}, {DiffDelete, "the quick fox\n", 2, 0, fox}, {DiffInsert, "the slow fox\n", 0, 2, fox}, {DiffEqual, "b\n", 3, 3, nil}},
+		},
+		{
+			"More deleted than inserted lines",
+			"the quick fox\nlazy dog\nx\n", "the slow fox\nlazy cat\n",
+			[]LineDiff{{DiffDelete, "the quick fox\n", 1, 0, fox}, {DiffDelete, "lazy dog\n", 2, 0, dog}, {DiffDelete, "x\n", 3, 0, nil}, {DiffInsert, "the slow fox\n", 0, 1, fox}, {DiffInsert, "lazy cat\n", 0, 2, dog}},
+		},
+		{
+			"Unpaired lines",
+			"a\n", "b\nc\n",
+			[]LineDiff{{DiffDelete, "a\n", 1, 0, []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}, {DiffEqual, "\n"}}}, {DiffInsert, "b\n", 0, 1, []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}, {DiffEqual, "\n"}}}, {DiffInsert, "c\n", 0, 2, nil}},
+		},
+	} {
+		actual := dmp.DiffLines(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maskedRegion is a region of a text which compares equal to the regions of the other text with the same label, regardless of their content.
+type maskedRegion struct {
+	Range
+	label string
+}
+
+// DiffMainMasked finds the differences between two texts like DiffMain, but compares every masked region of a text equal to any masked region of the other text regardless of their content, e.g. to ignore timestamps or UUIDs.
+// masks1 and masks2 are the masked byte ranges of text1 and text2, widened to whole runes.  Masked regions which compare equal are emitted with the text of text1.
+func (dmp *DiffMatchPatch) DiffMainMasked(text1, text2 string, masks1, masks2 []Range, checklines bool) []Diff {
+	regions := func(masks []Range) []maskedRegion {
+		regions := make([]maskedRegion, len(masks))
+		for i, mask := range masks {
+			regions[i] = maskedRegion{Range: mask}
+		}
+		return regions
+	}
+	return dmp.diffMasked(text1, text2, regions(masks1), regions(masks2), checklines)
+}
+
+// diffMasked diffs two texts in which every masked region is a single unit, and maps the result back onto the texts.
+func (dmp *DiffMatchPatch) diffMasked(text1, text2 string, regions1, regions2 []maskedRegion, checklines bool) []Diff {
+	// Every label is compared as a rune which occurs in neither text.
+	labels := map[string]rune{}
+	for _, regions := range [][]maskedRegion{regions1, regions2} {
+		for _, region := range regions {
+			labels[region.label] = 0
+		}
+	}
+	assignUnusedRunes(labels, text1, text2)
+	units1, texts1 := maskedUnits(text1, regions1, labels)
+	units2, texts2 := maskedUnits(text2, regions2, labels)
+
+	var diffs []Diff
+	pointer1, pointer2 := 0, 0
+	for _, aDiff := range dmp.diffRunes(units1, units2, checklines) {
+		n := len(aDiff.Text)
+		switch aDiff.Type {
+		case DiffEqual:
+			diffs = append(diffs, Diff{DiffEqual, strings.Join(texts1[pointer1:pointer1+n], "")})
+			pointer1 += n
+			pointer2 += n
+		case DiffDelete:
+			diffs = append(diffs, Diff{DiffDelete, strings.Join(texts1[pointer1:pointer1+n], "")})
+			pointer1 += n
+		case DiffInsert:
+			diffs = append(diffs, Diff{DiffInsert, strings.Join(texts2[pointer2:pointer2+n], "")})
+			pointer2 += n
+		}
+	}
+	// Merging the texts could move parts of masked regions into equalities.
+	return dmp.DiffCanonicalize(diffs)
+}
+
+// assignUnusedRunes assigns to every label a distinct rune of the private use planes which occurs in none of the texts.
+// Only if the texts use up the planes, labels are assigned invalid runes, which the line mode of a diff cannot tell apart.
+func assignUnusedRunes(labels map[string]rune, texts ...string) {
+	if len(labels) == 0 {
+		return
+	}
+	used := map[rune]bool{}
+	for _, text := range texts {
+		for _, r := range text {
+			if r >= 0xF0000 {
+				used[r] = true
+			}
+		}
+	}
+	next := rune(0xF0000)
+	for label := range labels {
+		for next <= utf8.MaxRune && used[next] {
+			next++
+		}
+		labels[label] = next
+		next++
+	}
+}
+
+// maskedUnits splits a text into the units compared by diffMasked, which are its masked regions and the runes in between, and returns them with their texts.
+func maskedUnits(text string, regions []maskedRegion, labels map[string]rune) ([]rune, []string) {
+	regions = append([]maskedRegion(nil), regions...)
+	sort.SliceStable(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+
+	units := make([]rune, 0, len(text))
+	texts := make([]string, 0, len(text))
+	addRunes := func(s string) {
+		for _, r := range s {
+			units = append(units, r)
+			texts = append(texts, string(r))
+		}
+	}
+	done := 0
+	for _, region := range regions {
+		// Overlapping and adjacent regions with the same label are joined, and regions are clipped to the text.
+		start, end := max(region.Start, done), min(region.End, len(text))
+		if start >= end {
+			continue
+		}
+		start = runeStart(text, start)
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+		unit := labels[region.label]
+		if start == done && len(units) != 0 && units[len(units)-1] == unit {
+			texts[len(texts)-1] += text[start:end]
+		} else {
+			addRunes(text[done:start])
+			units = append(units, unit)
+			texts = append(texts, text[start:end])
+		}
+		done = end
+	}
+	addRunes(text[done:])
+	return units, texts
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainMasked(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1  string
+		Text2  string
+		Masks1 []Range
+		Masks2 []Range
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	log1 := "12:00:01 start\n12:00:02 load config\n12:00:03 done\n"
+	log2 := "13:45:10 start\n13:45:11 load configs\n13:45:12 done\n"
+
+	for i, tc := range []TestCase{
+		{"No masks", "abc", "abd", nil, nil, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Masked timestamps", log1, log2,
+			[]Range{{0, 8}, {15, 23}, {36, 44}},
+			[]Range{{0, 8}, {15, 23}, {37, 45}},
+			[]Diff{{DiffEqual, "12:00:01 start\n12:00:02 load config"}, {DiffInsert, "s"}, {DiffEqual, "\n12:00:03 done\n"}}},
+		{"Mask of one text only", "id=42;", "id=43;", []Range{{3, 5}}, nil, []Diff{{DiffEqual, "id="}, {DiffDelete, "42"}, {DiffInsert, "43"}, {DiffEqual, ";"}}},
+		{"Different lengths", "a[x]b", "a[xyz]c", []Range{{1, 4}}, []Range{{1, 6}}, []Diff{{DiffEqual, "a[x]"}, {DiffDelete, "b"}, {DiffInsert, "c"}}},
+		{"Unsorted overlapping masks", "x 1234 y", "x 5 z", []Range{{4, 6}, {2, 5}}, []Range{{2, 3}}, []Diff{{DiffEqual, "x 1234 "}, {DiffDelete, "y"}, {DiffInsert, "z"}}},
+		{"Masks out of the text", "ab", "ab", []Range{{-1, 1}, {5, 9}}, []Range{{-3, 1}}, []Diff{{DiffEqual, "ab"}}},
+		{"Widened to runes", "日本語", "日本人", []Range{{4, 5}}, []Range{{3, 9}}, []Diff{{DiffEqual, "日本"}, {DiffDelete, "語"}}},
+		{"Private use rune", "a\U000F0000", "aX", nil, []Range{{1, 2}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "\U000F0000"}, {DiffInsert, "X"}}},
+	} {
+		actual := dmp.DiffMainMasked(tc.Text1, tc.Text2, tc.Masks1, tc.Masks2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
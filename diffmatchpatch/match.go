@@ -10,10 +10,12 @@ package diffmatchpatch
 
 import (
 	"math"
+	"sort"
+	"strconv"
 )
 
 // MatchMain locates the best instance of 'pattern' in 'text' near 'loc'.
-// Returns -1 if no match found.
+// Returns -1 if no match found. The returned offset is a byte offset.  Fuzzy matches and null patterns never point inside a multi-byte UTF-8 sequence, other exact matches are returned where the pattern starts.
 func (dmp *DiffMatchPatch) MatchMain(text, pattern string, loc int) int {
 	// Check for null inputs not needed since null can't be passed in C#.
 
@@ -26,30 +28,201 @@ func (dmp *DiffMatchPatch) MatchMain(text, pattern string, loc int) int {
 		return -1
 	} else if loc+len(pattern) <= len(text) && text[loc:loc+len(pattern)] == pattern {
 		// Perfect match at the perfect spot!  (Includes case of null pattern)
+		if len(pattern) == 0 {
+			return runeStart(text, loc)
+		}
 		return loc
 	}
 	// Do a fuzzy compare.
 	return dmp.MatchBitap(text, pattern, loc)
 }
 
+// MatchMainScored locates the best instance of 'pattern' in 'text' near 'loc' like MatchMain, and also returns the score of the match (0.0 = perfection, 1.0 = very loose), which combines its errors and its distance from 'loc' like MatchThreshold.
+// Returns -1 and an infinite score if no match found.
+func (dmp *DiffMatchPatch) MatchMainScored(text, pattern string, loc int) (int, float64) {
+	loc = max(0, min(loc, len(text)))
+	if text == pattern {
+		// Shortcut (potentially not guaranteed by the algorithm)
+		if len(pattern) == 0 {
+			return 0, 0
+		}
+		return 0, dmp.matchBitapScore(0, 0, loc, len(pattern))
+	} else if len(text) == 0 {
+		// Nothing to match.
+		return -1, math.Inf(1)
+	} else if loc+len(pattern) <= len(text) && text[loc:loc+len(pattern)] == pattern {
+		// Perfect match at the perfect spot!  (Includes case of null pattern)
+		if len(pattern) == 0 {
+			return runeStart(text, loc), 0
+		}
+		return loc, 0
+	}
+	// Do a fuzzy compare.
+	bestLoc, score := dmp.matchBitap(bytesToRunes(text), bytesToRunes(pattern), loc)
+	if bestLoc == -1 {
+		return -1, score
+	}
+	return runeStart(text, bestLoc), score
+}
+
+// MatchCandidate is a location where the Bitap algorithm found a pattern.
+type MatchCandidate struct {
+	// Byte offset of the candidate in the text.
+	Location int
+	// Number of errors of the candidate.
+	Errors int
+	// Score of the candidate (0.0 = perfection, 1.0 = very loose), which combines its errors and its distance from the expected location.
+	Score float64
+}
+
+// MatchCandidates returns up to k locations of 'pattern' in 'text' near 'loc' whose score is within MatchThreshold, best first (k <= 0 for all of them).
+// The candidates do not overlap, the first one is the match of MatchMainScored.  Unlike MatchMain, it searches the whole text, which takes time proportional to the lengths of the text and of the pattern.
+func (dmp *DiffMatchPatch) MatchCandidates(text, pattern string, loc, k int) []MatchCandidate {
+	loc = max(0, min(loc, len(text)))
+	if len(pattern) == 0 {
+		return []MatchCandidate{{Location: runeStart(text, loc)}}
+	}
+	byLocation := dmp.matchBitapCandidates(bytesToRunes(text), bytesToRunes(pattern), loc)
+	found := make([]MatchCandidate, 0, len(byLocation))
+	for _, candidate := range byLocation {
+		found = append(found, candidate)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Score != found[j].Score {
+			return found[i].Score < found[j].Score
+		}
+		return found[i].Location < found[j].Location
+	})
+
+	candidates := []MatchCandidate{}
+	for _, candidate := range found {
+		if k > 0 && len(candidates) == k {
+			break
+		}
+		candidate.Location = runeStart(text, candidate.Location)
+		overlaps := false
+		for _, picked := range candidates {
+			if abs(picked.Location-candidate.Location) < len(pattern) {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// matchBitapCandidates runs the Bitap algorithm without narrowing the search to the best match, and returns the candidate with the fewest errors at every location whose score is within MatchThreshold.
+func (dmp *DiffMatchPatch) matchBitapCandidates(text, pattern []rune, loc int) map[int]MatchCandidate {
+	s := dmp.matchAlphabetLong(pattern)
+	words := len(newBitapVector(len(pattern)))
+	empty := newBitapVector(len(pattern))
+	shifted := newBitapVector(len(pattern))
+	matchbit := len(pattern) - 1
+	finish := len(text) + len(pattern)
+
+	candidates := map[int]MatchCandidate{}
+	var lastRd []bitapVector
+	for d := 0; d < len(pattern); d++ {
+		if dmp.matchBitapScore(d, loc, loc, len(pattern)) > dmp.MatchThreshold {
+			// No match is good enough at this error level.
+			break
+		}
+		rd := make([]bitapVector, finish+2)
+		for j := range rd {
+			rd[j] = make(bitapVector, words)
+		}
+		for b := 0; b < d; b++ {
+			rd[finish+1].set(b)
+		}
+
+		for j := finish; j >= 1; j-- {
+			charMatch := empty
+			if j-1 < len(text) {
+				if m, ok := s[text[j-1]]; ok {
+					charMatch = m
+				}
+			}
+
+			rd[j+1].shiftOr(rd[j])
+			for w := range rd[j] {
+				rd[j][w] &= charMatch[w]
+			}
+			if d != 0 {
+				for w := range shifted {
+					shifted[w] = lastRd[j+1][w] | lastRd[j][w]
+				}
+				shifted.shiftOr(shifted)
+				for w := range rd[j] {
+					rd[j][w] |= shifted[w] | lastRd[j+1][w]
+				}
+			}
+			if x := j - 1; rd[j].isSet(matchbit) && x < len(text) {
+				if _, ok := candidates[x]; !ok {
+					if score := dmp.matchBitapScore(d, x, loc, len(pattern)); score <= dmp.MatchThreshold {
+						candidates[x] = MatchCandidate{Location: x, Errors: d, Score: score}
+					}
+				}
+			}
+		}
+		lastRd = rd
+	}
+	return candidates
+}
+
+// MatchMainRunes locates the best instance of 'pattern' in 'text' near 'loc', where 'loc' and the returned index are rune offsets.
+// Returns -1 if no match found.
+func (dmp *DiffMatchPatch) MatchMainRunes(text, pattern []rune, loc int) int {
+	loc = max(0, min(loc, len(text)))
+	if runesEqual(text, pattern) {
+		// Shortcut (potentially not guaranteed by the algorithm)
+		return 0
+	} else if len(text) == 0 {
+		// Nothing to match.
+		return -1
+	} else if loc+len(pattern) <= len(text) && runesEqual(text[loc:loc+len(pattern)], pattern) {
+		// Perfect match at the perfect spot!  (Includes case of null pattern)
+		return loc
+	}
+	// Do a fuzzy compare.
+	bestLoc, _ := dmp.matchBitap(text, pattern, loc)
+	return bestLoc
+}
+
 // MatchBitap locates the best instance of 'pattern' in 'text' near 'loc' using the Bitap algorithm.
-// Returns -1 if no match was found.
+// Returns -1 if no match was found. The returned offset is a byte offset which never points inside a multi-byte UTF-8 sequence.
 func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
+	bestLoc, _ := dmp.matchBitap(bytesToRunes(text), bytesToRunes(pattern), loc)
+	if bestLoc == -1 {
+		return -1
+	}
+	return runeStart(text, bestLoc)
+}
+
+// matchBitap locates the best instance of 'pattern' in 'text' near 'loc' using the Bitap algorithm, and returns its score.
+func (dmp *DiffMatchPatch) matchBitap(text, pattern []rune, loc int) (int, float64) {
+	if len(pattern) > strconv.IntSize {
+		// The pattern does not fit into a single int, use multi-word bit vectors instead.
+		return dmp.matchBitapLong(text, pattern, loc)
+	}
+
 	// Initialise the alphabet.
-	s := dmp.MatchAlphabet(pattern)
+	s := dmp.matchAlphabet(pattern)
 
 	// Highest score beyond which we give up.
 	scoreThreshold := dmp.MatchThreshold
 	// Is there a nearby exact match? (speedup)
-	bestLoc := indexOf(text, pattern, loc)
+	bestLoc := runesIndexOf(text, pattern, loc)
 	if bestLoc != -1 {
 		scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
-			pattern), scoreThreshold)
+			len(pattern)), scoreThreshold)
 		// What about in the other direction? (speedup)
-		bestLoc = lastIndexOf(text, pattern, loc+len(pattern))
+		bestLoc = runesLastIndexOf(text, pattern, loc+len(pattern))
 		if bestLoc != -1 {
 			scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
-				pattern), scoreThreshold)
+				len(pattern)), scoreThreshold)
 		}
 	}
 
@@ -65,7 +238,7 @@ func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
 		binMin = 0
 		binMid = binMax
 		for binMin < binMid {
-			if dmp.matchBitapScore(d, loc+binMid, loc, pattern) <= scoreThreshold {
+			if dmp.matchBitapScore(d, loc+binMid, loc, len(pattern)) <= scoreThreshold {
 				binMin = binMid
 			} else {
 				binMax = binMid
@@ -99,7 +272,7 @@ func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
 				rd[j] = ((rd[j+1]<<1)|1)&charMatch | (((lastRd[j+1] | lastRd[j]) << 1) | 1) | lastRd[j+1]
 			}
 			if (rd[j] & matchmask) != 0 {
-				score := dmp.matchBitapScore(d, j-1, loc, pattern)
+				score := dmp.matchBitapScore(d, j-1, loc, len(pattern))
 				// This match will almost certainly be better than any existing match.  But check anyway.
 				if score <= scoreThreshold {
 					// Told you so.
@@ -115,18 +288,128 @@ func (dmp *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
 				}
 			}
 		}
-		if dmp.matchBitapScore(d+1, loc, loc, pattern) > scoreThreshold {
+		if dmp.matchBitapScore(d+1, loc, loc, len(pattern)) > scoreThreshold {
 			// No hope for a (better) match at greater error levels.
 			break
 		}
 		lastRd = rd
 	}
-	return bestLoc
+	if bestLoc == -1 {
+		return -1, math.Inf(1)
+	}
+	// The threshold was lowered to the score of the best match.
+	return bestLoc, scoreThreshold
+}
+
+// MatchResult describes one approximate occurrence of a pattern in a text.
+type MatchResult struct {
+	// Byte offset of the match in the text.
+	Location int
+	// Length of the matched text in bytes.
+	Length int
+	// Edit distance between the pattern and the matched text.
+	Errors int
+	// Quality of the match (0.0 = perfection, 1.0 = very loose).
+	Score float64
+}
+
+// MatchAll locates every instance of 'pattern' in 'text' which differs from 'pattern' by at most maxErrors edits.
+// Matches do not overlap; where candidates compete for the same text the one with fewer errors wins. The results are ordered by location.
+func (dmp *DiffMatchPatch) MatchAll(text, pattern string, maxErrors int) []MatchResult {
+	textRunes := []rune(text)
+	patternRunes := []rune(pattern)
+	// Allowing as many errors as the pattern is long would match the empty string everywhere.
+	maxErrors = min(maxErrors, len(patternRunes)-1)
+	if maxErrors < 0 {
+		return nil
+	}
+
+	type candidate struct {
+		start, end, errors int
+	}
+	var candidates []candidate
+
+	// Column of the edit distance matrix, where a match may begin at any position of the text. starts tracks where the alignment of each cell began.
+	m := len(patternRunes)
+	costs := make([]int, m+1)
+	starts := make([]int, m+1)
+	for i := range costs {
+		costs[i] = i
+	}
+	for j, c := range textRunes {
+		diagCost, diagStart := costs[0], j
+		costs[0], starts[0] = 0, j+1
+		for i := 1; i <= m; i++ {
+			cost, start := diagCost, diagStart
+			if patternRunes[i-1] != c {
+				cost++
+			}
+			if costs[i]+1 < cost {
+				// Extra character in the text.
+				cost, start = costs[i]+1, starts[i]
+			}
+			if costs[i-1]+1 < cost {
+				// Character missing from the text.
+				cost, start = costs[i-1]+1, starts[i-1]
+			}
+			diagCost, diagStart = costs[i], starts[i]
+			costs[i], starts[i] = cost, start
+		}
+		if costs[m] <= maxErrors {
+			candidates = append(candidates, candidate{starts[m], j + 1, costs[m]})
+		}
+	}
+
+	// Prefer the best candidates, then the leftmost and longest ones.
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].errors != candidates[j].errors {
+			return candidates[i].errors < candidates[j].errors
+		}
+		if candidates[i].start != candidates[j].start {
+			return candidates[i].start < candidates[j].start
+		}
+		return candidates[i].end > candidates[j].end
+	})
+	covered := make([]bool, len(textRunes))
+	var picked []candidate
+	for _, c := range candidates {
+		free := true
+		for k := c.start; k < c.end && free; k++ {
+			free = !covered[k]
+		}
+		if !free {
+			continue
+		}
+		for k := c.start; k < c.end; k++ {
+			covered[k] = true
+		}
+		picked = append(picked, c)
+	}
+	sort.Slice(picked, func(i, j int) bool {
+		return picked[i].start < picked[j].start
+	})
+
+	// Convert rune offsets to byte offsets.
+	offsets := make([]int, 0, len(textRunes)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+	results := make([]MatchResult, 0, len(picked))
+	for _, c := range picked {
+		results = append(results, MatchResult{
+			Location: offsets[c.start],
+			Length:   offsets[c.end] - offsets[c.start],
+			Errors:   c.errors,
+			Score:    float64(c.errors) / float64(m),
+		})
+	}
+	return results
 }
 
 // matchBitapScore computes and returns the score for a match with e errors and x location.
-func (dmp *DiffMatchPatch) matchBitapScore(e, x, loc int, pattern string) float64 {
-	accuracy := float64(e) / float64(len(pattern))
+func (dmp *DiffMatchPatch) matchBitapScore(e, x, loc int, patternLen int) float64 {
+	accuracy := float64(e) / float64(patternLen)
 	proximity := math.Abs(float64(loc - x))
 	if dmp.MatchDistance == 0 {
 		// Dodge divide by zero error.
@@ -141,6 +424,7 @@ func (dmp *DiffMatchPatch) matchBitapScore(e, x, loc int, pattern string) float6
 
 // MatchAlphabet initialises the alphabet for the Bitap algorithm.
 func (dmp *DiffMatchPatch) MatchAlphabet(pattern string) map[byte]int {
+	// Unused in this code, but retained for interface compatibility.
 	s := map[byte]int{}
 	charPattern := []byte(pattern)
 	for _, c := range charPattern {
@@ -158,3 +442,156 @@ func (dmp *DiffMatchPatch) MatchAlphabet(pattern string) map[byte]int {
 	}
 	return s
 }
+
+// matchAlphabet initialises the alphabet for the Bitap algorithm over runes.
+func (dmp *DiffMatchPatch) matchAlphabet(pattern []rune) map[rune]int {
+	s := map[rune]int{}
+	for i, c := range pattern {
+		s[c] |= int(uint(1) << uint((len(pattern) - i - 1)))
+	}
+	return s
+}
+
+// bitapVector is a bit vector of arbitrary length used by the Bitap algorithm for patterns that do not fit into an int.
+type bitapVector []uint64
+
+func newBitapVector(bits int) bitapVector {
+	return make(bitapVector, (bits+63)/64)
+}
+
+// set sets bit i.
+func (v bitapVector) set(i int) {
+	v[i/64] |= 1 << uint(i%64)
+}
+
+// isSet reports whether bit i is set.
+func (v bitapVector) isSet(i int) bool {
+	return v[i/64]&(1<<uint(i%64)) != 0
+}
+
+// shiftOr computes (v << 1) | 1 into dst.
+func (v bitapVector) shiftOr(dst bitapVector) {
+	carry := uint64(1)
+	for i, w := range v {
+		dst[i] = w<<1 | carry
+		carry = w >> 63
+	}
+}
+
+// matchAlphabetLong initialises the alphabet for the Bitap algorithm using multi-word bit vectors.
+func (dmp *DiffMatchPatch) matchAlphabetLong(pattern []rune) map[rune]bitapVector {
+	s := map[rune]bitapVector{}
+	for i, c := range pattern {
+		if _, ok := s[c]; !ok {
+			s[c] = newBitapVector(len(pattern))
+		}
+		s[c].set(len(pattern) - i - 1)
+	}
+	return s
+}
+
+// matchBitapLong is the equivalent of matchBitap for patterns of any length.
+func (dmp *DiffMatchPatch) matchBitapLong(text, pattern []rune, loc int) (int, float64) {
+	// Initialise the alphabet.
+	s := dmp.matchAlphabetLong(pattern)
+	words := len(newBitapVector(len(pattern)))
+	empty := newBitapVector(len(pattern))
+	shifted := newBitapVector(len(pattern))
+
+	// Highest score beyond which we give up.
+	scoreThreshold := dmp.MatchThreshold
+	// Is there a nearby exact match? (speedup)
+	bestLoc := runesIndexOf(text, pattern, loc)
+	if bestLoc != -1 {
+		scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
+			len(pattern)), scoreThreshold)
+		// What about in the other direction? (speedup)
+		bestLoc = runesLastIndexOf(text, pattern, loc+len(pattern))
+		if bestLoc != -1 {
+			scoreThreshold = math.Min(dmp.matchBitapScore(0, bestLoc, loc,
+				len(pattern)), scoreThreshold)
+		}
+	}
+
+	matchbit := len(pattern) - 1
+	bestLoc = -1
+
+	var binMin, binMid int
+	binMax := len(pattern) + len(text)
+	var lastRd []bitapVector
+	for d := 0; d < len(pattern); d++ {
+		// Scan for the best match; each iteration allows for one more error. Run a binary search to determine how far from 'loc' we can stray at this error level.
+		binMin = 0
+		binMid = binMax
+		for binMin < binMid {
+			if dmp.matchBitapScore(d, loc+binMid, loc, len(pattern)) <= scoreThreshold {
+				binMin = binMid
+			} else {
+				binMax = binMid
+			}
+			binMid = (binMax-binMin)/2 + binMin
+		}
+		// Use the result from this iteration as the maximum for the next.
+		binMax = binMid
+		start := max(1, loc-binMid+1)
+		finish := min(loc+binMid, len(text)) + len(pattern)
+
+		rd := make([]bitapVector, finish+2)
+		for j := range rd {
+			rd[j] = make(bitapVector, words)
+		}
+		for b := 0; b < d; b++ {
+			rd[finish+1].set(b)
+		}
+
+		for j := finish; j >= start; j-- {
+			charMatch := empty
+			if j-1 < len(text) {
+				if m, ok := s[text[j-1]]; ok {
+					charMatch = m
+				}
+			}
+
+			rd[j+1].shiftOr(rd[j])
+			for w := range rd[j] {
+				rd[j][w] &= charMatch[w]
+			}
+			if d != 0 {
+				// Subsequent passes: fuzzy match.
+				for w := range shifted {
+					shifted[w] = lastRd[j+1][w] | lastRd[j][w]
+				}
+				shifted.shiftOr(shifted)
+				for w := range rd[j] {
+					rd[j][w] |= shifted[w] | lastRd[j+1][w]
+				}
+			}
+			if rd[j].isSet(matchbit) {
+				score := dmp.matchBitapScore(d, j-1, loc, len(pattern))
+				// This match will almost certainly be better than any existing match.  But check anyway.
+				if score <= scoreThreshold {
+					// Told you so.
+					scoreThreshold = score
+					bestLoc = j - 1
+					if bestLoc > loc {
+						// When passing loc, don't exceed our current distance from loc.
+						start = max(1, 2*loc-bestLoc)
+					} else {
+						// Already passed loc, downhill from here on in.
+						break
+					}
+				}
+			}
+		}
+		if dmp.matchBitapScore(d+1, loc, loc, len(pattern)) > scoreThreshold {
+			// No hope for a (better) match at greater error levels.
+			break
+		}
+		lastRd = rd
+	}
+	if bestLoc == -1 {
+		return -1, math.Inf(1)
+	}
+	// The threshold was lowered to the score of the best match.
+	return bestLoc, scoreThreshold
+}
@@ -10,6 +10,8 @@ package diffmatchpatch
 
 import (
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -138,6 +140,34 @@ func TestMatchBitap(t *testing.T) {
 	}
 }
 
+func TestMatchBitapLong(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected int
+	}
+
+	dmp := New()
+	dmp.MatchDistance = 1000
+	dmp.MatchThreshold = 0.5
+
+	long := strings.Repeat("abcdefghijklmnopqrstuvwxyz", 4)
+
+	for i, tc := range []TestCase{
+		{"Exact match", "0123456789" + long + "0123456789", long, 10, 10},
+		{"Fuzzy match", "0123456789" + long + "0123456789", strings.Replace(long, "m", "X", -1), 0, 10},
+		{"Word boundary", "xx" + long[:65] + "yy", long[:65], 0, 2},
+		{"No match", strings.Repeat("0123456789", 20), long, 0, -1},
+	} {
+		actual := dmp.MatchBitap(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
 func TestMatchMain(t *testing.T) {
 	type TestCase struct {
 		Name string
@@ -172,3 +202,153 @@ func TestMatchMain(t *testing.T) {
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
 	}
 }
+
+func TestMatchMainScored(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected      int
+		ExpectedScore float64
+	}
+
+	dmp := New()
+	dmp.MatchThreshold = 0.7
+
+	for i, tc := range []TestCase{
+		{"Equality", "abcdef", "abcdef", 1000, 0, 0.006},
+		{"Null text", "", "abcdef", 1, -1, math.Inf(1)},
+		{"Null pattern", "abcdef", "", 3, 3, 0},
+		{"Exact match", "abcdef", "de", 3, 3, 0},
+		{"Beyond end match", "abcdef", "defy", 4, 3, 0.251},
+		{"Exact match elsewhere", "abcxyzabcxyzabc", "abc", 5, 6, 0.001},
+		{"Complex match", "I am the very model of a modern major general.", " that berry ", 5, 4, 0.3343333},
+		{"No match", "abcdef", "xyz", 0, -1, math.Inf(1)},
+		{"Multi-byte match", "日本語のテキスト", "テキス", 0, 12, 0.012},
+	} {
+		actual, actualScore := dmp.MatchMainScored(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, dmp.MatchMain(tc.Text, tc.Pattern, tc.Location), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if math.IsInf(tc.ExpectedScore, 1) {
+			assert.True(t, math.IsInf(actualScore, 1), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.InDelta(t, tc.ExpectedScore, actualScore, 1e-6, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestMatchCandidates(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+		K        int
+
+		Expected []MatchCandidate
+	}
+
+	dmp := New()
+	dmp.MatchThreshold = 0.7
+
+	for i, tc := range []TestCase{
+		{"Exact matches", "abcxyzabcxyzabc", "abc", 5, 0, []MatchCandidate{{6, 0, 0.001}, {0, 0, 0.005}, {12, 0, 0.007}}},
+		{"Top two", "abcxyzabcxyzabc", "abc", 5, 2, []MatchCandidate{{6, 0, 0.001}, {0, 0, 0.005}}},
+		{"Fuzzy matches", "abcxyzabcxyzabc", "abd", 6, 0, []MatchCandidate{{6, 1, 0.3333333}, {0, 1, 0.3393333}, {12, 1, 0.3393333}}},
+		{"Alternative", "I am the very model of a modern major general.", " that berry ", 5, 0, []MatchCandidate{{4, 4, 0.3343333}, {22, 8, 0.6836667}}},
+		{"Null pattern", "abcdef", "", 3, 0, []MatchCandidate{{3, 0, 0}}},
+		{"No match", "abcdef", "xyz", 0, 0, []MatchCandidate{}},
+	} {
+		actual := dmp.MatchCandidates(tc.Text, tc.Pattern, tc.Location, tc.K)
+		if assert.Len(t, actual, len(tc.Expected), fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			for j, expected := range tc.Expected {
+				assert.Equal(t, expected.Location, actual[j].Location, fmt.Sprintf("Test case #%d, %s, candidate %d", i, tc.Name, j))
+				assert.Equal(t, expected.Errors, actual[j].Errors, fmt.Sprintf("Test case #%d, %s, candidate %d", i, tc.Name, j))
+				assert.InDelta(t, expected.Score, actual[j].Score, 1e-6, fmt.Sprintf("Test case #%d, %s, candidate %d", i, tc.Name, j))
+			}
+		}
+	}
+}
+
+func TestMatchMainRunes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected int
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Equality", "abcdef", "abcdef", 1000, 0},
+		{"Null text", "", "abcdef", 1, -1},
+		{"Null pattern", "abcdef", "", 3, 3},
+		{"Exact match", "abcdef", "de", 3, 3},
+		{"Beyond end match", "abcdef", "defy", 4, 3},
+		{"Oversized pattern", "abcdef", "abcdefy", 0, 0},
+		{"Exact multi-byte match", "日本語のテキスト", "テキスト", 4, 4},
+		{"Fuzzy multi-byte match", "日本語のテキスト", "のテキスっ", 0, 3},
+	} {
+		actual := dmp.MatchMainRunes([]rune(tc.Text), []rune(tc.Pattern), tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestMatchMainRuneBoundaries(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text     string
+		Pattern  string
+		Location int
+
+		Expected int
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null pattern inside rune", "a\u03b2c", "", 2, 1},
+		{"Fuzzy match starting inside rune", "xx\u03b2abc", "\xb2abd", 0, 2},
+		{"Exact match starting inside rune", "a\u03b2c", "\xb2c", 2, 2},
+	} {
+		actual := dmp.MatchMain(tc.Text, tc.Pattern, tc.Location)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text      string
+		Pattern   string
+		MaxErrors int
+
+		Expected []MatchResult
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null pattern", "abc", "", 1, nil},
+		{"Negative budget", "abc", "abc", -1, nil},
+		{"No match", "abcdef", "xyz", 1, []MatchResult{}},
+		{"Exact matches", "abcxabc", "abc", 0, []MatchResult{{0, 3, 0, 0}, {4, 3, 0, 0}}},
+		{"Adjacent exact matches", "abcabc", "abc", 1, []MatchResult{{0, 3, 0, 0}, {3, 3, 0, 0}}},
+		{"Substitution", "the cat and the bat", "cat", 1, []MatchResult{{4, 3, 0, 0}, {16, 3, 1, 1.0 / 3}}},
+		{"Insertion and deletion", "xxabxcxxacxx", "abc", 1, []MatchResult{{2, 4, 1, 1.0 / 3}, {8, 2, 1, 1.0 / 3}}},
+		{"Multi-byte", "日本語と日本誤", "日本語", 1, []MatchResult{{0, 9, 0, 0}, {12, 9, 1, 1.0 / 3}}},
+	} {
+		actual := dmp.MatchAll(tc.Text, tc.Pattern, tc.MaxErrors)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
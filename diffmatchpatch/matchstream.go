@@ -0,0 +1,83 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// matchStreamChunkSize is the number of bytes MatchStream reads at once.
+var matchStreamChunkSize = 64 * 1024
+
+// MatchStream locates the approximate occurrences of 'pattern' in the text read from r like MatchAll, without holding more of the text in memory than a chunk and the length of a match, e.g. to grep large files.
+// The matches differ from 'pattern' by at most threshold times its number of runes edits.  Their locations are byte offsets in the stream.
+// A match is reported as soon as the text after it can no longer extend it, so where candidates compete for the same text the result may differ from MatchAll on the whole text.
+func (dmp *DiffMatchPatch) MatchStream(r io.Reader, pattern string, threshold float64) (_ []MatchResult, err error) {
+	defer dmp.recoverInternal(&err)
+	patternLen := utf8.RuneCountInString(pattern)
+	if patternLen == 0 || threshold < 0 {
+		return nil, nil
+	}
+	maxErrors := int(threshold * float64(patternLen))
+	// The longest match has a rune for every rune of the pattern and every error.
+	overlap := utf8.UTFMax * (patternLen + maxErrors)
+
+	var results []MatchResult
+	var buf []byte
+	// Offset of buf in the stream, and end of the last match.
+	base, done := 0, 0
+	chunk := make([]byte, matchStreamChunkSize)
+	for eof := false; !eof; {
+		n, err := io.ReadFull(r, chunk)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			eof = true
+		} else if err != nil {
+			return results, err
+		}
+		buf = append(buf, chunk[:n]...)
+
+		// Search the complete runes, and only report the matches which end before the text the next chunk may extend.
+		end, limit := len(buf), len(buf)
+		if !eof {
+			for i := len(buf) - 1; i >= 0 && i >= len(buf)-utf8.UTFMax; i-- {
+				if utf8.RuneStart(buf[i]) {
+					if !utf8.FullRune(buf[i:]) {
+						end = i
+					}
+					break
+				}
+			}
+			limit = end - overlap
+			if limit <= 0 {
+				continue
+			}
+		}
+		for _, match := range dmp.MatchAll(string(buf[:end]), pattern, maxErrors) {
+			if base+match.Location >= done && match.Location+match.Length <= limit {
+				match.Location += base
+				results = append(results, match)
+				done = match.Location + match.Length
+			}
+		}
+
+		if !eof {
+			// Keep the text where the matches which may still be extended start.
+			keep := limit - overlap
+			for keep > 0 && !utf8.RuneStart(buf[keep]) {
+				keep--
+			}
+			if keep > 0 {
+				buf = append(buf[:0:0], buf[keep:]...)
+				base += keep
+			}
+		}
+	}
+	return results, nil
+}
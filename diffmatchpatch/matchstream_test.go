@@ -0,0 +1,70 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchStream(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text      string
+		Pattern   string
+		Threshold float64
+		ChunkSize int
+
+		Expected []MatchResult
+	}
+
+	dmp := New()
+	defer func(size int) { matchStreamChunkSize = size }(matchStreamChunkSize)
+
+	text := strings.Repeat("lorem ipsum ", 5) + "quick brown fox " + strings.Repeat("dolor sit ", 5) + "quack brown fix " + strings.Repeat("amet ", 5)
+	fuzzy := []MatchResult{{60, 15, 0, 0}, {126, 15, 2, 2.0 / 15}}
+
+	for i, tc := range []TestCase{
+		{"Single chunk", text, "quick brown fox", 0.2, 1024, fuzzy},
+		{"Small chunks", text, "quick brown fox", 0.2, 7, fuzzy},
+		{"Chunks shorter than a match", text, "quick brown fox", 0.2, 1, fuzzy},
+		{"Exact", text, "quick brown fox", 0, 7, fuzzy[:1]},
+		{"Match at the end", "abcabx", "abx", 0, 2, []MatchResult{{3, 3, 0, 0}}},
+		{"Multi-byte runes", "日本語のテキストと日本語のテクスト", "テキスト", 0.25, 5, []MatchResult{{12, 12, 0, 0}, {39, 12, 1, 0.25}}},
+		{"Empty text", "", "abc", 0.5, 7, nil},
+		{"Empty pattern", "abc", "", 0.5, 7, nil},
+	} {
+		matchStreamChunkSize = tc.ChunkSize
+		actual, err := dmp.MatchStream(strings.NewReader(tc.Text), tc.Pattern, tc.Threshold)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if len(tc.Pattern) != 0 {
+			assert.Equal(t, dmp.MatchAll(tc.Text, tc.Pattern, int(tc.Threshold*float64(len([]rune(tc.Pattern))))), nonNil(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// Read errors end the search with the matches found before them.
+	matchStreamChunkSize = 32
+	actual, err := dmp.MatchStream(iotest.TimeoutReader(strings.NewReader(text)), "lorem", 0)
+	assert.Equal(t, iotest.ErrTimeout, err)
+	assert.Equal(t, []MatchResult{{0, 5, 0, 0}}, actual)
+}
+
+// nonNil returns an empty list instead of nil.
+func nonNil(results []MatchResult) []MatchResult {
+	if results == nil {
+		return []MatchResult{}
+	}
+	return results
+}
@@ -21,3 +21,10 @@ func max(x, y int) int {
 	}
 	return y
 }
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+)
+
+// MergeLabel tells which side changed a region of a three-way merge.
+type MergeLabel int8
+
+const (
+	// MergeUnchanged regions are the same in all three texts.
+	MergeUnchanged MergeLabel = iota
+	// MergeOurs regions were only changed by our side.
+	MergeOurs
+	// MergeTheirs regions were only changed by their side.
+	MergeTheirs
+	// MergeBoth regions were changed the same way by both sides.
+	MergeBoth
+	// MergeConflict regions were changed differently by both sides.
+	MergeConflict
+)
+
+// String returns the name of the label.
+func (l MergeLabel) String() string {
+	switch l {
+	case MergeUnchanged:
+		return "Unchanged"
+	case MergeOurs:
+		return "Ours"
+	case MergeTheirs:
+		return "Theirs"
+	case MergeBoth:
+		return "Both"
+	case MergeConflict:
+		return "Conflict"
+	}
+	return fmt.Sprintf("MergeLabel(%d)", l)
+}
+
+// MergeRegion is one region of a three-way merge, with its content in each text.
+type MergeRegion struct {
+	Label  MergeLabel
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// MergePreview aligns two versions, ours and theirs, of a common base text, and splits them into regions labeled by the side which changed them.
+// Unlike a merged text, the regions keep the content of every side, e.g. to let the user of a merge tool resolve the conflicts.
+func (dmp *DiffMatchPatch) MergePreview(base, ours, theirs string) []MergeRegion {
+	texts := []string{base, ours, theirs}
+	diffs := [][]Diff{
+		nil,
+		dmp.DiffCleanupSemantic(dmp.withoutPostProcess().DiffMain(base, ours, true)),
+		dmp.DiffCleanupSemantic(dmp.withoutPostProcess().DiffMain(base, theirs, true)),
+	}
+	regions := []MergeRegion{}
+	for _, segment := range dmp.diffNMerge(texts, 0, diffs) {
+		region := MergeRegion{Base: segment.Texts[0], Ours: segment.Texts[1], Theirs: segment.Texts[2]}
+		switch {
+		case segment.Common:
+			region.Label = MergeUnchanged
+		case region.Theirs == region.Base:
+			region.Label = MergeOurs
+		case region.Ours == region.Base:
+			region.Label = MergeTheirs
+		case region.Ours == region.Theirs:
+			region.Label = MergeBoth
+		default:
+			region.Label = MergeConflict
+		}
+		regions = append(regions, region)
+	}
+	return regions
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePreview(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Base   string
+		Ours   string
+		Theirs string
+
+		Expected []MergeRegion
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", "", "", "", []MergeRegion{}},
+		{"Unchanged", "same", "same", "same", []MergeRegion{{MergeUnchanged, "same", "same", "same"}}},
+		{"Insertion into empty base", "", "a", "", []MergeRegion{{MergeOurs, "", "a", ""}}},
+		{
+			"Changes of each side",
+			"The quick brown fox jumps over the lazy dog.",
+			"The quick red fox jumps over the lazy dog.",
+			"The quick brown fox jumps over the sleepy dog.",
+			[]MergeRegion{
+				{MergeUnchanged, "The quick ", "The quick ", "The quick "},
+				{MergeOurs, "brown", "red", "brown"},
+				{MergeUnchanged, " fox jumps over the ", " fox jumps over the ", " fox jumps over the "},
+				{MergeTheirs, "laz", "laz", "sleep"},
+				{MergeUnchanged, "y dog.", "y dog.", "y dog."},
+			},
+		},
+		{
+			"Same change",
+			"The quick brown fox.",
+			"The quick red fox.",
+			"The quick red fox.",
+			[]MergeRegion{
+				{MergeUnchanged, "The quick ", "The quick ", "The quick "},
+				{MergeBoth, "brown", "red", "red"},
+				{MergeUnchanged, " fox.", " fox.", " fox."},
+			},
+		},
+		{
+			"Conflict",
+			"The quick brown fox.",
+			"The quick red fox.",
+			"The quick black fox.",
+			[]MergeRegion{
+				{MergeUnchanged, "The quick ", "The quick ", "The quick "},
+				{MergeConflict, "brown", "red", "black"},
+				{MergeUnchanged, " fox.", " fox.", " fox."},
+			},
+		},
+	} {
+		actual := dmp.MergePreview(tc.Base, tc.Ours, tc.Theirs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestMergeLabelString(t *testing.T) {
+	assert.Equal(t, "Conflict", MergeConflict.String())
+	assert.Equal(t, "MergeLabel(9)", MergeLabel(9).String())
+}
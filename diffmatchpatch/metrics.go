@@ -0,0 +1,111 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Metrics reports how diff computations spent their time. Durations of parallel computations are summed up.
+type Metrics struct {
+	// Number of diffs computed.
+	Diffs int
+	// Time spent searching for the middle snakes of bisections.
+	Bisect time.Duration
+	// Time spent on the line-level diffs of the line mode speedup, before rediffing the replaced lines.
+	LineMode time.Duration
+	// Time spent cleaning up diffs while computing them.
+	Cleanup time.Duration
+	// Number of diff computations on parts of the texts, counting every level of recursion.
+	Recursions int
+	// Whether DiffTimeout truncated a diff, which then is coarser than the optimal diff.
+	TimedOut bool
+	// Whether MaxMemoryBytes coarsened a diff.
+	MemoryLimited bool
+}
+
+// Indexes of the durations measured by diffMetrics.
+const (
+	metricBisect = iota
+	metricLineMode
+	metricCleanup
+	metricCount
+)
+
+// diffMetrics collects the metrics of one diff computation, which may run on several goroutines.
+type diffMetrics struct {
+	durations     [metricCount]int64
+	recursions    int64
+	timedOut      int32
+	memoryLimited int32
+}
+
+// diffRunesWithMetrics diffs two rune sequences and adds the metrics of the computation to DiffMetrics.
+func (dmp *DiffMatchPatch) diffRunesWithMetrics(text1, text2 []rune, checklines bool) []runeDiff {
+	measured := *dmp
+	m := &diffMetrics{}
+	measured.metrics = m
+	diffs := measured.diffRunes(text1, text2, checklines)
+
+	dmp.DiffMetrics.Diffs++
+	dmp.DiffMetrics.Bisect += time.Duration(m.durations[metricBisect])
+	dmp.DiffMetrics.LineMode += time.Duration(m.durations[metricLineMode])
+	dmp.DiffMetrics.Cleanup += time.Duration(m.durations[metricCleanup])
+	dmp.DiffMetrics.Recursions += int(m.recursions)
+	if m.timedOut != 0 {
+		dmp.DiffMetrics.TimedOut = true
+	}
+	if m.memoryLimited != 0 {
+		dmp.DiffMetrics.MemoryLimited = true
+	}
+	return diffs
+}
+
+// metricsNow returns the current time if metrics are collected, and the zero time otherwise.
+func (dmp *DiffMatchPatch) metricsNow() time.Time {
+	if dmp.metrics == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// addDuration adds the time elapsed since start to the given duration of the metrics.
+func (dmp *DiffMatchPatch) addDuration(metric int, start time.Time) {
+	if dmp.metrics != nil {
+		atomic.AddInt64(&dmp.metrics.durations[metric], int64(time.Since(start)))
+	}
+}
+
+// addRecursion counts one diff computation on parts of the texts.
+func (dmp *DiffMatchPatch) addRecursion() {
+	if dmp.metrics != nil {
+		atomic.AddInt64(&dmp.metrics.recursions, 1)
+	}
+}
+
+// markTimedOut records that DiffTimeout truncated the diff.
+func (dmp *DiffMatchPatch) markTimedOut() {
+	if dmp.metrics != nil {
+		atomic.StoreInt32(&dmp.metrics.timedOut, 1)
+	}
+	if dmp.timedOut != nil {
+		atomic.StoreInt32(dmp.timedOut, 1)
+	}
+}
+
+// markMemoryLimited records that MaxMemoryBytes coarsened the diff.
+func (dmp *DiffMatchPatch) markMemoryLimited() {
+	if dmp.metrics != nil {
+		atomic.StoreInt32(&dmp.metrics.memoryLimited, 1)
+	}
+	if dmp.memoryLimited != nil {
+		atomic.StoreInt32(dmp.memoryLimited, 1)
+	}
+}
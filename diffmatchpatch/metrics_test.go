@@ -0,0 +1,79 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMetrics(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1      string
+		Text2      string
+		Checklines bool
+		Timeout    time.Duration
+
+		ExpectedBisect   bool
+		ExpectedLineMode bool
+		ExpectedTimedOut bool
+	}
+
+	lines1 := strings.Repeat("1234567890\n", 20)
+	lines2 := strings.Repeat("abcdefghij\n1234567890\n", 10)
+	a := strings.Repeat("`Twas brillig, and the slithy toves\nDid gyre and gimble in the wabe:\n", 1024)
+	b := strings.Repeat("I am the very model of a modern major general,\nI've information vegetable, animal, and mineral,\n", 1024)
+
+	for i, tc := range []TestCase{
+		{"Identical texts", "abc", "abc", false, 0, false, false, false},
+		{"Insertion", "abc", "ab123c", false, 0, false, false, false},
+		{"Bisect", "cat", "map", false, 0, true, false, false},
+		{"Line mode", lines1, lines2, true, 0, true, true, false},
+		{"Timeout", a, b, false, 10 * time.Millisecond, true, false, true},
+	} {
+		dmp := New()
+		dmp.DiffTimeout = tc.Timeout
+		dmp.DiffLineModeThreshold = 10
+		dmp.DiffMetrics = &Metrics{}
+
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, tc.Checklines)
+		if tc.Timeout == 0 {
+			metrics := dmp.DiffMetrics
+			dmp.DiffMetrics = nil
+			assert.Equal(t, dmp.DiffMain(tc.Text1, tc.Text2, tc.Checklines), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			dmp.DiffMetrics = metrics
+		}
+
+		assert.Equal(t, 1, dmp.DiffMetrics.Diffs, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, dmp.DiffMetrics.Recursions >= 1, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedBisect, dmp.DiffMetrics.Bisect > 0, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedLineMode, dmp.DiffMetrics.LineMode > 0, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedTimedOut, dmp.DiffMetrics.TimedOut, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffMetricsAccumulate(t *testing.T) {
+	dmp := New()
+	dmp.DiffMetrics = &Metrics{}
+
+	dmp.DiffMain("The quick brown fox", "The slow green turtle", false)
+	first := *dmp.DiffMetrics
+	dmp.DiffMain("The quick brown fox", "The slow green turtle", false)
+
+	assert.Equal(t, 2, dmp.DiffMetrics.Diffs)
+	assert.Equal(t, 2*first.Recursions, dmp.DiffMetrics.Recursions)
+	assert.True(t, dmp.DiffMetrics.Bisect >= first.Bisect)
+	assert.True(t, dmp.DiffMetrics.Cleanup > first.Cleanup)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sort"
+)
+
+// DiffNResult holds the comparison of several versions of a text.
+type DiffNResult struct {
+	// Diffs[i][j] transforms the i-th text into the j-th text.
+	Diffs [][][]Diff
+	// Distances[i][j] is the Levenshtein distance between the i-th and the j-th text.
+	Distances [][]int
+	// Index of the text which is closest to all others. The merge preview is aligned to it.
+	Reference int
+	// Index of the text which is furthest from all others, or -1 if there is no single such text.
+	Outlier int
+	// The texts side by side, split into segments which are either common to all texts or differ between them.
+	Merge []NWaySegment
+}
+
+// NWaySegment is one segment of an n-way merge preview.
+type NWaySegment struct {
+	// Whether all texts agree on this segment.
+	Common bool
+	// The content of this segment in each text, in the order of the texts given to DiffN.
+	Texts []string
+}
+
+// DiffN compares any number of versions of a text with each other, without requiring a common ancestor.
+func (dmp *DiffMatchPatch) DiffN(texts []string) DiffNResult {
+	n := len(texts)
+	result := DiffNResult{
+		Diffs:     make([][][]Diff, n),
+		Distances: make([][]int, n),
+		Reference: -1,
+		Outlier:   -1,
+	}
+	for i := range texts {
+		result.Diffs[i] = make([][]Diff, n)
+		result.Distances[i] = make([]int, n)
+		result.Diffs[i][i] = []Diff{}
+		if len(texts[i]) != 0 {
+			result.Diffs[i][i] = []Diff{{DiffEqual, texts[i]}}
+		}
+	}
+	if n == 0 {
+		return result
+	}
+
+	unprocessed := dmp.withoutPostProcess()
+	totals := make([]int, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			diffs := dmp.DiffCleanupSemantic(unprocessed.DiffMain(texts[i], texts[j], true))
+			result.Diffs[i][j] = diffs
+			result.Diffs[j][i] = diffInvert(diffs)
+			distance := dmp.DiffLevenshtein(diffs)
+			result.Distances[i][j] = distance
+			result.Distances[j][i] = distance
+			totals[i] += distance
+			totals[j] += distance
+		}
+	}
+
+	result.Reference = 0
+	for i := range totals {
+		if totals[i] < totals[result.Reference] {
+			result.Reference = i
+		}
+	}
+	if n > 2 {
+		// The outlier must be strictly further away than every other text.
+		for i := range totals {
+			if result.Outlier == -1 || totals[i] > totals[result.Outlier] {
+				result.Outlier = i
+			}
+		}
+		for i := range totals {
+			if i != result.Outlier && totals[i] == totals[result.Outlier] {
+				result.Outlier = -1
+				break
+			}
+		}
+	}
+
+	result.Merge = dmp.diffNMerge(texts, result.Reference, result.Diffs[result.Reference])
+	return result
+}
+
+// nwayHunk replaces the bytes [start, end) of the reference text with text in one version.
+type nwayHunk struct {
+	version    int
+	start, end int
+	text       string
+}
+
+// diffNMerge aligns all texts to the reference text using the diffs from the reference to each text.
+func (dmp *DiffMatchPatch) diffNMerge(texts []string, reference int, diffs [][]Diff) []NWaySegment {
+	ref := texts[reference]
+
+	// Collect the edits of every version as hunks on the reference text.
+	var hunks []nwayHunk
+	for version, versionDiffs := range diffs {
+		pos := 0
+		var hunk *nwayHunk
+		for _, aDiff := range versionDiffs {
+			if aDiff.Type == DiffEqual {
+				if hunk != nil {
+					hunks = append(hunks, *hunk)
+					hunk = nil
+				}
+				pos += len(aDiff.Text)
+				continue
+			}
+			if hunk == nil {
+				hunk = &nwayHunk{version: version, start: pos, end: pos}
+			}
+			if aDiff.Type == DiffDelete {
+				pos += len(aDiff.Text)
+				hunk.end = pos
+			} else {
+				hunk.text += aDiff.Text
+			}
+		}
+		if hunk != nil {
+			hunks = append(hunks, *hunk)
+		}
+	}
+	sort.SliceStable(hunks, func(i, j int) bool {
+		if hunks[i].start != hunks[j].start {
+			return hunks[i].start < hunks[j].start
+		}
+		return hunks[i].end < hunks[j].end
+	})
+
+	var segments []NWaySegment
+	common := func(text string) {
+		if len(text) == 0 {
+			return
+		}
+		segment := NWaySegment{Common: true, Texts: make([]string, len(texts))}
+		for i := range segment.Texts {
+			segment.Texts[i] = text
+		}
+		segments = append(segments, segment)
+	}
+
+	pos := 0
+	for i := 0; i < len(hunks); {
+		// Group the hunks which overlap each other, or insert at the same location.
+		start, end := hunks[i].start, hunks[i].end
+		j := i + 1
+		for j < len(hunks) && (hunks[j].start < end || (hunks[j].start == start && start == end)) {
+			end = max(end, hunks[j].end)
+			j++
+		}
+
+		common(ref[pos:start])
+		segment := NWaySegment{Texts: make([]string, len(texts))}
+		for v := range texts {
+			// Apply the hunks of each version to the reference text of the group.
+			text := ""
+			at := start
+			for _, h := range hunks[i:j] {
+				if h.version == v {
+					text += ref[at:h.start] + h.text
+					at = h.end
+				}
+			}
+			segment.Texts[v] = text + ref[at:end]
+		}
+		segments = append(segments, segment)
+
+		pos = end
+		i = j
+	}
+	common(ref[pos:])
+
+	return segments
+}
+
+// diffInvert returns the diffs transforming text2 back into text1, with the deletions of every edit before its insertions.
+func diffInvert(diffs []Diff) []Diff {
+	inverted := make([]Diff, 0, len(diffs))
+	var deletions, insertions []Diff
+	flush := func() {
+		inverted = append(append(inverted, deletions...), insertions...)
+		deletions, insertions = deletions[:0], insertions[:0]
+	}
+	for _, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffInsert:
+			deletions = append(deletions, Diff{DiffDelete, aDiff.Text})
+		case DiffDelete:
+			insertions = append(insertions, Diff{DiffInsert, aDiff.Text})
+		case DiffEqual:
+			flush()
+			inverted = append(inverted, aDiff)
+		}
+	}
+	flush()
+	return inverted
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffN(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Texts []string
+
+		ExpectedReference int
+		ExpectedOutlier   int
+		ExpectedMerge     []NWaySegment
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No texts", nil, -1, -1, nil},
+		{"Single text", []string{"abc"}, 0, -1, []NWaySegment{{true, []string{"abc"}}}},
+		{"Two texts", []string{"port=80\n", "port=81\n"}, 0, -1, []NWaySegment{
+			{true, []string{"port=8", "port=8"}},
+			{false, []string{"0", "1"}},
+			{true, []string{"\n", "\n"}},
+		}},
+		{"Identical", []string{"abc", "abc", "abc"}, 0, -1, []NWaySegment{{true, []string{"abc", "abc", "abc"}}}},
+		{"Outlier", []string{"host=a\nport=80\n", "host=a\nport=80\n", "host=a\nport=8080\n"}, 0, 2, []NWaySegment{
+			{true, []string{"host=a\nport=80", "host=a\nport=80", "host=a\nport=80"}},
+			{false, []string{"", "", "80"}},
+			{true, []string{"\n", "\n", "\n"}},
+		}},
+		{"Overlapping edits", []string{"abcdef", "abXYef", "abcZef"}, 0, 1, []NWaySegment{
+			{true, []string{"ab", "ab", "ab"}},
+			{false, []string{"cd", "XY", "cZ"}},
+			{true, []string{"ef", "ef", "ef"}},
+		}},
+		{"Insertions at the same location", []string{"ac", "abc", "axc", "ac"}, 0, -1, []NWaySegment{
+			{true, []string{"a", "a", "a", "a"}},
+			{false, []string{"", "b", "x", ""}},
+			{true, []string{"c", "c", "c", "c"}},
+		}},
+	} {
+		actual := dmp.DiffN(tc.Texts)
+		assert.Equal(t, tc.ExpectedReference, actual.Reference, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedOutlier, actual.Outlier, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedMerge, actual.Merge, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		for a := range tc.Texts {
+			// Every text can be rebuilt from the merge preview.
+			text := ""
+			for _, segment := range actual.Merge {
+				text += segment.Texts[a]
+			}
+			assert.Equal(t, tc.Texts[a], text, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+			for b := range tc.Texts {
+				assert.Equal(t, tc.Texts[a], dmp.DiffText1(actual.Diffs[a][b]), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.Equal(t, tc.Texts[b], dmp.DiffText2(actual.Diffs[a][b]), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.Equal(t, actual.Distances[b][a], actual.Distances[a][b], fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+		}
+	}
+
+	// The inverted diffs delete before they insert, like a diff computed the other way round.
+	actual := dmp.DiffN([]string{"axc", "abc"})
+	assert.Equal(t, dmp.DiffCleanupSemantic(dmp.DiffMain("abc", "axc", true)), actual.Diffs[1][0])
+	assert.Equal(t, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "c"}}, actual.Diffs[1][0])
+}
@@ -11,22 +11,38 @@ package diffmatchpatch
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Patch represents one patch operation.
 type Patch struct {
-	diffs   []Diff
+	Diffs   []Diff
 	Start1  int
 	Start2  int
 	Length1 int
 	Length2 int
 }
 
+// NewPatch creates a patch which applies diffs at the byte offsets start1 of the source text and start2 of the destination text.  The lengths are derived from diffs.
+func NewPatch(start1, start2 int, diffs []Diff) Patch {
+	patch := Patch{Diffs: diffs, Start1: start1, Start2: start2}
+	for _, aDiff := range diffs {
+		if aDiff.Type != DiffInsert {
+			patch.Length1 += len(aDiff.Text)
+		}
+		if aDiff.Type != DiffDelete {
+			patch.Length2 += len(aDiff.Text)
+		}
+	}
+	return patch
+}
+
 // String emulates GNU diff's format.
 // Header: @@ -382,8 +481,9 @@
 // Indices are printed as 1-based, not 0-based.
@@ -53,7 +69,7 @@ func (p *Patch) String() string {
 	_, _ = text.WriteString("@@ -" + coords1 + " +" + coords2 + " @@\n")
 
 	// Escape the body of the patch with %xx notation.
-	for _, aDiff := range p.diffs {
+	for _, aDiff := range p.Diffs {
 		switch aDiff.Type {
 		case DiffInsert:
 			_, _ = text.WriteString("+")
@@ -75,13 +91,18 @@ func (dmp *DiffMatchPatch) PatchAddContext(patch Patch, text string) Patch {
 	if len(text) == 0 {
 		return patch
 	}
+	prefixStart, suffixEnd := dmp.uniqueContext(patch, text)
+	return addContext(patch, text, prefixStart, suffixEnd)
+}
 
+// uniqueContext returns the bounds in text of the smallest context, grown by PatchMargin, which makes the patch unique.
+func (dmp *DiffMatchPatch) uniqueContext(patch Patch, text string) (int, int) {
 	pattern := text[patch.Start2 : patch.Start2+patch.Length1]
 	padding := 0
 
 	// Look for the first and last matches of pattern in text.  If two different matches are found, increase the pattern length.
 	for strings.Index(text, pattern) != strings.LastIndex(text, pattern) &&
-		len(pattern) < dmp.MatchMaxBits-2*dmp.PatchMargin {
+		(dmp.MatchMaxBits == 0 || len(pattern) < dmp.MatchMaxBits-2*dmp.PatchMargin) {
 		padding += dmp.PatchMargin
 		maxStart := max(0, patch.Start2-padding)
 		minEnd := min(len(text), patch.Start2+patch.Length1+padding)
@@ -90,15 +111,44 @@ func (dmp *DiffMatchPatch) PatchAddContext(patch Patch, text string) Patch {
 	// Add one chunk for good luck.
 	padding += dmp.PatchMargin
 
-	// Add the prefix.
-	prefix := text[max(0, patch.Start2-padding):patch.Start2]
+	return max(0, patch.Start2-padding), min(len(text), patch.Start2+patch.Length1+padding)
+}
+
+// lineContext returns the bounds in text of the context made of the rest of the lines the patch starts and ends in, and of the given number of whole lines on each side.
+func lineContext(patch Patch, text string, lines int) (int, int) {
+	start := strings.LastIndexByte(text[:patch.Start2], '\n') + 1
+	for i := 0; i < lines && start > 0; i++ {
+		start = strings.LastIndexByte(text[:start-1], '\n') + 1
+	}
+	end := patch.Start2 + patch.Length1
+	if end > 0 && text[end-1] != '\n' {
+		// Complete the line the patch ends in.
+		lines++
+	}
+	for i := 0; i < lines && end < len(text); i++ {
+		if newline := strings.IndexByte(text[end:], '\n'); newline != -1 {
+			end += newline + 1
+		} else {
+			end = len(text)
+		}
+	}
+	return start, end
+}
+
+// addContext adds the text from prefixStart to suffixEnd around the patch as context.
+func addContext(patch Patch, text string, prefixStart, suffixEnd int) Patch {
+	// Add the prefix, extended to the start of its first rune.
+	prefix := text[runeStart(text, prefixStart):patch.Start2]
 	if len(prefix) != 0 {
-		patch.diffs = append([]Diff{Diff{DiffEqual, prefix}}, patch.diffs...)
+		patch.Diffs = append([]Diff{Diff{DiffEqual, prefix}}, patch.Diffs...)
+	}
+	// Add the suffix, extended to the end of its last rune.
+	for suffixEnd < len(text) && !utf8.RuneStart(text[suffixEnd]) {
+		suffixEnd++
 	}
-	// Add the suffix.
-	suffix := text[patch.Start2+patch.Length1 : min(len(text), patch.Start2+patch.Length1+padding)]
+	suffix := text[patch.Start2+patch.Length1 : suffixEnd]
 	if len(suffix) != 0 {
-		patch.diffs = append(patch.diffs, Diff{DiffEqual, suffix})
+		patch.Diffs = append(patch.Diffs, Diff{DiffEqual, suffix})
 	}
 
 	// Roll back the start points.
@@ -111,6 +161,47 @@ func (dmp *DiffMatchPatch) PatchAddContext(patch Patch, text string) Patch {
 	return patch
 }
 
+// PatchOptions controls the context PatchMakeWithOptions adds around each patch.  The context of a patch is the larger of the contexts given by ContextChars and ContextLines.
+type PatchOptions struct {
+	// Number of bytes of context on each side of a patch (0 for none, -1 for enough context to make the patch unique, as PatchMake does).
+	ContextChars int
+	// Number of whole lines of context on each side of a patch, which then also includes the rest of the lines the patch starts and ends in (0 for none).
+	ContextLines int
+}
+
+// DefaultPatchOptions returns the options used by PatchMake.
+func DefaultPatchOptions() PatchOptions {
+	return PatchOptions{
+		ContextChars: -1,
+	}
+}
+
+// PatchMakeWithOptions computes a list of patches to turn text1 into text2, where diffs are the delta between text1 and text2, with the context configured by opts.
+// Larger contexts let patches apply to texts which changed more, smaller ones make patches more compact.
+func (dmp *DiffMatchPatch) PatchMakeWithOptions(text1 string, diffs []Diff, opts PatchOptions) []Patch {
+	return dmp.patchMake2(text1, diffs, opts)
+}
+
+// patchAddContext adds the context configured by opts to the patch.
+func (dmp *DiffMatchPatch) patchAddContext(patch Patch, text string, opts PatchOptions) Patch {
+	if len(text) == 0 {
+		return patch
+	}
+	var prefixStart, suffixEnd int
+	if opts.ContextChars < 0 {
+		prefixStart, suffixEnd = dmp.uniqueContext(patch, text)
+	} else {
+		prefixStart = max(0, patch.Start2-opts.ContextChars)
+		suffixEnd = min(len(text), patch.Start2+patch.Length1+opts.ContextChars)
+	}
+	if opts.ContextLines > 0 {
+		lineStart, lineEnd := lineContext(patch, text, opts.ContextLines)
+		prefixStart = min(prefixStart, lineStart)
+		suffixEnd = max(suffixEnd, lineEnd)
+	}
+	return addContext(patch, text, prefixStart, suffixEnd)
+}
+
 // PatchMake computes a list of patches.
 func (dmp *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
 	if len(opt) == 1 {
@@ -121,14 +212,15 @@ func (dmp *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
 		text1 := opt[0].(string)
 		switch t := opt[1].(type) {
 		case string:
-			diffs := dmp.DiffMain(text1, t, true)
+			// Post-process the diff once it is cleaned up.
+			diffs := dmp.withoutPostProcess().DiffMain(text1, t, true)
 			if len(diffs) > 2 {
 				diffs = dmp.DiffCleanupSemantic(diffs)
 				diffs = dmp.DiffCleanupEfficiency(diffs)
 			}
-			return dmp.PatchMake(text1, diffs)
+			return dmp.PatchMake(text1, dmp.postProcessDiffs(diffs))
 		case []Diff:
-			return dmp.patchMake2(text1, t)
+			return dmp.patchMake2(text1, t, DefaultPatchOptions())
 		}
 	} else if len(opt) == 3 {
 		return dmp.PatchMake(opt[0], opt[2])
@@ -138,7 +230,7 @@ func (dmp *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
 
 // patchMake2 computes a list of patches to turn text1 into text2.
 // text2 is not provided, diffs are the delta between text1 and text2.
-func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
+func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff, opts PatchOptions) []Patch {
 	// Check for null inputs not needed since null can't be passed in C#.
 	patches := []Patch{}
 	if len(diffs) == 0 {
@@ -153,7 +245,7 @@ func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 	postpatchText := text1
 
 	for i, aDiff := range diffs {
-		if len(patch.diffs) == 0 && aDiff.Type != DiffEqual {
+		if len(patch.Diffs) == 0 && aDiff.Type != DiffEqual {
 			// A new patch starts here.
 			patch.Start1 = charCount1
 			patch.Start2 = charCount2
@@ -161,26 +253,26 @@ func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 
 		switch aDiff.Type {
 		case DiffInsert:
-			patch.diffs = append(patch.diffs, aDiff)
+			patch.Diffs = append(patch.Diffs, aDiff)
 			patch.Length2 += len(aDiff.Text)
 			postpatchText = postpatchText[:charCount2] +
 				aDiff.Text + postpatchText[charCount2:]
 		case DiffDelete:
 			patch.Length1 += len(aDiff.Text)
-			patch.diffs = append(patch.diffs, aDiff)
+			patch.Diffs = append(patch.Diffs, aDiff)
 			postpatchText = postpatchText[:charCount2] + postpatchText[charCount2+len(aDiff.Text):]
 		case DiffEqual:
 			if len(aDiff.Text) <= 2*dmp.PatchMargin &&
-				len(patch.diffs) != 0 && i != len(diffs)-1 {
+				len(patch.Diffs) != 0 && i != len(diffs)-1 {
 				// Small equality inside a patch.
-				patch.diffs = append(patch.diffs, aDiff)
+				patch.Diffs = append(patch.Diffs, aDiff)
 				patch.Length1 += len(aDiff.Text)
 				patch.Length2 += len(aDiff.Text)
 			}
 			if len(aDiff.Text) >= 2*dmp.PatchMargin {
 				// Time for a new patch.
-				if len(patch.diffs) != 0 {
-					patch = dmp.PatchAddContext(patch, prepatchText)
+				if len(patch.Diffs) != 0 {
+					patch = dmp.patchAddContext(patch, prepatchText, opts)
 					patches = append(patches, patch)
 					patch = Patch{}
 					// Unlike Unidiff, our patch lists have a rolling context. http://code.google.com/p/google-diff-match-patch/wiki/Unidiff Update prepatch text & pos to reflect the application of the just completed patch.
@@ -200,8 +292,8 @@ func (dmp *DiffMatchPatch) patchMake2(text1 string, diffs []Diff) []Patch {
 	}
 
 	// Pick up the leftover patch if not empty.
-	if len(patch.diffs) != 0 {
-		patch = dmp.PatchAddContext(patch, prepatchText)
+	if len(patch.Diffs) != 0 {
+		patch = dmp.patchAddContext(patch, prepatchText, opts)
 		patches = append(patches, patch)
 	}
 
@@ -213,8 +305,8 @@ func (dmp *DiffMatchPatch) PatchDeepCopy(patches []Patch) []Patch {
 	patchesCopy := []Patch{}
 	for _, aPatch := range patches {
 		patchCopy := Patch{}
-		for _, aDiff := range aPatch.diffs {
-			patchCopy.diffs = append(patchCopy.diffs, Diff{
+		for _, aDiff := range aPatch.Diffs {
+			patchCopy.Diffs = append(patchCopy.Diffs, Diff{
 				aDiff.Type,
 				aDiff.Text,
 			})
@@ -228,45 +320,315 @@ func (dmp *DiffMatchPatch) PatchDeepCopy(patches []Patch) []Patch {
 	return patchesCopy
 }
 
+// MatchStrategy is how PatchApplyWithOptions locates the text each patch applies to.
+type MatchStrategy int
+
+const (
+	// MatchStrategyBitap locates patches with the Bitap algorithm, scored by MatchThreshold and MatchDistance, or as the nearest match of MatchAll if Fuzz is set.
+	MatchStrategyBitap MatchStrategy = iota
+	// MatchStrategyExact only applies patches where their text occurs exactly, at the occurrence nearest to the expected location.
+	MatchStrategyExact
+	// MatchStrategyLevenshtein compares the text of a patch with every window of as many runes of the text, and applies the patch at the window with the fewest edits, the nearest to the expected location among those.
+	// The edits may not exceed Fuzz, or MatchThreshold times the length of the text of the patch if Fuzz is -1.  Its cost grows with the square of the length of the patches.
+	MatchStrategyLevenshtein
+)
+
+// matchStrategyNames are the names of the strategies.
+var matchStrategyNames = map[MatchStrategy]string{
+	MatchStrategyBitap:       "bitap",
+	MatchStrategyExact:       "exact",
+	MatchStrategyLevenshtein: "levenshtein",
+}
+
+// String returns the name of the strategy.
+func (s MatchStrategy) String() string {
+	if name, ok := matchStrategyNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("MatchStrategy(%d)", int(s))
+}
+
+// PatchApplyOptions controls how strictly PatchApplyWithOptions matches the context of each patch.
+type PatchApplyOptions struct {
+	// Maximum number of edits between the expected and the actual text of a patch (0 for exact matches only, -1 to use MatchThreshold).
+	Fuzz int
+	// Maximum number of bytes a patch may be moved from its expected location (-1 for no limit).
+	MaxOffset int
+	// Whether to stop at the first patch which fails to apply, leaving the remaining patches unapplied.
+	FailFast bool
+	// Whether to return the original text and an error if any patch fails to apply.
+	AllOrNothing bool
+	// Function which chooses the patches to apply by their index, nil to apply all of them.  The patches which are not chosen are skipped without affecting the positions of the following patches.
+	Selector func(i int, p Patch) bool
+	// Algorithm locating the text each patch applies to.
+	Strategy MatchStrategy
+	// Function called for each patch which applies, e.g. to log it or collect metrics, nil for none.  Returning false aborts the application, leaving the remaining patches unapplied.
+	OnHunkApplied func(e HunkEvent) bool
+	// Function called for each patch which fails to apply, nil for none.  Returning false aborts the application like for OnHunkApplied.
+	OnHunkFailed func(e HunkEvent) bool
+}
+
+// HunkEvent describes a patch which PatchApplyWithOptions applied or failed to apply, as passed to OnHunkApplied and OnHunkFailed.
+type HunkEvent struct {
+	// Index of the patch among the patches given.  Patches split by PatchSplitMax report each of their pieces with the same index.
+	Index int
+	// The patch as given.
+	Patch Patch
+	// Where the patch, or its piece, was placed.  Location is -1 if it failed to apply.
+	Placement PatchPlacement
+	// Text the patch was matched with if it applies, or else the text of the length of the patch at its expected location, to compare with the text the patch expects.
+	Found string
+}
+
+// DefaultPatchApplyOptions returns the options used by PatchApply.
+func DefaultPatchApplyOptions() PatchApplyOptions {
+	return PatchApplyOptions{
+		Fuzz:      -1,
+		MaxOffset: -1,
+	}
+}
+
 // PatchApply merges a set of patches onto the text.  Returns a patched text, as well as an array of true/false values indicating which patches were applied.
 func (dmp *DiffMatchPatch) PatchApply(patches []Patch, text string) (string, []bool) {
+	text, results, _ := dmp.PatchApplyWithOptions(patches, text, DefaultPatchApplyOptions())
+	return text, results
+}
+
+// PatchApplySelective merges the patches chosen by selector onto the text, e.g. the hunks approved by the user of an interactive tool.  Returns a patched text, as well as an array of true/false values indicating which patches were applied.
+func (dmp *DiffMatchPatch) PatchApplySelective(patches []Patch, text string, selector func(i int, p Patch) bool) (string, []bool) {
+	opts := DefaultPatchApplyOptions()
+	opts.Selector = selector
+	text, results, _ := dmp.PatchApplyWithOptions(patches, text, opts)
+	return text, results
+}
+
+// PatchApplyWithOptions merges a set of patches onto the text, matching their context as configured by opts.  Returns a patched text, as well as an array of true/false values indicating which patches were applied.
+// An error is returned in AllOrNothing mode, together with the unmodified text, and if OnHunkApplied or OnHunkFailed aborts the application, together with the text patched so far, or the unmodified text in AllOrNothing mode.
+func (dmp *DiffMatchPatch) PatchApplyWithOptions(patches []Patch, text string, opts PatchApplyOptions) (_ string, _ []bool, err error) {
+	defer dmp.recoverInternal(&err)
+	patched, placements, aborted := dmp.patchApply(patches, text, opts)
+	results := make([]bool, len(placements))
+	failed := 0
+	for i, placement := range placements {
+		results[i] = placement.Applied
+		if !placement.Applied && !placement.Skipped {
+			failed++
+		}
+	}
+	if aborted >= 0 {
+		if opts.AllOrNothing {
+			patched = text
+		}
+		return patched, results, fmt.Errorf("Patch application aborted at patch %d of %d", aborted, len(patches))
+	}
+	if opts.AllOrNothing && failed > 0 {
+		return text, results, fmt.Errorf("%d of %d patches do not apply", failed, len(placements))
+	}
+	return patched, results, nil
+}
+
+// PatchPlacement describes where a patch was found in the text it is applied to.
+type PatchPlacement struct {
+	// Whether the patch applies.
+	Applied bool
+	// Location in bytes, or runes for PatchApplyRunes, of the text matching the patch, or -1 if no such text was found.  This is relative to the text as modified by the preceding patches.
+	Location int
+	// Length in bytes, or runes for PatchApplyRunes, of the text matching the patch.
+	Length int
+	// Number of bytes, or runes for PatchApplyRunes, between the location the patch expects and its actual location.
+	Offset int
+	// Whether the patch was skipped since the Selector of PatchApplyOptions did not choose it.
+	Skipped bool
+}
+
+// PatchCheck determines where each patch would be applied to the text, without applying them.  Returns an error if any patch would fail to apply.
+// The placements correspond to the patches as split by PatchSplitMax.
+func (dmp *DiffMatchPatch) PatchCheck(patches []Patch, text string) (_ []PatchPlacement, err error) {
+	defer dmp.recoverInternal(&err)
+	_, placements, _ := dmp.patchApply(patches, text, DefaultPatchApplyOptions())
+	failed := 0
+	for _, placement := range placements {
+		if !placement.Applied {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return placements, fmt.Errorf("%d of %d patches do not apply", failed, len(placements))
+	}
+	return placements, nil
+}
+
+// PatchValidate checks that the headers of the patches agree with their diffs, so that corrupted patches, e.g. edited by hand or damaged in transport, can be rejected before they are applied.
+// Each patch must have non-negative positions, valid operations and no empty diffs, and lengths which match the texts of its diffs.
+func (dmp *DiffMatchPatch) PatchValidate(patches []Patch) (err error) {
+	defer dmp.recoverInternal(&err)
+	for i, aPatch := range patches {
+		if aPatch.Start1 < 0 || aPatch.Start2 < 0 || aPatch.Length1 < 0 || aPatch.Length2 < 0 {
+			return fmt.Errorf("Patch %d has a negative position or length", i)
+		}
+		for _, aDiff := range aPatch.Diffs {
+			if !aDiff.Type.valid() {
+				return fmt.Errorf("Invalid diff operation in patch %d: %v", i, aDiff.Type)
+			}
+			if len(aDiff.Text) == 0 {
+				return fmt.Errorf("Empty diff in patch %d", i)
+			}
+		}
+		if length1 := len(dmp.DiffText1(aPatch.Diffs)); length1 != aPatch.Length1 {
+			return fmt.Errorf("Patch %d has a source length of %d but its diffs have %d", i, aPatch.Length1, length1)
+		}
+		if length2 := len(dmp.DiffText2(aPatch.Diffs)); length2 != aPatch.Length2 {
+			return fmt.Errorf("Patch %d has a destination length of %d but its diffs have %d", i, aPatch.Length2, length2)
+		}
+	}
+	return nil
+}
+
+// PatchApplyChecked merges a set of patches onto the text like PatchApply, but first checks that the patches are consistent with themselves and with the text.
+// Returns the unmodified text and an error if PatchValidate rejects the patches, or if a patch expects text beyond the end of the text as modified by the preceding patches.
+func (dmp *DiffMatchPatch) PatchApplyChecked(patches []Patch, text string) (_ string, _ []bool, err error) {
+	defer dmp.recoverInternal(&err)
+	if err := dmp.PatchValidate(patches); err != nil {
+		return text, nil, err
+	}
+	if err := patchCheckBounds(patches, len(text)); err != nil {
+		return text, nil, err
+	}
+	return dmp.PatchApplyWithOptions(patches, text, DefaultPatchApplyOptions())
+}
+
+// patchCheckBounds checks that the text each patch expects lies within a text of textLen bytes, as modified by the preceding patches.
+func patchCheckBounds(patches []Patch, textLen int) error {
+	for i, aPatch := range patches {
+		if aPatch.Start2 > textLen || aPatch.Length1 > textLen-aPatch.Start2 {
+			return fmt.Errorf("Patch %d expects %d bytes at offset %d of a text of %d bytes", i, aPatch.Length1, aPatch.Start2, textLen)
+		}
+		textLen += aPatch.Length2 - aPatch.Length1
+	}
+	return nil
+}
+
+// PatchApplyRunes merges a set of patches onto a text given as runes, like PatchApply.  Returns the patched text, as well as the placements of the patches, in runes rather than bytes.
+func (dmp *DiffMatchPatch) PatchApplyRunes(patches []Patch, text []rune) ([]rune, []PatchPlacement) {
+	runes := *dmp
+	runes.runePlacements = true
+	patched, placements, _ := runes.patchApply(patches, string(text), DefaultPatchApplyOptions())
+	return []rune(patched), placements
+}
+
+// runeDistance returns the number of runes from the byte offset from to the byte offset to of text, negative if to comes first.  Offsets beyond the end of the text count one rune per byte.
+func runeDistance(text string, from, to int) int {
+	runeOffset := func(i int) int {
+		return utf8.RuneCountInString(text[:min(i, len(text))]) + max(0, i-len(text))
+	}
+	return runeOffset(to) - runeOffset(from)
+}
+
+// patchApply merges a set of patches onto the text and reports where each of them was placed, and the index of the patch at which a hook aborted the application, or -1.
+func (dmp *DiffMatchPatch) patchApply(patches []Patch, text string, opts PatchApplyOptions) (string, []PatchPlacement, int) {
 	if len(patches) == 0 {
-		return text, []bool{}
+		return text, []PatchPlacement{}, -1
+	}
+	// The hooks get the patches as given.
+	given := patches
+
+	// Choose the patches before they are modified.
+	var selected []bool
+	if opts.Selector != nil {
+		selected = make([]bool, len(patches))
+		for i, aPatch := range patches {
+			selected[i] = opts.Selector(i, aPatch)
+		}
 	}
 
 	// Deep copy the patches so that no changes are made to originals.
 	patches = dmp.PatchDeepCopy(patches)
+	if dmp.IgnoreLineEndings {
+		dmp.patchLineEndings(patches, text)
+	}
 
-	nullPadding := dmp.PatchAddPadding(patches)
+	nullPadding := dmp.patchAddPadding(patches, len(text))
 	text = nullPadding + text + nullPadding
-	patches = dmp.PatchSplitMax(patches)
+	// Split the patches, remembering which patch each piece comes from.
+	var origins []int
+	split := make([]Patch, 0, len(patches))
+	for i, aPatch := range patches {
+		pieces := dmp.PatchSplitMax([]Patch{aPatch})
+		for range pieces {
+			origins = append(origins, i)
+		}
+		split = append(split, pieces...)
+	}
+	patches = split
 
 	x := 0
 	// delta keeps track of the offset between the expected and actual location of the previous patch.  If there are patches expected at positions 10 and 20, but the first patch was found at 12, delta is 2 and the second patch has an effective expected position of 22.
 	delta := 0
 	results := make([]bool, len(patches))
+	placements := make([]PatchPlacement, len(patches))
+	for i := range placements {
+		placements[i].Location = -1
+	}
+	aborted := -1
 	for _, aPatch := range patches {
+		if selected != nil && !selected[origins[x]] {
+			// Skip the patch like one which does not apply, so that the following patches are expected where they would have been.
+			placements[x].Skipped = true
+			delta -= aPatch.Length2 - aPatch.Length1
+			x++
+			continue
+		}
 		expectedLoc := aPatch.Start2 + delta
-		text1 := dmp.DiffText1(aPatch.diffs)
+		text1 := dmp.DiffText1(aPatch.Diffs)
 		var startLoc int
 		endLoc := -1
-		if len(text1) > dmp.MatchMaxBits {
-			// PatchSplitMax will only provide an oversized pattern in the case of a monster delete.
-			startLoc = dmp.MatchMain(text, text1[:dmp.MatchMaxBits], expectedLoc)
+		// End of the matched text, if it differs in length from text1.
+		matchEnd := -1
+		if opts.Strategy == MatchStrategyExact {
+			startLoc = patchMatchExact(text, text1, expectedLoc)
+		} else if opts.Strategy == MatchStrategyLevenshtein {
+			maxErrors := opts.Fuzz
+			if maxErrors < 0 {
+				maxErrors = int(dmp.MatchThreshold * float64(utf8.RuneCountInString(text1)))
+			}
+			var length int
+			startLoc, length = patchMatchWindow(text, text1, expectedLoc, maxErrors, opts.MaxOffset)
+			matchEnd = startLoc + length
+		} else if opts.Fuzz >= 0 {
+			var length int
+			startLoc, length = dmp.patchMatchFuzzy(text, text1, expectedLoc, opts.Fuzz)
+			matchEnd = startLoc + length
+		} else if dmp.MatchMaxBits > 0 && len(text1) > dmp.MatchMaxBits {
+			// PatchSplitMax will only provide an oversized pattern in the case of a monster delete.  Its ends are matched as whole runes.
+			prefix := text1[:runeStart(text1, dmp.MatchMaxBits)]
+			suffixStart := len(text1) - dmp.MatchMaxBits
+			for suffixStart < len(text1) && !utf8.RuneStart(text1[suffixStart]) {
+				suffixStart++
+			}
+			startLoc = dmp.MatchMain(text, prefix, expectedLoc)
 			if startLoc != -1 {
-				endLoc = dmp.MatchMain(text,
-					text1[len(text1)-dmp.MatchMaxBits:], expectedLoc+len(text1)-dmp.MatchMaxBits)
+				endLoc = dmp.MatchMain(text, text1[suffixStart:], expectedLoc+suffixStart)
 				if endLoc == -1 || startLoc >= endLoc {
 					// Can't find valid trailing context.  Drop this patch.
 					startLoc = -1
 				}
+				matchEnd = endLoc + len(text1) - suffixStart
 			}
 		} else {
 			startLoc = dmp.MatchMain(text, text1, expectedLoc)
 		}
+		if startLoc != -1 && opts.MaxOffset >= 0 && abs(startLoc-expectedLoc) > opts.MaxOffset {
+			// Found too far away from where the patch expects to be.
+			startLoc = -1
+		}
+		// Text found for the patch, without the padding.
+		var found string
+		textLen := len(text) - 2*len(nullPadding)
 		if startLoc == -1 {
 			// No match found.  :(
 			results[x] = false
+			location := runeStart(text, min(max(expectedLoc, len(nullPadding)), len(nullPadding)+textLen))
+			found = text[location:runeStart(text, min(location+len(text1), len(nullPadding)+textLen))]
 			// Subtract the delta for this failed patch from subsequent patches.
 			delta -= aPatch.Length2 - aPatch.Length1
 		} else {
@@ -274,34 +636,53 @@ func (dmp *DiffMatchPatch) PatchApply(patches []Patch, text string) (string, []b
 			results[x] = true
 			delta = startLoc - expectedLoc
 			var text2 string
-			if endLoc == -1 {
+			if matchEnd == -1 {
 				text2 = text[startLoc:int(math.Min(float64(startLoc+len(text1)), float64(len(text))))]
 			} else {
-				text2 = text[startLoc:int(math.Min(float64(endLoc+dmp.MatchMaxBits), float64(len(text))))]
+				text2 = text[startLoc:int(math.Min(float64(matchEnd), float64(len(text))))]
+			}
+			// Report the placement without the padding.
+			location := min(max(startLoc-len(nullPadding), 0), textLen)
+			placements[x] = PatchPlacement{
+				Location: location,
+				Length:   min(max(startLoc+len(text2)-len(nullPadding), 0), textLen) - location,
+				Offset:   startLoc - aPatch.Start2,
+			}
+			found = text[len(nullPadding)+location : len(nullPadding)+location+placements[x].Length]
+			if dmp.runePlacements {
+				unpadded := text[len(nullPadding) : len(nullPadding)+textLen]
+				placements[x] = PatchPlacement{
+					Location: utf8.RuneCountInString(unpadded[:location]),
+					Length:   utf8.RuneCountInString(unpadded[location : location+placements[x].Length]),
+					Offset:   runeDistance(text, aPatch.Start2, startLoc),
+				}
 			}
 			if text1 == text2 {
 				// Perfect match, just shove the Replacement text in.
-				text = text[:startLoc] + dmp.DiffText2(aPatch.diffs) + text[startLoc+len(text1):]
+				text = text[:startLoc] + dmp.DiffText2(aPatch.Diffs) + text[startLoc+len(text1):]
 			} else {
 				// Imperfect match.  Run a diff to get a framework of equivalent indices.
-				diffs := dmp.DiffMain(text1, text2, false)
-				if len(text1) > dmp.MatchMaxBits && float64(dmp.DiffLevenshtein(diffs))/float64(len(text1)) > dmp.PatchDeleteThreshold {
+				// The diff has to be exact, regardless of which differences DiffMain ignores.
+				diffs := runeDiffsToDiffs(dmp.diffRunes([]rune(text1), []rune(text2), false))
+				if opts.Strategy == MatchStrategyBitap && opts.Fuzz < 0 && dmp.MatchMaxBits > 0 && len(text1) > dmp.MatchMaxBits && float64(dmp.DiffLevenshtein(diffs))/float64(len(text1)) > dmp.PatchDeleteThreshold {
 					// The end points match, but the content is unacceptably bad.
 					results[x] = false
 				} else {
 					diffs = dmp.DiffCleanupSemanticLossless(diffs)
+					// The earlier changes of this patch can shift the indices beyond the end of the text, or inside a rune.  Keep them between the padding and on rune boundaries.
+					textIndex := func(index2 int) int {
+						return max(runeStart(text, min(startLoc+index2, len(text)-len(nullPadding))), len(nullPadding))
+					}
 					index1 := 0
-					for _, aDiff := range aPatch.diffs {
+					for _, aDiff := range aPatch.Diffs {
 						if aDiff.Type != DiffEqual {
-							index2 := dmp.DiffXIndex(diffs, index1)
+							startIndex := textIndex(dmp.DiffXIndex(diffs, index1))
 							if aDiff.Type == DiffInsert {
 								// Insertion
-								text = text[:startLoc+index2] + aDiff.Text + text[startLoc+index2:]
+								text = text[:startIndex] + aDiff.Text + text[startIndex:]
 							} else if aDiff.Type == DiffDelete {
 								// Deletion
-								startIndex := startLoc + index2
-								text = text[:startIndex] +
-									text[startIndex+dmp.DiffXIndex(diffs, index1+len(aDiff.Text))-index2:]
+								text = text[:startIndex] + text[textIndex(dmp.DiffXIndex(diffs, index1+len(aDiff.Text))):]
 							}
 						}
 						if aDiff.Type != DiffDelete {
@@ -311,21 +692,133 @@ func (dmp *DiffMatchPatch) PatchApply(patches []Patch, text string) (string, []b
 				}
 			}
 		}
+		placements[x].Applied = results[x]
+		hook := opts.OnHunkFailed
+		if results[x] {
+			hook = opts.OnHunkApplied
+		}
+		if hook != nil && !hook(HunkEvent{Index: origins[x], Patch: given[origins[x]], Placement: placements[x], Found: found}) {
+			aborted = origins[x]
+			break
+		}
+		if !results[x] && opts.FailFast {
+			break
+		}
 		x++
 	}
 	// Strip the padding off.
 	text = text[len(nullPadding) : len(nullPadding)+(len(text)-2*len(nullPadding))]
-	return text, results
+	return text, placements, aborted
+}
+
+// patchMatchFuzzy locates the occurrence of pattern with at most fuzz errors which is closest to loc.  Returns its location and length in bytes, or -1 if there is none.
+func (dmp *DiffMatchPatch) patchMatchFuzzy(text, pattern string, loc int, fuzz int) (int, int) {
+	bestLoc, bestLength := -1, 0
+	for _, match := range dmp.MatchAll(text, pattern, fuzz) {
+		if bestLoc == -1 || abs(match.Location-loc) < abs(bestLoc-loc) {
+			bestLoc, bestLength = match.Location, match.Length
+		}
+	}
+	return bestLoc, bestLength
+}
+
+// patchMatchExact returns the location of the occurrence of pattern nearest to loc, or -1 if there is none.
+func patchMatchExact(text, pattern string, loc int) int {
+	bestLoc := -1
+	for start := 0; start <= len(text); {
+		i := strings.Index(text[start:], pattern)
+		if i == -1 {
+			break
+		}
+		if bestLoc == -1 || abs(start+i-loc) < abs(bestLoc-loc) {
+			bestLoc = start + i
+		}
+		if start+i > loc {
+			// Later occurrences are farther away.
+			break
+		}
+		start += i + 1
+	}
+	return bestLoc
+}
+
+// patchMatchWindow returns the location and length in bytes of the window of text of as many runes as pattern which is the fewest edits from pattern and nearest to loc, or -1 if every window within maxOffset bytes of loc is more than maxErrors edits away.
+func patchMatchWindow(text, pattern string, loc, maxErrors, maxOffset int) (int, int) {
+	patternRunes := []rune(pattern)
+	// Byte offsets of the runes of the text.
+	offsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+	runes := len(offsets) - 1
+	width := min(len(patternRunes), runes)
+
+	bestLoc, bestEnd, bestErrors := -1, 0, maxErrors
+	row := make([]int, width+1)
+	for s := 0; s+width <= runes; s++ {
+		start, end := offsets[s], offsets[s+width]
+		if maxOffset >= 0 && abs(start-loc) > maxOffset {
+			continue
+		}
+		edits := windowDistance(patternRunes, []rune(text[start:end]), row, bestErrors)
+		if edits < bestErrors || edits == bestErrors && (bestLoc == -1 || abs(start-loc) < abs(bestLoc-loc)) {
+			bestLoc, bestEnd, bestErrors = start, end, edits
+		}
+	}
+	return bestLoc, bestEnd - bestLoc
+}
+
+// windowDistance returns the Levenshtein distance between pattern and window, or a value larger than limit as soon as it exceeds limit.
+func windowDistance(pattern, window []rune, row []int, limit int) int {
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(pattern); i++ {
+		diag := row[0]
+		row[0] = i
+		rowMin := row[0]
+		for j := 1; j <= len(window); j++ {
+			cost := diag
+			if pattern[i-1] != window[j-1] {
+				cost++
+			}
+			diag = row[j]
+			cost = min(cost, min(row[j], row[j-1])+1)
+			row[j] = cost
+			rowMin = min(rowMin, cost)
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+	}
+	return row[len(window)]
 }
 
 // PatchAddPadding adds some padding on text start and end so that edges can match something.
 // Intended to be called only from within patchApply.
 func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
+	return dmp.patchAddPadding(patches, -1)
+}
+
+// patchAddPadding adds padding to the patches which reach the start or the end of a text of textLen bytes, or to the last patch if textLen is -1.
+// Patches without context, as made by PatchMakeWithOptions, do not reach the edges of the text just because they lack context.
+func (dmp *DiffMatchPatch) patchAddPadding(patches []Patch, textLen int) string {
 	paddingLength := dmp.PatchMargin
 	nullPadding := ""
 	for x := 1; x <= paddingLength; x++ {
 		nullPadding += string(rune(x))
 	}
+	padStart := patches[0].Start2 == 0
+	padEnd := true
+	if textLen >= 0 {
+		// The last patch applies to the text as changed by the patches before it.
+		for _, aPatch := range patches[:len(patches)-1] {
+			textLen += aPatch.Length2 - aPatch.Length1
+		}
+		last := patches[len(patches)-1]
+		padEnd = last.Start2+last.Length1 >= textLen
+	}
 
 	// Bump all the patches forward.
 	for i := range patches {
@@ -334,17 +827,19 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 	}
 
 	// Add some padding on start of first diff.
-	if len(patches[0].diffs) == 0 || patches[0].diffs[0].Type != DiffEqual {
+	if !padStart {
+		// The first patch does not reach the start of the text.
+	} else if len(patches[0].Diffs) == 0 || patches[0].Diffs[0].Type != DiffEqual {
 		// Add nullPadding equality.
-		patches[0].diffs = append([]Diff{Diff{DiffEqual, nullPadding}}, patches[0].diffs...)
+		patches[0].Diffs = append([]Diff{Diff{DiffEqual, nullPadding}}, patches[0].Diffs...)
 		patches[0].Start1 -= paddingLength // Should be 0.
 		patches[0].Start2 -= paddingLength // Should be 0.
 		patches[0].Length1 += paddingLength
 		patches[0].Length2 += paddingLength
-	} else if paddingLength > len(patches[0].diffs[0].Text) {
+	} else if paddingLength > len(patches[0].Diffs[0].Text) {
 		// Grow first equality.
-		extraLength := paddingLength - len(patches[0].diffs[0].Text)
-		patches[0].diffs[0].Text = nullPadding[len(patches[0].diffs[0].Text):] + patches[0].diffs[0].Text
+		extraLength := paddingLength - len(patches[0].Diffs[0].Text)
+		patches[0].Diffs[0].Text = nullPadding[len(patches[0].Diffs[0].Text):] + patches[0].Diffs[0].Text
 		patches[0].Start1 -= extraLength
 		patches[0].Start2 -= extraLength
 		patches[0].Length1 += extraLength
@@ -353,16 +848,18 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 
 	// Add some padding on end of last diff.
 	last := len(patches) - 1
-	if len(patches[last].diffs) == 0 || patches[last].diffs[len(patches[last].diffs)-1].Type != DiffEqual {
+	if !padEnd {
+		// The last patch does not reach the end of the text.
+	} else if len(patches[last].Diffs) == 0 || patches[last].Diffs[len(patches[last].Diffs)-1].Type != DiffEqual {
 		// Add nullPadding equality.
-		patches[last].diffs = append(patches[last].diffs, Diff{DiffEqual, nullPadding})
+		patches[last].Diffs = append(patches[last].Diffs, Diff{DiffEqual, nullPadding})
 		patches[last].Length1 += paddingLength
 		patches[last].Length2 += paddingLength
-	} else if paddingLength > len(patches[last].diffs[len(patches[last].diffs)-1].Text) {
+	} else if paddingLength > len(patches[last].Diffs[len(patches[last].Diffs)-1].Text) {
 		// Grow last equality.
-		lastDiff := patches[last].diffs[len(patches[last].diffs)-1]
+		lastDiff := patches[last].Diffs[len(patches[last].Diffs)-1]
 		extraLength := paddingLength - len(lastDiff.Text)
-		patches[last].diffs[len(patches[last].diffs)-1].Text += nullPadding[:extraLength]
+		patches[last].Diffs[len(patches[last].Diffs)-1].Text += nullPadding[:extraLength]
 		patches[last].Length1 += extraLength
 		patches[last].Length2 += extraLength
 	}
@@ -370,101 +867,158 @@ func (dmp *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
 	return nullPadding
 }
 
-// PatchSplitMax looks through the patches and breaks up any which are longer than the maximum limit of the match algorithm.
-// Intended to be called only from within patchApply.
+// PatchSplitMax looks through the patches and breaks up any which are longer than the maximum limit of the match algorithm, MatchMaxBits.
+// The patches are not modified, the split patches are returned in a new slice.
 func (dmp *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
-	patchSize := dmp.MatchMaxBits
-	for x := 0; x < len(patches); x++ {
-		if patches[x].Length1 <= patchSize {
-			continue
+	return dmp.PatchSplitMaxSize(patches, dmp.MatchMaxBits)
+}
+
+// PatchSplitMaxSize breaks up the patches which cover more than maxSize bytes of the source text, 0 for no limit, into smaller patches with PatchMargin bytes of context.  The patches are not modified, the split patches are returned in a new slice.
+// Patches are split between runes, and a maxSize which leaves no room for changes besides the context is raised to 2*PatchMargin+1.
+func (dmp *DiffMatchPatch) PatchSplitMaxSize(patches []Patch, maxSize int) []Patch {
+	if maxSize > 0 {
+		maxSize = max(maxSize, 2*dmp.PatchMargin+1)
+	}
+	split := make([]Patch, 0, len(patches))
+	for _, aPatch := range patches {
+		if maxSize <= 0 || aPatch.Length1 <= maxSize {
+			split = append(split, aPatch)
+		} else {
+			split = dmp.patchSplit(split, aPatch, maxSize)
 		}
-		bigpatch := patches[x]
-		// Remove the big old patch.
-		patches = append(patches[:x], patches[x+1:]...)
-		x--
-
-		Start1 := bigpatch.Start1
-		Start2 := bigpatch.Start2
-		precontext := ""
-		for len(bigpatch.diffs) != 0 {
-			// Create one of several smaller patches.
-			patch := Patch{}
-			empty := true
-			patch.Start1 = Start1 - len(precontext)
-			patch.Start2 = Start2 - len(precontext)
-			if len(precontext) != 0 {
-				patch.Length1 = len(precontext)
-				patch.Length2 = len(precontext)
-				patch.diffs = append(patch.diffs, Diff{DiffEqual, precontext})
-			}
-			for len(bigpatch.diffs) != 0 && patch.Length1 < patchSize-dmp.PatchMargin {
-				diffType := bigpatch.diffs[0].Type
-				diffText := bigpatch.diffs[0].Text
-				if diffType == DiffInsert {
-					// Insertions are harmless.
-					patch.Length2 += len(diffText)
-					Start2 += len(diffText)
-					patch.diffs = append(patch.diffs, bigpatch.diffs[0])
-					bigpatch.diffs = bigpatch.diffs[1:]
-					empty = false
-				} else if diffType == DiffDelete && len(patch.diffs) == 1 && patch.diffs[0].Type == DiffEqual && len(diffText) > 2*patchSize {
-					// This is a large deletion.  Let it pass in one chunk.
-					patch.Length1 += len(diffText)
-					Start1 += len(diffText)
-					empty = false
-					patch.diffs = append(patch.diffs, Diff{diffType, diffText})
-					bigpatch.diffs = bigpatch.diffs[1:]
-				} else {
-					// Deletion or equality.  Only take as much as we can stomach.
-					diffText = diffText[:min(len(diffText), patchSize-patch.Length1-dmp.PatchMargin)]
-
-					patch.Length1 += len(diffText)
-					Start1 += len(diffText)
-					if diffType == DiffEqual {
-						patch.Length2 += len(diffText)
-						Start2 += len(diffText)
-					} else {
-						empty = false
-					}
-					patch.diffs = append(patch.diffs, Diff{diffType, diffText})
-					if diffText == bigpatch.diffs[0].Text {
-						bigpatch.diffs = bigpatch.diffs[1:]
-					} else {
-						bigpatch.diffs[0].Text =
-							bigpatch.diffs[0].Text[len(diffText):]
+	}
+	return split
+}
+
+// patchSplit appends the pieces of bigpatch, none of which is longer than patchSize unless it is a single large deletion, to patches.
+func (dmp *DiffMatchPatch) patchSplit(patches []Patch, bigpatch Patch, patchSize int) []Patch {
+	// Copy the diffs since their texts are consumed below.
+	diffs := append([]Diff(nil), bigpatch.Diffs...)
+	start1 := bigpatch.Start1
+	start2 := bigpatch.Start2
+	precontext := ""
+	for len(diffs) != 0 {
+		// Create one of several smaller patches.
+		patch := Patch{}
+		empty := true
+		patch.Start1 = start1 - len(precontext)
+		patch.Start2 = start2 - len(precontext)
+		if len(precontext) != 0 {
+			patch.Length1 = len(precontext)
+			patch.Length2 = len(precontext)
+			patch.Diffs = append(patch.Diffs, Diff{DiffEqual, precontext})
+		}
+		for len(diffs) != 0 && patch.Length1 < patchSize-dmp.PatchMargin {
+			diffType := diffs[0].Type
+			diffText := diffs[0].Text
+			if diffType == DiffInsert {
+				// Insertions are harmless.
+				patch.Length2 += len(diffText)
+				start2 += len(diffText)
+				patch.Diffs = append(patch.Diffs, diffs[0])
+				diffs = diffs[1:]
+				empty = false
+			} else if diffType == DiffDelete && len(patch.Diffs) == 1 && patch.Diffs[0].Type == DiffEqual && len(diffText) > 2*patchSize {
+				// This is a large deletion.  Let it pass in one chunk.
+				patch.Length1 += len(diffText)
+				start1 += len(diffText)
+				empty = false
+				patch.Diffs = append(patch.Diffs, Diff{diffType, diffText})
+				diffs = diffs[1:]
+			} else {
+				// Deletion or equality.  Only take as much as we can stomach, without splitting a rune.
+				if n := patchSize - patch.Length1 - dmp.PatchMargin; n < len(diffText) {
+					n = runeStart(diffText, n)
+					if n == 0 {
+						if len(patch.Diffs) > 1 || len(patch.Diffs) == 1 && len(precontext) == 0 {
+							// The next rune does not fit, leave it to the next patch.
+							break
+						}
+						// There is nothing but context yet, so take a whole rune anyway.
+						_, n = utf8.DecodeRuneInString(diffText)
 					}
+					diffText = diffText[:n]
 				}
-			}
-			// Compute the head context for the next patch.
-			precontext = dmp.DiffText2(patch.diffs)
-			precontext = precontext[max(0, len(precontext)-dmp.PatchMargin):]
-
-			postcontext := ""
-			// Append the end context for this patch.
-			if len(dmp.DiffText1(bigpatch.diffs)) > dmp.PatchMargin {
-				postcontext = dmp.DiffText1(bigpatch.diffs)[:dmp.PatchMargin]
-			} else {
-				postcontext = dmp.DiffText1(bigpatch.diffs)
-			}
 
-			if len(postcontext) != 0 {
-				patch.Length1 += len(postcontext)
-				patch.Length2 += len(postcontext)
-				if len(patch.diffs) != 0 && patch.diffs[len(patch.diffs)-1].Type == DiffEqual {
-					patch.diffs[len(patch.diffs)-1].Text += postcontext
+				patch.Length1 += len(diffText)
+				start1 += len(diffText)
+				if diffType == DiffEqual {
+					patch.Length2 += len(diffText)
+					start2 += len(diffText)
 				} else {
-					patch.diffs = append(patch.diffs, Diff{DiffEqual, postcontext})
+					empty = false
+				}
+				patch.Diffs = append(patch.Diffs, Diff{diffType, diffText})
+				if len(diffText) == len(diffs[0].Text) {
+					diffs = diffs[1:]
+				} else {
+					diffs[0].Text = diffs[0].Text[len(diffText):]
 				}
 			}
-			if !empty {
-				x++
-				patches = append(patches[:x], append([]Patch{patch}, patches[x:]...)...)
-			}
+		}
+		// Compute the head context for the next patch.
+		precontext = dmp.patchPrecontext(dmp.DiffText2(patch.Diffs))
+		// Append the end context for this patch.
+		patch = appendPostcontext(patch, dmp.patchPostcontext(dmp.DiffText1(diffs)))
+		if !empty {
+			patches = append(patches, patch)
 		}
 	}
 	return patches
 }
 
+// PatchSplitHunk splits a patch between its diffs at and at-1 into two patches, each with context of PatchMargin bytes towards the other, e.g. to let the user of a review tool choose finer parts of a patch.
+// Like the patches of PatchMake, the second patch has the context of the text produced by the first one.  If at is not strictly inside the diffs, p is returned along with an empty patch.
+func (dmp *DiffMatchPatch) PatchSplitHunk(p Patch, at int) (Patch, Patch) {
+	if at <= 0 || at >= len(p.Diffs) {
+		return p, Patch{}
+	}
+	head := p.Diffs[:at]
+	tail := p.Diffs[at:]
+
+	first := NewPatch(p.Start1, p.Start2, append([]Diff{}, head...))
+	first = appendPostcontext(first, dmp.patchPostcontext(dmp.DiffText1(tail)))
+
+	precontext := dmp.patchPrecontext(dmp.DiffText2(head))
+	var diffs []Diff
+	if tail[0].Type == DiffEqual {
+		diffs = append([]Diff{{DiffEqual, precontext + tail[0].Text}}, tail[1:]...)
+	} else {
+		diffs = append([]Diff{{DiffEqual, precontext}}, tail...)
+	}
+	second := NewPatch(p.Start1+len(dmp.DiffText1(head))-len(precontext), p.Start2+len(dmp.DiffText2(head))-len(precontext), dmp.DiffCanonicalize(diffs))
+	return first, second
+}
+
+// patchPrecontext returns the end of text, up to PatchMargin bytes of whole runes, to lead a patch.
+func (dmp *DiffMatchPatch) patchPrecontext(text string) string {
+	start := max(0, len(text)-dmp.PatchMargin)
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	return text[start:]
+}
+
+// patchPostcontext returns the start of text, up to PatchMargin bytes of whole runes, to end a patch.
+func (dmp *DiffMatchPatch) patchPostcontext(text string) string {
+	return text[:runeStart(text, min(len(text), dmp.PatchMargin))]
+}
+
+// appendPostcontext appends postcontext to the diffs of patch as an equality.
+func appendPostcontext(patch Patch, postcontext string) Patch {
+	if len(postcontext) == 0 {
+		return patch
+	}
+	patch.Length1 += len(postcontext)
+	patch.Length2 += len(postcontext)
+	if len(patch.Diffs) != 0 && patch.Diffs[len(patch.Diffs)-1].Type == DiffEqual {
+		patch.Diffs[len(patch.Diffs)-1].Text += postcontext
+	} else {
+		patch.Diffs = append(patch.Diffs, Diff{DiffEqual, postcontext})
+	}
+	return patch
+}
+
 // PatchToText takes a list of patches and returns a textual representation.
 func (dmp *DiffMatchPatch) PatchToText(patches []Patch) string {
 	var text bytes.Buffer
@@ -475,7 +1029,8 @@ func (dmp *DiffMatchPatch) PatchToText(patches []Patch) string {
 }
 
 // PatchFromText parses a textual representation of patches and returns a List of Patch objects.
-func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
+func (dmp *DiffMatchPatch) PatchFromText(textline string) (_ []Patch, err error) {
+	defer dmp.recoverInternal(&err)
 	patches := []Patch{}
 	if len(textline) == 0 {
 		return patches, nil
@@ -496,7 +1051,20 @@ func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
 		patch = Patch{}
 		m := patchHeader.FindStringSubmatch(text[textPointer])
 
-		patch.Start1, _ = strconv.Atoi(m[1])
+		// The numbers are checked, so that a corrupted header cannot overflow the positions of the patch.
+		var numbers [4]int
+		for i, number := range m[1:] {
+			if len(number) == 0 {
+				continue
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return patches, errors.New("Invalid patch header: " + text[textPointer])
+			}
+			numbers[i] = n
+		}
+
+		patch.Start1 = numbers[0]
 		if len(m[2]) == 0 {
 			patch.Start1--
 			patch.Length1 = 1
@@ -504,10 +1072,10 @@ func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
 			patch.Length1 = 0
 		} else {
 			patch.Start1--
-			patch.Length1, _ = strconv.Atoi(m[2])
+			patch.Length1 = numbers[1]
 		}
 
-		patch.Start2, _ = strconv.Atoi(m[3])
+		patch.Start2 = numbers[2]
 
 		if len(m[4]) == 0 {
 			patch.Start2--
@@ -516,7 +1084,7 @@ func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
 			patch.Length2 = 0
 		} else {
 			patch.Start2--
-			patch.Length2, _ = strconv.Atoi(m[4])
+			patch.Length2 = numbers[3]
 		}
 		textPointer++
 
@@ -530,16 +1098,19 @@ func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
 
 			line = text[textPointer][1:]
 			line = strings.Replace(line, "+", "%2b", -1)
-			line, _ = url.QueryUnescape(line)
+			var err error
+			if line, err = url.QueryUnescape(line); err != nil {
+				return patches, errors.New("Invalid escape in patch: " + text[textPointer])
+			}
 			if sign == '-' {
 				// Deletion.
-				patch.diffs = append(patch.diffs, Diff{DiffDelete, line})
+				patch.Diffs = append(patch.Diffs, Diff{DiffDelete, line})
 			} else if sign == '+' {
 				// Insertion.
-				patch.diffs = append(patch.diffs, Diff{DiffInsert, line})
+				patch.Diffs = append(patch.Diffs, Diff{DiffInsert, line})
 			} else if sign == ' ' {
 				// Minor equality.
-				patch.diffs = append(patch.diffs, Diff{DiffEqual, line})
+				patch.Diffs = append(patch.Diffs, Diff{DiffEqual, line})
 			} else if sign == '@' {
 				// Start of next patch.
 				break
@@ -550,7 +1121,58 @@ func (dmp *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
 			textPointer++
 		}
 
+		if !validRuneBoundaries(patch.Diffs) {
+			if dmp.StrictUTF8 {
+				return patches, fmt.Errorf("Invalid patch %d: %v", len(patches), checkRuneBoundaries(patch.Diffs))
+			}
+			patch = NewPatch(patch.Start1, patch.Start2, repairRuneBoundaries(patch.Diffs))
+		}
 		patches = append(patches, patch)
 	}
 	return patches, nil
 }
+
+// patchLineEndings converts the line endings of patches to the prevailing line endings of the text they are applied to.
+func (dmp *DiffMatchPatch) patchLineEndings(patches []Patch, text string) {
+	crlf := DetectEOL(text) == EOLCRLF
+	for i := range patches {
+		aPatch := &patches[i]
+		patchCRLF := false
+		for j, aDiff := range aPatch.Diffs {
+			if strings.Contains(aDiff.Text, "\r\n") {
+				patchCRLF = true
+			}
+			aDiff.Text = strings.Replace(aDiff.Text, "\r\n", "\n", -1)
+			if crlf {
+				aDiff.Text = strings.Replace(aDiff.Text, "\n", "\r\n", -1)
+			}
+			aPatch.Diffs[j] = aDiff
+		}
+		if patchCRLF != crlf {
+			// Locate the patch in the text as if the text had the line endings of the patch.
+			aPatch.Start1 = lineEndingsOffset(text, aPatch.Start1, patchCRLF)
+			aPatch.Start2 = lineEndingsOffset(text, aPatch.Start2, patchCRLF)
+		}
+		aPatch.Length1 = len(dmp.DiffText1(aPatch.Diffs))
+		aPatch.Length2 = len(dmp.DiffText2(aPatch.Diffs))
+	}
+}
+
+// lineEndingsOffset converts an offset into text with either \r\n or \n line endings to an offset into text with its own line endings.
+func lineEndingsOffset(text string, offset int, crlf bool) int {
+	position := 0
+	for i := 0; i < len(text); i++ {
+		if position >= offset {
+			return i
+		}
+		switch {
+		case text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' && !crlf:
+			// Not part of the text with \n line endings.
+		case text[i] == '\n' && crlf && (i == 0 || text[i-1] != '\r'):
+			position += 2
+		default:
+			position++
+		}
+	}
+	return len(text)
+}
@@ -9,6 +9,7 @@
 package diffmatchpatch
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -31,7 +32,7 @@ func TestPatchString(t *testing.T) {
 				Length1: 18,
 				Length2: 17,
 
-				diffs: []Diff{
+				Diffs: []Diff{
 					{DiffEqual, "jump"},
 					{DiffDelete, "s"},
 					{DiffInsert, "ed"},
@@ -50,6 +51,28 @@ func TestPatchString(t *testing.T) {
 	}
 }
 
+func TestNewPatch(t *testing.T) {
+	dmp := New()
+
+	diffs := []Diff{
+		{DiffEqual, "jump"},
+		{DiffDelete, "s"},
+		{DiffInsert, "ed"},
+		{DiffEqual, " over "},
+		{DiffDelete, "the"},
+		{DiffInsert, "a"},
+		{DiffEqual, "\nlaz"},
+	}
+	patch := NewPatch(20, 21, diffs)
+	assert.Equal(t, Patch{Diffs: diffs, Start1: 20, Start2: 21, Length1: 18, Length2: 17}, patch)
+	assert.Equal(t, "@@ -21,18 +22,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n %0Alaz\n", patch.String())
+
+	// Patches built by hand apply like computed ones.
+	actual, applies := dmp.PatchApply([]Patch{NewPatch(4, 4, []Diff{{DiffEqual, "quic"}, {DiffInsert, "k"}, {DiffEqual, " bro"}})}, "The quic brown fox.")
+	assert.Equal(t, "The quick brown fox.", actual)
+	assert.Equal(t, []bool{true}, applies)
+}
+
 func TestPatchFromText(t *testing.T) {
 	type TestCase struct {
 		Patch string
@@ -67,6 +90,8 @@ func TestPatchFromText(t *testing.T) {
 		{"@@ -0,0 +1,3 @@\n+abc\n", ""},
 		{"@@ _0,0 +0,0 @@\n+abc\n", "Invalid patch string: @@ _0,0 +0,0 @@"},
 		{"Bad\nPatch\n", "Invalid patch string"},
+		{"@@ -1,99999999999999999999 +1 @@\n x\n", "Invalid patch header: @@ -1,99999999999999999999 +1 @@"},
+		{"@@ -1 +1 @@\n-%zz\n", "Invalid escape in patch: -%zz"},
 	} {
 		patches, err := dmp.PatchFromText(tc.Patch)
 		if tc.ErrorMessagePrefix == "" {
@@ -94,7 +119,7 @@ func TestPatchFromText(t *testing.T) {
 	patches, err := dmp.PatchFromText("@@ -1,21 +1,21 @@\n-%601234567890-=%5B%5D%5C;',./\n+~!@#$%25%5E&*()_+%7B%7D%7C:%22%3C%3E?\n")
 	assert.Len(t, patches, 1)
 	assert.Equal(t, diffs,
-		patches[0].diffs,
+		patches[0].Diffs,
 	)
 	assert.Nil(t, err)
 }
@@ -136,6 +161,7 @@ func TestPatchAddContext(t *testing.T) {
 		{"Not enough trailing context", "@@ -21,4 +21,10 @@\n-jump\n+somersault\n", "The quick brown fox jumps.", "@@ -17,10 +17,16 @@\n fox \n-jump\n+somersault\n s.\n"},
 		{"Not enough leading context", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.", "@@ -1,7 +1,8 @@\n Th\n-e\n+at\n  qui\n"},
 		{"Ambiguity", "@@ -3 +3,2 @@\n-e\n+at\n", "The quick brown fox jumps.  The quick brown fox crashes.", "@@ -1,27 +1,28 @@\n Th\n-e\n+at\n  quick brown fox jumps. \n"},
+		{"Context of whole runes", "@@ -7,3 +7,3 @@\n-%E8%AA%9E\n+%E6%96%87\n", "日本語日本", "@@ -1,15 +1,15 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E\n+%E6%96%87\n %E6%97%A5%E6%9C%AC\n"},
 	} {
 		patches, err := dmp.PatchFromText(tc.Patch)
 		assert.Nil(t, err)
@@ -208,6 +234,43 @@ func TestPatchMakeAndPatchToText(t *testing.T) {
 	assert.Equal(t, []Patch{}, patches)
 }
 
+func TestPatchMakeWithOptions(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1   string
+		Text2   string
+		Options PatchOptions
+
+		Expected string
+	}
+
+	dmp := New()
+
+	text1 := "alpha\nbeta\ngamma\ndelta\nepsilon\n"
+	text2 := "alpha\nbeta\ngamma ray\ndelta\nepsilon\n"
+
+	for i, tc := range []TestCase{
+		{"Default", text1, text2, DefaultPatchOptions(), "@@ -9,16 +9,20 @@\n ta%0Agamma\n+ ray\n %0Adelta%0Ae\n"},
+		{"No context", text1, text2, PatchOptions{}, "@@ -16,0 +17,4 @@\n+ ray\n"},
+		{"Characters", text1, text2, PatchOptions{ContextChars: 2}, "@@ -15,4 +15,8 @@\n ma\n+ ray\n %0Ad\n"},
+		{"Lines", text1, text2, PatchOptions{ContextLines: 1}, "@@ -7,17 +7,21 @@\n beta%0Agamma\n+ ray\n %0Adelta%0A\n"},
+		{"Lines beyond the default", text1, text2, PatchOptions{ContextChars: -1, ContextLines: 1}, "@@ -7,18 +7,22 @@\n beta%0Agamma\n+ ray\n %0Adelta%0Ae\n"},
+		{"Lines beyond the text", text1, text2, PatchOptions{ContextLines: 5}, "@@ -1,31 +1,35 @@\n alpha%0Abeta%0Agamma\n+ ray\n %0Adelta%0Aepsilon%0A\n"},
+		{"Lines at the edges", "a\nb\nc\nd\ne\nf\ng\nh\n", "A\nb\nc\nd\ne\nf\ng\nH\n", PatchOptions{ContextLines: 1}, "@@ -1,4 +1,4 @@\n-a\n+A\n %0Ab%0A\n@@ -13,4 +13,4 @@\n g%0A\n-h\n+H\n %0A\n"},
+		{"Whole runes", "日本語", "日本人語", PatchOptions{ContextChars: 1}, "@@ -4,6 +4,9 @@\n %E6%9C%AC\n+%E4%BA%BA\n %E8%AA%9E\n"},
+	} {
+		patches := dmp.PatchMakeWithOptions(tc.Text1, dmp.DiffMain(tc.Text1, tc.Text2, false), tc.Options)
+		assert.Equal(t, tc.Expected, dmp.PatchToText(patches), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, results := dmp.PatchApply(patches, tc.Text1)
+		assert.Equal(t, tc.Text2, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for _, result := range results {
+			assert.True(t, result, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
 func TestPatchSplitMax(t *testing.T) {
 	type TestCase struct {
 		Text1 string
@@ -230,6 +293,131 @@ func TestPatchSplitMax(t *testing.T) {
 		actual := dmp.PatchToText(patches)
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
 	}
+
+	// Patches up to MatchMaxBits long are kept whole.
+	atLimit := []Patch{NewPatch(0, 0, []Diff{{DiffEqual, "ab"}, {DiffDelete, strings.Repeat("x", dmp.MatchMaxBits-4)}, {DiffEqual, "cd"}})}
+	assert.Equal(t, atLimit, dmp.PatchSplitMax(atLimit))
+	aboveLimit := []Patch{NewPatch(0, 0, []Diff{{DiffEqual, "ab"}, {DiffDelete, strings.Repeat("x", dmp.MatchMaxBits-3)}, {DiffEqual, "cd"}})}
+	assert.Len(t, dmp.PatchSplitMax(aboveLimit), 2)
+	dmp.MatchMaxBits = 0
+	assert.Equal(t, aboveLimit, dmp.PatchSplitMax(aboveLimit))
+}
+
+func TestPatchSplitMaxRunes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Emoji", "\né😀😀é\r\n😀é😀\r\n  日 ", "\né😀é "},
+		{"Combining marks", "😀日\tb e\u0301bé\n  éc😀日e\u0301  \t日  \tx日\t日a", "😀x日\t日a"},
+		{"Line endings", "c\r\n\r\n日c😀b日  \néx  😀 e\u0301a\t日c\r\n\n😀xae\u0301é b", "c\r\n\r\n日c😀 e\u0301b"},
+		{"Long deletion", "cbxa😀x  \r\na  日\r\n😀日é😀\t  ", "cbxa😀x  \naé"},
+		{"Rune at the limit", "\t😀日日éa c\r\n😀x\ta😀😀\té😀éa\tc\n😀x é😀", " bé\t\r\n\r\n\r\nc \r\nb日c日😀😀éé\té 日c😀\r\n 😀b\r\n\ta"},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+		for _, aPatch := range dmp.PatchSplitMax(patches) {
+			assert.True(t, aPatch.Length1 <= dmp.MatchMaxBits, fmt.Sprintf("Test case #%d, %s, %q", i, tc.Name, aPatch.String()))
+		}
+
+		actual, results := dmp.PatchApply(patches, tc.Text1)
+		assert.Equal(t, tc.Text2, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for _, result := range results {
+			assert.True(t, result, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestPatchSplitMaxSize(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Patches []Patch
+		MaxSize int
+
+		Expected string
+	}
+
+	dmp := New()
+
+	text1 := "abcdefghijklmnopqrstuvwxyz"
+	text2 := "aXcdeXghiXklmXopqXstuXwxyX"
+	patches := dmp.PatchMake(text1, text2)
+	unicode := []Patch{NewPatch(0, 0, []Diff{{DiffEqual, "日本"}, {DiffDelete, "語語語語"}, {DiffInsert, "語"}, {DiffEqual, "日本"}})}
+
+	for i, tc := range []TestCase{
+		{"No limit", patches, 0, "@@ -1,26 +1,26 @@\n a\n-bcdefghijklmnopqrstuvwxyz\n+XcdeXghiXklmXopqXstuXwxyX\n"},
+		{"At the limit", patches, 26, "@@ -1,26 +1,26 @@\n a\n-bcdefghijklmnopqrstuvwxyz\n+XcdeXghiXklmXopqXstuXwxyX\n"},
+		{"Above the limit", patches, 25, "@@ -1,25 +1,5 @@\n a\n-bcdefghijklmnopqrstu\n vwxy\n@@ -21,6 +1,26 @@\n a\n-vwxyz\n+XcdeXghiXklmXopqXstuXwxyX\n"},
+		{"Smaller than the context", patches, 1, "@@ -1,26 +1 @@\n a\n-bcdefghijklmnopqrstuvwxyz\n@@ -26 +1,26 @@\n a\n+XcdeXghiXklmXopqXstuXwxyX\n"},
+		{"Multibyte runes", unicode, 16, "@@ -1,15 +1,9 @@\n %E6%97%A5%E6%9C%AC\n-%E8%AA%9E%E8%AA%9E\n %E8%AA%9E\n@@ -10,15 +4,12 @@\n %E6%9C%AC\n-%E8%AA%9E%E8%AA%9E\n+%E8%AA%9E\n %E6%97%A5%E6%9C%AC\n"},
+		{"Multibyte runes and little room", unicode, 10, "@@ -4,9 +4,6 @@\n %E6%9C%AC\n-%E8%AA%9E\n %E8%AA%9E\n@@ -7,9 +4,6 @@\n %E6%9C%AC\n-%E8%AA%9E\n %E8%AA%9E\n@@ -10,9 +4,6 @@\n %E6%9C%AC\n-%E8%AA%9E\n %E8%AA%9E\n@@ -13,9 +4,6 @@\n %E6%9C%AC\n-%E8%AA%9E\n %E6%97%A5\n@@ -16,9 +4,12 @@\n %E6%9C%AC\n+%E8%AA%9E\n %E6%97%A5%E6%9C%AC\n"},
+	} {
+		text := dmp.PatchToText(tc.Patches)
+
+		actual := dmp.PatchSplitMaxSize(tc.Patches, tc.MaxSize)
+		assert.Equal(t, tc.Expected, dmp.PatchToText(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.NoError(t, dmp.PatchValidate(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		// The patches are not modified.
+		assert.Equal(t, text, dmp.PatchToText(tc.Patches), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// The split patches still apply.
+	for _, maxSize := range []int{25, 1} {
+		patched, _ := dmp.PatchApply(dmp.PatchSplitMaxSize(patches, maxSize), text1)
+		assert.Equal(t, text2, patched)
+	}
+	patched, _ := dmp.PatchApply(dmp.PatchSplitMaxSize(unicode, 10), "日本語語語語日本")
+	assert.Equal(t, "日本語日本", patched)
+}
+
+func TestPatchSplitHunk(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		At int
+
+		Expected string
+	}
+
+	dmp := New()
+
+	text1 := "The quick brown fox jumps over the lazy dog."
+	patches := dmp.PatchMake(text1, "That quick brown fox jumped over a lazy dog.")
+	hunk := patches[1]
+	text := hunk.String()
+
+	for i, tc := range []TestCase{
+		{"Between two changes", 4, "@@ -22,15 +22,16 @@\n jump\n-s\n+ed\n  over the \n@@ -29,11 +30,9 @@\n ver \n-the\n+a\n  laz\n"},
+		{"Between deletion and insertion", 2, "@@ -22,9 +22,8 @@\n jump\n-s\n  ove\n@@ -23,17 +22,17 @@\n jump\n+ed\n  over \n-the\n+a\n  laz\n"},
+		{"After the leading context", 1, "@@ -22,8 +22,8 @@\n jumps ov\n@@ -22,18 +22,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n  laz\n"},
+		{"Out of range", 0, "@@ -22,18 +22,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n  laz\n@@ -0,0 +0,0 @@\n"},
+	} {
+		first, second := dmp.PatchSplitHunk(hunk, tc.At)
+		assert.Equal(t, tc.Expected, dmp.PatchToText([]Patch{first, second}), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		// The hunk is not modified.
+		assert.Equal(t, text, hunk.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Either part of a split hunk applies on its own.
+	first, second := dmp.PatchSplitHunk(hunk, 4)
+	split := []Patch{patches[0], first, second}
+	for i, expected := range []string{
+		"The quick brown fox jumped over a lazy dog.",
+		"That quick brown fox jumps over a lazy dog.",
+		"That quick brown fox jumped over the lazy dog.",
+	} {
+		actual, results := dmp.PatchApplySelective(split, text1, func(j int, p Patch) bool { return j != i })
+		assert.Equal(t, expected, actual, fmt.Sprintf("Test case #%d", i))
+		for j, result := range results {
+			assert.Equal(t, j != i, result, fmt.Sprintf("Test case #%d, patch %d", i, j))
+		}
+	}
 }
 
 func TestPatchAddPadding(t *testing.T) {
@@ -329,6 +517,7 @@ func TestPatchApply(t *testing.T) {
 		{"Edge exact match", "", "test", "", "test", []bool{true}},
 		{"Near edge exact match", "XY", "XtestY", "XY", "XtestY", []bool{true}},
 		{"Edge partial match", "y", "y123", "x", "x123", []bool{true}},
+		{"Monster delete of multibyte text", "cbxa😀x  \r\na  日\r\n😀日é😀\t  ", "cbxa😀x  \naé", "cbxa😀x  \r\na  日\r\n😀日é😀\t  ", "cbxa😀x  \naé", []bool{true, true}},
 	} {
 		patches := dmp.PatchMake(tc.Text1, tc.Text2)
 
@@ -336,4 +525,385 @@ func TestPatchApply(t *testing.T) {
 		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
 		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
 	}
+
+	dmp.MatchMaxBits = 0
+
+	for i, tc := range []TestCase{
+		{"Unlimited match bits", "The quick brown fox jumps over the lazy dog. The five boxing wizards jump quickly.", "The quick brown fox leaps over the lazy dog. The five boxing wizards jump quickly!", "The quick brown fox jumps over the lazy cat. The five boxing wizards jump quickly.", "The quick brown fox leaps over the lazy cat. The five boxing wizards jump quickly!", []bool{true, true}},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+
+		actual, actualApplies := dmp.PatchApply(patches, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchApplyWithOptions(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		TextBase     string
+		Fuzz         int
+		MaxOffset    int
+		FailFast     bool
+		AllOrNothing bool
+
+		Expected        string
+		ExpectedApplies []bool
+		ExpectedError   bool
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+
+	for i, tc := range []TestCase{
+		{"Default", "The quick brown fox jumps over the lazy dog.", -1, -1, false, false, "That quick brown fox jumped over a lazy dog.", []bool{true, true}, false},
+		{"Exact context", "The quick brown fox jumps over the lazy dog.", 0, -1, false, false, "That quick brown fox jumped over a lazy dog.", []bool{true, true}, false},
+		{"Exact context, modified base", "The quick brown fox jumps over the lame dog.", 0, -1, false, false, "That quick brown fox jumps over the lame dog.", []bool{true, false}, false},
+		{"Fuzzy context, modified base", "The quick brown fox jumps over the lame dog.", 3, -1, false, false, "That quick brown fox jumped over a lame dog.", []bool{true, true}, false},
+		{"Fail fast", "The slow brown fox jumps over the lazy dog.", 0, -1, true, false, "The slow brown fox jumps over the lazy dog.", []bool{false, false}, false},
+		{"Shifted base", "Look! The quick brown fox jumps over the lazy dog.", -1, -1, false, false, "Look! That quick brown fox jumped over a lazy dog.", []bool{true, true}, false},
+		{"Shifted base, limited offset", "Look! The quick brown fox jumps over the lazy dog.", -1, 3, false, false, "Look! The quick brown fox jumps over the lazy dog.", []bool{false, false}, false},
+		{"All or nothing", "The quick brown fox jumps over the lame dog.", 0, -1, false, true, "The quick brown fox jumps over the lame dog.", []bool{true, false}, true},
+		{"All or nothing, all applied", "The quick brown fox jumps over the lazy dog.", 0, -1, false, true, "That quick brown fox jumped over a lazy dog.", []bool{true, true}, false},
+		{"Shifted base, sufficient offset", "Look! The quick brown fox jumps over the lazy dog.", -1, 6, false, false, "Look! That quick brown fox jumped over a lazy dog.", []bool{true, true}, false},
+	} {
+		actual, actualApplies, err := dmp.PatchApplyWithOptions(patches, tc.TextBase, PatchApplyOptions{Fuzz: tc.Fuzz, MaxOffset: tc.MaxOffset, FailFast: tc.FailFast, AllOrNothing: tc.AllOrNothing})
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedError {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestPatchApplyStrategies(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		TextBase string
+		Strategy MatchStrategy
+		Fuzz     int
+
+		Expected        string
+		ExpectedApplies []bool
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+
+	for i, tc := range []TestCase{
+		{"Exact", "The quick brown fox jumps over the lazy dog.", MatchStrategyExact, -1, "That quick brown fox jumped over a lazy dog.", []bool{true, true}},
+		{"Exact, modified base", "The quick brown fox jumps over the lame dog.", MatchStrategyExact, -1, "That quick brown fox jumps over the lame dog.", []bool{true, false}},
+		{"Exact, shifted base", "Look! The quick brown fox jumps over the lazy dog.", MatchStrategyExact, -1, "Look! The quick brown fox jumped over a lazy dog.", []bool{false, true}},
+		{"Levenshtein, modified base", "The quick brown fox jumps over the lame dog.", MatchStrategyLevenshtein, -1, "That quick brown fox jumped over a lame dog.", []bool{true, true}},
+		{"Levenshtein, shifted base", "Look! The quick brown fox jumps over the lazy dog.", MatchStrategyLevenshtein, -1, "Look! That quick brown fox jumped over a lazy dog.", []bool{true, true}},
+		{"Levenshtein, limited edits", "The quick brown fox leaps over the lazy cat.", MatchStrategyLevenshtein, 1, "That quick brown fox leaps over the lazy cat.", []bool{true, false}},
+		{"Levenshtein, threshold", "The quick brown fox leaps over the lazy cat.", MatchStrategyLevenshtein, -1, "That quick brown fox leaped over a lazy cat.", []bool{true, true}},
+	} {
+		opts := DefaultPatchApplyOptions()
+		opts.Strategy = tc.Strategy
+		opts.Fuzz = tc.Fuzz
+		actual, actualApplies, _ := dmp.PatchApplyWithOptions(patches, tc.TextBase, opts)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedApplies, actualApplies, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Among equally good matches, the nearest one wins.
+	patches = dmp.PatchMakeWithOptions("ab ab ab", []Diff{{DiffEqual, "ab ab "}, {DiffDelete, "a"}, {DiffEqual, "b"}}, PatchOptions{ContextChars: 1})
+	for _, strategy := range []MatchStrategy{MatchStrategyExact, MatchStrategyLevenshtein} {
+		opts := DefaultPatchApplyOptions()
+		opts.Strategy = strategy
+		actual, _, _ := dmp.PatchApplyWithOptions(patches, "ab ab ab ab", opts)
+		assert.Equal(t, "ab ab b ab", actual, strategy.String())
+	}
+
+	assert.Equal(t, "levenshtein", MatchStrategyLevenshtein.String())
+	assert.Equal(t, "MatchStrategy(7)", MatchStrategy(7).String())
+}
+
+func TestPatchApplyLineEndings(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+		Text  string
+
+		Expected string
+	}
+
+	dmp := New()
+	dmp.IgnoreLineEndings = true
+
+	lines := "The quick brown\nfox jumps over\nthe lazy dog.\nThe end.\n"
+	changed := "The quick brown\nfox leaps\nover\nthe lazy dog.\nThe end.\n"
+	crlf := strings.Replace(lines, "\n", "\r\n", -1)
+	changedCRLF := strings.Replace(changed, "\n", "\r\n", -1)
+
+	for i, tc := range []TestCase{
+		{"Same line endings", lines, changed, lines, changed},
+		{"Patch with LF applied to CRLF", lines, changed, crlf, changedCRLF},
+		{"Patch with CRLF applied to LF", crlf, changedCRLF, lines, changed},
+		{"Insertion of lines", lines, lines + "Really.\nThe end.\n", crlf, crlf + "Really.\r\nThe end.\r\n"},
+	} {
+		patches := dmp.PatchMake(tc.Text1, tc.Text2)
+		actual, results := dmp.PatchApply(patches, tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for _, result := range results {
+			assert.True(t, result, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestPatchApplySelective(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Selected []int
+
+		Expected        string
+		ExpectedResults []bool
+	}
+
+	dmp := New()
+
+	text1 := "The quick brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs."
+	text2 := "That quick brown fox jumped over a lazy dog.  Pack my box with six dozen liquor jugs!"
+	patches := dmp.PatchMake(text1, text2)
+
+	for i, tc := range []TestCase{
+		{"All", []int{0, 1, 2, 3}, text2, []bool{true, true, true, true}},
+		{"None", nil, text1, []bool{false, false, false, false}},
+		{"First", []int{0}, "That quick brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs.", []bool{true, false, false, false}},
+		{"All but the first", []int{1, 2, 3}, "The quick brown fox jumped over a lazy dog.  Pack my box with six dozen liquor jugs!", []bool{false, true, true, true}},
+		{"Last", []int{3}, "The quick brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs!", []bool{false, false, false, true}},
+	} {
+		var calls []int
+		selector := func(i int, p Patch) bool {
+			calls = append(calls, i)
+			assert.Equal(t, patches[i], p)
+			for _, selected := range tc.Selected {
+				if selected == i {
+					return true
+				}
+			}
+			return false
+		}
+
+		actual, results := dmp.PatchApplySelective(patches, text1, selector)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedResults, results, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, []int{0, 1, 2, 3}, calls, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// Skipped patches are not failures.
+		opts := DefaultPatchApplyOptions()
+		opts.Selector = selector
+		opts.AllOrNothing = true
+		actual, _, err := dmp.PatchApplyWithOptions(patches, text1, opts)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchApplyHooks(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		TextBase     string
+		AbortAt      int
+		AllOrNothing bool
+
+		Expected        string
+		ExpectedResults []bool
+		ExpectedEvents  []string
+		ExpectedError   bool
+	}
+
+	dmp := New()
+
+	text1 := "The quick brown fox jumps over the lazy dog.  Pack my box with five dozen liquor jugs."
+	text2 := "That quick brown fox jumped over a lazy dog.  Pack my box with six dozen liquor jugs!"
+	patches := dmp.PatchMake(text1, text2)
+
+	for i, tc := range []TestCase{
+		{"All applied", text1, -1, false, text2, []bool{true, true, true, true}, []string{"applied 0 \"The quick b\"", "applied 1 \"jumps over the laz\"", "applied 2 \"ith five doz\"", "applied 3 \"uor jugs.\""}, false},
+		{"Failed patch", "The quick brown fox jumps over the lazy dog.  Pack my box with many dozen liquor jugs.", -1, false, "That quick brown fox jumped over a lazy dog.  Pack my box with many dozen liquor jugs!", []bool{true, true, false, true}, []string{"applied 0 \"The quick b\"", "applied 1 \"jumps over the laz\"", "failed 2 \"ith many doz\"", "applied 3 \"uor jugs.\""}, false},
+		{"Aborted", text1, 1, false, "That quick brown fox jumped over a lazy dog.  Pack my box with five dozen liquor jugs.", []bool{true, true, false, false}, []string{"applied 0 \"The quick b\"", "applied 1 \"jumps over the laz\""}, true},
+		{"Aborted, all or nothing", text1, 1, true, text1, []bool{true, true, false, false}, []string{"applied 0 \"The quick b\"", "applied 1 \"jumps over the laz\""}, true},
+	} {
+		var events []string
+		hook := func(status string) func(e HunkEvent) bool {
+			return func(e HunkEvent) bool {
+				events = append(events, fmt.Sprintf("%s %d %q", status, e.Index, e.Found))
+				assert.Equal(t, patches[e.Index], e.Patch)
+				assert.Equal(t, status == "applied", e.Placement.Applied)
+				return e.Index != tc.AbortAt
+			}
+		}
+
+		opts := DefaultPatchApplyOptions()
+		opts.Fuzz = 0
+		opts.OnHunkApplied = hook("applied")
+		opts.OnHunkFailed = hook("failed")
+		opts.AllOrNothing = tc.AllOrNothing
+		actual, results, err := dmp.PatchApplyWithOptions(patches, tc.TextBase, opts)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedResults, results, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedEvents, events, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedError {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestPatchApplyRunes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected           string
+		ExpectedPlacements []PatchPlacement
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("日本語のテキストです。猫が好き。", "日本語のテキストでした。犬が好き。")
+
+	for i, tc := range []TestCase{
+		{"Exact match", "日本語のテキストです。猫が好き。", "日本語のテキストでした。犬が好き。", []PatchPlacement{{true, 7, 7, 0, false}}},
+		{"Shifted", "はい、日本語のテキストです。猫が好き。", "はい、日本語のテキストでした。犬が好き。", []PatchPlacement{{true, 10, 7, 3, false}}},
+		{"Failed match", "まったく別の文章。", "まったく別の文章。", []PatchPlacement{{false, -1, 0, 0, false}}},
+	} {
+		actual, placements := dmp.PatchApplyRunes(patches, []rune(tc.Text))
+		assert.Equal(t, []rune(tc.Expected), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedPlacements, placements, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// The changes of an imperfect match stay within the text.
+	text := "\néc日bb😀 日日\n cbb日c😀  😀😀"
+	patches = dmp.PatchMake("\nécb😀 日日\n cbb日c😀 😀😀", text+"éé")
+	last, empty := dmp.PatchSplitHunk(patches[len(patches)-1], 0)
+	patches = append(patches[:len(patches)-1], last, empty)
+	expected := "\néc日b日bb😀 日日\n cbb日c😀   😀😀éé"
+
+	actual, results := dmp.PatchApply(patches, text)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, []bool{true, true, true, true}, results)
+
+	actualRunes, _ := dmp.PatchApplyRunes(patches, []rune(text))
+	assert.Equal(t, []rune(expected), actualRunes)
+}
+
+func TestPatchCheck(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		TextBase string
+
+		Expected      []PatchPlacement
+		ExpectedError bool
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+
+	for i, tc := range []TestCase{
+		{"Exact match", "The quick brown fox jumps over the lazy dog.", []PatchPlacement{{true, 0, 11, 0, false}, {true, 21, 18, 0, false}}, false},
+		{"Shifted", "Look! The quick brown fox jumps over the lazy dog.", []PatchPlacement{{true, 4, 13, 6, false}, {true, 27, 18, 6, false}}, false},
+		{"Failed match", "I am the very model of a modern major general.", []PatchPlacement{{false, -1, 0, 0, false}, {false, -1, 0, 0, false}}, true},
+	} {
+		actual, err := dmp.PatchCheck(patches, tc.TextBase)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if tc.ExpectedError {
+			assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	// Checking does not modify the patches.
+	assert.Equal(t, "@@ -1,11 +1,12 @@\n Th\n-e\n+at\n  quick b\n@@ -22,18 +22,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n  laz\n", dmp.PatchToText(patches))
+}
+
+func TestPatchValidate(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Patches []Patch
+
+		ExpectedError error
+	}
+
+	dmp := New()
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+	modified := func(modify func(patches []Patch)) []Patch {
+		patches := dmp.PatchDeepCopy(patches)
+		modify(patches)
+		return patches
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil},
+		{"Made patches", patches, nil},
+		{"Split patches", dmp.PatchSplitMax(dmp.PatchMake(strings.Repeat("abcdefghij", 10), strings.Repeat("abcdefXhij", 10))), nil},
+		{"Negative start", modified(func(p []Patch) { p[0].Start1 = -1 }), errors.New("Patch 0 has a negative position or length")},
+		{"Invalid operation", modified(func(p []Patch) { p[0].Diffs[1].Type = 7 }), errors.New("Invalid diff operation in patch 0: Operation(7)")},
+		{"Empty diff", modified(func(p []Patch) { p[1].Diffs[1].Text = "" }), errors.New("Empty diff in patch 1")},
+		{"Wrong source length", modified(func(p []Patch) { p[1].Length1++ }), errors.New("Patch 1 has a source length of 19 but its diffs have 18")},
+		{"Wrong destination length", modified(func(p []Patch) { p[0].Length2 = 11 }), errors.New("Patch 0 has a destination length of 11 but its diffs have 12")},
+		{"Wrong context", modified(func(p []Patch) { p[0].Diffs[0].Text = "Tha" }), errors.New("Patch 0 has a source length of 11 but its diffs have 12")},
+	} {
+		actual := dmp.PatchValidate(tc.Patches)
+		assert.Equal(t, tc.ExpectedError, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchApplyChecked(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Patches []Patch
+		Text    string
+
+		Expected        string
+		ExpectedResults []bool
+		ExpectedError   error
+	}
+
+	dmp := New()
+
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	patches := dmp.PatchMake(text1, text2)
+	modified := func(modify func(patches []Patch)) []Patch {
+		patches := dmp.PatchDeepCopy(patches)
+		modify(patches)
+		return patches
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, text1, text1, []bool{}, nil},
+		{"Made patches", patches, text1, text2, []bool{true, true}, nil},
+		{"Fuzzy match", patches, "The quick red rabbit jumps over the tired tiger.", "That quick red rabbit jumped over a tired tiger.", []bool{true, true}, nil},
+		{"Insertion at the end", dmp.PatchMake("abc", "abcd"), "abc", "abcd", []bool{true}, nil},
+		{"Wrong source length", modified(func(p []Patch) { p[1].Length1++ }), text1, text1, nil, errors.New("Patch 1 has a source length of 19 but its diffs have 18")},
+		{"Start beyond the text", modified(func(p []Patch) { p[1].Start1, p[1].Start2 = 1000, 1000 }), text1, text1, nil, errors.New("Patch 1 expects 18 bytes at offset 1000 of a text of 45 bytes")},
+		{"End beyond the text", patches, "The quick brown fox", "The quick brown fox", nil, errors.New("Patch 1 expects 18 bytes at offset 21 of a text of 20 bytes")},
+	} {
+		actual, results, err := dmp.PatchApplyChecked(tc.Patches, tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedResults, results, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
 }
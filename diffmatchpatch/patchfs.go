@@ -0,0 +1,193 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FS is a file system which PatchSetApplyFS reads and writes files in, by slash-separated names relative to its root.
+// Errors for missing files must satisfy os.IsNotExist.
+type FS interface {
+	// Open opens a file for reading.
+	Open(name string) (io.ReadCloser, error)
+	// Write replaces the content of a file, creating it if needed.
+	Write(name string, data []byte) error
+	// Rename moves a file to a new name.
+	Rename(oldName, newName string) error
+	// Stat describes a file.
+	Stat(name string) (os.FileInfo, error)
+}
+
+// DirFS is an FS of the files below a directory of the operating system.
+type DirFS string
+
+// path converts a slash-separated name into a path below the directory.
+func (dir DirFS) path(name string) string {
+	return filepath.Join(string(dir), filepath.FromSlash(name))
+}
+
+// Open opens a file for reading.
+func (dir DirFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(dir.path(name))
+}
+
+// Write replaces the content of a file, creating it if needed. Existing files keep their permissions.
+func (dir DirFS) Write(name string, data []byte) error {
+	return ioutil.WriteFile(dir.path(name), data, 0666)
+}
+
+// Rename moves a file to a new name.
+func (dir DirFS) Rename(oldName, newName string) error {
+	return os.Rename(dir.path(oldName), dir.path(newName))
+}
+
+// Stat describes a file.
+func (dir DirFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(dir.path(name))
+}
+
+// MemFS is an in-memory FS of the contents of files by name, e.g. for tests.
+type MemFS map[string][]byte
+
+// Open opens a file for reading.
+func (m MemFS) Open(name string) (io.ReadCloser, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Write replaces the content of a file, creating it if needed.
+func (m MemFS) Write(name string, data []byte) error {
+	m[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// Rename moves a file to a new name.
+func (m MemFS) Rename(oldName, newName string) error {
+	data, ok := m[oldName]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: os.ErrNotExist}
+	}
+	delete(m, oldName)
+	m[newName] = data
+	return nil
+}
+
+// Stat describes a file.
+func (m MemFS) Stat(name string) (os.FileInfo, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+	}
+	return memFileInfo{path.Base(name), int64(len(data))}, nil
+}
+
+// memFileInfo describes a file of a MemFS.
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) Mode() os.FileMode  { return 0666 }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return false }
+func (fi memFileInfo) Sys() interface{}   { return nil }
+
+// validFSName returns whether a name of a patch set stays within the root of an FS, i.e. is relative, without a drive letter, and has no ".." elements.
+func validFSName(name string) bool {
+	if path.IsAbs(name) || strings.HasPrefix(name, `\`) || len(name) >= 2 && name[1] == ':' {
+		return false
+	}
+	for _, elem := range strings.Split(strings.Replace(name, `\`, "/", -1), "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// PatchSetApplyFS applies a patch set to the file OldName of fsys, and writes the result to the file NewName.
+// An empty OldName creates the file NewName, which must not exist yet, and an empty NewName patches the file OldName in place. Otherwise the file is renamed when the names differ.
+// The file is left untouched and an error is returned unless every patch applies, as reported by the returned results.
+func (dmp *DiffMatchPatch) PatchSetApplyFS(fsys FS, set PatchSet) (_ []bool, err error) {
+	defer dmp.recoverInternal(&err)
+	oldName, newName := set.OldName, set.NewName
+	if len(newName) == 0 {
+		newName = oldName
+	}
+	if len(newName) == 0 {
+		return nil, errors.New("Patch set has no file name")
+	}
+	for _, name := range []string{oldName, newName} {
+		if !validFSName(name) {
+			return nil, fmt.Errorf("Invalid file name in patch set: %q", name)
+		}
+	}
+
+	var text []byte
+	if len(oldName) != 0 {
+		info, err := fsys.Stat(oldName)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("File %s is a directory", oldName)
+		}
+		f, err := fsys.Open(oldName)
+		if err != nil {
+			return nil, err
+		}
+		text, err = ioutil.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if newName != oldName {
+		if _, err := fsys.Stat(newName); err == nil {
+			return nil, fmt.Errorf("File %s already exists", newName)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if err := dmp.PatchValidate(set.Patches); err != nil {
+		return nil, err
+	}
+	result, applied, err := dmp.PatchApplyWithOptions(set.Patches, string(text), DefaultPatchApplyOptions())
+	if err != nil {
+		return applied, err
+	}
+	for i, ok := range applied {
+		if !ok {
+			return applied, fmt.Errorf("Patch %d does not apply to %s", i, oldName)
+		}
+	}
+
+	if len(oldName) != 0 && newName != oldName {
+		if err := fsys.Rename(oldName, newName); err != nil {
+			return applied, err
+		}
+	}
+	return applied, fsys.Write(newName, []byte(result))
+}
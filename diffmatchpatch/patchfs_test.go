@@ -0,0 +1,97 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchSetApplyFS(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Files   map[string]string
+		OldName string
+		NewName string
+		Text1   string
+		Text2   string
+
+		Expected      map[string]string
+		ExpectedError string
+	}
+
+	dmp := New()
+	fox, redFox := "The quick brown fox.", "The quick red fox."
+
+	for i, tc := range []TestCase{
+		{"Modify", map[string]string{"fox.txt": fox}, "fox.txt", "fox.txt", fox, redFox, map[string]string{"fox.txt": redFox}, ""},
+		{"Modify in place", map[string]string{"a/fox.txt": fox}, "a/fox.txt", "", fox, redFox, map[string]string{"a/fox.txt": redFox}, ""},
+		{"Create", map[string]string{}, "", "new/fox.txt", "", redFox, map[string]string{"new/fox.txt": redFox}, ""},
+		{"Rename", map[string]string{"fox.txt": fox}, "fox.txt", "red.txt", fox, redFox, map[string]string{"red.txt": redFox}, ""},
+		{"Missing file", map[string]string{}, "fox.txt", "fox.txt", fox, redFox, map[string]string{}, "stat fox.txt: file does not exist"},
+		{"Existing file", map[string]string{"red.txt": ""}, "", "red.txt", "", redFox, map[string]string{"red.txt": ""}, "File red.txt already exists"},
+		{"Patch failure", map[string]string{"fox.txt": "Lorem ipsum dolor sit amet"}, "fox.txt", "red.txt", fox, redFox, map[string]string{"fox.txt": "Lorem ipsum dolor sit amet"}, "Patch 0 does not apply to fox.txt"},
+		{"Shifted", map[string]string{"fox.txt": "Lo! " + fox}, "fox.txt", "", fox, redFox, map[string]string{"fox.txt": "Lo! " + redFox}, ""},
+		{"No name", map[string]string{}, "", "", fox, redFox, map[string]string{}, "Patch set has no file name"},
+		{"Parent directory", map[string]string{}, "", "a/../../fox.txt", "", redFox, map[string]string{}, `Invalid file name in patch set: "a/../../fox.txt"`},
+		{"Absolute name", map[string]string{}, "/etc/fox.txt", "", fox, redFox, map[string]string{}, `Invalid file name in patch set: "/etc/fox.txt"`},
+		{"Drive letter", map[string]string{}, `C:\fox.txt`, "", fox, redFox, map[string]string{}, `Invalid file name in patch set: "C:\\fox.txt"`},
+	} {
+		fsys := MemFS{}
+		for name, content := range tc.Files {
+			fsys[name] = []byte(content)
+		}
+		set := PatchSet{OldName: tc.OldName, NewName: tc.NewName, Patches: dmp.PatchMake(tc.Text1, tc.Text2)}
+
+		_, err := dmp.PatchSetApplyFS(fsys, set)
+		if tc.ExpectedError != "" {
+			assert.EqualError(t, err, tc.ExpectedError, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		actual := map[string]string{}
+		for name, content := range fsys {
+			actual[name] = string(content)
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchSetApplyDirFS(t *testing.T) {
+	dmp := New()
+	dir, err := ioutil.TempDir("", "patchfs")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fox, redFox := "The quick brown fox.", "The quick red fox."
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "a"), 0777))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", "fox.txt"), []byte(fox), 0600))
+
+	_, err = dmp.PatchSetApplyFS(DirFS(dir), PatchSet{OldName: "a/fox.txt", NewName: "red.txt", Patches: dmp.PatchMake(fox, redFox)})
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "red.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, redFox, string(data))
+	info, err := os.Stat(filepath.Join(dir, "red.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	_, err = os.Stat(filepath.Join(dir, "a", "fox.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = dmp.PatchSetApplyFS(DirFS(dir), PatchSet{OldName: "a", Patches: dmp.PatchMake(fox, redFox)})
+	assert.EqualError(t, err, "File a is a directory")
+}
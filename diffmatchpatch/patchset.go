@@ -0,0 +1,162 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PatchSet is a list of patches with metadata, like the headers of a unified diff or of a patch sent by email.
+type PatchSet struct {
+	// Names of the original and the new file (empty if unknown).
+	OldName string
+	NewName string
+	// Modification times of the original and the new file (zero if unknown).
+	OldTime time.Time
+	NewTime time.Time
+	// Author of the patches (empty if unknown), written as the "From" header.
+	Author string
+	// Other headers by key, e.g. "Subject" or "Date".
+	Fields map[string]string
+
+	Patches []Patch
+}
+
+// Layouts of the modification times in the file lines of a patch set, as written by GNU diff.
+const (
+	patchSetTimeFormat = "2006-01-02 15:04:05.000000000 -0700"
+	patchSetTimeParse  = "2006-01-02 15:04:05 -0700"
+)
+
+// patchSetEscape escapes the control characters, the percent sign and the characters of special in a name or a header with %xx notation, keeping the text readable otherwise.
+func patchSetEscape(text, special string) string {
+	var escaped bytes.Buffer
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c < ' ' || c == 0x7f || c == '%' || strings.IndexByte(special, c) != -1 {
+			_, _ = fmt.Fprintf(&escaped, "%%%02X", c)
+		} else {
+			_ = escaped.WriteByte(c)
+		}
+	}
+	return escaped.String()
+}
+
+// PatchSetToText returns the textual representation of a patch set: its headers as "Key: value" lines sorted by key after the "From" line of the author, then its file names and times as "---" and "+++" lines, then its patches as by PatchToText.
+// Control characters and percent signs in the headers and the file names, and colons in the header keys, are escaped with %xx notation, so that any text round-trips through PatchSetFromText.
+func (dmp *DiffMatchPatch) PatchSetToText(set PatchSet) string {
+	var text bytes.Buffer
+	if len(set.Author) != 0 {
+		_, _ = text.WriteString("From: " + patchSetEscape(set.Author, "") + "\n")
+	}
+	keys := make([]string, 0, len(set.Fields))
+	for key := range set.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		_, _ = text.WriteString(patchSetEscape(key, ":") + ": " + patchSetEscape(set.Fields[key], "") + "\n")
+	}
+
+	if len(set.OldName) != 0 || len(set.NewName) != 0 || !set.OldTime.IsZero() || !set.NewTime.IsZero() {
+		fileLine := func(prefix, name string, t time.Time) {
+			_, _ = text.WriteString(prefix + patchSetEscape(name, ""))
+			if !t.IsZero() {
+				_, _ = text.WriteString("\t" + t.Format(patchSetTimeFormat))
+			}
+			_, _ = text.WriteString("\n")
+		}
+		fileLine("--- ", set.OldName, set.OldTime)
+		fileLine("+++ ", set.NewName, set.NewTime)
+	}
+
+	_, _ = text.WriteString(dmp.PatchToText(set.Patches))
+	return text.String()
+}
+
+// PatchSetFromText parses the textual representation of a patch set written by PatchSetToText.
+// A blank line ends the headers, and the lines after it up to the file lines or the first patch are ignored like the message of an email.
+func (dmp *DiffMatchPatch) PatchSetFromText(textline string) (_ PatchSet, err error) {
+	defer dmp.recoverInternal(&err)
+	var set PatchSet
+	lines := strings.Split(textline, "\n")
+	i := 0
+	inHeaders := true
+	for ; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") {
+			if i+1 == len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+				return set, errors.New("Missing +++ line after: " + line)
+			}
+			if set.OldName, set.OldTime, err = parsePatchSetFileLine(line); err != nil {
+				return set, err
+			}
+			if set.NewName, set.NewTime, err = parsePatchSetFileLine(lines[i+1]); err != nil {
+				return set, err
+			}
+			i += 2
+			break
+		}
+		if !inHeaders {
+			continue
+		}
+		if len(line) == 0 {
+			inHeaders = false
+			continue
+		}
+
+		sep := strings.Index(line, ": ")
+		if sep == -1 {
+			return set, errors.New("Invalid patch set header: " + line)
+		}
+		key, err := url.PathUnescape(line[:sep])
+		if err != nil {
+			return set, errors.New("Invalid patch set header: " + line)
+		}
+		value, err := url.PathUnescape(line[sep+2:])
+		if err != nil {
+			return set, errors.New("Invalid patch set header: " + line)
+		}
+		if key == "From" {
+			set.Author = value
+		} else {
+			if set.Fields == nil {
+				set.Fields = map[string]string{}
+			}
+			set.Fields[key] = value
+		}
+	}
+
+	set.Patches, err = dmp.PatchFromText(strings.Join(lines[i:], "\n"))
+	return set, err
+}
+
+// parsePatchSetFileLine parses the name and the optional time of a "---" or "+++" line.
+func parsePatchSetFileLine(line string) (string, time.Time, error) {
+	field := line[4:]
+	var t time.Time
+	if tab := strings.IndexByte(field, '\t'); tab != -1 {
+		var err error
+		if t, err = time.Parse(patchSetTimeParse, field[tab+1:]); err != nil {
+			return "", t, errors.New("Invalid time in patch set: " + line)
+		}
+		field = field[:tab]
+	}
+	name, err := url.PathUnescape(field)
+	if err != nil {
+		return "", t, errors.New("Invalid file name in patch set: " + line)
+	}
+	return name, t, nil
+}
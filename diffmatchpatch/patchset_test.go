@@ -0,0 +1,119 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchSetText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Set PatchSet
+
+		Expected string
+	}
+
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox.", "The quick red fox.")
+	patchText := dmp.PatchToText(patches)
+	oldTime := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	newTime := time.Date(2026, 10, 16, 11, 45, 12, 500, time.FixedZone("", 2*60*60))
+
+	for i, tc := range []TestCase{
+		{"Patches only", PatchSet{Patches: patches}, patchText},
+		{
+			"Headers",
+			PatchSet{
+				OldName: "a/fox.txt",
+				NewName: "b/fox.txt",
+				OldTime: oldTime,
+				NewTime: newTime,
+				Author:  "Jane Doe <jane@example.com>",
+				Fields:  map[string]string{"Subject": "[PATCH] Paint the fox red", "Date": "Fri, 16 Oct 2026 11:45:12 +0200"},
+				Patches: patches,
+			},
+			"From: Jane Doe <jane@example.com>\n" +
+				"Date: Fri, 16 Oct 2026 11:45:12 +0200\n" +
+				"Subject: [PATCH] Paint the fox red\n" +
+				"--- a/fox.txt\t2026-10-16 09:30:00.000000000 +0000\n" +
+				"+++ b/fox.txt\t2026-10-16 11:45:12.000000500 +0200\n" +
+				patchText,
+		},
+		{"New file", PatchSet{NewName: "fox.txt"}, "--- \n+++ fox.txt\n"},
+		{
+			"Escaping",
+			PatchSet{
+				OldName: "old\tname%.txt",
+				NewName: "new\nname.txt",
+				Fields:  map[string]string{"Key: with\nbreaks": "Value\nwith: breaks"},
+			},
+			"Key%3A with%0Abreaks: Value%0Awith: breaks\n--- old%09name%25.txt\n+++ new%0Aname.txt\n",
+		},
+	} {
+		actual := dmp.PatchSetToText(tc.Set)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		set, err := dmp.PatchSetFromText(actual)
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Set.OldName, set.OldName, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Set.NewName, set.NewName, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, tc.Set.OldTime.Equal(set.OldTime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, tc.Set.NewTime.Equal(set.NewTime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Set.Author, set.Author, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Set.Fields, set.Fields, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, dmp.PatchesEqual(tc.Set.Patches, set.Patches), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchSetFromText(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		ExpectedAuthor  string
+		ExpectedFields  map[string]string
+		ExpectedPatches int
+		ExpectedError   string
+	}
+
+	dmp := New()
+	patchText := dmp.PatchToText(dmp.PatchMake("The quick brown fox.", "The quick red fox."))
+
+	for i, tc := range []TestCase{
+		{"Empty", "", "", nil, 0, ""},
+		{
+			"Email",
+			"From: Jane Doe <jane@example.com>\nSubject: [PATCH] Paint the fox red\n\nThe fox was brown.\n\n--- a/fox.txt\n+++ b/fox.txt\n" + patchText,
+			"Jane Doe <jane@example.com>", map[string]string{"Subject": "[PATCH] Paint the fox red"}, 1, "",
+		},
+		{"Message without file lines", "Subject: Fox\n\nRed.\n" + patchText, "", map[string]string{"Subject": "Fox"}, 1, ""},
+		{"Invalid header", "Subject Fox\n" + patchText, "", nil, 0, "Invalid patch set header: Subject Fox"},
+		{"Invalid escape", "Subject: %zz\n", "", nil, 0, "Invalid patch set header: Subject: %zz"},
+		{"Missing new file", "--- a.txt\n" + patchText, "", nil, 0, "Missing +++ line after: --- a.txt"},
+		{"Invalid time", "--- a.txt\tyesterday\n+++ b.txt\n", "", nil, 0, "Invalid time in patch set: --- a.txt\tyesterday"},
+		{"Invalid patch", "--- a.txt\n+++ b.txt\n@@ -1 +1 @@\n*x\n", "", nil, 0, "Invalid patch mode '*' in: x"},
+	} {
+		set, err := dmp.PatchSetFromText(tc.Text)
+		if tc.ExpectedError != "" {
+			assert.EqualError(t, err, tc.ExpectedError, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			continue
+		}
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedAuthor, set.Author, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedFields, set.Fields, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Len(t, set.Patches, tc.ExpectedPatches, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
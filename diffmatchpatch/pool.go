@@ -0,0 +1,62 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sync"
+)
+
+// intsPool holds the buffers of the V arrays of diffBisect.
+var intsPool = sync.Pool{
+	New: func() interface{} { return new([]int) },
+}
+
+// runesPool holds the buffers in which DiffCleanupMerge collects the text of edits.
+var runesPool = sync.Pool{
+	New: func() interface{} { return new([]rune) },
+}
+
+// getInts returns a slice of n ints and the buffer to release once the slice is no longer used. The buffer is nil unless buffers are reused.
+func (dmp *DiffMatchPatch) getInts(n int) ([]int, *[]int) {
+	if !dmp.DiffReuseBuffers {
+		return make([]int, n), nil
+	}
+	buf := intsPool.Get().(*[]int)
+	if cap(*buf) < n {
+		*buf = make([]int, n)
+	}
+	return (*buf)[:n], buf
+}
+
+// putInts releases a buffer returned by getInts.
+func putInts(buf *[]int) {
+	if buf != nil {
+		intsPool.Put(buf)
+	}
+}
+
+// getRunes returns an empty slice of runes and the buffer to release once the slice is no longer used. The buffer is nil unless buffers are reused.
+func (dmp *DiffMatchPatch) getRunes() ([]rune, *[]rune) {
+	if !dmp.DiffReuseBuffers {
+		return nil, nil
+	}
+	buf := runesPool.Get().(*[]rune)
+	return (*buf)[:0], buf
+}
+
+// putRunes releases a buffer returned by getRunes, keeping runes if it has grown beyond the buffer.
+func putRunes(buf *[]rune, runes []rune) {
+	if buf == nil {
+		return
+	}
+	if cap(runes) > cap(*buf) {
+		*buf = runes[:0]
+	}
+	runesPool.Put(buf)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffReuseBuffers(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+	}
+
+	tcs := []TestCase{
+		{"Empty", "", ""},
+		{"Insertion", "abc", "ab123c"},
+		{"Replacement", "The quick brown fox", "The slow green turtle"},
+		{"Multibyte", "日本語のテキスト", "日本のテキストです"},
+		{"Lines", "1\n2\n3\n4\n5\n6\n7\n8\n9\n0\n", "1\n2\nx\n4\n5\n6\ny\n8\n9\n0\n"},
+	}
+
+	dmp := New()
+	reuse := New()
+	reuse.DiffReuseBuffers = true
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 10; round++ {
+				for i, tc := range tcs {
+					expected := dmp.DiffMain(tc.Text1, tc.Text2, true)
+					assert.Equal(t, expected, reuse.DiffMain(tc.Text1, tc.Text2, true), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+					assert.Equal(t, dmp.DiffCleanupMerge(append([]Diff{}, expected...)), reuse.DiffCleanupMerge(append([]Diff{}, expected...)), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
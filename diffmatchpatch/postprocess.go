@@ -0,0 +1,35 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+// WithPostProcess returns a copy of dmp whose DiffMain passes every diff it computes through fn, after the functions added before, e.g. to apply custom cleanups or merge diffs in a way of its own.
+// PatchMake runs the functions on the diff it computes from two texts after its own cleanups, DiffMainWithAnchors and DiffIncremental on the diff they return. The diffs which other functions compute internally, e.g. MergePreview and DiffN, are not post-processed. The functions must return a diff of the same texts.
+func (dmp *DiffMatchPatch) WithPostProcess(fn func([]Diff) []Diff) *DiffMatchPatch {
+	processed := *dmp
+	processed.postProcess = append(append([]func([]Diff) []Diff(nil), dmp.postProcess...), fn)
+	return &processed
+}
+
+// withoutPostProcess returns dmp, or a copy of it without the functions added by WithPostProcess if there are any, to compute the diffs which other algorithms build on.
+func (dmp *DiffMatchPatch) withoutPostProcess() *DiffMatchPatch {
+	if len(dmp.postProcess) == 0 {
+		return dmp
+	}
+	unprocessed := *dmp
+	unprocessed.postProcess = nil
+	return &unprocessed
+}
+
+// postProcessDiffs passes a diff through the functions added by WithPostProcess.
+func (dmp *DiffMatchPatch) postProcessDiffs(diffs []Diff) []Diff {
+	for _, fn := range dmp.postProcess {
+		diffs = fn(diffs)
+	}
+	return diffs
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPostProcess(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []Diff
+	}
+
+	// Merges all changes into a single deletion and insertion.
+	coarse := func(diffs []Diff) []Diff {
+		return []Diff{{DiffDelete, New().DiffText1(diffs)}, {DiffInsert, New().DiffText2(diffs)}}
+	}
+	calls := 0
+	count := func(diffs []Diff) []Diff {
+		calls++
+		return diffs
+	}
+
+	dmp := New()
+	processed := dmp.WithPostProcess(count).WithPostProcess(coarse)
+
+	for i, tc := range []TestCase{
+		{"Replacement", "abc", "axc", []Diff{{DiffDelete, "abc"}, {DiffInsert, "axc"}}},
+		{"Unicode", "日本語", "日本人", []Diff{{DiffDelete, "日本語"}, {DiffInsert, "日本人"}}},
+	} {
+		calls = 0
+		actual := processed.DiffMain(tc.Text1, tc.Text2, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, 1, calls, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// The original is not changed.
+	assert.Equal(t, []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "c"}}, dmp.DiffMain("abc", "axc", false))
+
+	// Functions added to a copy do not show up in the other copies.
+	other := processed.WithPostProcess(func(diffs []Diff) []Diff { return nil })
+	assert.Equal(t, []Diff{{DiffDelete, "abc"}, {DiffInsert, "axc"}}, processed.WithPostProcess(count).DiffMain("abc", "axc", false))
+	assert.Nil(t, other.DiffMain("abc", "axc", false))
+
+	// Comparison modes are post-processed too.
+	spaces := dmp.WithPostProcess(count)
+	spaces.IgnoreAllSpace = true
+	calls = 0
+	spaces.DiffMain("a bc", "axc", false)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPatchMakeWithPostProcess(t *testing.T) {
+	calls := 0
+	dmp := New().WithPostProcess(func(diffs []Diff) []Diff {
+		calls++
+		return []Diff{{DiffDelete, New().DiffText1(diffs)}, {DiffInsert, New().DiffText2(diffs)}}
+	})
+
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "The quick red fox jumps over the lazy cat."
+	patches := dmp.PatchMake(text1, text2)
+	// The functions run once, after the cleanups.
+	assert.Equal(t, 1, calls)
+	if assert.Len(t, patches, 1) {
+		assert.Equal(t, []Diff{{DiffDelete, text1}, {DiffInsert, text2}}, patches[0].Diffs)
+	}
+
+	actual, applied := dmp.PatchApply(patches, text1)
+	assert.Equal(t, text2, actual)
+	assert.NotContains(t, applied, false)
+}
+
+func TestPostProcessInternalDiffs(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Run func(dmp *DiffMatchPatch)
+
+		ExpectedCalls int
+	}
+
+	calls := 0
+	dmp := New().WithPostProcess(func(diffs []Diff) []Diff {
+		calls++
+		return diffs
+	})
+	intraline := *dmp
+	intraline.DiffLinesIntraline = true
+
+	for i, tc := range []TestCase{
+		{"DiffMainWithAnchors", func(dmp *DiffMatchPatch) {
+			dmp.DiffMainWithAnchors("ab\ncd\n", "ax\ncy\n", []Anchor{{3, 3}})
+		}, 1},
+		{"DiffIncremental", func(dmp *DiffMatchPatch) {
+			dmp.DiffIncremental([]Diff{{DiffEqual, "abcdef"}}, "abcdef", "abXdef", Range{2, 3})
+		}, 1},
+		{"MergePreview", func(dmp *DiffMatchPatch) { dmp.MergePreview("abc", "axc", "abx") }, 0},
+		{"DiffN", func(dmp *DiffMatchPatch) { dmp.DiffN([]string{"abc", "axc", "abx"}) }, 0},
+		{"DiffLines", func(*DiffMatchPatch) { intraline.DiffLines("abc\n", "axc\n") }, 0},
+		{"DetectRenames", func(dmp *DiffMatchPatch) {
+			dmp.DetectRenames(map[string]string{"a": "abcdef"}, map[string]string{"b": "abcdxf"}, 0.5)
+		}, 0},
+	} {
+		calls = 0
+		tc.Run(dmp)
+		assert.Equal(t, tc.ExpectedCalls, calls, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
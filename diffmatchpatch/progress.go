@@ -0,0 +1,71 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// diffProgress tracks how many runes of both texts a diff computation has resolved.
+type diffProgress struct {
+	// Serializes the calls of the callback, which may come from several goroutines.
+	mu     sync.Mutex
+	report func(done, total int) bool
+
+	done  int
+	total int
+	// Set to 1 once the callback has cancelled the computation.
+	cancelled int32
+}
+
+// diffRunesWithProgress diffs two rune sequences while reporting the progress to DiffProgress.
+func (dmp *DiffMatchPatch) diffRunesWithProgress(text1, text2 []rune, checklines bool) []runeDiff {
+	reported := *dmp
+	reported.progress = &diffProgress{report: dmp.DiffProgress, total: len(text1) + len(text2)}
+	diffs := reported.diffRunes(text1, text2, checklines)
+
+	// Parts of the texts may have been resolved without being reported, e.g. when MaxEditDistance was exceeded.
+	if p := reported.progress; p.done < p.total {
+		reported.reportProgress(p.total - p.done)
+	}
+	return diffs
+}
+
+// reportProgress adds n resolved runes to the progress of the diff computation and reports it.
+func (dmp *DiffMatchPatch) reportProgress(n int) {
+	if dmp.progress == nil {
+		return
+	}
+	p := dmp.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += n
+	if atomic.LoadInt32(&p.cancelled) == 0 && !p.report(p.done, p.total) {
+		atomic.StoreInt32(&p.cancelled, 1)
+	}
+}
+
+// progressCancelled returns whether the DiffProgress callback has cancelled the diff computation.
+func (dmp *DiffMatchPatch) progressCancelled() bool {
+	return dmp.progress != nil && atomic.LoadInt32(&dmp.progress.cancelled) != 0
+}
+
+// diffLength returns the number of runes of both texts which a part of a diff covers.
+func diffLength(diffs []runeDiff) int {
+	n := 0
+	for _, aDiff := range diffs {
+		if aDiff.Type == DiffEqual {
+			n += 2 * len(aDiff.Text)
+		} else {
+			n += len(aDiff.Text)
+		}
+	}
+	return n
+}
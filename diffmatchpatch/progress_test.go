@@ -0,0 +1,89 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffProgress(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1       string
+		Text2       string
+		Checklines  bool
+		Parallelism int
+	}
+
+	lines1 := strings.Repeat("1234567890\n", 20)
+	lines2 := strings.Repeat("abcdefghij\n1234567890\n", 10)
+
+	for i, tc := range []TestCase{
+		{"Identical texts", "abc", "abc", false, 0},
+		{"Insertion", "abc", "ab123c", false, 0},
+		{"Replacement", "The quick brown fox", "The slow green turtle", false, 0},
+		{"Half-match", "1234567890abcdefghij", "a345678z90bcdefghij", false, 0},
+		{"Empty texts", "", "", false, 0},
+		{"Line mode", lines1, lines2, true, 0},
+		{"Parallel", lines1, lines2, false, 4},
+	} {
+		dmp := New()
+		dmp.DiffTimeout = 0
+		dmp.DiffLineModeThreshold = 10
+		dmp.DiffParallelism = tc.Parallelism
+
+		var reports [][2]int
+		dmp.DiffProgress = func(done, total int) bool {
+			reports = append(reports, [2]int{done, total})
+			return true
+		}
+
+		actual := dmp.DiffMain(tc.Text1, tc.Text2, tc.Checklines)
+		dmp.DiffProgress = nil
+		assert.Equal(t, dmp.DiffMain(tc.Text1, tc.Text2, tc.Checklines), actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		total := utf8.RuneCountInString(tc.Text1) + utf8.RuneCountInString(tc.Text2)
+		if total == 0 {
+			continue
+		}
+		if assert.NotEmpty(t, reports, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			assert.Equal(t, [2]int{total, total}, reports[len(reports)-1], fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		for j := 1; j < len(reports); j++ {
+			assert.True(t, reports[j-1][0] <= reports[j][0], fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+}
+
+func TestDiffProgressCancel(t *testing.T) {
+	a := strings.Repeat("`Twas brillig, and the slithy toves\nDid gyre and gimble in the wabe:\n", 64)
+	b := strings.Repeat("I am the very model of a modern major general,\nI've information vegetable, animal, and mineral,\n", 64)
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	calls := 0
+	dmp.DiffProgress = func(done, total int) bool {
+		calls++
+		return false
+	}
+
+	diffs := dmp.DiffMain(a, b, false)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, a, dmp.DiffText1(diffs))
+	assert.Equal(t, b, dmp.DiffText2(diffs))
+
+	dmp.DiffProgress = nil
+	assert.True(t, len(diffs) < len(dmp.DiffMain(a, b, false)))
+}
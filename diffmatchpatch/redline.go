@@ -0,0 +1,85 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// RedlineChange is the metadata of an insertion or a deletion in a redline.
+type RedlineChange struct {
+	// Author of the change (empty if unknown).
+	Author string
+	// Time of the change (zero if unknown).
+	Time time.Time
+}
+
+// RedlineRenderer is a Renderer writing HTML in the style of the tracked changes of word processors: insertions are underlined and deletions are struck through.
+type RedlineRenderer struct {
+	// Function returning the metadata of the n-th insertion or deletion rendered, counting from 0 (nil for none).
+	// The author is written in the data-author and title attributes of the <ins> or <del> element of the change, and the time in its datetime attribute.
+	Metadata func(n int, op Operation, text string) RedlineChange
+
+	// Number of insertions and deletions rendered so far.
+	changes int
+}
+
+// redlineText escapes text as HTML, with line breaks.
+func redlineText(text string) string {
+	return strings.Replace(html.EscapeString(text), "\n", "<br>", -1)
+}
+
+// renderChange writes an insertion or a deletion as the element tag with the given style.
+func (r *RedlineRenderer) renderChange(w io.Writer, op Operation, tag, style, text string) error {
+	var change RedlineChange
+	if r.Metadata != nil {
+		change = r.Metadata(r.changes, op, text)
+	}
+	r.changes++
+
+	var buff bytes.Buffer
+	_, _ = buff.WriteString("<" + tag + " style=\"" + style + "\"")
+	if len(change.Author) != 0 {
+		author := html.EscapeString(change.Author)
+		_, _ = buff.WriteString(" data-author=\"" + author + "\" title=\"" + author + "\"")
+	}
+	if !change.Time.IsZero() {
+		_, _ = buff.WriteString(" datetime=\"" + change.Time.Format(time.RFC3339) + "\"")
+	}
+	_, _ = buff.WriteString(">" + redlineText(text) + "</" + tag + ">")
+	_, err := w.Write(buff.Bytes())
+	return err
+}
+
+// RenderEqual writes an equality as text.
+func (r *RedlineRenderer) RenderEqual(w io.Writer, text string) error {
+	_, err := io.WriteString(w, redlineText(text))
+	return err
+}
+
+// RenderInsert writes an insertion as an underlined <ins> element.
+func (r *RedlineRenderer) RenderInsert(w io.Writer, text string) error {
+	return r.renderChange(w, DiffInsert, "ins", "color:#0645ad;text-decoration:underline;", text)
+}
+
+// RenderDelete writes a deletion as a struck through <del> element.
+func (r *RedlineRenderer) RenderDelete(w io.Writer, text string) error {
+	return r.renderChange(w, DiffDelete, "del", "color:#b32424;text-decoration:line-through;", text)
+}
+
+// DiffRedline compares two documents word by word with DiffMainWords and returns their redline as HTML, with the metadata of every change given by metadata (nil for none).
+func (dmp *DiffMatchPatch) DiffRedline(text1, text2 string, metadata func(n int, op Operation, text string) RedlineChange) string {
+	var buff bytes.Buffer
+	_ = dmp.DiffRender(&buff, dmp.DiffMainWords(text1, text2), &RedlineRenderer{Metadata: metadata})
+	return buff.String()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRedline(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1    string
+		Text2    string
+		Metadata func(n int, op Operation, text string) RedlineChange
+
+		Expected string
+	}
+
+	dmp := New()
+	changed := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	ins := `<ins style="color:#0645ad;text-decoration:underline;"`
+	del := `<del style="color:#b32424;text-decoration:line-through;"`
+
+	for i, tc := range []TestCase{
+		{"Equal", "Payment is due.", "Payment is due.", nil, "Payment is due."},
+		{
+			"Words",
+			"The Seller shall deliver within 30 days.\nPayment is due.",
+			"The Supplier shall deliver within 14 days.\nPayment is due.",
+			nil,
+			"The " + del + ">Seller</del>" + ins + ">Supplier</ins> shall deliver within " + del + ">30</del>" + ins + ">14</ins> days.<br>Payment is due.",
+		},
+		{
+			"Metadata",
+			"Seller & Buyer agree.",
+			"Seller and Buyer agree.",
+			func(n int, op Operation, text string) RedlineChange {
+				return RedlineChange{fmt.Sprintf("<%s> %d", op, n), changed}
+			},
+			"Seller " +
+				del + ` data-author="&lt;Delete&gt; 0" title="&lt;Delete&gt; 0" datetime="2026-10-16T09:30:00Z">&amp;</del>` +
+				ins + ` data-author="&lt;Insert&gt; 1" title="&lt;Insert&gt; 1" datetime="2026-10-16T09:30:00Z">and</ins> Buyer agree.`,
+		},
+		{
+			"Time only",
+			"",
+			"New clause.",
+			func(n int, op Operation, text string) RedlineChange {
+				return RedlineChange{Time: changed}
+			},
+			ins + ` datetime="2026-10-16T09:30:00Z">New clause.</ins>`,
+		},
+	} {
+		actual := dmp.DiffRedline(tc.Text1, tc.Text2, tc.Metadata)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Rename pairs a file which was deleted with a file which was added in its place.
+type Rename struct {
+	OldPath string
+	NewPath string
+	// Similarity of the contents of both files as computed by DiffSimilarity.
+	Score float64
+}
+
+// DetectRenames pairs the files of oldFiles with those of newFiles, both given as contents by path, whose contents are at least as similar as threshold, like git -M.
+// Paths which are in both maps are not renamed.  Every file is paired at most once, the most similar pairs first.  The renames are sorted by their old paths.
+func (dmp *DiffMatchPatch) DetectRenames(oldFiles, newFiles map[string]string, threshold float64) []Rename {
+	var candidates []Rename
+	for oldPath, oldText := range oldFiles {
+		if _, ok := newFiles[oldPath]; ok {
+			continue
+		}
+		oldLength := utf8.RuneCountInString(oldText)
+		for newPath, newText := range newFiles {
+			if _, ok := oldFiles[newPath]; ok {
+				continue
+			}
+			var score float64
+			if oldText == newText {
+				score = 1
+			} else {
+				// Skip files whose lengths or runes alone make them too different.
+				newLength := utf8.RuneCountInString(newText)
+				if float64(2*min(oldLength, newLength)) < threshold*float64(oldLength+newLength) || dmp.QuickRatio(oldText, newText) < threshold {
+					continue
+				}
+				score = dmp.DiffSimilarity(dmp.withoutPostProcess().DiffMain(oldText, newText, true))
+			}
+			if score >= threshold {
+				candidates = append(candidates, Rename{oldPath, newPath, score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.OldPath != b.OldPath {
+			return a.OldPath < b.OldPath
+		}
+		return a.NewPath < b.NewPath
+	})
+
+	var renames []Rename
+	pairedOld := map[string]bool{}
+	pairedNew := map[string]bool{}
+	for _, candidate := range candidates {
+		if pairedOld[candidate.OldPath] || pairedNew[candidate.NewPath] {
+			continue
+		}
+		pairedOld[candidate.OldPath] = true
+		pairedNew[candidate.NewPath] = true
+		renames = append(renames, candidate)
+	}
+	sort.Slice(renames, func(i, j int) bool {
+		return renames[i].OldPath < renames[j].OldPath
+	})
+	return renames
+}
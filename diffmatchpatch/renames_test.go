@@ -0,0 +1,56 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectRenames(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		OldFiles  map[string]string
+		NewFiles  map[string]string
+		Threshold float64
+
+		Expected []Rename
+	}
+
+	story := strings.Repeat("Once upon a time there was a file.\n", 8)
+	ending := "The end.\n"
+	score := 2 * float64(len(story)) / float64(2*len(story)+len(ending))
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil, 0.5, nil},
+		{"Identical", map[string]string{"a": story}, map[string]string{"b": story}, 0.5, []Rename{{"a", "b", 1}}},
+		{"Similar", map[string]string{"a": story}, map[string]string{"b": story + ending}, 0.5, []Rename{{"a", "b", score}}},
+		{"Below threshold", map[string]string{"a": story}, map[string]string{"b": story + ending}, 0.99, nil},
+		{"Too different", map[string]string{"a": story}, map[string]string{"b": "Something else entirely.\n"}, 0.5, nil},
+		{"Most similar first", map[string]string{"a": story, "b": story + ending}, map[string]string{"c": story + ending}, 0.5, []Rename{{"b", "c", 1}}},
+		{"Several renames", map[string]string{"a": story, "b": "Something else entirely.\n"}, map[string]string{"c": "Something else entirely.\n", "d": story + ending}, 0.5, []Rename{{"a", "d", score}, {"b", "c", 1}}},
+		{"Path in both sets", map[string]string{"a": story, "b": "x"}, map[string]string{"a": "y", "c": story}, 0.5, nil},
+	} {
+		actual := dmp.DetectRenames(tc.OldFiles, tc.NewFiles, tc.Threshold)
+		assert.Equal(t, len(tc.Expected), len(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		for j := range tc.Expected {
+			if j < len(actual) {
+				assert.Equal(t, tc.Expected[j].OldPath, actual[j].OldPath, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.Equal(t, tc.Expected[j].NewPath, actual[j].NewPath, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+				assert.InDelta(t, tc.Expected[j].Score, actual[j].Score, 1e-9, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+		}
+	}
+}
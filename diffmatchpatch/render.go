@@ -0,0 +1,109 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// Renderer writes the diffs of a report to w, one method per operation, e.g. to render diffs as HTML, ANSI colored text or a document with tracked changes.
+type Renderer interface {
+	RenderEqual(w io.Writer, text string) error
+	RenderInsert(w io.Writer, text string) error
+	RenderDelete(w io.Writer, text string) error
+}
+
+// DiffRender writes a report of a []Diff to w by calling the method of r for the operation of every diff, skipping diffs of invalid operations.
+// It stops at the first error returned by r.
+func (dmp *DiffMatchPatch) DiffRender(w io.Writer, diffs []Diff, r Renderer) error {
+	for _, aDiff := range diffs {
+		var err error
+		switch aDiff.Type {
+		case DiffInsert:
+			err = r.RenderInsert(w, aDiff.Text)
+		case DiffDelete:
+			err = r.RenderDelete(w, aDiff.Text)
+		case DiffEqual:
+			err = r.RenderEqual(w, aDiff.Text)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HtmlRenderer is the Renderer of DiffPrettyHtml.
+type HtmlRenderer struct {
+	// Whether to isolate the text of every diff in a <bdi> element.
+	BidiIsolation bool
+	// Function converting the text of every diff into safe HTML (nil to escape the text, showing line breaks as pilcrows).
+	Sanitizer func(text string) string
+}
+
+// render writes the text of a diff as HTML enclosed by open and close.
+func (r HtmlRenderer) render(w io.Writer, open, text, close string) error {
+	if r.Sanitizer != nil {
+		text = r.Sanitizer(text)
+	} else {
+		text = strings.Replace(html.EscapeString(text), "\n", "&para;<br>", -1)
+	}
+	if r.BidiIsolation && len(text) != 0 {
+		text = "<bdi>" + text + "</bdi>"
+	}
+	_, err := io.WriteString(w, open+text+close)
+	return err
+}
+
+// RenderEqual writes an equality as a <span> element.
+func (r HtmlRenderer) RenderEqual(w io.Writer, text string) error {
+	return r.render(w, "<span>", text, "</span>")
+}
+
+// RenderInsert writes an insertion as an <ins> element with a green background.
+func (r HtmlRenderer) RenderInsert(w io.Writer, text string) error {
+	return r.render(w, "<ins style=\"background:#e6ffe6;\">", text, "</ins>")
+}
+
+// RenderDelete writes a deletion as a <del> element with a red background.
+func (r HtmlRenderer) RenderDelete(w io.Writer, text string) error {
+	return r.render(w, "<del style=\"background:#ffe6e6;\">", text, "</del>")
+}
+
+// TextRenderer is the Renderer of DiffPrettyText, which colors insertions and deletions with ANSI escape sequences.
+type TextRenderer struct {
+	// Whether to isolate every line of the text of every diff between the Unicode characters FIRST STRONG ISOLATE and POP DIRECTIONAL ISOLATE.
+	BidiIsolation bool
+}
+
+// render writes the text of a diff enclosed by open and close.
+func (r TextRenderer) render(w io.Writer, open, text, close string) error {
+	if r.BidiIsolation {
+		text = isolateLines(text)
+	}
+	_, err := io.WriteString(w, open+text+close)
+	return err
+}
+
+// RenderEqual writes an equality as is.
+func (r TextRenderer) RenderEqual(w io.Writer, text string) error {
+	return r.render(w, "", text, "")
+}
+
+// RenderInsert writes an insertion in green.
+func (r TextRenderer) RenderInsert(w io.Writer, text string) error {
+	return r.render(w, "\x1b[32m", text, "\x1b[0m")
+}
+
+// RenderDelete writes a deletion in red.
+func (r TextRenderer) RenderDelete(w io.Writer, text string) error {
+	return r.render(w, "\x1b[31m", text, "\x1b[0m")
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// markRenderer renders diffs in CriticMarkup, failing after limit diffs.
+type markRenderer struct {
+	limit int
+}
+
+var errRenderLimit = errors.New("Render limit reached")
+
+func (r *markRenderer) write(w io.Writer, format, text string) error {
+	if r.limit == 0 {
+		return errRenderLimit
+	}
+	r.limit--
+	_, err := fmt.Fprintf(w, format, text)
+	return err
+}
+
+func (r *markRenderer) RenderEqual(w io.Writer, text string) error {
+	return r.write(w, "%s", text)
+}
+
+func (r *markRenderer) RenderInsert(w io.Writer, text string) error {
+	return r.write(w, "{++%s++}", text)
+}
+
+func (r *markRenderer) RenderDelete(w io.Writer, text string) error {
+	return r.write(w, "{--%s--}", text)
+}
+
+func TestDiffRender(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+		Limit int
+
+		Expected      string
+		ExpectedError error
+	}
+
+	dmp := New()
+	diffs := []Diff{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "c"}, {DiffEqual, "d"}}
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, 0, "", nil},
+		{"Operations", diffs, 10, "a{--b--}{++c++}d", nil},
+		{"Invalid operation", []Diff{{DiffEqual, "a"}, {Operation(7), "x"}, {DiffInsert, "c"}}, 10, "a{++c++}", nil},
+		{"Error", diffs, 2, "a{--b--}", errRenderLimit},
+	} {
+		var buff bytes.Buffer
+		err := dmp.DiffRender(&buff, tc.Diffs, &markRenderer{tc.Limit})
+		assert.Equal(t, tc.ExpectedError, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, buff.String(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffRenderPretty(t *testing.T) {
+	dmp := New()
+	diffs := []Diff{{DiffEqual, "a\n"}, {DiffDelete, "<B>b</B>"}, {DiffInsert, "c&d"}, {DiffEqual, ""}}
+
+	for _, bidi := range []bool{false, true} {
+		dmp.DiffBidiIsolation = bidi
+
+		var buff bytes.Buffer
+		assert.NoError(t, dmp.DiffRender(&buff, diffs, HtmlRenderer{BidiIsolation: bidi}))
+		assert.Equal(t, dmp.DiffPrettyHtml(diffs), buff.String())
+
+		buff.Reset()
+		assert.NoError(t, dmp.DiffRender(&buff, diffs, TextRenderer{BidiIsolation: bidi}))
+		assert.Equal(t, dmp.DiffPrettyText(diffs), buff.String())
+	}
+
+	var buff bytes.Buffer
+	assert.NoError(t, dmp.DiffRender(&buff, diffs, HtmlRenderer{Sanitizer: func(text string) string { return "[" + text + "]" }}))
+	assert.Equal(t, "<span>[a\n]</span><del style=\"background:#ffe6e6;\">[<B>b</B>]</del><ins style=\"background:#e6ffe6;\">[c&d]</ins><span>[]</span>", buff.String())
+}
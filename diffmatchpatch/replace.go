@@ -0,0 +1,47 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Replacement is a rule of DiffMainReplaced which compares every match of Pattern as Placeholder, e.g. to diff logs without their volatile timestamps or request IDs.
+type Replacement struct {
+	Pattern     *regexp.Regexp
+	Placeholder string
+}
+
+// DiffMainReplaced finds the differences between two texts like DiffMain, after replacing the matches of the rules in both texts with their placeholders, so that matches compare equal if their placeholders are equal.
+// Earlier rules take precedence over overlapping matches of later rules.  The diff is mapped back onto the original texts, with replaced matches which compare equal emitted with the text of text1.
+func (dmp *DiffMatchPatch) DiffMainReplaced(text1, text2 string, rules []Replacement, checklines bool) []Diff {
+	return dmp.diffMasked(text1, text2, replacedRegions(text1, rules), replacedRegions(text2, rules), checklines)
+}
+
+// replacedRegions returns the non-overlapping matches of the rules in a text, labelled with their placeholders.
+func replacedRegions(text string, rules []Replacement) []maskedRegion {
+	var regions []maskedRegion
+	for _, rule := range rules {
+		for _, m := range rule.Pattern.FindAllStringIndex(text, -1) {
+			if m[0] == m[1] {
+				continue
+			}
+			// Find the first region which ends after the match starts.
+			i := sort.Search(len(regions), func(i int) bool { return regions[i].End > m[0] })
+			if i < len(regions) && regions[i].Start < m[1] {
+				continue
+			}
+			regions = append(regions, maskedRegion{})
+			copy(regions[i+1:], regions[i:])
+			regions[i] = maskedRegion{Range{m[0], m[1]}, rule.Placeholder}
+		}
+	}
+	return regions
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainReplaced(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+		Rules []Replacement
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	logRules := []Replacement{
+		{regexp.MustCompile(`\d\d:\d\d:\d\d`), "<TIME>"},
+		{regexp.MustCompile(`id=\w+`), "<ID>"},
+	}
+
+	for i, tc := range []TestCase{
+		{"No rules", "abc", "abd", nil, []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+		{"Volatile tokens",
+			"12:00:01 GET id=ab12 200\n12:00:02 GET id=cd34 404\n",
+			"09:13:55 GET id=ff00 200\n09:13:56 GET id=0a0a 403\n",
+			logRules,
+			[]Diff{{DiffEqual, "12:00:01 GET id=ab12 200\n12:00:02 GET id=cd34 40"}, {DiffDelete, "4"}, {DiffInsert, "3"}, {DiffEqual, "\n"}}},
+		{"Moved token", "t=5 id=7", "id=8 t=5", logRules, []Diff{{DiffInsert, "id=8 "}, {DiffEqual, "t=5"}, {DiffDelete, " id=7"}}},
+		{"Different placeholders",
+			"v 12", "v AB",
+			[]Replacement{{regexp.MustCompile(`\d+`), "<N>"}, {regexp.MustCompile(`[A-F]+`), "<HEX>"}},
+			[]Diff{{DiffEqual, "v "}, {DiffDelete, "12"}, {DiffInsert, "AB"}}},
+		{"Earlier rules take precedence",
+			"at 10:00:00", "at 11:30:00",
+			[]Replacement{{regexp.MustCompile(`\d\d:\d\d`), "<HM>"}, {regexp.MustCompile(`\d\d:\d\d:\d\d`), "<TIME>"}},
+			[]Diff{{DiffEqual, "at 10:00:00"}}},
+		{"Empty matches", "a1b", "a2b", []Replacement{{regexp.MustCompile(`x*`), "<X>"}}, []Diff{{DiffEqual, "a"}, {DiffDelete, "1"}, {DiffInsert, "2"}, {DiffEqual, "b"}}},
+	} {
+		actual := dmp.DiffMainReplaced(tc.Text1, tc.Text2, tc.Rules, false)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
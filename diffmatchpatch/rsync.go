@@ -0,0 +1,354 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// defaultRsyncBlockSize is the length of the blocks of RsyncSignature when RsyncBlockSize is not set.
+const defaultRsyncBlockSize = 2048
+
+// Signature describes the blocks of an old file, so that RsyncDelta can compute a delta of a new file without the old file, like rsync.
+type Signature struct {
+	// Length of the old file in bytes.
+	Length int
+	// Length of the blocks in bytes. The last block is shorter if the length of the file is not a multiple of it.
+	BlockSize int
+	// Checksums of the blocks.
+	Blocks []BlockChecksum
+}
+
+// BlockChecksum holds the checksums of a block of a Signature.
+type BlockChecksum struct {
+	// Rolling checksum of rsync, which is cheap to update while sliding over the new file.
+	Weak uint32
+	// Start of the SHA-256 of the block, which confirms the matches of the weak checksum.
+	Strong [16]byte
+}
+
+// rollingChecksum is the weak checksum of rsync, made of the sum of the bytes of a window and the sum of those sums.
+type rollingChecksum struct {
+	a, b uint32
+}
+
+// newRollingChecksum computes the checksum of a window.
+func newRollingChecksum(window []byte) rollingChecksum {
+	var c rollingChecksum
+	for i, x := range window {
+		c.a += uint32(x)
+		c.b += uint32(len(window)-i) * uint32(x)
+	}
+	return c
+}
+
+// sum returns the checksum.
+func (c rollingChecksum) sum() uint32 {
+	return c.a&0xffff | c.b<<16
+}
+
+// roll removes the first byte out of a window of n bytes, and appends the byte in unless the window shrinks at the end of the file.
+func (c *rollingChecksum) roll(out byte, n int, in byte, shrink bool) {
+	c.a -= uint32(out)
+	c.b -= uint32(n) * uint32(out)
+	if !shrink {
+		c.a += uint32(in)
+		c.b += c.a
+	}
+}
+
+// strongChecksum returns the strong checksum of a block.
+func strongChecksum(block []byte) [16]byte {
+	var strong [16]byte
+	sum := sha256.Sum256(block)
+	copy(strong[:], sum[:])
+	return strong
+}
+
+// RsyncSignature reads an old file and computes the checksums of its blocks of RsyncBlockSize bytes.
+func (dmp *DiffMatchPatch) RsyncSignature(old io.Reader) (_ *Signature, err error) {
+	defer dmp.recoverInternal(&err)
+	sig := &Signature{BlockSize: dmp.RsyncBlockSize}
+	if sig.BlockSize <= 0 {
+		sig.BlockSize = defaultRsyncBlockSize
+	}
+	block := make([]byte, sig.BlockSize)
+	for {
+		n, err := io.ReadFull(old, block)
+		if n != 0 {
+			sig.Length += n
+			sig.Blocks = append(sig.Blocks, BlockChecksum{
+				Weak:   newRollingChecksum(block[:n]).sum(),
+				Strong: strongChecksum(block[:n]),
+			})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return sig, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// blockLength returns the length of the i-th block of a signature.
+func (sig *Signature) blockLength(i int) int {
+	if i == len(sig.Blocks)-1 {
+		return sig.Length - i*sig.BlockSize
+	}
+	return sig.BlockSize
+}
+
+// SignatureToBinary encodes a signature in the binary format of DiffToBinary, to send it to where the new file is.
+func (dmp *DiffMatchPatch) SignatureToBinary(sig *Signature) []byte {
+	b := appendBinaryHeader(nil, binaryKindSignature)
+	b = appendUvarint(b, uint64(sig.Length))
+	b = appendUvarint(b, uint64(sig.BlockSize))
+	for _, block := range sig.Blocks {
+		b = appendUvarint(b, uint64(block.Weak))
+		b = append(b, block.Strong[:]...)
+	}
+	return b
+}
+
+// SignatureFromBinary decodes a signature encoded by SignatureToBinary.
+func (dmp *DiffMatchPatch) SignatureFromBinary(data []byte) (_ *Signature, err error) {
+	defer dmp.recoverInternal(&err)
+	r := &binaryReader{data: data}
+	if err := r.header(binaryKindSignature); err != nil {
+		return nil, err
+	}
+	sig := &Signature{}
+	if sig.Length, err = r.int(); err != nil {
+		return nil, err
+	}
+	if sig.BlockSize, err = r.int(); err != nil {
+		return nil, err
+	}
+	if sig.BlockSize == 0 {
+		return nil, errors.New("Invalid block size 0 in signature")
+	}
+	count := (sig.Length + sig.BlockSize - 1) / sig.BlockSize
+	// Every block takes at least 17 bytes.
+	if count > len(r.data)/17 {
+		return nil, errors.New("Truncated binary diff")
+	}
+	sig.Blocks = make([]BlockChecksum, count)
+	for i := range sig.Blocks {
+		weak, err := r.int()
+		if err != nil {
+			return nil, err
+		}
+		if weak > int(^uint32(0)) || len(r.data) < 16 {
+			return nil, errors.New("Invalid block checksum in signature")
+		}
+		sig.Blocks[i].Weak = uint32(weak)
+		copy(sig.Blocks[i].Strong[:], r.data)
+		r.data = r.data[16:]
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("Trailing data after signature")
+	}
+	return sig, nil
+}
+
+// rsyncDeltaWriter appends the instructions of an rsync delta, merging copies of consecutive blocks.
+type rsyncDeltaWriter struct {
+	instructions []byte
+	count        int
+	// Start and number of blocks of the pending copy.
+	first, blocks int
+}
+
+func (w *rsyncDeltaWriter) flushCopy() {
+	if w.blocks != 0 {
+		w.instructions = append(w.instructions, 1)
+		w.instructions = appendUvarint(w.instructions, uint64(w.first))
+		w.instructions = appendUvarint(w.instructions, uint64(w.blocks))
+		w.count++
+		w.blocks = 0
+	}
+}
+
+func (w *rsyncDeltaWriter) insert(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	w.flushCopy()
+	w.instructions = append(w.instructions, 0)
+	w.instructions = appendUvarint(w.instructions, uint64(len(data)))
+	w.instructions = append(w.instructions, data...)
+	w.count++
+}
+
+func (w *rsyncDeltaWriter) copyBlock(block int) {
+	if w.blocks != 0 && w.first+w.blocks == block {
+		w.blocks++
+		return
+	}
+	w.flushCopy()
+	w.first, w.blocks = block, 1
+}
+
+// RsyncDelta reads a new file and encodes it as a delta of the old file described by a signature, made of copies of the blocks of the old file which the new file still contains and of the data in between.
+// Blocks are found at any offset, so only the changed parts of the new file end up in the delta.
+func (dmp *DiffMatchPatch) RsyncDelta(sig *Signature, newFile io.Reader) (_ []byte, err error) {
+	defer dmp.recoverInternal(&err)
+	if sig.BlockSize <= 0 {
+		return nil, fmt.Errorf("Invalid block size %d in signature", sig.BlockSize)
+	}
+	data, err := ioutil.ReadAll(newFile)
+	if err != nil {
+		return nil, err
+	}
+
+	blocksByWeak := map[uint32][]int{}
+	for i, block := range sig.Blocks {
+		blocksByWeak[block.Weak] = append(blocksByWeak[block.Weak], i)
+	}
+	match := func(window []byte, weak uint32) int {
+		candidates := blocksByWeak[weak]
+		if len(candidates) == 0 {
+			return -1
+		}
+		strong := strongChecksum(window)
+		for _, i := range candidates {
+			if sig.blockLength(i) == len(window) && sig.Blocks[i].Strong == strong {
+				return i
+			}
+		}
+		return -1
+	}
+
+	w := &rsyncDeltaWriter{}
+	literal := 0
+	fresh := true
+	var checksum rollingChecksum
+	for i := 0; i < len(data); {
+		n := sig.BlockSize
+		if n > len(data)-i {
+			n = len(data) - i
+		}
+		if fresh {
+			checksum = newRollingChecksum(data[i : i+n])
+			fresh = false
+		}
+		if block := match(data[i:i+n], checksum.sum()); block != -1 {
+			w.insert(data[literal:i])
+			w.copyBlock(block)
+			i += n
+			literal = i
+			fresh = true
+			continue
+		}
+		if i+n < len(data) {
+			checksum.roll(data[i], n, data[i+n], false)
+		} else {
+			checksum.roll(data[i], n, 0, true)
+		}
+		i++
+	}
+	w.insert(data[literal:])
+	w.flushCopy()
+
+	b := appendBinaryHeader(nil, binaryKindRsyncDelta)
+	b = appendUvarint(b, uint64(sig.BlockSize))
+	b = appendUvarint(b, uint64(sig.Length))
+	b = appendUvarint(b, uint64(len(data)))
+	b = appendUvarint(b, uint64(w.count))
+	b = append(b, w.instructions...)
+	sum := sha256.Sum256(data)
+	return append(b, sum[:]...), nil
+}
+
+// RsyncApplyDelta reads the old file a delta of RsyncDelta was computed for and returns the new file.
+// An error is returned if the old file differs from the one of the signature, which is detected by the checksum of the new file the delta carries.
+func (dmp *DiffMatchPatch) RsyncApplyDelta(old io.Reader, delta []byte) (_ []byte, err error) {
+	defer dmp.recoverInternal(&err)
+	r := &binaryReader{data: delta}
+	if err := r.header(binaryKindRsyncDelta); err != nil {
+		return nil, err
+	}
+	var blockSize, oldLength, newLength int
+	for _, field := range []*int{&blockSize, &oldLength, &newLength} {
+		if *field, err = r.int(); err != nil {
+			return nil, err
+		}
+	}
+	if blockSize == 0 {
+		return nil, errors.New("Invalid block size 0 in rsync delta")
+	}
+	count, err := r.count()
+	if err != nil {
+		return nil, err
+	}
+
+	oldData, err := ioutil.ReadAll(old)
+	if err != nil {
+		return nil, err
+	}
+	if len(oldData) != oldLength {
+		return nil, fmt.Errorf("Old file of %d bytes instead of %d bytes", len(oldData), oldLength)
+	}
+
+	oldBlocks := (oldLength + blockSize - 1) / blockSize
+	var result bytes.Buffer
+	for i := 0; i < count; i++ {
+		if len(r.data) == 0 {
+			return nil, errors.New("Truncated binary diff")
+		}
+		op := r.data[0]
+		r.data = r.data[1:]
+		switch op {
+		case 0:
+			n, err := r.int()
+			if err != nil {
+				return nil, err
+			}
+			if n > len(r.data) {
+				return nil, errors.New("Truncated binary diff")
+			}
+			_, _ = result.Write(r.data[:n])
+			r.data = r.data[n:]
+		case 1:
+			first, err := r.int()
+			if err != nil {
+				return nil, err
+			}
+			blocks, err := r.int()
+			if err != nil {
+				return nil, err
+			}
+			if blocks == 0 || first >= oldBlocks || blocks > oldBlocks-first {
+				return nil, fmt.Errorf("Rsync delta copies %d blocks from block %d of %d blocks of the old file", blocks, first, oldBlocks)
+			}
+			start, end := first*blockSize, (first+blocks)*blockSize
+			if end > oldLength {
+				end = oldLength
+			}
+			_, _ = result.Write(oldData[start:end])
+		default:
+			return nil, fmt.Errorf("Invalid instruction %d in rsync delta", op)
+		}
+		if result.Len() > newLength {
+			return nil, errors.New("Rsync delta exceeds the length of the new file")
+		}
+	}
+	if len(r.data) != sha256.Size {
+		return nil, errors.New("Missing checksum at the end of rsync delta")
+	}
+	if sum := sha256.Sum256(result.Bytes()); result.Len() != newLength || !bytes.Equal(sum[:], r.data) {
+		return nil, errors.New("Rsync delta does not match the old file")
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollingChecksum(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog.")
+	n := 8
+	checksum := newRollingChecksum(data[:n])
+	for i := 0; i+n < len(data); i++ {
+		checksum.roll(data[i], n, data[i+n], false)
+		assert.Equal(t, newRollingChecksum(data[i+1:i+1+n]).sum(), checksum.sum(), fmt.Sprintf("Window at %d", i+1))
+	}
+	// Shrinking windows at the end of the data.
+	for i := len(data) - n; i < len(data)-1; i++ {
+		checksum.roll(data[i], len(data)-i, 0, true)
+		assert.Equal(t, newRollingChecksum(data[i+1:]).sum(), checksum.sum(), fmt.Sprintf("Window at %d", i+1))
+	}
+}
+
+func TestRsyncDelta(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Old       string
+		New       string
+		BlockSize int
+	}
+
+	s1, s2 := speedtestTexts()
+
+	for i, tc := range []TestCase{
+		{"Empty files", "", "", 4},
+		{"New file", "", "The quick brown fox", 4},
+		{"Deleted file", "The quick brown fox", "", 4},
+		{"Equal files", "The quick brown fox", "The quick brown fox", 4},
+		{"Short last block", "The quick brown fox", "A quick brown fox", 4},
+		{"Moved blocks", "aaaabbbbccccdddd", "ccccddddaaaabbbb", 4},
+		{"Shifted blocks", "aaaabbbbccccdddd", "xaaaabbbbccccddddx", 4},
+		{"Speedtest", s1, s2, 64},
+		{"Default block size", s1, s2, 0},
+	} {
+		dmp := New()
+		dmp.RsyncBlockSize = tc.BlockSize
+
+		sig, err := dmp.RsyncSignature(strings.NewReader(tc.Old))
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, len(tc.Old), sig.Length, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		// The signature is sent in binary.
+		sig, err = dmp.SignatureFromBinary(dmp.SignatureToBinary(sig))
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		delta, err := dmp.RsyncDelta(sig, strings.NewReader(tc.New))
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+
+		actual, err := dmp.RsyncApplyDelta(strings.NewReader(tc.Old), delta)
+		assert.Nil(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.New, string(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestRsyncDeltaSize(t *testing.T) {
+	s1, _ := speedtestTexts()
+	s2 := s1[:len(s1)/2] + "An inserted sentence." + s1[len(s1)/2:]
+
+	dmp := New()
+	dmp.RsyncBlockSize = 256
+	sig, _ := dmp.RsyncSignature(strings.NewReader(s1))
+	delta, _ := dmp.RsyncDelta(sig, strings.NewReader(s2))
+	// The delta holds the changed block and a few copies.
+	assert.True(t, len(delta) < 2*256+100, fmt.Sprintf("Delta of %d bytes", len(delta)))
+	assert.True(t, len(dmp.SignatureToBinary(sig)) < len(s1)/5)
+
+	actual, err := dmp.RsyncApplyDelta(strings.NewReader(s1), delta)
+	assert.Nil(t, err)
+	assert.Equal(t, s2, string(actual))
+}
+
+func TestRsyncApplyDeltaErrors(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Old   string
+		Delta []byte
+
+		ExpectedError string
+	}
+
+	dmp := New()
+	dmp.RsyncBlockSize = 4
+	sig, _ := dmp.RsyncSignature(strings.NewReader("aaaabbbbcccc"))
+	delta, _ := dmp.RsyncDelta(sig, strings.NewReader("ccccaaaaxx"))
+
+	// Block size 4, old length 12, new length 4, one copy of block 7.
+	outOfRange := append(appendBinaryHeader(nil, binaryKindRsyncDelta), 4, 12, 4, 1, 1, 7, 1)
+
+	for i, tc := range []TestCase{
+		{"Other kind", "aaaabbbbcccc", dmp.SignatureToBinary(sig), "Binary data of kind 'S' instead of 'R'"},
+		{"Changed old file", "aaaabbbbdddd", delta, "Rsync delta does not match the old file"},
+		{"Shorter old file", "aaaabbbb", delta, "Old file of 8 bytes instead of 12 bytes"},
+		{"Truncated", "aaaabbbbcccc", delta[:len(delta)-1], "Missing checksum"},
+		{"Out of range", "aaaabbbbcccc", outOfRange, "Rsync delta copies 1 blocks from block 7 of 3 blocks"},
+	} {
+		_, err := dmp.RsyncApplyDelta(strings.NewReader(tc.Old), tc.Delta)
+		if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			assert.Contains(t, err.Error(), tc.ExpectedError, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+
+	_, err := dmp.SignatureFromBinary(dmp.SignatureToBinary(sig)[:20])
+	assert.Error(t, err)
+}
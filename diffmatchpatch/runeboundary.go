@@ -0,0 +1,134 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// validRuneBoundaries returns whether the text of every diff is valid UTF-8, which implies that no diff begins or ends within a rune.
+func validRuneBoundaries(diffs []Diff) bool {
+	for _, aDiff := range diffs {
+		if !utf8.ValidString(aDiff.Text) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRuneBoundaries returns an error for the first diff whose text is not valid UTF-8, e.g. because it begins or ends within a rune.
+func checkRuneBoundaries(diffs []Diff) error {
+	for i, aDiff := range diffs {
+		if !utf8.ValidString(aDiff.Text) {
+			return fmt.Errorf("Invalid UTF-8 in diff %d: %q", i, aDiff.Text)
+		}
+	}
+	return nil
+}
+
+// checkUTF8 returns an error if a text is not valid UTF-8.
+func checkUTF8(name, text string) error {
+	for i, r := range text {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(text[i:]); size == 1 {
+				return fmt.Errorf("Invalid UTF-8 in %s at byte %d", name, i)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRunes returns an error if a text contains runes which cannot be encoded in UTF-8, such as surrogate halves.
+func checkRunes(name string, runes []rune) error {
+	for i, r := range runes {
+		if !utf8.ValidRune(r) {
+			return fmt.Errorf("Invalid rune %U in %s at index %d", r, name, i)
+		}
+	}
+	return nil
+}
+
+// repairRuneBoundaries returns diffs whose texts are valid UTF-8, or diffs itself if they already are.
+// The bytes of runes split between an equality and the edits next to it are moved from the equality to both the deletion and the insertion, the edits between two equalities are merged into one deletion and one insertion, and the remaining invalid bytes are replaced by U+FFFD.
+func repairRuneBoundaries(diffs []Diff) []Diff {
+	if validRuneBoundaries(diffs) {
+		return diffs
+	}
+
+	// Join adjacent equalities, so that runes split between them are whole again.
+	joined := make([]Diff, 0, len(diffs))
+	for _, aDiff := range diffs {
+		if n := len(joined); n != 0 && aDiff.Type == DiffEqual && joined[n-1].Type == DiffEqual {
+			joined[n-1].Text += aDiff.Text
+		} else {
+			joined = append(joined, aDiff)
+		}
+	}
+
+	repaired := make([]Diff, 0, len(joined))
+	var textDelete, textInsert bytes.Buffer
+	flush := func() {
+		if textDelete.Len() != 0 {
+			repaired = append(repaired, Diff{DiffDelete, strings.ToValidUTF8(textDelete.String(), "\uFFFD")})
+		}
+		if textInsert.Len() != 0 {
+			repaired = append(repaired, Diff{DiffInsert, strings.ToValidUTF8(textInsert.String(), "\uFFFD")})
+		}
+		textDelete.Reset()
+		textInsert.Reset()
+	}
+	for i, aDiff := range joined {
+		if aDiff.Type != DiffEqual {
+			if aDiff.Type == DiffDelete {
+				_, _ = textDelete.WriteString(aDiff.Text)
+			} else if aDiff.Type == DiffInsert {
+				_, _ = textInsert.WriteString(aDiff.Text)
+			}
+			continue
+		}
+
+		text := aDiff.Text
+		// Continuation bytes at the start of the equality end runes begun by the preceding edits.
+		if i != 0 {
+			n := 0
+			for n < len(text) && n < utf8.UTFMax-1 && !utf8.RuneStart(text[n]) {
+				n++
+			}
+			_, _ = textDelete.WriteString(text[:n])
+			_, _ = textInsert.WriteString(text[:n])
+			text = text[n:]
+		}
+		if len(text) == 0 {
+			continue
+		}
+		// An incomplete rune at the end of the equality is completed by the following edits.
+		var tail string
+		if i != len(joined)-1 {
+			for q := len(text) - 1; q >= 0 && q >= len(text)-(utf8.UTFMax-1); q-- {
+				if utf8.RuneStart(text[q]) {
+					if !utf8.FullRuneInString(text[q:]) {
+						text, tail = text[:q], text[q:]
+					}
+					break
+				}
+			}
+		}
+		if len(text) != 0 {
+			flush()
+			repaired = append(repaired, Diff{DiffEqual, strings.ToValidUTF8(text, "\uFFFD")})
+		}
+		_, _ = textDelete.WriteString(tail)
+		_, _ = textInsert.WriteString(tail)
+	}
+	flush()
+	return repaired
+}
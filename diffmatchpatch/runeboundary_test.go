@@ -0,0 +1,149 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepairRuneBoundaries(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []Diff
+	}
+
+	for i, tc := range []TestCase{
+		{"Null case", nil, nil},
+		{"Valid", []Diff{{DiffEqual, "日"}, {DiffDelete, "本"}}, []Diff{{DiffEqual, "日"}, {DiffDelete, "本"}}},
+		{
+			"Split prefix",
+			[]Diff{{DiffEqual, "x\xe6\x97"}, {DiffDelete, "\xa5"}, {DiffInsert, "\xa6"}, {DiffEqual, "y"}},
+			[]Diff{{DiffEqual, "x"}, {DiffDelete, "日"}, {DiffInsert, "旦"}, {DiffEqual, "y"}},
+		},
+		{
+			"Split suffix",
+			[]Diff{{DiffDelete, "a\xe6"}, {DiffInsert, "b\xe6"}, {DiffEqual, "\x97\xa5z"}},
+			[]Diff{{DiffDelete, "a日"}, {DiffInsert, "b日"}, {DiffEqual, "z"}},
+		},
+		{
+			"Split equalities",
+			[]Diff{{DiffEqual, "\xe6"}, {DiffEqual, "\x97\xa5"}, {DiffInsert, "x"}},
+			[]Diff{{DiffEqual, "日"}, {DiffInsert, "x"}},
+		},
+		{
+			"Split edits",
+			[]Diff{{DiffDelete, "\xf0\x9f"}, {DiffInsert, "x"}, {DiffDelete, "\x98\x80"}},
+			[]Diff{{DiffDelete, "😀"}, {DiffInsert, "x"}},
+		},
+		{
+			"Equality within a rune",
+			[]Diff{{DiffDelete, "\xf0"}, {DiffEqual, "\x9f\x98"}, {DiffDelete, "\x80"}, {DiffInsert, "y"}},
+			[]Diff{{DiffDelete, "😀"}, {DiffInsert, "\uFFFDy"}},
+		},
+		{
+			"Invalid bytes",
+			[]Diff{{DiffEqual, "a\xffb"}, {DiffInsert, "\xfe"}},
+			[]Diff{{DiffEqual, "a\uFFFDb"}, {DiffInsert, "\uFFFD"}},
+		},
+	} {
+		actual := repairRuneBoundaries(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.True(t, validRuneBoundaries(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+// splitBytes splits the texts of diffs at random bytes, within runes or not.
+func splitBytes(rng *rand.Rand, diffs []Diff) []Diff {
+	var split []Diff
+	for _, aDiff := range diffs {
+		text := aDiff.Text
+		for len(text) > 1 && rng.Intn(2) == 0 {
+			n := 1 + rng.Intn(len(text)-1)
+			split = append(split, Diff{aDiff.Type, text[:n]})
+			text = text[n:]
+		}
+		split = append(split, Diff{aDiff.Type, text})
+	}
+	return split
+}
+
+func TestRuneBoundariesEntryPoints(t *testing.T) {
+	dmp := New()
+	rng := rand.New(rand.NewSource(1))
+	pieces := []string{"a", "b", " ", "日", "旦", "本", "😀", "😁", "é", "\xff", "\xe6\x97"}
+	randomText := func() string {
+		var text strings.Builder
+		for n := rng.Intn(12); n > 0; n-- {
+			_, _ = text.WriteString(pieces[rng.Intn(len(pieces))])
+		}
+		return text.String()
+	}
+	cleanups := map[string]func([]Diff) []Diff{
+		"DiffCleanupMerge":            dmp.DiffCleanupMerge,
+		"DiffCleanupSemantic":         dmp.DiffCleanupSemantic,
+		"DiffCleanupSemanticLossless": dmp.DiffCleanupSemanticLossless,
+		"DiffCleanupEfficiency":       dmp.DiffCleanupEfficiency,
+		"DiffCleanupLines":            dmp.DiffCleanupLines,
+		"DiffCanonicalize":            dmp.DiffCanonicalize,
+		"DiffCleanupCompact": func(diffs []Diff) []Diff {
+			return dmp.DiffCleanupCompact(diffs, 2)
+		},
+	}
+
+	for i := 0; i < 300; i++ {
+		text1, text2 := randomText(), randomText()
+		diffs := dmp.DiffMain(text1, text2, false)
+		assert.True(t, validRuneBoundaries(diffs), fmt.Sprintf("DiffMain(%q, %q)", text1, text2))
+		runes := dmp.DiffMainRunes([]rune{'a', 0xD800, 'b'}, []rune(text2), false)
+		assert.True(t, validRuneBoundaries(runes), fmt.Sprintf("DiffMainRunes(%q)", text2))
+
+		// Splitting the diffs of valid texts within runes must not change the texts.
+		valid1, valid2 := dmp.DiffText1(diffs), dmp.DiffText2(diffs)
+		for name, cleanup := range cleanups {
+			cleaned := cleanup(splitBytes(rng, dmp.DiffMain(valid1, valid2, false)))
+			assert.True(t, validRuneBoundaries(cleaned), fmt.Sprintf("%s(%q, %q)", name, valid1, valid2))
+			assert.Equal(t, valid1, dmp.DiffText1(cleaned), fmt.Sprintf("%s(%q, %q)", name, valid1, valid2))
+			assert.Equal(t, valid2, dmp.DiffText2(cleaned), fmt.Sprintf("%s(%q, %q)", name, valid1, valid2))
+		}
+	}
+}
+
+func TestStrictUTF8(t *testing.T) {
+	dmp := New()
+
+	// Repaired by default.
+	diffs, err := dmp.DiffMainChecked("a\xffb", "ab", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []Diff{{DiffEqual, "a"}, {DiffDelete, "\uFFFD"}, {DiffEqual, "b"}}, diffs)
+	patches, err := dmp.PatchFromText("@@ -1,3 +1,3 @@\n %E6%97\n-%A5\n+%A6\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []Patch{{Diffs: []Diff{{DiffDelete, "日"}, {DiffInsert, "旦"}}, Start1: 0, Start2: 0, Length1: 3, Length2: 3}}, patches)
+
+	dmp.StrictUTF8 = true
+	_, err = dmp.DiffMainChecked("ab", "a\xffb", false)
+	assert.EqualError(t, err, "Invalid UTF-8 in text2 at byte 1")
+	_, err = dmp.DiffMainRunesChecked([]rune{'a', 0xD800}, []rune("a"), false)
+	assert.EqualError(t, err, "Invalid rune U+D800 in text1 at index 1")
+	_, err = dmp.PatchFromText("@@ -1,3 +1,3 @@\n %E6%97\n-%A5\n+%A6\n")
+	assert.EqualError(t, err, `Invalid patch 0: Invalid UTF-8 in diff 0: "\xe6\x97"`)
+	dmp.DeltaUnits = DeltaBytes
+	_, err = dmp.DiffFromDelta("a\xffb", "=3")
+	assert.EqualError(t, err, `Invalid UTF-8 in diff 0: "a\xffb"`)
+	diffs, err = dmp.DiffMainChecked("日本", "日", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []Diff{{DiffEqual, "日"}, {DiffDelete, "本"}}, diffs)
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"unicode"
+)
+
+// sentenceProperty is the Sentence_Break property of a rune as defined by UAX #29.
+type sentenceProperty int
+
+const (
+	sentenceOther sentenceProperty = iota
+	sentenceCR
+	sentenceLF
+	sentenceSep
+	sentenceExtend
+	sentenceSp
+	sentenceLower
+	sentenceUpper
+	sentenceOLetter
+	sentenceNumeric
+	sentenceATerm
+	sentenceSTerm
+	sentenceClose
+	sentenceSContinue
+)
+
+// sentenceSTerms are the runes other than '!' and '?' which end sentences.
+var sentenceSTerms = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0589, 0x0589, 1},
+		{0x061f, 0x061f, 1},
+		{0x06d4, 0x06d4, 1},
+		{0x0700, 0x0702, 1},
+		{0x0964, 0x0965, 1},
+		{0x203c, 0x203d, 1},
+		{0x2047, 0x2049, 1},
+		{0x3002, 0x3002, 1},
+		{0xfe56, 0xfe57, 1},
+		{0xff01, 0xff01, 1},
+		{0xff1f, 0xff1f, 1},
+		{0xff61, 0xff61, 1},
+	},
+}
+
+// sentenceSContinues are the runes which continue a sentence after a full stop, like commas and colons.
+var sentenceSContinues = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x002c, 0x002d, 1},
+		{0x003a, 0x003b, 1},
+		{0x055d, 0x055d, 1},
+		{0x060c, 0x060d, 1},
+		{0x07f8, 0x07f8, 1},
+		{0x1802, 0x1802, 1},
+		{0x1808, 0x1808, 1},
+		{0x2013, 0x2014, 1},
+		{0x3001, 0x3001, 1},
+		{0xfe10, 0xfe11, 1},
+		{0xfe13, 0xfe13, 1},
+		{0xfe31, 0xfe32, 1},
+		{0xfe50, 0xfe51, 1},
+		{0xfe55, 0xfe55, 1},
+		{0xfe58, 0xfe58, 1},
+		{0xfe63, 0xfe63, 1},
+		{0xff0c, 0xff0d, 1},
+		{0xff1a, 0xff1b, 1},
+		{0xff64, 0xff64, 1},
+	},
+}
+
+// sentencePropertyOf classifies r for sentence segmentation.
+func sentencePropertyOf(r rune) sentenceProperty {
+	switch {
+	case r == '\r':
+		return sentenceCR
+	case r == '\n':
+		return sentenceLF
+	case r == 0x85, r == 0x2028, r == 0x2029:
+		return sentenceSep
+	case r == '.', r == 0x2024, r == 0xfe52, r == 0xff0e:
+		return sentenceATerm
+	case r == '!', r == '?', unicode.Is(sentenceSTerms, r):
+		return sentenceSTerm
+	case unicode.Is(sentenceSContinues, r):
+		return sentenceSContinue
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf):
+		// Extend and Format runes are treated alike.
+		return sentenceExtend
+	case unicode.IsSpace(r):
+		return sentenceSp
+	case unicode.IsLower(r):
+		return sentenceLower
+	case unicode.IsUpper(r), unicode.IsTitle(r):
+		return sentenceUpper
+	case unicode.IsLetter(r):
+		return sentenceOLetter
+	case unicode.IsDigit(r):
+		return sentenceNumeric
+	case r == '"', r == '\'', unicode.In(r, unicode.Ps, unicode.Pe, unicode.Pi, unicode.Pf):
+		return sentenceClose
+	}
+	return sentenceOther
+}
+
+// isParaSep returns whether a rune with the property p separates paragraphs, which always ends a sentence.
+func isParaSep(p sentenceProperty) bool {
+	return p == sentenceCR || p == sentenceLF || p == sentenceSep
+}
+
+// sentenceBoundaries returns the byte offsets at which the sentences of text start, followed by len(text).
+// The segmentation follows the rules of UAX #29, so abbreviations followed by a capital letter, like "Mr. Smith", end a sentence.
+func sentenceBoundaries(text string) []int {
+	// Runes with their properties, where Extend and Format runes are attached to the preceding rune.
+	var offsets []int
+	var props []sentenceProperty
+	for i, r := range text {
+		prop := sentencePropertyOf(r)
+		if prop == sentenceExtend {
+			if len(props) != 0 && !isParaSep(props[len(props)-1]) {
+				continue
+			}
+			prop = sentenceOther
+		}
+		offsets = append(offsets, i)
+		props = append(props, prop)
+	}
+	n := len(props)
+	offset := func(i int) int {
+		if i < n {
+			return offsets[i]
+		}
+		return len(text)
+	}
+	// paraSepEnd returns the index after the paragraph separator at i, which includes a following LF after a CR.
+	paraSepEnd := func(i int) int {
+		if props[i] == sentenceCR && i+1 < n && props[i+1] == sentenceLF {
+			return i + 2
+		}
+		return i + 1
+	}
+
+	boundaries := []int{0}
+	for i := 0; i < n; {
+		prop := props[i]
+		if isParaSep(prop) {
+			i = paraSepEnd(i)
+			boundaries = append(boundaries, offset(i))
+			continue
+		}
+		if prop != sentenceATerm && prop != sentenceSTerm {
+			i++
+			continue
+		}
+
+		j := i + 1
+		if prop == sentenceATerm && j < n && (props[j] == sentenceNumeric || (props[j] == sentenceUpper && i > 0 && (props[i-1] == sentenceUpper || props[i-1] == sentenceLower))) {
+			// Decimal numbers and abbreviations like "U.S.".
+			i = j
+			continue
+		}
+		for j < n && props[j] == sentenceClose {
+			j++
+		}
+		for j < n && props[j] == sentenceSp {
+			j++
+		}
+		if j < n && isParaSep(props[j]) {
+			j = paraSepEnd(j)
+		} else if j < n {
+			if prop == sentenceATerm {
+				// A full stop followed by a lowercase word, like "e.g. this", does not end the sentence.
+				k := j
+				for k < n && props[k] != sentenceOLetter && props[k] != sentenceUpper && props[k] != sentenceLower && props[k] != sentenceATerm && props[k] != sentenceSTerm && !isParaSep(props[k]) {
+					k++
+				}
+				if k < n && props[k] == sentenceLower {
+					i = j
+					continue
+				}
+			}
+			if props[j] == sentenceSContinue || props[j] == sentenceATerm || props[j] == sentenceSTerm {
+				i = j
+				continue
+			}
+		}
+		boundaries = append(boundaries, offset(j))
+		i = j
+	}
+	if boundaries[len(boundaries)-1] != len(text) {
+		boundaries = append(boundaries, len(text))
+	}
+	return boundaries
+}
+
+// SentenceSegmenter splits texts into sentences following the rules of UAX #29.  Each sentence includes the white space and the line break which follow it.
+// It can be used as the WordSegmenter of DiffMainWords to compare texts sentence by sentence, as DiffSentences does.
+type SentenceSegmenter struct{}
+
+// Segment splits text into sentences.
+func (SentenceSegmenter) Segment(text string) []string {
+	boundaries := sentenceBoundaries(text)
+	sentences := make([]string, 0, len(boundaries)-1)
+	for i := 1; i < len(boundaries); i++ {
+		sentences = append(sentences, text[boundaries[i-1]:boundaries[i]])
+	}
+	return sentences
+}
+
+// DiffSentences finds the differences between two texts sentence by sentence, as split by SentenceSegmenter, e.g. to compare prose for which word diffs are too noisy and line diffs too coarse.
+func (dmp *DiffMatchPatch) DiffSentences(text1, text2 string) []Diff {
+	sentences := *dmp
+	sentences.WordSegmenter = SentenceSegmenter{}
+	return sentences.DiffMainWords(text1, text2)
+}
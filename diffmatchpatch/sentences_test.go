@@ -0,0 +1,79 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentenceSegmenter(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", []string{}},
+		{"Single sentence", "The quick brown fox.", []string{"The quick brown fox."}},
+		{"Unterminated", "The quick brown fox", []string{"The quick brown fox"}},
+		{"Several sentences", "Hello world. How are you? Fine!", []string{"Hello world. ", "How are you? ", "Fine!"}},
+		{"Several spaces", "One.   Two.", []string{"One.   ", "Two."}},
+		{"Closing quote", "He said \"Stop.\" Then he left.", []string{"He said \"Stop.\" ", "Then he left."}},
+		{"Closing parenthesis", "(This is it.) Next one.", []string{"(This is it.) ", "Next one."}},
+		{"Decimal number", "It costs 3.50 dollars. Cheap.", []string{"It costs 3.50 dollars. ", "Cheap."}},
+		{"Abbreviation with periods", "The U.S.A. is big. Yes.", []string{"The U.S.A. is big. ", "Yes."}},
+		{"Lowercase after full stop", "See e.g. this example. Done.", []string{"See e.g. this example. ", "Done."}},
+		{"Comma after full stop", "Etc., and so on. Done.", []string{"Etc., and so on. ", "Done."}},
+		{"Capital after abbreviation", "Mr. Smith arrived.", []string{"Mr. ", "Smith arrived."}},
+		{"Repeated terminators", "What?! Really...", []string{"What?! ", "Really..."}},
+		{"Line breaks", "First line\nSecond line\r\nThird", []string{"First line\n", "Second line\r\n", "Third"}},
+		{"Terminator before line break", "Done.\n\nNext.", []string{"Done.\n", "\n", "Next."}},
+		{"Paragraph separator", "One\u2029Two", []string{"One\u2029", "Two"}},
+		{"Combining mark", "Cafe\u0301. Ole\u0301!", []string{"Cafe\u0301. ", "Ole\u0301!"}},
+		{"Japanese", "今日は晴れです。明日は雨です。", []string{"今日は晴れです。", "明日は雨です。"}},
+		{"Fullwidth terminators", "本当？はい！", []string{"本当？", "はい！"}},
+	} {
+		actual := SentenceSegmenter{}.Segment(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text, strings.Join(actual, ""), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffSentences(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Equal texts", "One. Two.", "One. Two.", []Diff{{DiffEqual, "One. Two."}}},
+		{"Changed sentence", "The fox runs. The dog sleeps. The cat eats.", "The fox runs. The dog barks. The cat eats.", []Diff{{DiffEqual, "The fox runs. "}, {DiffDelete, "The dog sleeps. "}, {DiffInsert, "The dog barks. "}, {DiffEqual, "The cat eats."}}},
+		{"Inserted sentence", "One. Three.", "One. Two. Three.", []Diff{{DiffEqual, "One. "}, {DiffInsert, "Two. "}, {DiffEqual, "Three."}}},
+		{"Moved sentence", "One. Two. Three.", "Two. One. Three.", []Diff{{DiffDelete, "One. "}, {DiffEqual, "Two. "}, {DiffInsert, "One. "}, {DiffEqual, "Three."}}},
+	} {
+		actual := dmp.DiffSentences(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// The configured WordSegmenter is left alone.
+	assert.Nil(t, dmp.WordSegmenter)
+}
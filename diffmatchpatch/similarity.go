@@ -0,0 +1,113 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Defaults of the shingles and signatures of MinHash.
+const (
+	defaultShingleSize = 4
+	minHashSize        = 128
+)
+
+// QuickRatio returns an upper bound of the DiffSimilarity of the diff of two texts, computed from the runes both texts contain regardless of their order like difflib's quick_ratio.
+// It takes linear time, so texts whose QuickRatio is below a threshold can be discarded without diffing them.
+func (dmp *DiffMatchPatch) QuickRatio(text1, text2 string) float64 {
+	counts := map[rune]int{}
+	length := 0
+	for _, r := range text1 {
+		counts[r]++
+		length++
+	}
+	matches := 0
+	for _, r := range text2 {
+		if counts[r] > 0 {
+			counts[r]--
+			matches++
+		}
+		length++
+	}
+	if length == 0 {
+		// Two empty texts are equal.
+		return 1
+	}
+	return float64(2*matches) / float64(length)
+}
+
+// MinHash is a signature of the shingles of a text, i.e. its substrings of ShingleSize runes, whose minimum hashes under several hash functions estimate how many shingles two texts share.
+type MinHash []uint64
+
+// MinHash computes the signature of a text, which can be kept to compare the text with many others.
+func (dmp *DiffMatchPatch) MinHash(text string) MinHash {
+	size := dmp.ShingleSize
+	if size <= 0 {
+		size = defaultShingleSize
+	}
+	signature := make(MinHash, minHashSize)
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return signature
+	}
+	if len(runes) < size {
+		// A short text is a single shingle.
+		size = len(runes)
+	}
+	h := fnv.New64a()
+	buf := make([]byte, 0, 4*size)
+	for start := 0; start+size <= len(runes); start++ {
+		buf = buf[:0]
+		for _, r := range runes[start : start+size] {
+			buf = append(buf, byte(r), byte(r>>8), byte(r>>16), byte(r>>24))
+		}
+		h.Reset()
+		_, _ = h.Write(buf)
+		shingle := h.Sum64()
+		for i := range signature {
+			if v := mix64(shingle + uint64(i)*0x9E3779B97F4A7C15); v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return signature
+}
+
+// mix64 is the finalizer of SplitMix64, which turns a shingle hash into the hash of one of the hash functions of MinHash.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	return x ^ x>>31
+}
+
+// Similarity estimates the Jaccard similarity of the shingles of the texts of two signatures, from 0 for texts without a shingle in common to 1 for texts with the same shingles.
+func (m MinHash) Similarity(other MinHash) float64 {
+	if len(m) != len(other) || len(m) == 0 {
+		return 0
+	}
+	equal := 0
+	for i := range m {
+		if m[i] == other[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(m))
+}
+
+// HashSimilarity estimates how similar two texts are from their MinHash signatures, which is much cheaper than diffing them for long texts. Texts with a low similarity are unlikely to have a useful diff.
+func (dmp *DiffMatchPatch) HashSimilarity(text1, text2 string) float64 {
+	return dmp.MinHash(text1).Similarity(dmp.MinHash(text2))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuickRatio(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		Expected float64
+	}
+
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty texts", "", "", 1},
+		{"Empty text", "abc", "", 0},
+		{"Equal texts", "abc", "abc", 1},
+		{"Reordered runes", "abc", "cba", 1},
+		{"Nothing in common", "abc", "xyz", 0},
+		{"Half in common", "abcd", "abxy", 0.5},
+		{"Repeated runes", "aab", "abb", 2.0 / 3},
+		{"Unicode", "日本語", "日本人", 2.0 / 3},
+		{"Speedtest", s1, s2, -1},
+	} {
+		actual := dmp.QuickRatio(tc.Text1, tc.Text2)
+		if tc.Expected >= 0 {
+			assert.InDelta(t, tc.Expected, actual, 1e-9, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		// QuickRatio is an upper bound of the similarity of the diff.
+		assert.True(t, actual >= dmp.DiffSimilarity(dmp.DiffMain(tc.Text1, tc.Text2, false)), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestHashSimilarity(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Text2 string
+
+		ExpectedMin float64
+		ExpectedMax float64
+	}
+
+	s1, s2 := speedtestTexts()
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Empty texts", "", "", 1, 1},
+		{"Empty text", "abc", "", 0, 0},
+		{"Equal texts", s1, s1, 1, 1},
+		{"Short equal texts", "ab", "ab", 1, 1},
+		{"Unrelated texts", s1, "The quick brown fox jumps over the lazy dog.", 0, 0.1},
+		{"Small edit", s1, s1[:len(s1)/2] + "An inserted sentence." + s1[len(s1)/2:], 0.9, 1},
+		{"Speedtest", s1, s2, 0.1, 0.9},
+	} {
+		actual := dmp.HashSimilarity(tc.Text1, tc.Text2)
+		assert.True(t, actual >= tc.ExpectedMin && actual <= tc.ExpectedMax, fmt.Sprintf("Test case #%d, %s: %v", i, tc.Name, actual))
+	}
+
+	// Signatures depend on the shingle size and only compare with signatures of the same length.
+	dmp.ShingleSize = 2
+	assert.Equal(t, 1.0, dmp.HashSimilarity("abcab", "cabca"))
+	dmp.ShingleSize = 4
+	assert.True(t, dmp.HashSimilarity("abcab", "cabca") < 1)
+	assert.Equal(t, 0.0, dmp.MinHash("abc").Similarity(MinHash{}))
+}
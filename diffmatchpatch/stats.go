@@ -0,0 +1,136 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// EditStats summarizes the size of a set of changes, similar to `git diff --stat`.
+// A deletion directly followed or preceded by an insertion counts as modified as far as they overlap, the excess as deleted or added.
+type EditStats struct {
+	// Number of patches, or of separate runs of edits in a diff.
+	Hunks int
+
+	LinesAdded    int
+	LinesDeleted  int
+	LinesModified int
+
+	// Characters are counted as runes.
+	CharsAdded    int
+	CharsDeleted  int
+	CharsModified int
+
+	// Prevailing line endings of the first and the second text, as detected by DetectEOL.
+	EOL1 EOL
+	EOL2 EOL
+}
+
+// DiffStats computes statistics about the changes of a diff.
+func (dmp *DiffMatchPatch) DiffStats(diffs []Diff) EditStats {
+	stats := EditStats{}
+	stats.addDiffs(diffs, utf8.RuneCountInString, true)
+
+	// Count lines on a line by line diff of the texts.
+	text1, text2 := dmp.DiffText1(diffs), dmp.DiffText2(diffs)
+	lineDiffs := dmp.diffLines(text1, text2, dmp.diffDeadline())
+	stats.addDiffs(lineDiffs, countLines, false)
+	stats.EOL1, stats.EOL2 = DetectEOL(text1), DetectEOL(text2)
+
+	return stats
+}
+
+// PatchStats computes statistics about the changes of a list of patches.
+func (dmp *DiffMatchPatch) PatchStats(patches []Patch) EditStats {
+	stats := EditStats{}
+	var text1, text2 strings.Builder
+	for _, aPatch := range patches {
+		_, _ = text1.WriteString(dmp.DiffText1(aPatch.Diffs))
+		_, _ = text2.WriteString(dmp.DiffText2(aPatch.Diffs))
+		patchStats := dmp.DiffStats(aPatch.Diffs)
+		stats.LinesAdded += patchStats.LinesAdded
+		stats.LinesDeleted += patchStats.LinesDeleted
+		stats.LinesModified += patchStats.LinesModified
+		stats.CharsAdded += patchStats.CharsAdded
+		stats.CharsDeleted += patchStats.CharsDeleted
+		stats.CharsModified += patchStats.CharsModified
+	}
+	stats.Hunks = len(patches)
+	// The line endings of the patches stand for those of the texts.
+	stats.EOL1, stats.EOL2 = DetectEOL(text1.String()), DetectEOL(text2.String())
+	return stats
+}
+
+// DiffSimilarity returns how similar the two texts of a diff are, from 0 for texts without anything in common to 1 for equal texts.
+// The similarity is twice the number of equal runes divided by the number of runes of both texts.
+func (dmp *DiffMatchPatch) DiffSimilarity(diffs []Diff) float64 {
+	equal, total := 0, 0
+	for _, aDiff := range diffs {
+		n := utf8.RuneCountInString(aDiff.Text)
+		if aDiff.Type == DiffEqual {
+			equal += n
+			total += 2 * n
+		} else {
+			total += n
+		}
+	}
+	if total == 0 {
+		// Two empty texts are equal.
+		return 1
+	}
+	return float64(2*equal) / float64(total)
+}
+
+// addDiffs accumulates the edits of diffs, measured with count, into either the character or the line statistics.
+func (s *EditStats) addDiffs(diffs []Diff, count func(string) int, chars bool) {
+	insertions := 0
+	deletions := 0
+	flush := func() {
+		if insertions == 0 && deletions == 0 {
+			return
+		}
+		modified := min(insertions, deletions)
+		if chars {
+			s.Hunks++
+			s.CharsAdded += insertions - modified
+			s.CharsDeleted += deletions - modified
+			s.CharsModified += modified
+		} else {
+			s.LinesAdded += insertions - modified
+			s.LinesDeleted += deletions - modified
+			s.LinesModified += modified
+		}
+		insertions = 0
+		deletions = 0
+	}
+
+	for _, aDiff := range diffs {
+		switch aDiff.Type {
+		case DiffInsert:
+			insertions += count(aDiff.Text)
+		case DiffDelete:
+			deletions += count(aDiff.Text)
+		case DiffEqual:
+			if len(aDiff.Text) != 0 {
+				flush()
+			}
+		}
+	}
+	flush()
+}
+
+// countLines returns the number of lines in text, including an unterminated last line.
+func countLines(text string) int {
+	n := strings.Count(text, "\n")
+	if len(text) != 0 && text[len(text)-1] != '\n' {
+		n++
+	}
+	return n
+}
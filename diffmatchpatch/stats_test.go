@@ -0,0 +1,73 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffStats(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected EditStats
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, EditStats{}},
+		{"Equality only", []Diff{{DiffEqual, "abc\n"}}, EditStats{EOL1: EOLLF, EOL2: EOLLF}},
+		{"Modified line", []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "xy"}, {DiffEqual, "\n"}}, EditStats{Hunks: 1, LinesModified: 1, CharsAdded: 1, CharsModified: 1, EOL1: EOLLF, EOL2: EOLLF}},
+		{"Added lines", []Diff{{DiffEqual, "a\n"}, {DiffInsert, "b\nc\n"}}, EditStats{Hunks: 1, LinesAdded: 2, CharsAdded: 4, EOL1: EOLLF, EOL2: EOLLF}},
+		{"Deleted line", []Diff{{DiffDelete, "a\n"}, {DiffEqual, "b\n"}}, EditStats{Hunks: 1, LinesDeleted: 1, CharsDeleted: 2, EOL1: EOLLF, EOL2: EOLLF}},
+		{"Several hunks", []Diff{{DiffDelete, "a"}, {DiffEqual, "\nb\n"}, {DiffInsert, "ü"}, {DiffEqual, "c\n"}}, EditStats{Hunks: 2, LinesModified: 2, CharsAdded: 1, CharsDeleted: 1, EOL1: EOLLF, EOL2: EOLLF}},
+		{"Converted line endings", []Diff{{DiffEqual, "a"}, {DiffDelete, "\n"}, {DiffInsert, "\r\n"}, {DiffEqual, "b"}}, EditStats{Hunks: 1, LinesModified: 1, CharsAdded: 1, CharsModified: 1, EOL1: EOLLF, EOL2: EOLCRLF}},
+	} {
+		actual := dmp.DiffStats(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestPatchStats(t *testing.T) {
+	dmp := New()
+
+	assert.Equal(t, EditStats{}, dmp.PatchStats(nil))
+
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.", "That quick brown fox jumped over a lazy dog.")
+	assert.Equal(t, EditStats{Hunks: 2, LinesModified: 2, CharsAdded: 2, CharsDeleted: 2, CharsModified: 3}, dmp.PatchStats(patches))
+}
+
+func TestDiffSimilarity(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected float64
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, 1},
+		{"Equality only", []Diff{{DiffEqual, "abc"}}, 1},
+		{"Nothing in common", []Diff{{DiffDelete, "abc"}, {DiffInsert, "xyz"}}, 0},
+		{"Insertion", []Diff{{DiffEqual, "ab"}, {DiffInsert, "cd"}}, 2 * 2.0 / 6},
+		{"Runes", []Diff{{DiffEqual, "日本"}, {DiffDelete, "語"}, {DiffInsert, "人"}}, 2 * 2.0 / 6},
+	} {
+		actual := dmp.DiffSimilarity(tc.Diffs)
+		assert.InDelta(t, tc.Expected, actual, 1e-9, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
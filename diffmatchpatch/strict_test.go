@@ -0,0 +1,118 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNoTermination checks that no library code panics or terminates the process, but returns errors instead.
+func TestNoTermination(t *testing.T) {
+	fset := token.NewFileSet()
+	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			name := ""
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				name = fun.Name
+			case *ast.SelectorExpr:
+				if pkg, ok := fun.X.(*ast.Ident); ok {
+					name = pkg.Name + "." + fun.Sel.Name
+				}
+			}
+			if name == "panic" || name == "os.Exit" || strings.HasPrefix(name, "log.Fatal") || strings.HasPrefix(name, "log.Panic") {
+				t.Errorf("%s calls %s", fset.Position(call.Pos()), name)
+			}
+			return true
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestMalformedInput(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Call func() error
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"DiffFromDelta", func() error {
+			_, err := dmp.DiffFromDelta("abc", "=9\t-x\t+%zz")
+			return err
+		}},
+		{"DiffFromDeltaV2", func() error {
+			_, err := dmp.DiffFromDeltaV2("abc", "v2 unit=x\n=1")
+			return err
+		}},
+		{"DiffFromBinary", func() error {
+			_, err := dmp.DiffFromBinary([]byte{0xff, 0xff, 0xff})
+			return err
+		}},
+		{"PatchFromBinary", func() error {
+			_, err := dmp.PatchFromBinary([]byte{0x01})
+			return err
+		}},
+		{"PatchFromText", func() error {
+			_, err := dmp.PatchFromText("@@ -1,99999999999999999999 +1 @@\n x\n")
+			return err
+		}},
+		{"LinePatchFromText", func() error {
+			_, err := dmp.LinePatchFromText("@@ -1,2 +1,2 @@\n x\n")
+			return err
+		}},
+		{"GitDeltaToDiff", func() error {
+			_, err := dmp.GitDeltaToDiff("abc", []byte{0x80})
+			return err
+		}},
+		{"VCDiffToDiff", func() error {
+			_, err := dmp.VCDiffToDiff("abc", []byte("VCD"))
+			return err
+		}},
+		{"DiffCharsToLinesChecked", func() error {
+			_, err := dmp.DiffCharsToLinesChecked([]Diff{{DiffEqual, "\U0010FFFF"}}, []string{""})
+			return err
+		}},
+		{"DiffTransformChecked", func() error {
+			_, _, err := dmp.DiffTransformChecked([]Diff{{DiffDelete, "abc"}}, nil)
+			return err
+		}},
+		{"PatchApplyChecked", func() error {
+			_, _, err := dmp.PatchApplyChecked([]Patch{{Start1: 9, Start2: 9, Length1: 1, Diffs: []Diff{{DiffDelete, "x"}}}}, "abc")
+			return err
+		}},
+	} {
+		var err error
+		assert.NotPanics(t, func() { err = tc.Call() }, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
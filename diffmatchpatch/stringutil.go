@@ -9,7 +9,6 @@
 package diffmatchpatch
 
 import (
-	"fmt"
 	"strings"
 	"unicode/utf8"
 )
@@ -70,6 +69,17 @@ func runesIndexOf(target, pattern []rune, i int) int {
 	return ind + i
 }
 
+// runesLastIndexOf returns the last index of pattern in target, starting at target[i].
+func runesLastIndexOf(target, pattern []rune, i int) int {
+	if i < 0 {
+		return -1
+	}
+	if i >= len(target) {
+		return runesLastIndex(target, pattern)
+	}
+	return runesLastIndex(target[:i+1], pattern)
+}
+
 func runesEqual(r1, r2 []rune) bool {
 	if len(r1) != len(r2) {
 		return false
@@ -93,6 +103,61 @@ func runesIndex(r1, r2 []rune) int {
 	return -1
 }
 
+// runesLastIndex is the equivalent of strings.LastIndex for rune slices.
+func runesLastIndex(r1, r2 []rune) int {
+	for i := len(r1) - len(r2); i >= 0; i-- {
+		if runesEqual(r1[i:i+len(r2)], r2) {
+			return i
+		}
+	}
+	return -1
+}
+
+// runesHasPrefix is the equivalent of strings.HasPrefix for rune slices.
+func runesHasPrefix(r, prefix []rune) bool {
+	return len(r) >= len(prefix) && runesEqual(r[:len(prefix)], prefix)
+}
+
+// runesHasSuffix is the equivalent of strings.HasSuffix for rune slices.
+func runesHasSuffix(r, suffix []rune) bool {
+	return len(r) >= len(suffix) && runesEqual(r[len(r)-len(suffix):], suffix)
+}
+
+// runesConcat joins two rune slices without copying if the second one directly follows the first one in memory.
+// The slices are never appended to in place, since they may share their memory with the texts being diffed.
+func runesConcat(r1, r2 []rune) []rune {
+	if len(r1) == 0 {
+		return r2
+	}
+	if len(r2) == 0 {
+		return r1
+	}
+	if cap(r1) >= len(r1)+len(r2) && &r1[:len(r1)+1][len(r1)] == &r2[0] {
+		return r1[:len(r1)+len(r2)]
+	}
+	r := make([]rune, len(r1)+len(r2))
+	copy(r, r1)
+	copy(r[len(r1):], r2)
+	return r
+}
+
+// bytesToRunes converts every byte of str into one rune, so that byte-oriented algorithms can share rune-based implementations.
+func bytesToRunes(str string) []rune {
+	runes := make([]rune, len(str))
+	for i := 0; i < len(str); i++ {
+		runes[i] = rune(str[i])
+	}
+	return runes
+}
+
+// runeStart moves the byte offset i back to the start of the UTF-8 sequence it points into.
+func runeStart(str string, i int) int {
+	for i > 0 && i < len(str) && !utf8.RuneStart(str[i]) {
+		i--
+	}
+	return i
+}
+
 func intArrayToString(ns []uint32) string {
 	if len(ns) == 0 {
 		return ""
@@ -117,7 +182,10 @@ func getBits(i uint32, cnt byte, from byte) byte {
 	return byte((i >> from) & ((1 << cnt) - 1))
 }
 
-// Converts an integer in the range 0~1112060 into a rune.
+// maxRuneInt is the largest integer intToRune converts into a rune.
+const maxRuneInt = UNICODE_RANGE_MAX - UNICODE_INVALID_RANGE_DELTA - 3
+
+// Converts an integer in the range 0~1112060 into a rune other than utf8.RuneError, which is returned for integers out of range.
 // Based on the ranges table in https://en.wikipedia.org/wiki/UTF-8
 func intToRune(i uint32) rune {
 	if i < (1 << ONE_BYTE_BITS) {
@@ -126,8 +194,8 @@ func intToRune(i uint32) rune {
 
 	if i < (1 << TWO_BYTE_BITS) {
 		r, size := utf8.DecodeRune([]byte{0b11000000 | getBits(i, 5, 6), 0b10000000 | getBits(i, 6, 0)})
-		if size != 2 || r == utf8.RuneError {
-			panic(fmt.Sprintf("Error encoding an int %d with size 2, got rune %v and size %d", size, r, i))
+		if size != 2 {
+			return utf8.RuneError
 		}
 		return r
 	}
@@ -140,8 +208,8 @@ func intToRune(i uint32) rune {
 		}
 
 		r, size := utf8.DecodeRune([]byte{0b11100000 | getBits(i, 4, 12), 0b10000000 | getBits(i, 6, 6), 0b10000000 | getBits(i, 6, 0)})
-		if size != 3 || r == utf8.RuneError {
-			panic(fmt.Sprintf("Error encoding an int %d with size 3, got rune %v and size %d", size, r, i))
+		if size != 3 {
+			return utf8.RuneError
 		}
 		return r
 	}
@@ -149,12 +217,12 @@ func intToRune(i uint32) rune {
 	if i < (1<<FOUR_BYTE_BITS - UNICODE_INVALID_RANGE_DELTA - 3) {
 		i += UNICODE_INVALID_RANGE_DELTA + 3
 		r, size := utf8.DecodeRune([]byte{0b11110000 | getBits(i, 3, 18), 0b10000000 | getBits(i, 6, 12), 0b10000000 | getBits(i, 6, 6), 0b10000000 | getBits(i, 6, 0)})
-		if size != 4 || r == utf8.RuneError {
-			panic(fmt.Sprintf("Error encoding an int %d with size 4, got rune %v and size %d", size, r, i))
+		if size != 4 {
+			return utf8.RuneError
 		}
 		return r
 	}
-	panic(fmt.Sprintf("The integer %d is too large for runeToInt()", i))
+	return utf8.RuneError
 }
 
 // Converts a rune generated by intToRune back to an integer
@@ -186,5 +254,23 @@ func runeToInt(r rune) uint32 {
 		return result - UNICODE_INVALID_RANGE_DELTA - 3
 	}
 
-	panic(fmt.Sprintf("Unexpected state decoding rune=%v size=%d", r, size))
+	// Runes beyond ASCII take between two and four bytes, so this is not reached.
+	return i
+}
+
+// utf16Len returns the number of UTF-16 code units needed to encode text.
+func utf16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// utf16RuneLen returns the number of UTF-16 code units needed to encode r.
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
 }
@@ -11,6 +11,7 @@ package diffmatchpatch
 import (
 	"fmt"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -115,6 +116,57 @@ func TestLastIndexOf(t *testing.T) {
 	}
 }
 
+func TestRunesLastIndexOf(t *testing.T) {
+	type TestCase struct {
+		String   string
+		Pattern  string
+		Position int
+
+		Expected int
+	}
+
+	for i, tc := range []TestCase{
+		{"hi world", "world", -1, -1},
+		{"hi world", "world", 6, -1},
+		{"hi world", "world", 7, 3},
+		{"hi world", "world", 8, 3},
+		{"abbc", "b", 0, -1},
+		{"abbc", "b", 1, 1},
+		{"abbc", "b", 2, 2},
+		{"abbc", "b", 4, 2},
+		{"a\u03b2\u03b2c", "\u03b2", 1, 1},
+		{"a\u03b2\u03b2c", "\u03b2", 2, 2},
+		{"a\u03b2\u03b2c", "\u03b2", 4, 2},
+	} {
+		actual := runesLastIndexOf([]rune(tc.String), []rune(tc.Pattern), tc.Position)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
+	}
+}
+
+func TestRuneStart(t *testing.T) {
+	type TestCase struct {
+		String   string
+		Position int
+
+		Expected int
+	}
+
+	for i, tc := range []TestCase{
+		{"abc", 0, 0},
+		{"abc", 2, 2},
+		{"abc", 3, 3},
+		// The greek letter beta is the two-byte sequence of "\u03b2".
+		{"a\u03b2c", 1, 1},
+		{"a\u03b2c", 2, 1},
+		{"a\u03b2c", 3, 3},
+		// The snowman is the three-byte sequence of "\u2603".
+		{"\u2603", 2, 0},
+	} {
+		actual := runeStart(tc.String, tc.Position)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %#v", i, tc))
+	}
+}
+
 // Exhaustive check for all ints from 0 to 1112060 for correctness of implementation
 // of `intToRune() -> runeToInt()`.
 // This test is slow and runs longer than 5 seconds but it does provide a safety
@@ -128,7 +180,36 @@ func TestRuneToInt(t *testing.T) {
 		assert.Equal(t, i, ic, fmt.Sprintf("intToRune(%d)=%d and runeToInt(%d)=%d", i, r, r, ic))
 	}
 
-	assert.Panics(t, func() {
-		intToRune(UNICODE_RANGE_MAX - UNICODE_INVALID_RANGE_DELTA - 2)
-	})
+	assert.Equal(t, utf8.RuneError, intToRune(maxRuneInt+1))
+}
+
+func TestRunesConcat(t *testing.T) {
+	runes := []rune("abcdef")
+
+	type TestCase struct {
+		Name string
+
+		Runes1 []rune
+		Runes2 []rune
+
+		Expected string
+		Shared   bool
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, nil, "", false},
+		{"First empty", nil, runes[2:4], "cd", true},
+		{"Second empty", runes[1:3], nil, "bc", true},
+		{"Adjacent", runes[0:2], runes[2:5], "abcde", true},
+		{"Gap", runes[0:2], runes[3:5], "abde", false},
+		{"Reversed", runes[3:5], runes[0:2], "deab", false},
+		{"Separate", runes[0:2], []rune("cd"), "abcd", false},
+	} {
+		actual := runesConcat(tc.Runes1, tc.Runes2)
+		assert.Equal(t, tc.Expected, string(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		if len(actual) != 0 {
+			assert.Equal(t, tc.Shared, &actual[0] == &runes[0] || &actual[0] == &runes[1] || &actual[0] == &runes[2], fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+	}
+	assert.Equal(t, "abcdef", string(runes))
 }
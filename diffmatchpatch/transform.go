@@ -0,0 +1,134 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+)
+
+// DiffTransform implements the operational transformation of two concurrent diffs against the same source text.
+// clientDiffs and serverDiffs must both have been computed from the same text1. The returned clientPrime applies the client's edits on top of the server's result, and serverPrime applies the server's edits on top of the client's result, so that both sides converge on the same text:
+//
+//	DiffText2(clientPrime) == DiffText2(serverPrime)
+//
+// When both sides insert at the same location the client's insertion is placed first.
+// If the diffs do not share the same source text, both results are nil.
+func (dmp *DiffMatchPatch) DiffTransform(clientDiffs, serverDiffs []Diff) (clientPrime, serverPrime []Diff) {
+	clientPrime, serverPrime, _ = dmp.DiffTransformChecked(clientDiffs, serverDiffs)
+	return clientPrime, serverPrime
+}
+
+// DiffTransformChecked is DiffTransform which returns an error if the diffs do not share the same source text.
+func (dmp *DiffMatchPatch) DiffTransformChecked(clientDiffs, serverDiffs []Diff) (clientPrime, serverPrime []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	a := newDiffCursor(clientDiffs)
+	b := newDiffCursor(serverDiffs)
+
+	for !a.done() || !b.done() {
+		if !a.done() && a.op() == DiffInsert {
+			// Client insertion: the server must keep it.
+			text := a.take(len(a.head()))
+			clientPrime = append(clientPrime, Diff{DiffInsert, text})
+			serverPrime = append(serverPrime, Diff{DiffEqual, text})
+			continue
+		}
+		if !b.done() && b.op() == DiffInsert {
+			// Server insertion: the client must keep it.
+			text := b.take(len(b.head()))
+			clientPrime = append(clientPrime, Diff{DiffEqual, text})
+			serverPrime = append(serverPrime, Diff{DiffInsert, text})
+			continue
+		}
+		if a.done() || b.done() {
+			return nil, nil, fmt.Errorf("Diffs do not share the same source text (client remaining %d, server remaining %d)", a.remaining(), b.remaining())
+		}
+
+		// Both diffs consume source text, advance by the shorter of the two.
+		n := min(len(a.head()), len(b.head()))
+		opA, opB := a.op(), b.op()
+		text := a.take(n)
+		b.take(n)
+
+		switch {
+		case opA == DiffEqual && opB == DiffEqual:
+			clientPrime = append(clientPrime, Diff{DiffEqual, text})
+			serverPrime = append(serverPrime, Diff{DiffEqual, text})
+		case opA == DiffDelete && opB == DiffEqual:
+			clientPrime = append(clientPrime, Diff{DiffDelete, text})
+		case opA == DiffEqual && opB == DiffDelete:
+			serverPrime = append(serverPrime, Diff{DiffDelete, text})
+		}
+		// Both sides deleted the same text, nothing is left to do.
+	}
+
+	return dmp.DiffCleanupMerge(clientPrime), dmp.DiffCleanupMerge(serverPrime), nil
+}
+
+// diffCursor walks over a []Diff rune by rune, allowing callers to consume partial diffs.
+type diffCursor struct {
+	diffs []Diff
+	i     int
+	rest  []rune
+}
+
+func newDiffCursor(diffs []Diff) *diffCursor {
+	c := &diffCursor{diffs: diffs, i: -1}
+	c.next()
+	return c
+}
+
+// next advances to the next non-empty diff.
+func (c *diffCursor) next() {
+	c.rest = nil
+	for c.i++; c.i < len(c.diffs); c.i++ {
+		if len(c.diffs[c.i].Text) != 0 {
+			c.rest = []rune(c.diffs[c.i].Text)
+			return
+		}
+	}
+}
+
+func (c *diffCursor) done() bool {
+	return c.i >= len(c.diffs)
+}
+
+func (c *diffCursor) op() Operation {
+	return c.diffs[c.i].Type
+}
+
+func (c *diffCursor) head() []rune {
+	return c.rest
+}
+
+// take consumes n runes of the current diff and returns them.
+func (c *diffCursor) take(n int) string {
+	text := string(c.rest[:n])
+	c.rest = c.rest[n:]
+	if len(c.rest) == 0 {
+		c.next()
+	}
+	return text
+}
+
+// remaining returns the number of source runes left to consume.
+func (c *diffCursor) remaining() int {
+	if c.done() {
+		return 0
+	}
+	n := 0
+	if c.op() != DiffInsert {
+		n = len(c.rest)
+	}
+	for _, aDiff := range c.diffs[c.i+1:] {
+		if aDiff.Type != DiffInsert {
+			n += len([]rune(aDiff.Text))
+		}
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffTransform(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text   string
+		Client string
+		Server string
+
+		Expected string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"No changes", "abc", "abc", "abc", "abc"},
+		{"Client only", "The cat", "The fat cat", "The cat", "The fat cat"},
+		{"Server only", "The cat", "The cat", "The cat sat", "The cat sat"},
+		{"Disjoint edits", "The cat sat.", "A cat sat.", "The cat ran.", "A cat ran."},
+		{"Same insertion point", "ac", "abc", "axc", "abxc"},
+		{"Overlapping deletions", "abcdef", "af", "abef", "af"},
+		{"Delete and insert inside", "abcdef", "af", "abcXdef", "aXf"},
+		{"Unicode", "日本語", "日本の語", "日語", "日の語"},
+	} {
+		clientDiffs := dmp.DiffMain(tc.Text, tc.Client, false)
+		serverDiffs := dmp.DiffMain(tc.Text, tc.Server, false)
+
+		clientPrime, serverPrime := dmp.DiffTransform(clientDiffs, serverDiffs)
+
+		assert.Equal(t, tc.Server, dmp.DiffText1(clientPrime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Client, dmp.DiffText1(serverPrime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, dmp.DiffText2(clientPrime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, dmp.DiffText2(serverPrime), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	clientPrime, serverPrime := dmp.DiffTransform([]Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "ab"}})
+	assert.Nil(t, clientPrime)
+	assert.Nil(t, serverPrime)
+	_, _, err := dmp.DiffTransformChecked([]Diff{{DiffEqual, "abc"}}, []Diff{{DiffEqual, "ab"}})
+	assert.EqualError(t, err, "Diffs do not share the same source text (client remaining 1, server remaining 0)")
+}
@@ -0,0 +1,471 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"errors"
+	"fmt"
+	"hash/adler32"
+)
+
+// VCDIFF (RFC 3284) header and window indicator bits.
+const (
+	vcdDecompress = 0x01
+	vcdCodeTable  = 0x02
+	// vcdAppHeader is an extension of open-vcdiff and xdelta for an application specific header.
+	vcdAppHeader = 0x04
+
+	vcdSource = 0x01
+	vcdTarget = 0x02
+	// vcdAdler32 is an extension of open-vcdiff for a checksum of the target window.
+	vcdAdler32 = 0x04
+)
+
+// VCDIFF instruction types.
+const (
+	vcdNoop = iota
+	vcdAdd
+	vcdRun
+	vcdCopy
+)
+
+// Sizes of the address caches of the default code table.
+const (
+	vcdNearSize = 4
+	vcdSameSize = 3
+)
+
+var vcdiffMagic = []byte{0xd6, 0xc3, 0xc4, 0x00}
+
+// vcdiffInstruction is one half of an entry of a VCDIFF code table.
+type vcdiffInstruction struct {
+	typ  int
+	size int
+	mode int
+}
+
+// vcdiffCodeTable is the default code table of RFC 3284 section 5.6.
+var vcdiffCodeTable = func() (table [256][2]vcdiffInstruction) {
+	i := 0
+	table[i][0] = vcdiffInstruction{vcdRun, 0, 0}
+	i++
+	for size := 0; size <= 17; size++ {
+		table[i][0] = vcdiffInstruction{vcdAdd, size, 0}
+		i++
+	}
+	for mode := 0; mode <= 8; mode++ {
+		table[i][0] = vcdiffInstruction{vcdCopy, 0, mode}
+		i++
+		for size := 4; size <= 18; size++ {
+			table[i][0] = vcdiffInstruction{vcdCopy, size, mode}
+			i++
+		}
+	}
+	for mode := 0; mode <= 5; mode++ {
+		for addSize := 1; addSize <= 4; addSize++ {
+			for copySize := 4; copySize <= 6; copySize++ {
+				table[i] = [2]vcdiffInstruction{{vcdAdd, addSize, 0}, {vcdCopy, copySize, mode}}
+				i++
+			}
+		}
+	}
+	for mode := 6; mode <= 8; mode++ {
+		for addSize := 1; addSize <= 4; addSize++ {
+			table[i] = [2]vcdiffInstruction{{vcdAdd, addSize, 0}, {vcdCopy, 4, mode}}
+			i++
+		}
+	}
+	for mode := 0; mode <= 8; mode++ {
+		table[i] = [2]vcdiffInstruction{{vcdCopy, 4, mode}, {vcdAdd, 1, 0}}
+		i++
+	}
+	return table
+}()
+
+// DiffToVCDiff encodes a diff as a VCDIFF (RFC 3284) delta, as used by xdelta and SDCH, with the source text as source segment.
+// Equalities become copies from the source text and insertions become adds, using the default code table and no secondary compression.
+func (dmp *DiffMatchPatch) DiffToVCDiff(diffs []Diff) []byte {
+	var data, instructions, addresses []byte
+	offset := 0 // Offset into the source text.
+	for _, aDiff := range diffs {
+		if len(aDiff.Text) == 0 {
+			continue
+		}
+		switch aDiff.Type {
+		case DiffEqual:
+			// A copy of explicit size in VCD_SELF mode, i.e. at an absolute address.
+			instructions = append(instructions, 19)
+			instructions = appendVCDiffInt(instructions, len(aDiff.Text))
+			addresses = appendVCDiffInt(addresses, offset)
+			offset += len(aDiff.Text)
+		case DiffInsert:
+			// An add of explicit size.
+			instructions = append(instructions, 1)
+			instructions = appendVCDiffInt(instructions, len(aDiff.Text))
+			data = append(data, aDiff.Text...)
+		case DiffDelete:
+			offset += len(aDiff.Text)
+		}
+	}
+	sourceLength := len(dmp.DiffText1(diffs))
+
+	var encoding []byte
+	encoding = appendVCDiffInt(encoding, len(dmp.DiffText2(diffs)))
+	encoding = append(encoding, 0) // No compressed sections.
+	encoding = appendVCDiffInt(encoding, len(data))
+	encoding = appendVCDiffInt(encoding, len(instructions))
+	encoding = appendVCDiffInt(encoding, len(addresses))
+	encoding = append(encoding, data...)
+	encoding = append(encoding, instructions...)
+	encoding = append(encoding, addresses...)
+
+	delta := append([]byte{}, vcdiffMagic...)
+	delta = append(delta, 0) // No header extensions.
+	if sourceLength > 0 {
+		delta = append(delta, vcdSource)
+		delta = appendVCDiffInt(delta, sourceLength)
+		delta = appendVCDiffInt(delta, 0)
+	} else {
+		delta = append(delta, 0)
+	}
+	delta = appendVCDiffInt(delta, len(encoding))
+	return append(delta, encoding...)
+}
+
+// VCDiffToDiff decodes a VCDIFF (RFC 3284) delta of the source text text1 into a diff.
+// Secondary compression and custom code tables are not supported.  Copies which move forward through the source text become equalities, all other output becomes insertions.
+func (dmp *DiffMatchPatch) VCDiffToDiff(text1 string, delta []byte) (_ []Diff, err error) {
+	defer dmp.recoverInternal(&err)
+	r := &vcdiffReader{data: delta}
+	magic, err := r.bytes(len(vcdiffMagic))
+	if err != nil || string(magic[:3]) != string(vcdiffMagic[:3]) {
+		return nil, errors.New("Missing VCDIFF header")
+	}
+	if magic[3] != 0 {
+		return nil, fmt.Errorf("Unsupported VCDIFF version %v", magic[3])
+	}
+	indicator, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	if indicator&(vcdDecompress|vcdCodeTable) != 0 {
+		return nil, errors.New("VCDIFF secondary compression and custom code tables are not supported")
+	}
+	if indicator&vcdAppHeader != 0 {
+		n, err := r.int()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.bytes(n); err != nil {
+			return nil, err
+		}
+	}
+
+	d := &vcdiffDecoder{text1: text1}
+	for len(r.data) > 0 {
+		if err := d.window(r); err != nil {
+			return nil, err
+		}
+	}
+	if d.pointer < len(text1) {
+		d.diffs = append(d.diffs, Diff{DiffDelete, text1[d.pointer:]})
+	}
+
+	return dmp.DiffCleanupMerge(d.diffs), nil
+}
+
+// vcdiffDecoder holds the state of decoding a VCDIFF delta across windows.
+type vcdiffDecoder struct {
+	text1 string
+	// The target text decoded so far.
+	target []byte
+	// Offset into the source text up to which it has been consumed.
+	pointer int
+	diffs   []Diff
+
+	near     [vcdNearSize]int
+	nextSlot int
+	same     [vcdSameSize * 256]int
+}
+
+// window decodes the next window of the delta.
+func (d *vcdiffDecoder) window(r *vcdiffReader) error {
+	indicator, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if indicator&vcdSource != 0 && indicator&vcdTarget != 0 {
+		return errors.New("VCDIFF window has both a source and a target segment")
+	}
+	// The source segment, and its offset into text1 if it is taken from there.
+	var segment []byte
+	segmentOffset := -1
+	if indicator&(vcdSource|vcdTarget) != 0 {
+		size, err := r.int()
+		if err != nil {
+			return err
+		}
+		position, err := r.int()
+		if err != nil {
+			return err
+		}
+		if indicator&vcdSource != 0 {
+			if size > len(d.text1) || position > len(d.text1)-size {
+				return fmt.Errorf("VCDIFF source segment (%v bytes at %v) exceeds the source text length (%v)", size, position, len(d.text1))
+			}
+			segment = []byte(d.text1[position : position+size])
+			segmentOffset = position
+		} else {
+			if size > len(d.target) || position > len(d.target)-size {
+				return fmt.Errorf("VCDIFF target segment (%v bytes at %v) exceeds the decoded length (%v)", size, position, len(d.target))
+			}
+			segment = append([]byte{}, d.target[position:position+size]...)
+		}
+	}
+
+	length, err := r.int()
+	if err != nil {
+		return err
+	}
+	encoding, err := r.bytes(length)
+	if err != nil {
+		return err
+	}
+	e := &vcdiffReader{data: encoding}
+	targetLength, err := e.int()
+	if err != nil {
+		return err
+	}
+	deltaIndicator, err := e.byte()
+	if err != nil {
+		return err
+	}
+	if deltaIndicator != 0 {
+		return errors.New("VCDIFF secondary compression is not supported")
+	}
+	var sections [3]int
+	for i := range sections {
+		if sections[i], err = e.int(); err != nil {
+			return err
+		}
+	}
+	var checksum []byte
+	if indicator&vcdAdler32 != 0 {
+		if checksum, err = e.bytes(4); err != nil {
+			return err
+		}
+	}
+	dataSection, err := e.bytes(sections[0])
+	if err != nil {
+		return err
+	}
+	instructionSection, err := e.bytes(sections[1])
+	if err != nil {
+		return err
+	}
+	addressSection, err := e.bytes(sections[2])
+	if err != nil {
+		return err
+	}
+	if len(e.data) != 0 {
+		return errors.New("Trailing data in VCDIFF window")
+	}
+	data := &vcdiffReader{data: dataSection}
+	instructions := &vcdiffReader{data: instructionSection}
+	addresses := &vcdiffReader{data: addressSection}
+
+	d.near = [vcdNearSize]int{}
+	d.nextSlot = 0
+	d.same = [vcdSameSize * 256]int{}
+
+	var window []byte
+	for len(instructions.data) > 0 {
+		index, _ := instructions.byte()
+		for _, inst := range vcdiffCodeTable[index] {
+			if inst.typ == vcdNoop {
+				continue
+			}
+			size := inst.size
+			if size == 0 {
+				if size, err = instructions.int(); err != nil {
+					return err
+				}
+			}
+			if size > targetLength-len(window) {
+				return fmt.Errorf("VCDIFF window exceeds its target length (%v)", targetLength)
+			}
+
+			switch inst.typ {
+			case vcdAdd:
+				text, err := data.bytes(size)
+				if err != nil {
+					return err
+				}
+				window = append(window, text...)
+				d.insert(text)
+			case vcdRun:
+				b, err := data.byte()
+				if err != nil {
+					return err
+				}
+				start := len(window)
+				for i := 0; i < size; i++ {
+					window = append(window, b)
+				}
+				d.insert(window[start:])
+			case vcdCopy:
+				here := len(segment) + len(window)
+				address, err := d.address(addresses, inst.mode, here)
+				if err != nil {
+					return err
+				}
+				if segmentOffset != -1 && address+size <= len(segment) {
+					window = append(window, segment[address:address+size]...)
+					d.copySource(segmentOffset+address, size)
+					continue
+				}
+				// Copies from the target window may overlap the data they produce.
+				start := len(window)
+				for i := address; i < address+size; i++ {
+					if i < len(segment) {
+						window = append(window, segment[i])
+					} else {
+						window = append(window, window[i-len(segment)])
+					}
+				}
+				d.insert(window[start:])
+			}
+		}
+	}
+	if len(window) != targetLength {
+		return fmt.Errorf("VCDIFF window target length (%v) is different from decoded length (%v)", targetLength, len(window))
+	}
+	if checksum != nil {
+		sum := adler32.Checksum(window)
+		if byte(sum>>24) != checksum[0] || byte(sum>>16) != checksum[1] || byte(sum>>8) != checksum[2] || byte(sum) != checksum[3] {
+			return errors.New("VCDIFF window checksum mismatch")
+		}
+	}
+
+	return nil
+}
+
+// address decodes the address of a copy with the given mode, checks that it comes before here and updates the address caches.
+func (d *vcdiffDecoder) address(r *vcdiffReader, mode int, here int) (int, error) {
+	var address int
+	switch {
+	case mode == 0:
+		n, err := r.int()
+		if err != nil {
+			return 0, err
+		}
+		address = n
+	case mode == 1:
+		n, err := r.int()
+		if err != nil {
+			return 0, err
+		}
+		address = here - n
+	case mode < 2+vcdNearSize:
+		n, err := r.int()
+		if err != nil {
+			return 0, err
+		}
+		address = d.near[mode-2] + n
+	default:
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		address = d.same[(mode-2-vcdNearSize)*256+int(b)]
+	}
+	if address < 0 || address >= here {
+		return 0, fmt.Errorf("VCDIFF copy address %v out of bounds", address)
+	}
+
+	d.near[d.nextSlot] = address
+	d.nextSlot = (d.nextSlot + 1) % vcdNearSize
+	d.same[address%(vcdSameSize*256)] = address
+	return address, nil
+}
+
+// insert records text as inserted into the target.
+func (d *vcdiffDecoder) insert(text []byte) {
+	d.target = append(d.target, text...)
+	d.diffs = append(d.diffs, Diff{DiffInsert, string(text)})
+}
+
+// copySource records size bytes at offset of the source text as copied into the target.
+func (d *vcdiffDecoder) copySource(offset, size int) {
+	text := d.text1[offset : offset+size]
+	d.target = append(d.target, text...)
+	if offset < d.pointer {
+		// The data was already consumed, it has to be inserted again.
+		d.diffs = append(d.diffs, Diff{DiffInsert, text})
+		return
+	}
+	if offset > d.pointer {
+		d.diffs = append(d.diffs, Diff{DiffDelete, d.text1[d.pointer:offset]})
+	}
+	d.diffs = append(d.diffs, Diff{DiffEqual, text})
+	d.pointer = offset + size
+}
+
+// vcdiffReader consumes the sections of a VCDIFF delta.
+type vcdiffReader struct {
+	data []byte
+}
+
+func (r *vcdiffReader) byte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("Truncated VCDIFF delta")
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b, nil
+}
+
+func (r *vcdiffReader) bytes(n int) ([]byte, error) {
+	if n < 0 || n > len(r.data) {
+		return nil, errors.New("Truncated VCDIFF delta")
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+// int reads a big-endian base 128 number.
+func (r *vcdiffReader) int() (int, error) {
+	n := 0
+	for i := 0; ; i++ {
+		if i == 9 {
+			return 0, errors.New("Integer in VCDIFF delta is too large")
+		}
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		n = n<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			return n, nil
+		}
+	}
+}
+
+// appendVCDiffInt appends n as a big-endian base 128 number.
+func appendVCDiffInt(b []byte, n int) []byte {
+	var buf [10]byte
+	i := len(buf) - 1
+	buf[i] = byte(n & 0x7f)
+	for n >>= 7; n > 0; n >>= 7 {
+		i--
+		buf[i] = byte(n&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
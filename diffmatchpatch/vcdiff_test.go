@@ -0,0 +1,133 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffToVCDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Diffs []Diff
+
+		Expected []byte
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Null case", []Diff{}, []byte{0xd6, 0xc3, 0xc4, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"Copy, skip and add", []Diff{{DiffEqual, "abc"}, {DiffDelete, "d"}, {DiffInsert, "xy"}, {DiffEqual, "ef"}}, []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x06, 0x00, 0x0f,
+			0x07, 0x00, 0x02, 0x06, 0x02,
+			'x', 'y',
+			19, 0x03, 1, 0x02, 19, 0x02,
+			0x00, 0x04,
+		}},
+		{"Large sizes", []Diff{{DiffInsert, string(make([]byte, 200))}}, append(append([]byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x00, 0x81, 0x52,
+			0x81, 0x48, 0x00, 0x81, 0x48, 0x03, 0x00,
+		}, make([]byte, 200)...), 1, 0x81, 0x48)},
+	} {
+		actual := dmp.DiffToVCDiff(tc.Diffs)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestVCDiffToDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1 string
+		Delta []byte
+
+		Expected      []Diff
+		ErrorContains string
+	}
+
+	dmp := New()
+
+	for i, tc := range []TestCase{
+		{"Copy, skip and add", "abcdef", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x06, 0x00, 0x0f,
+			0x07, 0x00, 0x02, 0x06, 0x02,
+			'x', 'y',
+			19, 0x03, 1, 0x02, 19, 0x02,
+			0x00, 0x04,
+		}, []Diff{{DiffEqual, "abc"}, {DiffDelete, "d"}, {DiffInsert, "xy"}, {DiffEqual, "ef"}}, ""},
+		{"Code table and address modes", "abcdefgh", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x08, 0x00, 0x13,
+			0x18, 0x00, 0x02, 0x07, 0x05,
+			'X', '!',
+			// ADD 1 + COPY 4 (self), COPY 4 (here), COPY 4 (self), RUN 3, COPY 4 (near 1), COPY 4 (same 0).
+			163, 36, 20, 0, 0x03, 68, 116,
+			0x00, 0x09, 0x08, 0x00, 0x08,
+		}, []Diff{{DiffInsert, "X"}, {DiffEqual, "abcdefgh"}, {DiffInsert, "Xabc!!!efghXabc"}}, ""},
+		{"Several windows", "abcdef", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x02, 0x04, 0x0b,
+			0x04, 0x00, 0x00, 0x04, 0x02, 19, 0x02, 19, 0x02, 0x00, 0x02,
+			// A window copying from the target of the first one.
+			0x02, 0x02, 0x01, 0x0a,
+			0x03, 0x00, 0x01, 0x03, 0x01, 'z', 2, 19, 0x02, 0x00,
+		}, []Diff{{DiffDelete, "abcd"}, {DiffEqual, "ef"}, {DiffInsert, "efzfe"}}, ""},
+		{"Checksum", "", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x04, 0x0c,
+			0x02, 0x00, 0x02, 0x01, 0x00, 0x01, 0x26, 0x00, 0xc4, 'a', 'b', 3,
+		}, []Diff{{DiffInsert, "ab"}}, ""},
+		{"Checksum mismatch", "", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x04, 0x0c,
+			0x02, 0x00, 0x02, 0x01, 0x00, 0x01, 0x26, 0x00, 0xc5, 'a', 'b', 3,
+		}, nil, "checksum"},
+		{"Application header", "", []byte{0xd6, 0xc3, 0xc4, 0x00, 0x04, 0x02, 'h', 'i'}, []Diff{}, ""},
+		{"Missing header", "", []byte{0xd6, 0xc3}, nil, "Missing VCDIFF header"},
+		{"Secondary compression", "", []byte{0xd6, 0xc3, 0xc4, 0x00, 0x01}, nil, "not supported"},
+		{"Source out of bounds", "ab", []byte{0xd6, 0xc3, 0xc4, 0x00, 0x00, 0x01, 0x03, 0x00}, nil, "exceeds the source text"},
+		{"Copy out of bounds", "ab", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x02, 0x00, 0x08,
+			0x01, 0x00, 0x00, 0x02, 0x01, 19, 0x01, 0x02,
+		}, nil, "out of bounds"},
+		{"Copy address before the start", "a", []byte("\xd6\xc3\xc4\x00\x00\x01\x01\x00\x07\x04\x00\x00\x01\x01\x24\x05"), nil, "out of bounds"},
+		{"Target length mismatch", "ab", []byte{
+			0xd6, 0xc3, 0xc4, 0x00, 0x00,
+			0x01, 0x02, 0x00, 0x08,
+			0x03, 0x00, 0x00, 0x02, 0x01, 19, 0x02, 0x00,
+		}, nil, "target length"},
+		{"Truncated window", "", []byte{0xd6, 0xc3, 0xc4, 0x00, 0x00, 0x00, 0x05, 0x00}, nil, "Truncated"},
+	} {
+		actual, err := dmp.VCDiffToDiff(tc.Text1, tc.Delta)
+		if tc.ErrorContains != "" {
+			if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+				assert.Contains(t, err.Error(), tc.ErrorContains, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+			}
+		} else {
+			assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+
+	// Deltas round-trip.
+	text1, text2 := speedtestTexts()
+	diffs := dmp.DiffMain(text1, text2, false)
+	actual, err := dmp.VCDiffToDiff(text1, dmp.DiffToVCDiff(diffs))
+	assert.NoError(t, err)
+	assert.Equal(t, diffs, actual)
+}
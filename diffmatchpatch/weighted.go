@@ -0,0 +1,111 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+// Gap stands for the missing rune of an insertion or a deletion in a WeightFunc.
+const Gap rune = -1
+
+// WeightFunc returns the cost of an edit for DiffMainWeighted, which must not be negative: of deleting r1 if r2 is Gap, of inserting r2 if r1 is Gap, and of substituting r2 for r1 otherwise.
+// E.g. edits of white space may cost less than edits of letters, or substitutions of characters which OCR confuses less than other substitutions.
+type WeightFunc func(r1, r2 rune) float64
+
+// unitWeight is the WeightFunc of the Levenshtein distance.
+func unitWeight(r1, r2 rune) float64 {
+	return 1
+}
+
+// Moves of the backtrace of DiffMainWeighted.
+const (
+	weightedMatch byte = iota
+	weightedDelete
+	weightedInsert
+)
+
+// DiffMainWeighted finds the differences between two texts with the least total cost of their edits according to weight, where a substitution is a deletion and an insertion (nil for the costs of the Levenshtein distance).
+// Unlike DiffMain, it takes time and memory proportional to the product of the lengths of the texts after their common prefix and suffix.
+func (dmp *DiffMatchPatch) DiffMainWeighted(text1, text2 string, weight WeightFunc) []Diff {
+	if weight == nil {
+		weight = unitWeight
+	}
+	runes1, runes2 := []rune(text1), []rune(text2)
+
+	// Trim off the common prefix and suffix, which cost nothing.
+	prefixLength := commonPrefixLength(runes1, runes2)
+	prefix := runes1[:prefixLength]
+	runes1, runes2 = runes1[prefixLength:], runes2[prefixLength:]
+	suffixLength := commonSuffixLength(runes1, runes2)
+	suffix := runes1[len(runes1)-suffixLength:]
+	runes1, runes2 = runes1[:len(runes1)-suffixLength], runes2[:len(runes2)-suffixLength]
+
+	n, m := len(runes1), len(runes2)
+	// moves[i*(m+1)+j] is the last move of the cheapest alignment of runes1[:i] with runes2[:j].
+	moves := make([]byte, (n+1)*(m+1))
+	prev := make([]float64, m+1)
+	cur := make([]float64, m+1)
+	for j := 1; j <= m; j++ {
+		prev[j] = prev[j-1] + weight(Gap, runes2[j-1])
+		moves[j] = weightedInsert
+	}
+	for i := 1; i <= n; i++ {
+		cur[0] = prev[0] + weight(runes1[i-1], Gap)
+		moves[i*(m+1)] = weightedDelete
+		for j := 1; j <= m; j++ {
+			// Prefer matches and substitutions, then deletions, then insertions.
+			cost, move := prev[j-1], weightedMatch
+			if runes1[i-1] != runes2[j-1] {
+				cost += weight(runes1[i-1], runes2[j-1])
+			}
+			if c := prev[j] + weight(runes1[i-1], Gap); c < cost {
+				cost, move = c, weightedDelete
+			}
+			if c := cur[j-1] + weight(Gap, runes2[j-1]); c < cost {
+				cost, move = c, weightedInsert
+			}
+			cur[j], moves[i*(m+1)+j] = cost, move
+		}
+		prev, cur = cur, prev
+	}
+
+	// Backtrace the cheapest alignment from the end.
+	var reversed []Diff
+	add := func(op Operation, r rune) {
+		reversed = append(reversed, Diff{op, string(r)})
+	}
+	for i, j := n, m; i > 0 || j > 0; {
+		switch moves[i*(m+1)+j] {
+		case weightedMatch:
+			if runes1[i-1] == runes2[j-1] {
+				add(DiffEqual, runes1[i-1])
+			} else {
+				add(DiffInsert, runes2[j-1])
+				add(DiffDelete, runes1[i-1])
+			}
+			i--
+			j--
+		case weightedDelete:
+			add(DiffDelete, runes1[i-1])
+			i--
+		case weightedInsert:
+			add(DiffInsert, runes2[j-1])
+			j--
+		}
+	}
+
+	diffs := make([]Diff, 0, len(reversed)+2)
+	if len(prefix) != 0 {
+		diffs = append(diffs, Diff{DiffEqual, string(prefix)})
+	}
+	for k := len(reversed) - 1; k >= 0; k-- {
+		diffs = append(diffs, reversed[k])
+	}
+	if len(suffix) != 0 {
+		diffs = append(diffs, Diff{DiffEqual, string(suffix)})
+	}
+	return dmp.DiffCleanupMerge(diffs)
+}
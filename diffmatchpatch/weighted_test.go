@@ -0,0 +1,64 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMainWeighted(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text1  string
+		Text2  string
+		Weight WeightFunc
+
+		Expected []Diff
+	}
+
+	dmp := New()
+
+	whiteSpace := func(r1, r2 rune) float64 {
+		if (r1 == Gap || unicode.IsSpace(r1)) && (r2 == Gap || unicode.IsSpace(r2)) {
+			return 0.1
+		}
+		return 1
+	}
+	ocr := func(r1, r2 rune) float64 {
+		if r1 == 'l' && r2 == '1' || r1 == 'O' && r2 == '0' {
+			return 0.1
+		}
+		if r1 != Gap && r2 != Gap {
+			return 2
+		}
+		return 1
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", "", nil, []Diff{}},
+		{"Equal", "abc", "abc", nil, []Diff{{DiffEqual, "abc"}}},
+		{"Deletion", "abc", "", nil, []Diff{{DiffDelete, "abc"}}},
+		{"Insertion", "日本語", "日本人語", nil, []Diff{{DiffEqual, "日本"}, {DiffInsert, "人"}, {DiffEqual, "語"}}},
+		{"Levenshtein substitutions", "ab cd", "abc d", nil, []Diff{{DiffEqual, "ab"}, {DiffDelete, " c"}, {DiffInsert, "c "}, {DiffEqual, "d"}}},
+		{"Cheap white space", "ab cd", "abc d", whiteSpace, []Diff{{DiffEqual, "ab"}, {DiffDelete, " "}, {DiffEqual, "c"}, {DiffInsert, " "}, {DiffEqual, "d"}}},
+		{"Levenshtein OCR", "Ol", "l0", nil, []Diff{{DiffDelete, "Ol"}, {DiffInsert, "l0"}}},
+		{"Cheap OCR confusions", "Ol", "l0", ocr, []Diff{{DiffDelete, "O"}, {DiffEqual, "l"}, {DiffInsert, "0"}}},
+		{"Substituted OCR confusions", "lab", "1ba", ocr, []Diff{{DiffDelete, "l"}, {DiffInsert, "1b"}, {DiffEqual, "a"}, {DiffDelete, "b"}}},
+	} {
+		actual := dmp.DiffMainWeighted(tc.Text1, tc.Text2, tc.Weight)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text1, dmp.DiffText1(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Text2, dmp.DiffText2(actual), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordSegmenter splits a text into the words compared by DiffMainWords, e.g. with a dictionary of a language which is written without spaces, like Chinese or Japanese.
+type WordSegmenter interface {
+	// Segment splits text into words, which joined together give back the text.
+	Segment(text string) []string
+}
+
+// WordSegmenterFunc adapts a function to the WordSegmenter interface.
+type WordSegmenterFunc func(text string) []string
+
+// Segment calls f(text).
+func (f WordSegmenterFunc) Segment(text string) []string {
+	return f(text)
+}
+
+// ScriptSegmenter is the default WordSegmenter.  Its words are runs of letters and digits of the same Unicode script, runs of white space, and single punctuation characters.
+// Since the script changes between the Han characters, the hiragana and the katakana of Japanese text, those runs approximate its words.
+type ScriptSegmenter struct{}
+
+// Segment splits text into words.
+func (ScriptSegmenter) Segment(text string) []string {
+	var words []string
+	for len(text) != 0 {
+		word := nextScriptWord(text)
+		words = append(words, word)
+		text = text[len(word):]
+	}
+	return words
+}
+
+// nextScriptWord returns the word which starts the text.
+func nextScriptWord(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	if unicode.IsSpace(first) {
+		if end := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsSpace(r) }); end != -1 {
+			return text[:end]
+		}
+		return text
+	}
+	if !isWordRune(first) {
+		// Punctuation characters are words of their own.
+		return text[:size]
+	}
+	// Characters common to all scripts, like digits, join the script of the word.
+	script := scriptOf(first)
+	for i, r := range text[size:] {
+		if !isWordRune(r) {
+			return text[:size+i]
+		}
+		if s := scriptOf(r); s != nil {
+			if script == nil {
+				script = s
+			} else if s != script {
+				return text[:size+i]
+			}
+		}
+	}
+	return text
+}
+
+// isWordRune returns whether r is part of words rather than white space or punctuation.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_' || r == 'ー'
+}
+
+// wordScripts are the scripts which tell words apart.
+var wordScripts = []*unicode.RangeTable{
+	unicode.Latin, unicode.Greek, unicode.Cyrillic, unicode.Armenian, unicode.Hebrew, unicode.Arabic,
+	unicode.Devanagari, unicode.Bengali, unicode.Thai, unicode.Lao, unicode.Tibetan, unicode.Georgian,
+	unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Bopomofo,
+}
+
+// scriptOf returns the script of r among wordScripts, nil for characters common to all scripts and those of other scripts.
+func scriptOf(r rune) *unicode.RangeTable {
+	for _, script := range wordScripts {
+		if unicode.Is(script, r) {
+			return script
+		}
+	}
+	return nil
+}
+
+// DiffWordsToIndexes splits two texts into words with WordSegmenter, ScriptSegmenter if it is nil, and reduces the texts to the indexes of their words in the returned array of distinct words.
+// A text which the segmenter does not split into words joining back to the text is split by ScriptSegmenter instead.
+func (dmp *DiffMatchPatch) DiffWordsToIndexes(text1, text2 string) ([]int, []int, []string) {
+	var wordArray []string
+	wordHash := map[string]int{}
+	toIndexes := func(text string) []int {
+		words := dmp.segmentWords(text)
+		indexes := make([]int, len(words))
+		for i, word := range words {
+			index, ok := wordHash[word]
+			if !ok {
+				index = len(wordArray)
+				wordArray = append(wordArray, word)
+				wordHash[word] = index
+			}
+			indexes[i] = index
+		}
+		return indexes
+	}
+	words1 := toIndexes(text1)
+	words2 := toIndexes(text2)
+	return words1, words2, wordArray
+}
+
+// DiffMainWords finds the differences between two texts word by word, as split by DiffWordsToIndexes.
+func (dmp *DiffMatchPatch) DiffMainWords(text1, text2 string) []Diff {
+	words1, words2, wordArray := dmp.DiffWordsToIndexes(text1, text2)
+	return dmp.DiffIndexesToLines(dmp.DiffMainIndexes(words1, words2), wordArray)
+}
+
+// segmentWords splits text into non-empty words with the configured segmenter.
+func (dmp *DiffMatchPatch) segmentWords(text string) []string {
+	if dmp.WordSegmenter != nil {
+		words := dmp.WordSegmenter.Segment(text)
+		pos := 0
+		nonEmpty := words[:0:0]
+		for _, word := range words {
+			if !strings.HasPrefix(text[pos:], word) {
+				pos = -1
+				break
+			}
+			pos += len(word)
+			if len(word) != 0 {
+				nonEmpty = append(nonEmpty, word)
+			}
+		}
+		if pos == len(text) {
+			return nonEmpty
+		}
+	}
+	return ScriptSegmenter{}.Segment(text)
+}
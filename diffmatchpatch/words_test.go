@@ -0,0 +1,91 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package diffmatchpatch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptSegmenter(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Text string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Empty", "", nil},
+		{"Latin", "Hello, world!", []string{"Hello", ",", " ", "world", "!"}},
+		{"Digits and underscores", "x_1 = 42;\n", []string{"x_1", " ", "=", " ", "42", ";", "\n"}},
+		{"Japanese", "日本語のテキストです。", []string{"日本語", "の", "テキスト", "です", "。"}},
+		{"Prolonged sound mark", "コーヒーを飲む", []string{"コーヒー", "を", "飲", "む"}},
+		{"Chinese and Latin", "我爱Go语言", []string{"我爱", "Go", "语言"}},
+		{"Digits join the script", "2024年12月", []string{"2024年12月"}},
+		{"Combining marks", "café au lait", []string{"café", " ", "au", " ", "lait"}},
+		{"White space runs", "a \t\nb", []string{"a", " \t\n", "b"}},
+	} {
+		actual := ScriptSegmenter{}.Segment(tc.Text)
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffWordsToIndexes(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Segmenter WordSegmenter
+		Text1     string
+		Text2     string
+
+		ExpectedIndexes1 []int
+		ExpectedIndexes2 []int
+		ExpectedWords    []string
+	}
+
+	byRune := WordSegmenterFunc(func(text string) []string { return strings.Split(text, "") })
+
+	for i, tc := range []TestCase{
+		{"Empty", nil, "", "", []int{}, []int{}, nil},
+		{"Default segmenter", nil, "Hello, world!", "hello  world", []int{0, 1, 2, 3, 4}, []int{5, 6, 3}, []string{"Hello", ",", " ", "world", "!", "hello", "  "}},
+		{"Japanese", nil, "日本語のテキスト", "英語のテキスト", []int{0, 1, 2}, []int{3, 1, 2}, []string{"日本語", "の", "テキスト", "英語"}},
+		{"Custom segmenter", byRune, "日本", "本日", []int{0, 1}, []int{1, 0}, []string{"日", "本"}},
+		{"Empty words are dropped", WordSegmenterFunc(func(text string) []string { return []string{"", text, ""} }), "ab", "", []int{0}, []int{}, []string{"ab"}},
+		{"Words not joining back to the text", WordSegmenterFunc(func(text string) []string { return strings.Fields(text) }), "a b", "a", []int{0, 1, 2}, []int{0}, []string{"a", " ", "b"}},
+	} {
+		dmp := New()
+		dmp.WordSegmenter = tc.Segmenter
+		actualIndexes1, actualIndexes2, actualWords := dmp.DiffWordsToIndexes(tc.Text1, tc.Text2)
+		assert.Equal(t, tc.ExpectedIndexes1, actualIndexes1, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedIndexes2, actualIndexes2, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.ExpectedWords, actualWords, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestDiffMainWords(t *testing.T) {
+	dmp := New()
+
+	assert.Equal(t, []Diff{
+		{DiffDelete, "日本語"},
+		{DiffInsert, "英語"},
+		{DiffEqual, "のテキスト"},
+	}, dmp.DiffMainWords("日本語のテキスト", "英語のテキスト"))
+
+	assert.Equal(t, []Diff{
+		{DiffEqual, "The "},
+		{DiffDelete, "quick"},
+		{DiffInsert, "slow"},
+		{DiffEqual, " fox."},
+	}, dmp.DiffMainWords("The quick fox.", "The slow fox."))
+}
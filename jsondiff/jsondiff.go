@@ -0,0 +1,283 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+// Package jsondiff compares JSON documents structurally, object member by object member and array element by array element, rather than as text.
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ChangeType is the kind of a change between two JSON documents.
+type ChangeType int8
+
+const (
+	// Added is a value which only the second document has.
+	Added ChangeType = iota
+	// Removed is a value which only the first document has.
+	Removed
+	// Replaced is a value which the second document has in place of a different value of the first one.
+	Replaced
+)
+
+// String returns a human readable name of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Replaced:
+		return "Replaced"
+	}
+	return fmt.Sprintf("ChangeType(%d)", t)
+}
+
+// Change is one difference between two JSON documents.
+type Change struct {
+	Type ChangeType
+	// JSON Pointer (RFC 6901) to the value.  Paths of removed values refer to the first document, all other paths to the second.
+	Path string
+	// The value in the first document, nil if it was added.
+	Old interface{}
+	// The value in the second document, nil if it was removed.
+	New interface{}
+}
+
+// String returns the change on one line, e.g. "Replaced /a/0: 1 -> 2".
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("%v %s: %s", c.Type, c.Path, encode(c.New, ""))
+	case Removed:
+		return fmt.Sprintf("%v %s: %s", c.Type, c.Path, encode(c.Old, ""))
+	}
+	return fmt.Sprintf("%v %s: %s -> %s", c.Type, c.Path, encode(c.Old, ""), encode(c.New, ""))
+}
+
+// decode parses a JSON document, keeping its numbers as json.Number so that they are compared exactly.
+func decode(doc []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("Trailing data after JSON document")
+	}
+	return v, nil
+}
+
+// decodeBoth parses the two documents to compare.
+func decodeBoth(doc1, doc2 []byte) (interface{}, interface{}, error) {
+	v1, err := decode(doc1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid first JSON document: %v", err)
+	}
+	v2, err := decode(doc2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid second JSON document: %v", err)
+	}
+	return v1, v2, nil
+}
+
+// encode returns the JSON encoding of a value, with the members of objects sorted by their names, compact or indented by indent.
+// Values which JSON cannot encode, such as NaN or functions given to CompareValues or in a Change, are formatted by fmt instead.
+func encode(v interface{}, indent string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Compare parses two JSON documents and returns their differences, ordered by their paths in the documents.
+func Compare(doc1, doc2 []byte) ([]Change, error) {
+	v1, v2, err := decodeBoth(doc1, doc2)
+	if err != nil {
+		return nil, err
+	}
+	return CompareValues(v1, v2), nil
+}
+
+// CompareValues returns the differences between two values decoded by encoding/json into interface{} values.
+// Members of objects are matched by their names, elements of arrays by a diff of the arrays, so that inserting an element reports a single change.  Removed and added elements of an array which are both objects or both arrays at the same place are compared in turn.
+func CompareValues(v1, v2 interface{}) []Change {
+	changes := []Change{}
+	return compare(changes, "", v1, v2)
+}
+
+// compare appends the differences between the values at path to changes.
+func compare(changes []Change, path string, v1, v2 interface{}) []Change {
+	if reflect.DeepEqual(v1, v2) {
+		return changes
+	}
+	switch t1 := v1.(type) {
+	case map[string]interface{}:
+		if t2, ok := v2.(map[string]interface{}); ok {
+			return compareObjects(changes, path, t1, t2)
+		}
+	case []interface{}:
+		if t2, ok := v2.([]interface{}); ok {
+			return compareArrays(changes, path, t1, t2)
+		}
+	}
+	return append(changes, Change{Type: Replaced, Path: path, Old: v1, New: v2})
+}
+
+// compareObjects appends the differences between the members of two objects at path to changes.
+func compareObjects(changes []Change, path string, o1, o2 map[string]interface{}) []Change {
+	names := make([]string, 0, len(o1)+len(o2))
+	for name := range o1 {
+		names = append(names, name)
+	}
+	for name := range o2 {
+		if _, ok := o1[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		memberPath := path + "/" + escapePointer(name)
+		v1, ok1 := o1[name]
+		v2, ok2 := o2[name]
+		switch {
+		case !ok1:
+			changes = append(changes, Change{Type: Added, Path: memberPath, New: v2})
+		case !ok2:
+			changes = append(changes, Change{Type: Removed, Path: memberPath, Old: v1})
+		default:
+			changes = compare(changes, memberPath, v1, v2)
+		}
+	}
+	return changes
+}
+
+// compareArrays appends the differences between the elements of two arrays at path to changes.
+func compareArrays(changes []Change, path string, a1, a2 []interface{}) []Change {
+	// Elements are diffed by their encodings.
+	indexes := map[string]int{}
+	toIndexes := func(a []interface{}) []int {
+		result := make([]int, len(a))
+		for i, v := range a {
+			key := encode(v, "")
+			index, ok := indexes[key]
+			if !ok {
+				index = len(indexes)
+				indexes[key] = index
+			}
+			result[i] = index
+		}
+		return result
+	}
+	indexes1 := toIndexes(a1)
+	indexes2 := toIndexes(a2)
+
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffMainIndexes(indexes1, indexes2)
+	i1, i2 := 0, 0
+	for d := 0; d < len(diffs); d++ {
+		n := len(diffs[d].Indexes)
+		switch diffs[d].Type {
+		case diffmatchpatch.DiffEqual:
+			i1 += n
+			i2 += n
+		case diffmatchpatch.DiffInsert:
+			for ; n > 0; n-- {
+				changes = append(changes, Change{Type: Added, Path: elementPath(path, i2), New: a2[i2]})
+				i2++
+			}
+		case diffmatchpatch.DiffDelete:
+			inserted := 0
+			if d+1 < len(diffs) && diffs[d+1].Type == diffmatchpatch.DiffInsert {
+				inserted = len(diffs[d+1].Indexes)
+				d++
+			}
+			// Pair removed and added elements which are containers of the same kind.
+			for k := 0; k < n || k < inserted; k++ {
+				switch {
+				case k < n && k < inserted && sameContainer(a1[i1], a2[i2]):
+					changes = compare(changes, elementPath(path, i2), a1[i1], a2[i2])
+					i1++
+					i2++
+				case k < n && k < inserted:
+					changes = append(changes, Change{Type: Replaced, Path: elementPath(path, i2), Old: a1[i1], New: a2[i2]})
+					i1++
+					i2++
+				case k < n:
+					changes = append(changes, Change{Type: Removed, Path: elementPath(path, i1), Old: a1[i1]})
+					i1++
+				default:
+					changes = append(changes, Change{Type: Added, Path: elementPath(path, i2), New: a2[i2]})
+					i2++
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// sameContainer returns whether two values are both objects or both arrays.
+func sameContainer(v1, v2 interface{}) bool {
+	switch v1.(type) {
+	case map[string]interface{}:
+		_, ok := v2.(map[string]interface{})
+		return ok
+	case []interface{}:
+		_, ok := v2.([]interface{})
+		return ok
+	}
+	return false
+}
+
+// elementPath returns the path of the i-th element of the array at path.
+func elementPath(path string, i int) string {
+	return path + "/" + strconv.Itoa(i)
+}
+
+// escapePointer escapes a member name for a JSON Pointer.
+func escapePointer(name string) string {
+	return strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+}
+
+// TextDiff parses two JSON documents and returns a line diff of their indented encodings, with the members of objects sorted by their names, so that formatting and member order do not show up as changes.
+// Every line is prefixed with "-" if only the first document has it, "+" if only the second one has it, and " " otherwise.
+func TextDiff(doc1, doc2 []byte) (string, error) {
+	v1, v2, err := decodeBoth(doc1, doc2)
+	if err != nil {
+		return "", err
+	}
+	dmp := diffmatchpatch.New()
+	lines1, lines2, lineArray := dmp.DiffLinesToIndexes(encode(v1, "  ")+"\n", encode(v2, "  ")+"\n")
+	var out strings.Builder
+	for _, aDiff := range dmp.DiffMainIndexes(lines1, lines2) {
+		prefix := " "
+		switch aDiff.Type {
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		}
+		for _, index := range aDiff.Indexes {
+			out.WriteString(prefix + lineArray[index])
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2012-2016 The go-diff authors. All rights reserved.
+// https://github.com/sergi/go-diff
+// See the included LICENSE file for license details.
+//
+// go-diff is a Go implementation of Google's Diff, Match, and Patch library
+// Original library is Copyright (c) 2006 Google Inc.
+// http://code.google.com/p/google-diff-match-patch/
+
+package jsondiff
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Doc1 string
+		Doc2 string
+
+		Expected []string
+	}
+
+	for i, tc := range []TestCase{
+		{"Equal", `{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`, []string{}},
+		{"Replaced scalar", `{"a": 1}`, `{"a": 2}`, []string{"Replaced /a: 1 -> 2"}},
+		{"Exact numbers", `{"a": 1.0}`, `{"a": 1.00}`, []string{"Replaced /a: 1.0 -> 1.00"}},
+		{"Added and removed members", `{"a": 1, "b": 2}`, `{"b": 2, "c": 3}`, []string{"Removed /a: 1", "Added /c: 3"}},
+		{"Nested object", `{"a": {"b": {"c": true}}}`, `{"a": {"b": {"c": false}}}`, []string{"Replaced /a/b/c: true -> false"}},
+		{"Changed type", `{"a": [1]}`, `{"a": {"0": 1}}`, []string{`Replaced /a: [1] -> {"0":1}`}},
+		{"Inserted element", `[1, 2, 3]`, `[1, 4, 2, 3]`, []string{"Added /1: 4"}},
+		{"Removed element", `[1, 2, 3]`, `[1, 3]`, []string{"Removed /1: 2"}},
+		{"Replaced element", `["a", "b", "c"]`, `["a", "x", "c"]`, []string{`Replaced /1: "b" -> "x"`}},
+		{"Changed object in array", `[{"id": 1, "v": "a"}, {"id": 2, "v": "b"}]`, `[{"id": 1, "v": "a"}, {"id": 2, "v": "c"}]`, []string{`Replaced /1/v: "b" -> "c"`}},
+		{"Shifted elements", `[1, 2, 3]`, `[0, 1, 2]`, []string{"Added /0: 0", "Removed /2: 3"}},
+		{"Escaped names", `{"a/b": 1, "c~d": 2}`, `{"a/b": 3, "c~d": 4}`, []string{"Replaced /a~1b: 1 -> 3", "Replaced /c~0d: 2 -> 4"}},
+		{"Root scalar", `"<x>"`, `null`, []string{`Replaced : "<x>" -> null`}},
+	} {
+		changes, err := Compare([]byte(tc.Doc1), []byte(tc.Doc2))
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		actual := []string{}
+		for _, change := range changes {
+			actual = append(actual, change.String())
+		}
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Doc1 string
+		Doc2 string
+
+		ExpectedError string
+	}
+
+	for i, tc := range []TestCase{
+		{"Invalid first document", `{"a":`, `{}`, "Invalid first JSON document: unexpected EOF"},
+		{"Invalid second document", `{}`, `{]`, "Invalid second JSON document: invalid character ']' looking for beginning of object key string"},
+		{"Trailing data", `{} {}`, `{}`, "Invalid first JSON document: Trailing data after JSON document"},
+	} {
+		_, err := Compare([]byte(tc.Doc1), []byte(tc.Doc2))
+		if assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name)) {
+			assert.Equal(t, tc.ExpectedError, err.Error(), fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		}
+		_, err = TextDiff([]byte(tc.Doc1), []byte(tc.Doc2))
+		assert.Error(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}
+
+func TestUnencodableValues(t *testing.T) {
+	// Values which JSON cannot encode are formatted by fmt.
+	assert.Equal(t, "Added /a: NaN", Change{Type: Added, Path: "/a", New: math.NaN()}.String())
+	assert.Equal(t, "Replaced /b: 1 -> +Inf", Change{Type: Replaced, Path: "/b", Old: 1, New: math.Inf(1)}.String())
+
+	changes := CompareValues([]interface{}{1.0, math.NaN()}, []interface{}{1.0, "x"})
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, `Replaced /1: NaN -> "x"`, changes[0].String())
+	}
+}
+
+func TestChangeType(t *testing.T) {
+	assert.Equal(t, "Added", Added.String())
+	assert.Equal(t, "Removed", Removed.String())
+	assert.Equal(t, "Replaced", Replaced.String())
+	assert.Equal(t, "ChangeType(7)", ChangeType(7).String())
+}
+
+func TestTextDiff(t *testing.T) {
+	type TestCase struct {
+		Name string
+
+		Doc1 string
+		Doc2 string
+
+		Expected string
+	}
+
+	for i, tc := range []TestCase{
+		{"Equal", `{"b":1,"a":"<x>"}`, `{"a": "<x>", "b": 1}`, " {\n   \"a\": \"<x>\",\n   \"b\": 1\n }\n"},
+		{"Changed member", `{"a": 1, "b": [1, 2]}`, `{"b": [1, 3], "a": 1}`, " {\n   \"a\": 1,\n   \"b\": [\n     1,\n-    2\n+    3\n   ]\n }\n"},
+	} {
+		actual, err := TextDiff([]byte(tc.Doc1), []byte(tc.Doc2))
+		assert.NoError(t, err, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+		assert.Equal(t, tc.Expected, actual, fmt.Sprintf("Test case #%d, %s", i, tc.Name))
+	}
+}